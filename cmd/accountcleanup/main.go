@@ -11,6 +11,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/cis"
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 	"github.com/vrischmann/envconfig"
 )
 
@@ -19,6 +20,7 @@ type Config struct {
 	CIS                  cis.Config
 	Database             storage.Config
 	Broker               broker.ClientConfig
+	Tracing              tracing.Config
 }
 
 func main() {
@@ -39,6 +41,9 @@ func main() {
 	err := envconfig.InitWithPrefix(&cfg, "APP")
 	fatalOnError(err)
 
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	fatalOnError(err)
+
 	// create CIS client
 	var client cis.CisClient
 	switch cfg.EventsServiceVersion {
@@ -68,7 +73,12 @@ func main() {
 
 	// create SubAccountCleanerService and execute process
 	sacs := cis.NewSubAccountCleanupService(client, brokerClient, db.Instances(), logger)
-	fatalOnError(sacs.Run())
+	err = sacs.Run()
+	// the spans are flushed before the job exits
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		slog.Warn(fmt.Sprintf("while shutting down tracing: %s", shutdownErr))
+	}
+	fatalOnError(err)
 }
 
 func fatalOnError(err error) {
@@ -7,6 +7,7 @@ import (
 
 	"k8s.io/client-go/dynamic"
 
+	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
@@ -60,6 +61,9 @@ func NewDeprovisioningProcessingQueue(ctx context.Context, workersAmount int, de
 			stages = append(stages, step.step.Name())
 		}
 	}
+	stepHooks := newStepHooks(cfg, logs)
+	stages, err := stepHooks.Stages(internal.OperationTypeDeprovision, stages)
+	fatalOnError(err, logs)
 	deprovisionManager.DefineStages(stages)
 	for _, step := range deprovisioningSteps {
 		if !step.disabled {
@@ -67,8 +71,9 @@ func NewDeprovisioningProcessingQueue(ctx context.Context, workersAmount int, de
 			fatalOnError(err, logs)
 		}
 	}
+	fatalOnError(stepHooks.AddSteps(deprovisionManager, internal.OperationTypeDeprovision, db.Operations()), logs)
 
-	queue := process.NewQueue(deprovisionManager, logs, "deprovisioning")
+	queue := newProcessingQueue(deprovisionManager, cfg, db, logs, "deprovisioning")
 	queue.Run(ctx.Done(), workersAmount)
 
 	return queue
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"crypto/fips140"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -18,11 +19,14 @@ import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/additionalproperties"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
+	"github.com/kyma-project/kyma-environment-broker/internal/authorization"
 	"github.com/kyma-project/kyma-environment-broker/internal/blocklist"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	brokerBindings "github.com/kyma-project/kyma-environment-broker/internal/broker/bindings"
 	kebConfig "github.com/kyma-project/kyma-environment-broker/internal/config"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
+	"github.com/kyma-project/kyma-environment-broker/internal/drift"
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
 	eventshandler "github.com/kyma-project/kyma-environment-broker/internal/events/handler"
@@ -30,17 +34,26 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/health"
 	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
 	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
+	"github.com/kyma-project/kyma-environment-broker/internal/instancetags"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
+	"github.com/kyma-project/kyma-environment-broker/internal/kymamodules"
+	"github.com/kyma-project/kyma-environment-broker/internal/kymastatus"
 	"github.com/kyma-project/kyma-environment-broker/internal/machinesavailability"
 	"github.com/kyma-project/kyma-environment-broker/internal/metrics"
+	"github.com/kyma-project/kyma-environment-broker/internal/operationretry"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/hooks"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/quota"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/kyma-project/kyma-environment-broker/internal/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/suspension"
 	"github.com/kyma-project/kyma-environment-broker/internal/swagger"
+	"github.com/kyma-project/kyma-environment-broker/internal/timeline"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
+	"github.com/kyma-project/kyma-environment-broker/internal/transfer"
 	"github.com/kyma-project/kyma-environment-broker/internal/version"
 	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
 	"github.com/kyma-project/kyma-environment-broker/internal/workers"
@@ -54,6 +67,7 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -110,6 +124,8 @@ type Config struct {
 	GvisorWhitelistedGlobalAccountsFilePath    string
 	OpenShellWhitelistedGlobalAccountsFilePath string
 	OperationBlocklistFilePath                 string `envconfig:"optional"`
+	OperationPriorityClassesFilePath           string `envconfig:"optional"`
+	StepHooksFilePath                          string `envconfig:"optional"`
 
 	DomainName string
 
@@ -120,6 +136,33 @@ type Config struct {
 
 	Events events.Config
 
+	// enables recording of every step run of operations in the step_executions table
+	StepExecutionsEnabled bool `envconfig:"default=false"`
+
+	DriftDetection drift.Config
+
+	SuspensionRecovery suspension.RecoveryConfig
+
+	ExpirationExtension expiration.ExtensionConfig
+
+	KymaModules kymamodules.Config
+
+	KymaStatus kymastatus.Config
+
+	Readiness health.Config
+
+	Tracing tracing.Config
+
+	Audit audit.Config
+
+	Authorization authorization.Config
+
+	InstanceTransfer transfer.Config
+
+	OperationRetry operationretry.Config
+
+	InstanceTags instancetags.Config
+
 	Metrics metrics.Config
 
 	Provisioning   process.StagedManagerConfiguration
@@ -138,6 +181,8 @@ type Config struct {
 
 	PlansConfigurationFilePath string
 
+	ResidencyPolicyFilePath string `envconfig:"optional"`
+
 	Quota                               quota.Config
 	QuotaWhitelistedSubaccountsFilePath string
 
@@ -262,6 +307,10 @@ func main() {
 		fatalOnError(fmt.Errorf("AvailablePlans is not initialized properly"), log)
 	}
 
+	// plans declared in the plans configuration must be registered before the enabled plans are validated
+	plansSpec, err := broker.RegisterPlansFromFile(cfg.PlansConfigurationFilePath)
+	fatalOnError(err, log)
+
 	err = cfg.Broker.Validate()
 	fatalOnError(err, log)
 	err = cfg.InfrastructureManager.Validate()
@@ -274,8 +323,12 @@ func main() {
 	log.Info(fmt.Sprintf("Access Control List enabled plans: %v", cfg.Broker.ACLEnabledPlans))
 	log.Info(fmt.Sprintf("Global Accounts configuration: %s", cfg.GlobalAccounts()))
 
-	log.Info("Registering healthz endpoint for health probes")
-	health.NewServer(cfg.Broker.Host, cfg.Broker.StatusPort, log).ServeAsync()
+	// the checkers are added when the dependencies are created, the broker is not ready until the configuration is loaded
+	readiness := health.NewReadiness(cfg.Readiness, prometheus.DefaultRegisterer, "kcp_keb_v2", log)
+	configurationGate := health.NewGate("configuration", "plans, providers and rules configuration is not loaded")
+	readiness.Add(configurationGate)
+	log.Info("Registering healthz and readyz endpoints for health probes")
+	health.NewServer(cfg.Broker.Host, cfg.Broker.StatusPort, log).WithReadiness(readiness).ServeAsync()
 	go periodicProfile(log, cfg.Profiler)
 
 	logConfiguration(log, cfg)
@@ -288,11 +341,22 @@ func main() {
 
 	log.Info(fmt.Sprintf("Synchronous update response enabled: %v", cfg.Broker.SyncEmptyUpdateResponseEnabled))
 
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	fatalOnError(err, log)
+	defer func() { _ = shutdownTracing(context.Background()) }()
+	log.Info(fmt.Sprintf("Tracing enabled: %t, endpoint: %s, sample ratio: %v", cfg.Tracing.Enabled, cfg.Tracing.Endpoint, cfg.Tracing.SampleRatio))
+
 	// create kubernetes client
 	kcpK8sConfig, err := config.GetConfig()
 	fatalOnError(err, log)
+	if cfg.Tracing.Enabled {
+		kcpK8sConfig.Wrap(tracing.WrapTransport)
+	}
 	kcpK8sClient, err := initClient(kcpK8sConfig)
 	fatalOnError(err, log)
+	kcpDiscovery, err := discovery.NewDiscoveryClientForConfig(kcpK8sConfig)
+	fatalOnError(err, log)
+	readiness.AddDependency(health.NewDiscoveryChecker("kcp", kcpDiscovery))
 	skrK8sClientProvider := kubeconfig.NewK8sClientFromSecretProvider(kcpK8sClient)
 
 	if cfg.Broker.MonitorAdditionalProperties {
@@ -310,6 +374,7 @@ func main() {
 		store, conn, err := storage.NewFromConfig(cfg.Database, cfg.Events, cipher)
 		fatalOnError(err, log)
 		db = store
+		readiness.Add(health.NewDatabaseChecker("database", conn))
 		dbStatsCollector := sqlstats.NewStatsCollector("broker", conn)
 		prometheus.MustRegister(dbStatsCollector)
 	}
@@ -327,6 +392,12 @@ func main() {
 		kebConfig.NewConfigMapConverter())
 	gardenerClusterConfig, err := gardener.NewGardenerClusterConfig(cfg.Gardener.KubeconfigPath)
 	fatalOnError(err, log)
+	if cfg.Tracing.Enabled {
+		gardenerClusterConfig.Wrap(tracing.WrapTransport)
+	}
+	gardenerDiscovery, err := discovery.NewDiscoveryClientForConfig(gardenerClusterConfig)
+	fatalOnError(err, log)
+	readiness.AddDependency(health.NewDiscoveryChecker("gardener", gardenerDiscovery))
 	cfg.Gardener.DNSProviders, err = gardener.ReadDNSProvidersValuesFromYAML(cfg.SkrDnsProvidersValuesYAMLFilePath)
 	fatalOnError(err, log)
 	dynamicGardener, err := dynamic.NewForConfig(gardenerClusterConfig)
@@ -343,14 +414,15 @@ func main() {
 
 	// metrics collectors
 	_ = metrics.Register(ctx, eventBroker, db, cfg.Metrics, gardenerClient, log)
+	if cfg.StepExecutionsEnabled {
+		eventBroker.Subscribe(process.OperationStepProcessed{}, process.NewStepExecutionRecorder(db.StepExecutions()).OnOperationStepProcessed)
+	}
 
 	rulesService, err := rules.NewRulesServiceFromFile(cfg.HapRuleFilePath, sets.New(broker.AvailablePlans.GetAllPlanNamesAsStrings()...), sets.New([]string(cfg.Broker.EnablePlans)...))
 	fatalOnError(err, log)
 
 	log.Info("Rules service configuration loaded successfully and valid")
 
-	plansSpec, err := configuration.NewPlanSpecificationsFromFile(cfg.PlansConfigurationFilePath)
-	fatalOnError(err, log)
 	for _, warning := range plansSpec.ValidateInternalOnlyMachines() {
 		log.Warn(warning)
 	}
@@ -359,6 +431,14 @@ func main() {
 	fatalOnError(providerSpec.ValidateZonesDiscovery(), log)
 	fatalOnError(providerSpec.ValidateMachinesVersions(), log)
 
+	residencyPolicies := residency.DefaultPolicies()
+	if cfg.ResidencyPolicyFilePath != "" {
+		residencyPolicies, err = residency.NewPoliciesFromFile(cfg.ResidencyPolicyFilePath)
+		fatalOnError(err, log)
+		fatalOnError(validateResidencyPolicies(residencyPolicies, providerSpec, plansSpec, rulesService), log)
+		log.Info(fmt.Sprintf("Residency policies loaded for platform regions: %s", strings.Join(residencyPolicies.PlatformRegions(), ", ")))
+	}
+
 	var kcrVolumeProvider *provider.KCRVolumeProvider
 	var volumeSizeProvider broker.VolumeSizeProvider
 	if cfg.Broker.DynamicVolumeSizeEnabled {
@@ -373,10 +453,15 @@ func main() {
 	channelResolver, err := kebConfig.NewChannelResolver(runtimeConfigProvider, broker.AvailablePlans.GetAllPlanNamesAsStrings(), log)
 	fatalOnError(err, log)
 
-	schemaService := broker.NewSchemaService(providerSpec, plansSpec, &oidcDefaultValues, cfg.Broker, cfg.InfrastructureManager.IngressFilteringPlans, channelResolver, volumeSizeProvider)
+	schemaService := broker.NewSchemaService(providerSpec, plansSpec, &oidcDefaultValues, cfg.Broker, cfg.InfrastructureManager.IngressFilteringPlans, channelResolver, volumeSizeProvider).
+		WithResidencyPolicies(residencyPolicies)
+	if cfg.Broker.MaintenanceInfoEnabled {
+		schemaService.WithMaintenanceInfo(cfg.InfrastructureManager.KubernetesVersion, cfg.Broker.MaintenanceInfoRevision)
+	}
 	fatalOnError(err, log)
 	fatalOnError(schemaService.Validate(), log)
 	log.Info("Plans and providers configuration is valid")
+	configurationGate.Open()
 	workersProvider := workers.NewProvider(cfg.InfrastructureManager, providerSpec, cfg.Broker.WorkerPoolLabelsAnnotationsEnabled)
 
 	factory := hyperscalers.NewFactory(providerSpec)
@@ -387,18 +472,40 @@ func main() {
 	// run queues
 	provisionManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.Broker.OperationTimeout, cfg.Provisioning, log.With("provisioning", "manager"))
 	provisionQueue := NewProvisioningProcessingQueue(ctx, provisionManager, cfg.Provisioning.WorkersAmount, &cfg, db, configProvider,
-		skrK8sClientProvider, kcpK8sClient, gardenerClient, oidcDefaultValues, log, rulesService, workersProvider, providerSpec, factory, kcrVolumeProvider)
+		skrK8sClientProvider, kcpK8sClient, gardenerClient, oidcDefaultValues, log, rulesService, workersProvider, providerSpec, factory, kcrVolumeProvider, residencyPolicies)
 
 	deprovisionManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.Broker.OperationTimeout, cfg.Deprovisioning, log.With("deprovisioning", "manager"))
 	deprovisionQueue := NewDeprovisioningProcessingQueue(ctx, cfg.Deprovisioning.WorkersAmount, deprovisionManager, &cfg, db,
 		skrK8sClientProvider, kcpK8sClient, configProvider, dynamicGardener, gardenerNamespace, log)
 
 	updateManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.Broker.OperationTimeout, cfg.Update, log.With("update", "manager"))
-	updateQueue := NewUpdateProcessingQueue(ctx, updateManager, cfg.Update.WorkersAmount, db, cfg, kcpK8sClient, log, workersProvider, schemaService, plansSpec, configProvider, providerSpec, gardenerClient, factory, kcrVolumeProvider)
+	updateQueue := NewUpdateProcessingQueue(ctx, updateManager, cfg.Update.WorkersAmount, db, cfg, kcpK8sClient, log, workersProvider, schemaService, plansSpec, configProvider, providerSpec, gardenerClient, factory, kcrVolumeProvider, residencyPolicies)
+	readiness.Add(
+		health.NewChecker("provisioning-queue", provisionQueue.CheckWorkers),
+		health.NewChecker("deprovisioning-queue", deprovisionQueue.CheckWorkers),
+		health.NewChecker("update-queue", updateQueue.CheckWorkers),
+	)
 	/***/
 	servicesConfig, err := broker.NewServicesConfigFromFile(cfg.CatalogFilePath)
 	fatalOnError(err, log)
 
+	if cfg.DriftDetection.Enabled {
+		driftDetector := drift.NewDetector(cfg.DriftDetection, db, kcpK8sClient, providerSpec, drift.NewMetrics(prometheus.DefaultRegisterer), log.With("service", "drift-detection"))
+		driftDetector.Start(ctx)
+		log.Info(fmt.Sprintf("Drift detection started with interval %s, reconcile back: %t", cfg.DriftDetection.Interval, cfg.DriftDetection.ReconcileBack))
+	}
+
+	var kymaStatusProvider broker.KymaStatusProvider
+	if cfg.KymaStatus.Enabled {
+		dynamicKcp, err := dynamic.NewForConfig(kcpK8sConfig)
+		fatalOnError(err, log)
+		kymaStatusInformer, err := kymastatus.NewInformer(dynamicKcp, cfg.KymaStatus, log)
+		fatalOnError(err, log)
+		fatalOnError(kymaStatusInformer.Start(ctx), log)
+		kymaStatusProvider = kymaStatusInformer
+		log.Info(fmt.Sprintf("Kyma status informer started in namespace %s, wait for Kyma Ready: %t", cfg.KymaStatus.Namespace, cfg.KymaStatus.WaitForReady))
+	}
+
 	// create kubeconfig builder
 	kcBuilder := kubeconfig.NewBuilder(kcpK8sClient, skrK8sClientProvider)
 
@@ -408,9 +515,25 @@ func main() {
 	// Apply panic recovery middleware to all HTTP endpoints
 	router.Use(httputil.PanicRecoveryMiddleware(log))
 
-	createAPI(router, schemaService, servicesConfig, &cfg, db, provisionQueue, deprovisionQueue, updateQueue, log,
+	if cfg.Authorization.Enabled {
+		fatalOnError(cfg.Authorization.Validate(), log)
+		authorizationPolicies, err := authorization.NewPoliciesFromFile(cfg.Authorization.PoliciesFilePath)
+		fatalOnError(err, log)
+		router.Use(authorization.NewAuthorizer(cfg.Authorization, authorizationPolicies, log).Middleware)
+		log.Info(fmt.Sprintf("Authorization enabled, issuer: %s, protected paths: %v", cfg.Authorization.IssuerURL, authorizationPolicies.ProtectedPaths))
+	}
+
+	if cfg.Audit.Enabled {
+		auditRecorder, err := audit.NewRecorder(cfg.Audit, db.AuditLog(), log)
+		fatalOnError(err, log)
+		// the recorder is applied after the authorizer, so it records the claims verified by the authorizer
+		router.Use(auditRecorder.Handler)
+		log.Info(fmt.Sprintf("Audit log enabled, file: %q", cfg.Audit.FilePath))
+	}
+
+	createAPI(ctx, router, schemaService, servicesConfig, &cfg, db, provisionQueue, deprovisionQueue, updateQueue, log,
 		kcBuilder, skrK8sClientProvider, skrK8sClientProvider, kcpK8sClient, eventBroker,
-		providerSpec, configProvider, plansSpec, rulesService, gardenerClient, factory)
+		providerSpec, configProvider, plansSpec, rulesService, gardenerClient, factory, kymaStatusProvider, residencyPolicies)
 
 	// create metrics endpoint
 	router.Handle("/metrics", promhttp.Handler())
@@ -444,7 +567,8 @@ func main() {
 	runtimeHandler := runtime.NewHandler(db, cfg.MaxPaginationPage,
 		cfg.Broker.DefaultRequestRegion,
 		kcpK8sClient,
-		log)
+		log).WithExpirationPeriods(cfg.ExpirationExtension.Periods()).
+		WithKymaStatus(kymaStatusProvider)
 	router.HandleFunc("/runtimes", runtimeHandler.GetRuntimes)
 
 	// create list requests with additional properties endpoint
@@ -454,17 +578,24 @@ func main() {
 	// create expiration endpoint
 	expirationHandler := expiration.NewHandler(db.Instances(), db.Operations(), deprovisionQueue, log)
 	expirationHandler.AttachRoutes(router)
+	if cfg.ExpirationExtension.Enabled {
+		expiration.NewExtensionHandler(cfg.ExpirationExtension, db.Instances(), db.Actions(), log).AttachRoutes(router)
+	}
 
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		http.StripPrefix("/", http.FileServer(http.Dir("/swagger"))).ServeHTTP(w, r)
 	})
 
+	if cfg.Audit.Enabled {
+		audit.NewHandler(db.AuditLog(), cfg.MaxPaginationPage, log).AttachRoutes(router)
+	}
+
 	svr := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		rec := httputil.NewResponseRecorder(w)
 		router.ServeHTTP(rec, r)
 		log.Info(fmt.Sprintf("Call handled: method=%s url=%s statusCode=%d size=%d", r.Method, r.URL.Path, rec.StatusCode, rec.Size))
 	})
-	fatalOnError(http.ListenAndServe(cfg.Broker.Host+":"+cfg.Broker.Port, svr), log)
+	fatalOnError(http.ListenAndServe(cfg.Broker.Host+":"+cfg.Broker.Port, tracing.NewHandler(svr, "kyma-environment-broker")), log)
 }
 
 func logConfiguration(logs *slog.Logger, cfg Config) {
@@ -494,11 +625,11 @@ func logConfiguration(logs *slog.Logger, cfg Config) {
 
 }
 
-func createAPI(router *httputil.Router, schemaService *broker.SchemaService, servicesConfig broker.ServicesConfig, cfg *Config, db storage.BrokerStorage,
+func createAPI(ctx context.Context, router *httputil.Router, schemaService *broker.SchemaService, servicesConfig broker.ServicesConfig, cfg *Config, db storage.BrokerStorage,
 	provisionQueue, deprovisionQueue, updateQueue *process.Queue, logs *slog.Logger, kcBuilder kubeconfig.KcBuilder, clientProvider K8sClientProvider,
 	kubeconfigProvider KubeconfigProvider, kcpK8sClient client.Client, publisher event.Publisher,
 	providerSpec *configuration.ProviderSpec, configProvider kebConfig.Provider, planSpec *configuration.PlanSpecifications, rulesService *rules.RulesService,
-	gardenerClient *gardener.Client, factory hyperscalers.Factory) {
+	gardenerClient *gardener.Client, factory hyperscalers.Factory, kymaStatusProvider broker.KymaStatusProvider, residencyPolicies *residency.Policies) {
 
 	if cfg.MachinesAvailabilityEndpoint {
 		machinesAvailability := machinesavailability.NewHandlerCB(providerSpec, rulesService, gardenerClient, factory, logs)
@@ -508,9 +639,16 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 	regions, err := provider.ReadPlatformRegionMappingFromFile(cfg.TrialRegionMappingFilePath)
 	fatalOnError(err, logs)
 	logs.Info(fmt.Sprintf("Platform region mapping for trial: %v", regions))
-	valuesProvider := provider.NewPlanSpecificValuesProvider(cfg.InfrastructureManager, regions, schemaService, planSpec)
-
-	suspensionCtxHandler := suspension.NewContextUpdateHandler(db.Operations(), provisionQueue, deprovisionQueue, logs)
+	valuesProvider := provider.NewPlanSpecificValuesProvider(cfg.InfrastructureManager, regions, schemaService, planSpec).
+		WithResidencyPolicies(residencyPolicies)
+
+	suspensionCtxHandler := suspension.NewContextUpdateHandler(db.Operations(), provisionQueue, deprovisionQueue, logs).
+		WithResidencyPolicies(residencyPolicies)
+	if cfg.SuspensionRecovery.Enabled {
+		suspensionRecovery := suspension.NewRecovery(cfg.SuspensionRecovery, db, suspensionCtxHandler, suspension.NewRecoveryMetrics(prometheus.DefaultRegisterer), logs.With("service", "suspension-recovery"))
+		suspensionRecovery.Start(ctx)
+		logs.Info(fmt.Sprintf("Suspension recovery started with interval %s, max attempts: %d", cfg.SuspensionRecovery.Interval, cfg.SuspensionRecovery.MaxAttempts))
+	}
 
 	defaultPlansConfig, err := servicesConfig.DefaultPlansConfig()
 	fatalOnError(err, logs)
@@ -523,7 +661,12 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 	fatalOnError(err, logs)
 	logs.Info(fmt.Sprintf("Number of globalAccountIds allowed for gvisor: %d", len(gvisorWhitelistedGlobalAccountIds)))
 
-	quotaClient := quota.NewClient(context.Background(), cfg.Quota, logs)
+	quotaLimits := quota.NoLimits()
+	if cfg.Quota.LimitsFilePath != "" {
+		quotaLimits, err = quota.NewLimitsFromFile(cfg.Quota.LimitsFilePath)
+		fatalOnError(err, logs)
+	}
+	quotaClient := quota.NewEnforcer(quota.NewCachedClient(quota.NewClient(context.Background(), cfg.Quota, logs), cfg.Quota.CacheTTL), db.Instances(), db.QuotaReservations(), quotaLimits)
 	quotaWhitelistedSubaccountIds, err := whitelist.ReadWhitelistedIdsFromFile(cfg.QuotaWhitelistedSubaccountsFilePath)
 	fatalOnError(err, logs)
 	logs.Info(fmt.Sprintf("Number of subaccountIds with unlimited quota: %d", len(quotaWhitelistedSubaccountIds)))
@@ -535,6 +678,16 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 	}
 	operationBlocklist, err = operationBlocklist.WithPlanValidator(broker.AvailablePlans)
 	fatalOnError(err, logs)
+	operationBlocklist = operationBlocklist.WithTagsProvider(db.InstanceTags())
+
+	var moduleCatalog *kymamodules.Catalog
+	var moduleValidator broker.ModuleValidator
+	if cfg.KymaModules.Enabled {
+		modulesSource, err := kymamodules.NewSource(cfg.KymaModules, kcpK8sClient)
+		fatalOnError(err, logs)
+		moduleCatalog = kymamodules.NewCatalog(cfg.KymaModules, modulesSource, logs).WithMetrics(prometheus.DefaultRegisterer)
+		moduleValidator = moduleCatalog
+	}
 
 	// create KymaEnvironmentBroker endpoints
 	kymaEnvBroker := &broker.KymaEnvironmentBroker{
@@ -544,15 +697,17 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 			freemiumGlobalAccountIds, gvisorWhitelistedGlobalAccountIds,
 			schemaService, providerSpec, planSpec, valuesProvider,
 			kebConfig.NewConfigMapConfigProvider(configProvider, cfg.Broker.GardenerSeedsCacheConfigMapName, kebConfig.ProviderConfigurationRequiredFields), quotaClient, quotaWhitelistedSubaccountIds,
-			rulesService, gardenerClient, factory, operationBlocklist),
+			rulesService, gardenerClient, factory, operationBlocklist, moduleValidator).
+			WithResidencyPolicies(residencyPolicies),
 		DeprovisionEndpoint: broker.NewDeprovision(db.Instances(), db.Operations(), deprovisionQueue, logs, operationBlocklist),
 		UpdateEndpoint: broker.NewUpdate(cfg.Broker, db,
 			suspensionCtxHandler, cfg.UpdateProcessingEnabled, cfg.Broker.SubaccountMovementEnabled, cfg.Broker.UpdateCustomResourcesLabelsOnAccountMove, updateQueue, defaultPlansConfig,
 			valuesProvider, logs, cfg.KymaDashboardConfig, kcBuilder, kcpK8sClient, providerSpec, planSpec, cfg.InfrastructureManager, schemaService, quotaClient,
 			quotaWhitelistedSubaccountIds, gvisorWhitelistedGlobalAccountIds,
-			rulesService, gardenerClient, factory, operationBlocklist),
-		GetInstanceEndpoint:          broker.NewGetInstance(cfg.Broker, db.Instances(), db.Operations(), kcBuilder, logs),
-		LastOperationEndpoint:        broker.NewLastOperation(db.Operations(), db.InstancesArchived(), logs),
+			rulesService, gardenerClient, factory, operationBlocklist).
+			WithResidencyPolicies(residencyPolicies),
+		GetInstanceEndpoint:          broker.NewGetInstance(cfg.Broker, db.Instances(), db.Operations(), kcBuilder, logs).WithKymaStatus(kymaStatusProvider),
+		LastOperationEndpoint:        broker.NewLastOperation(db.Operations(), db.InstancesArchived(), logs).WithKymaStatus(kymaStatusProvider),
 		BindEndpoint:                 broker.NewBind(cfg.Broker.Binding, db, logs, clientProvider, kubeconfigProvider, publisher),
 		UnbindEndpoint:               broker.NewUnbind(logs, db, brokerBindings.NewServiceAccountBindingsManager(clientProvider, kubeconfigProvider), publisher),
 		GetBindingEndpoint:           broker.NewGetBinding(logs, db),
@@ -572,10 +727,59 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 	eventsHandler := eventshandler.NewHandler(db.Events(), db.Instances())
 	router.Handle("/events", eventsHandler)
 
+	timeline.NewHandler(timeline.NewService(db), cfg.MaxPaginationPage, logs).AttachRoutes(router)
+
+	if cfg.Broker.CheckQuotaLimit {
+		quotaHandler := quota.NewHandler(quotaClient, db.Instances(), cfg.Broker.EnablePlans, quotaWhitelistedSubaccountIds, logs)
+		quotaHandler.AttachRoutes(router)
+	}
+
+	if cfg.InstanceTransfer.Enabled {
+		transferService := transfer.NewService(db, cfg.Broker, quotaClient, quotaWhitelistedSubaccountIds, freemiumGlobalAccountIds, rulesService,
+			broker.NewLabeler(kcpK8sClient), logs.With("service", "instance-transfer")).
+			WithResidencyPolicies(residencyPolicies)
+		transfer.NewHandler(transferService, logs).AttachRoutes(router)
+	}
+
+	if cfg.InstanceTags.Enabled {
+		instancetags.NewHandler(instancetags.NewService(db, logs.With("service", "instance-tags")), logs).AttachRoutes(router)
+	}
+
+	if moduleCatalog != nil {
+		kymamodules.NewHandler(moduleCatalog, logs).AttachRoutes(router)
+	}
+
+	if cfg.OperationRetry.Enabled {
+		retryService := operationretry.NewService(db, provisionQueue, updateQueue, cfg.Broker.OperationTimeout, logs.With("service", "operation-retry"))
+		operationretry.NewHandler(retryService, logs).AttachRoutes(router)
+	}
+
 	versionHandler := version.NewHandler(Version)
 	versionHandler.AttachRoutes(router)
 }
 
+// creates the processing queue, which shares the workers between the priority classes of operations if they are configured
+func newProcessingQueue(executor process.Executor, cfg *Config, db storage.BrokerStorage, logs *slog.Logger, name string) *process.Queue {
+	if cfg.OperationPriorityClassesFilePath == "" {
+		return process.NewQueue(executor, logs, name)
+	}
+	classes, err := process.NewPriorityClassesFromFile(cfg.OperationPriorityClassesFilePath, func(planName string) (string, bool) {
+		planID, found := broker.AvailablePlans.GetPlanIDByName(broker.PlanNameType(planName))
+		return string(planID), found
+	})
+	fatalOnError(err, logs)
+	return process.NewPriorityQueue(executor, logs, name, classes, process.NewOperationClassifier(db.Operations(), classes, logs))
+}
+
+func newStepHooks(cfg *Config, logs *slog.Logger) *hooks.Hooks {
+	if cfg.StepHooksFilePath == "" {
+		return &hooks.Hooks{}
+	}
+	stepHooks, err := hooks.NewHooksFromFile(cfg.StepHooksFilePath)
+	fatalOnError(err, logs)
+	return stepHooks
+}
+
 // queues all in progress operations by type
 func processOperationsInProgressByType(opType internal.OperationType, op storage.Operations, queue *process.Queue, log *slog.Logger) error {
 	operations, err := op.GetNotFinishedOperationsByType(opType)
@@ -589,6 +793,17 @@ func processOperationsInProgressByType(opType internal.OperationType, op storage
 	return nil
 }
 
+func validateResidencyPolicies(policies *residency.Policies, providerSpec *configuration.ProviderSpec, plansSpec *configuration.PlanSpecifications, rulesService *rules.RulesService) error {
+	var errs []error
+	errs = append(errs, policies.ValidateProviders(providerSpec)...)
+	errs = append(errs, policies.ValidatePlans(plansSpec, broker.PlanCloudProviders())...)
+	errs = append(errs, policies.ValidateHAPRules(rulesService, broker.PlanCloudProviders())...)
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid residency policy configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
 func initClient(cfg *rest.Config) (client.Client, error) {
 	httpClient, err := rest.HTTPClientFor(cfg)
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/process/steps"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/workers"
 
@@ -32,7 +33,8 @@ const (
 func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *process.StagedManager, workersAmount int, cfg *Config,
 	db storage.BrokerStorage, configProvider config.Provider,
 	k8sClientProvider provisioning.K8sClientProvider, k8sClient client.Client, gardenerClient *gardener.Client, defaultOIDC pkg.OIDCConfigDTO, logs *slog.Logger, rulesService *rules.RulesService,
-	workersProvider *workers.Provider, providerSpec *configuration.ProviderSpec, factory hyperscalers.Factory, kcrVolumeProvider *provider.KCRVolumeProvider) *process.Queue {
+	workersProvider *workers.Provider, providerSpec *configuration.ProviderSpec, factory hyperscalers.Factory, kcrVolumeProvider *provider.KCRVolumeProvider,
+	residencyPolicies *residency.Policies) *process.Queue {
 
 	provisioningSteps := []struct {
 		disabled  bool
@@ -49,7 +51,8 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 			step: provisioning.NewOverrideKymaModules(db.Operations()),
 		},
 		{
-			step: provisioning.NewResolveCredentialsBindingStep(db, gardenerClient, rulesService, internal.RetryTuple{Timeout: resolveSubscriptionSecretTimeout, Interval: resolveSubscriptionSecretRetryInterval}, &cfg.HapMultiHyperscalerAccount),
+			step: provisioning.NewResolveCredentialsBindingStep(db, gardenerClient, rulesService, internal.RetryTuple{Timeout: resolveSubscriptionSecretTimeout, Interval: resolveSubscriptionSecretRetryInterval}, &cfg.HapMultiHyperscalerAccount).
+				WithResidencyPolicies(residencyPolicies),
 		},
 		{
 			step: steps.NewDiscoverAvailableZonesCBStep(db, providerSpec, gardenerClient, factory),
@@ -73,6 +76,10 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 		{
 			step: provisioning.NewApplyKymaStep(db.Operations(), k8sClient),
 		},
+		{
+			disabled: !cfg.KymaStatus.WaitForReady,
+			step:     provisioning.NewCheckKymaReadyStep(db.Operations(), k8sClient, cfg.KymaStatus.WaitForReadyTimeout),
+		},
 	}
 	var stages []string
 	for _, step := range provisioningSteps {
@@ -80,6 +87,9 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 			stages = append(stages, step.step.Name())
 		}
 	}
+	stepHooks := newStepHooks(cfg, logs)
+	stages, err := stepHooks.Stages(internal.OperationTypeProvision, stages)
+	fatalOnError(err, logs)
 	provisionManager.DefineStages(stages)
 	for _, step := range provisioningSteps {
 		if !step.disabled {
@@ -89,8 +99,9 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 			}
 		}
 	}
+	fatalOnError(stepHooks.AddSteps(provisionManager, internal.OperationTypeProvision, db.Operations()), logs)
 
-	queue := process.NewQueue(provisionManager, logs, "provisioning")
+	queue := newProcessingQueue(provisionManager, cfg, db, logs, "provisioning")
 	queue.Run(ctx.Done(), workersAmount)
 
 	return queue
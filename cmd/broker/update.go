@@ -14,6 +14,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/process/update"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/workers"
 
@@ -22,15 +23,20 @@ import (
 
 func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManager, workersAmount int, db storage.BrokerStorage,
 	cfg Config, kcpClient client.Client, logs *slog.Logger, workersProvider *workers.Provider, schemaService *broker.SchemaService, planSpec *configuration.PlanSpecifications, configProvider config.Provider,
-	providerSpec *configuration.ProviderSpec, gardenerClient *gardener.Client, factory hyperscalers.Factory, kcrVolumeProvider *provider.KCRVolumeProvider) *process.Queue {
+	providerSpec *configuration.ProviderSpec, gardenerClient *gardener.Client, factory hyperscalers.Factory, kcrVolumeProvider *provider.KCRVolumeProvider,
+	residencyPolicies *residency.Policies) *process.Queue {
 
 	regions, err := provider.ReadPlatformRegionMappingFromFile(cfg.TrialRegionMappingFilePath)
 	if err != nil {
 		fatalOnError(err, logs)
 	}
-	valuesProvider := provider.NewPlanSpecificValuesProvider(cfg.InfrastructureManager, regions, schemaService, planSpec)
+	valuesProvider := provider.NewPlanSpecificValuesProvider(cfg.InfrastructureManager, regions, schemaService, planSpec).
+		WithResidencyPolicies(residencyPolicies)
 
-	manager.DefineStages([]string{"cluster", "btp-operator", "btp-operator-check", "check", "runtime_resource", "check_runtime_resource", "kyma_resource"})
+	stepHooks := newStepHooks(&cfg, logs)
+	stages, err := stepHooks.Stages(internal.OperationTypeUpdate, []string{"cluster", "btp-operator", "btp-operator-check", "check", "runtime_resource", "check_runtime_resource", "kyma_resource"})
+	fatalOnError(err, logs)
+	manager.DefineStages(stages)
 	updateSteps := []struct {
 		disabled  bool
 		stage     string
@@ -67,7 +73,9 @@ func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManage
 			}
 		}
 	}
-	queue := process.NewQueue(manager, logs, "update-processing")
+	fatalOnError(stepHooks.AddSteps(manager, internal.OperationTypeUpdate, db.Operations()), logs)
+
+	queue := newProcessingQueue(manager, &cfg, db, logs, "update-processing")
 	queue.Run(ctx.Done(), workersAmount)
 
 	return queue
@@ -7,9 +7,12 @@ import (
 	"os"
 	"time"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/expiration"
+	"github.com/kyma-project/kyma-environment-broker/internal/instancetags"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
@@ -24,13 +27,27 @@ type BrokerClient interface {
 type instancePredicate func(internal.Instance) bool
 
 type Config struct {
-	Database         storage.Config
-	Broker           broker.ClientConfig
-	DryRun           bool          `envconfig:"default=true"`
-	ExpirationPeriod time.Duration `envconfig:"default=720h"` // 30 days
-	TestRun          bool          `envconfig:"default=false"`
-	TestSubaccountID string        `envconfig:"default=prow-keb-trial-suspension"`
-	PlanID           string        `envconfig:"default=7d55d31d-35ae-4438-bf13-6ffdfa107d9f"`
+	Database storage.Config
+	Broker   broker.ClientConfig
+	DryRun   bool `envconfig:"default=true"`
+	// TrialExpirationPeriod and FreeExpirationPeriod are the same periods as the ones used by KEB, the period of the plan is used
+	TrialExpirationPeriod time.Duration `envconfig:"default=336h"`
+	FreeExpirationPeriod  time.Duration `envconfig:"default=2160h"`
+	TestRun               bool          `envconfig:"default=false"`
+	TestSubaccountID      string        `envconfig:"default=prow-keb-trial-suspension"`
+	PlanID                string        `envconfig:"default=7d55d31d-35ae-4438-bf13-6ffdfa107d9f"`
+	// TagSelector limits the expired instances to the instances with tags selected by the label selector, e.g. "!keep-alive"
+	TagSelector string `envconfig:"optional"`
+	// NotificationPeriod is the time before the expiration when the instance is notified about it, 0 disables the notifications
+	NotificationPeriod time.Duration `envconfig:"default=0"`
+	// NotificationWebhookURL receives the notifications in addition to the events of the instance
+	NotificationWebhookURL string `envconfig:"optional"`
+	Events                 events.Config
+}
+
+// expirationPeriod returns the period after which the instances of the plan expire, false if the instances of the plan do not expire
+func (c Config) expirationPeriod() (time.Duration, bool) {
+	return expiration.Periods{Trial: c.TrialExpirationPeriod, Free: c.FreeExpirationPeriod}.ForPlan(c.PlanID)
 }
 
 type Result struct {
@@ -40,12 +57,15 @@ type Result struct {
 	suspensionsAcceptedCount int
 	onlyMarkedAsExpiredCount int
 	failuresCount            int
+	notificationsSentCount   int
 }
 
 type CleanupService struct {
 	cfg             Config
 	instanceStorage storage.Instances
+	actionStorage   storage.Actions
 	brokerClient    BrokerClient
+	notifier        Notifier
 }
 
 func newCleanupService(cfg Config, brokerClient BrokerClient, instances storage.Instances) *CleanupService {
@@ -56,6 +76,13 @@ func newCleanupService(cfg Config, brokerClient BrokerClient, instances storage.
 	}
 }
 
+// withNotifier enables the notifications about the upcoming expiration, the sent notifications are recorded as actions
+func (s *CleanupService) withNotifier(notifier Notifier, actions storage.Actions) *CleanupService {
+	s.notifier = notifier
+	s.actionStorage = actions
+	return s
+}
+
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
@@ -73,53 +100,87 @@ func main() {
 		slog.Info("Dry run only - no changes")
 	}
 
-	slog.Info(fmt.Sprintf("Expiration period: %+v", cfg.ExpirationPeriod))
+	slog.Info(fmt.Sprintf("Trial expiration period: %+v, free expiration period: %+v", cfg.TrialExpirationPeriod, cfg.FreeExpirationPeriod))
 	slog.Info(fmt.Sprintf("PlanID: %s", cfg.PlanID))
+	if cfg.TagSelector != "" {
+		slog.Info(fmt.Sprintf("Tag selector: %s", cfg.TagSelector))
+	}
 
 	ctx := context.Background()
 	brokerClient := broker.NewClient(ctx, cfg.Broker)
 
 	// create storage connection
 	cipher := storage.NewEncrypter(cfg.Database.SecretKey)
-	db, conn, err := storage.NewFromConfig(cfg.Database, events.Config{}, cipher)
+	db, conn, err := storage.NewFromConfig(cfg.Database, cfg.Events, cipher)
 	fatalOnError(err)
 	defer func() { _ = conn.Close() }()
 	svc := newCleanupService(cfg, brokerClient, db.Instances())
+	if cfg.NotificationPeriod > 0 {
+		slog.Info(fmt.Sprintf("Notification period: %+v", cfg.NotificationPeriod))
+		svc.withNotifier(newNotifier(cfg.NotificationWebhookURL), db.Actions())
+	}
 
 	result, err := svc.PerformCleanup()
 	fatalOnError(err)
 
 	slog.Info(fmt.Sprintf(
-		"Instances: %+v, to expire: %+v, left non-expired: %+v, suspension under way: %+v, just marked expired: %+v, failures: %+v",
+		"Instances: %+v, to expire: %+v, left non-expired: %+v, suspension under way: %+v, just marked expired: %+v, failures: %+v, notifications sent: %+v",
 		result.count,
 		result.instancesToExpireCount,
 		result.instancesToBeLeftCount,
 		result.suspensionsAcceptedCount,
 		result.onlyMarkedAsExpiredCount,
 		result.failuresCount,
+		result.notificationsSentCount,
 	))
 
 	slog.Info("Expirator job finished successfully!")
 }
 
 func (s *CleanupService) PerformCleanup() (Result, error) {
+	expirationPeriod, expiring := s.cfg.expirationPeriod()
+	if !expiring {
+		return Result{}, fmt.Errorf("the instances of plan %s do not expire", s.cfg.PlanID)
+	}
 	filter := dbmodel.InstanceFilter{PlanIDs: []string{s.cfg.PlanID}, Expired: ptr.Bool(false)}
 	if s.cfg.TestRun {
 		filter.SubAccountIDs = []string{s.cfg.TestSubaccountID}
 	}
+	if s.cfg.TagSelector != "" {
+		selector, err := instancetags.ParseSelector(s.cfg.TagSelector)
+		if err != nil {
+			return Result{}, err
+		}
+		filter.TagSelector = selector
+	}
 	instances, count, err := s.getInstances(filter)
 
 	if err != nil {
 		return Result{}, fmt.Errorf("while getting instances: %s", err)
 	}
 
+	// the expiration time set for the instance overrides the expiration period
 	instancesToExpire, instancesToExpireCount := s.filterInstances(
 		instances,
-		func(instance internal.Instance) bool { return time.Since(instance.CreatedAt) >= s.cfg.ExpirationPeriod },
+		func(instance internal.Instance) bool {
+			return !time.Now().Before(instance.ExpirationTime(expirationPeriod))
+		},
 	)
 
 	instancesToBeLeftCount := count - instancesToExpireCount
 
+	var notificationsSentCount int
+	if s.notifier != nil {
+		instancesToNotify, _ := s.filterInstances(
+			instances,
+			func(instance internal.Instance) bool {
+				expiresIn := time.Until(instance.ExpirationTime(expirationPeriod))
+				return expiresIn > 0 && expiresIn <= s.cfg.NotificationPeriod
+			},
+		)
+		notificationsSentCount = s.notifyInstances(instancesToNotify, expirationPeriod)
+	}
+
 	if s.cfg.DryRun {
 		s.logInstances(instancesToExpire)
 		return Result{
@@ -129,6 +190,7 @@ func (s *CleanupService) PerformCleanup() (Result, error) {
 			suspensionsAcceptedCount: 0,
 			onlyMarkedAsExpiredCount: 0,
 			failuresCount:            0,
+			notificationsSentCount:   notificationsSentCount,
 		}, nil
 	}
 
@@ -141,6 +203,7 @@ func (s *CleanupService) PerformCleanup() (Result, error) {
 		suspensionsAcceptedCount: suspensionsAcceptedCount,
 		onlyMarkedAsExpiredCount: onlyMarkedAsExpiredCount,
 		failuresCount:            failuresCount,
+		notificationsSentCount:   notificationsSentCount,
 	}, nil
 }
 
@@ -185,6 +248,50 @@ func (s *CleanupService) cleanupInstances(instances []internal.Instance) (int, i
 	return suspensionAccepted, onlyExpirationMarked, failures
 }
 
+// notifyInstances notifies the instances about the upcoming expiration once for every expiration time, so the instance
+// with the extended expiration is notified again before the new expiration time
+func (s *CleanupService) notifyInstances(instances []internal.Instance, expirationPeriod time.Duration) int {
+	var notified int
+	for _, instance := range instances {
+		expiresAt := instance.ExpirationTime(expirationPeriod).UTC()
+		sent, err := s.isNotified(instance.InstanceID, expiresAt)
+		if err != nil {
+			slog.Error(fmt.Sprintf("while checking notifications for instanceID: %s, error: %s", instance.InstanceID, err))
+			continue
+		}
+		if sent {
+			continue
+		}
+		if s.cfg.DryRun {
+			slog.Info(fmt.Sprintf("instanceId: %s expires at %s, notification not sent in dry run mode", instance.InstanceID, expiresAt.Format(time.RFC3339)))
+			continue
+		}
+		if err := s.notifier.Notify(instance, expiresAt); err != nil {
+			slog.Error(fmt.Sprintf("while sending expiration notification for instanceID: %s, error: %s", instance.InstanceID, err))
+			continue
+		}
+		message := fmt.Sprintf("Notified about the expiration in %s", time.Until(expiresAt).Round(time.Hour))
+		if err := s.actionStorage.InsertAction(pkg.ExpirationNotificationActionType, instance.InstanceID, message, "", expiresAt.Format(time.RFC3339)); err != nil {
+			slog.Error(fmt.Sprintf("while inserting action %q for instance ID %s: %v", pkg.ExpirationNotificationActionType, instance.InstanceID, err))
+		}
+		notified++
+	}
+	return notified
+}
+
+func (s *CleanupService) isNotified(instanceID string, expiresAt time.Time) (bool, error) {
+	actions, err := s.actionStorage.ListActionsByInstanceID(instanceID)
+	if err != nil {
+		return false, err
+	}
+	for _, action := range actions {
+		if action.Type == pkg.ExpirationNotificationActionType && action.NewValue == expiresAt.Format(time.RFC3339) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (s *CleanupService) logInstances(instances []internal.Instance) {
 	for _, instance := range instances {
 		slog.Info(fmt.Sprintf("instanceId: %+v createdAt: %+v (%.0f days ago) servicePlanID: %+v servicePlanName: %+v",
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
@@ -48,6 +49,7 @@ func TestMain(m *testing.M) {
 func TestCleanup(t *testing.T) {
 	for tn, tc := range map[string]struct {
 		modifyInstance func(*internal.Instance)
+		tags           map[string]*string
 		config         Config
 		expectedResult Result
 	}{
@@ -96,11 +98,59 @@ func TestCleanup(t *testing.T) {
 				failuresCount:            0,
 			},
 		},
+		"should expire instance selected by tags": {
+			modifyInstance: func(i *internal.Instance) {},
+			tags:           map[string]*string{"customer-tier": ptr.String("free")},
+			config: Config{
+				PlanID:      broker.TrialPlanID,
+				TagSelector: "customer-tier=free,!keep-alive",
+			},
+			expectedResult: Result{
+				count:                    1,
+				instancesToExpireCount:   1,
+				instancesToBeLeftCount:   0,
+				suspensionsAcceptedCount: 0,
+				onlyMarkedAsExpiredCount: 1,
+				failuresCount:            0,
+			},
+		},
+		"should not expire instance excluded by tags": {
+			modifyInstance: func(i *internal.Instance) {},
+			tags:           map[string]*string{"keep-alive": ptr.String("true")},
+			config: Config{
+				PlanID:      broker.TrialPlanID,
+				TagSelector: "!keep-alive",
+			},
+			expectedResult: Result{
+				count:                    0,
+				instancesToExpireCount:   0,
+				instancesToBeLeftCount:   0,
+				suspensionsAcceptedCount: 0,
+				onlyMarkedAsExpiredCount: 0,
+				failuresCount:            0,
+			},
+		},
+		"should not expire instance with extended expiration": {
+			modifyInstance: func(i *internal.Instance) {
+				i.ExpiresAt = ptr.Time(time.Now().Add(24 * time.Hour))
+			},
+			config: Config{
+				PlanID: broker.TrialPlanID,
+			},
+			expectedResult: Result{
+				count:                    1,
+				instancesToExpireCount:   0,
+				instancesToBeLeftCount:   1,
+				suspensionsAcceptedCount: 0,
+				onlyMarkedAsExpiredCount: 0,
+				failuresCount:            0,
+			},
+		},
 		"should not expire instance before expiration period": {
 			modifyInstance: func(i *internal.Instance) {},
 			config: Config{
-				PlanID:           broker.TrialPlanID,
-				ExpirationPeriod: 1 * time.Hour,
+				PlanID:                broker.TrialPlanID,
+				TrialExpirationPeriod: 1 * time.Hour,
 			},
 			expectedResult: Result{
 				count:                    1,
@@ -132,6 +182,10 @@ func TestCleanup(t *testing.T) {
 			err = db.Instances().UpdateInstanceLastOperation("i1", "o1")
 			require.NoError(t, err)
 
+			if tc.tags != nil {
+				require.NoError(t, db.InstanceTags().Patch("i1", tc.tags))
+			}
+
 			svc := newCleanupService(
 				tc.config,
 				&mockBrokerClient{},
@@ -145,6 +199,65 @@ func TestCleanup(t *testing.T) {
 	}
 }
 
+func TestCleanup_Notifications(t *testing.T) {
+	// given
+	storageCleanup, db, err := storage.GetStorageForTests(expiratorTestConfig())
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, storageCleanup())
+	}()
+
+	soon := fixture.FixInstance("expires-soon")
+	soon.ServicePlanID = broker.TrialPlanID
+	soon.CreatedAt = time.Now().Add(-23 * time.Hour)
+	require.NoError(t, db.Instances().Insert(soon))
+
+	later := fixture.FixInstance("expires-later")
+	later.ServicePlanID = broker.TrialPlanID
+	later.CreatedAt = time.Now()
+	require.NoError(t, db.Instances().Insert(later))
+
+	notifier := &mockNotifier{}
+	svc := newCleanupService(
+		Config{PlanID: broker.TrialPlanID, TrialExpirationPeriod: 24 * time.Hour, NotificationPeriod: 2 * time.Hour},
+		&mockBrokerClient{},
+		db.Instances(),
+	).withNotifier(notifier, db.Actions())
+
+	// when
+	result, err := svc.PerformCleanup()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.notificationsSentCount)
+	assert.Equal(t, []string{"expires-soon"}, notifier.notified)
+	actions, err := db.Actions().ListActionsByInstanceID("expires-soon")
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, pkg.ExpirationNotificationActionType, actions[0].Type)
+
+	// when
+	result, err = svc.PerformCleanup()
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, result.notificationsSentCount)
+	assert.Len(t, notifier.notified, 1)
+
+	// when the expiration is extended, the instance is notified again
+	extended, err := db.Instances().GetByID("expires-soon")
+	require.NoError(t, err)
+	extended.ExpiresAt = ptr.Time(time.Now().Add(time.Hour))
+	_, err = db.Instances().Update(*extended)
+	require.NoError(t, err)
+	result, err = svc.PerformCleanup()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.notificationsSentCount)
+	assert.Len(t, notifier.notified, 2)
+}
+
 func expiratorTestConfig() storage.Config {
 	return storage.Config{
 		Host:            "localhost",
@@ -165,3 +278,12 @@ type mockBrokerClient struct{}
 func (m *mockBrokerClient) SendExpirationRequest(_ internal.Instance) (bool, error) {
 	return false, nil
 }
+
+type mockNotifier struct {
+	notified []string
+}
+
+func (m *mockNotifier) Notify(instance internal.Instance, _ time.Time) error {
+	m.notified = append(m.notified, instance.InstanceID)
+	return nil
+}
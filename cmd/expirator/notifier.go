@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+)
+
+const notificationTimeout = 10 * time.Second
+
+type Notifier interface {
+	Notify(instance internal.Instance, expiresAt time.Time) error
+}
+
+// expirationNotification is the body sent to the notification webhook
+type expirationNotification struct {
+	InstanceID      string    `json:"instanceID"`
+	GlobalAccountID string    `json:"globalAccountID"`
+	SubAccountID    string    `json:"subAccountID"`
+	PlanName        string    `json:"planName"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+}
+
+// notifier records the upcoming expiration as an event of the instance and sends it to the webhook, if configured
+type notifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+func newNotifier(webhookURL string) *notifier {
+	return &notifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: notificationTimeout},
+	}
+}
+
+func (n *notifier) Notify(instance internal.Instance, expiresAt time.Time) error {
+	events.Infof(instance.InstanceID, "", "Instance expires at %s", expiresAt.UTC().Format(time.RFC3339))
+	if n.webhookURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(expirationNotification{
+		InstanceID:      instance.InstanceID,
+		GlobalAccountID: instance.GlobalAccountID,
+		SubAccountID:    instance.SubAccountID,
+		PlanName:        instance.ServicePlanName,
+		ExpiresAt:       expiresAt.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("while marshaling notification: %w", err)
+	}
+	response, err := n.client.Post(n.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("while sending notification: %w", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("notification webhook returned %s: %s", response.Status, string(responseBody))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier(t *testing.T) {
+	t.Run("should send notification to webhook", func(t *testing.T) {
+		// given
+		var received expirationNotification
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+		instance := fixture.FixInstance("instance-id")
+		expiresAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+		// when
+		err := newNotifier(server.URL).Notify(instance, expiresAt)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "instance-id", received.InstanceID)
+		assert.Equal(t, instance.SubAccountID, received.SubAccountID)
+		assert.True(t, expiresAt.Equal(received.ExpiresAt))
+	})
+
+	t.Run("should return error when webhook fails", func(t *testing.T) {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		// when
+		err := newNotifier(server.URL).Notify(fixture.FixInstance("instance-id"), time.Now())
+
+		// then
+		assert.ErrorContains(t, err, "503")
+	})
+
+	t.Run("should only record event without webhook", func(t *testing.T) {
+		// when
+		err := newNotifier("").Notify(fixture.FixInstance("instance-id"), time.Now())
+
+		// then
+		assert.NoError(t, err)
+	})
+}
@@ -31,8 +31,9 @@ type Config struct {
 		Name     string `envconfig:"default=broker"`
 		SSLMode  string `envconfig:"default=disable"`
 	}
-	Port            string        `envconfig:"default=8080"`
-	RefreshInterval time.Duration `envconfig:"default=1h"`
+	Port                       string        `envconfig:"default=8080"`
+	RefreshInterval            time.Duration `envconfig:"default=1h"`
+	PlansConfigurationFilePath string        `envconfig:"optional"`
 }
 
 type cache struct {
@@ -79,7 +80,14 @@ func main() {
 
 	reader := analytics.NewDBReader(conn.NewSession(nil))
 
-	// Build planID → planName lookup from broker constants.
+	if cfg.PlansConfigurationFilePath != "" {
+		if _, err := broker.RegisterPlansFromFile(cfg.PlansConfigurationFilePath); err != nil {
+			slog.Error("failed to register plans", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Build planID → planName lookup from broker constants and declared plans.
 	planIDToName := make(map[string]string, len(broker.PlanIDsMapping))
 	for name, id := range broker.PlanIDsMapping {
 		planIDToName[string(id)] = string(name)
@@ -204,6 +212,26 @@ func main() {
 		}
 	})
 
+	mux.HandleFunc("/api/steps", func(w http.ResponseWriter, r *http.Request) {
+		tr, err := parseTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := reader.FetchStepStatsInRange(tr)
+		if err != nil {
+			slog.Error("failed to fetch step stats", "error", err)
+			http.Error(w, "failed to build step stats", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(data); err != nil {
+			slog.Error("failed to encode step stats", "error", err)
+		}
+	})
+
 	mux.HandleFunc("/api/refresh", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/export"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+
+	"github.com/vrischmann/envconfig"
+)
+
+const connectionRetries = 10
+
+type Config struct {
+	Database        storage.Config
+	OutputDirectory string `envconfig:"default=/export"`
+	BatchSize       int    `envconfig:"default=1000"`
+	// Tables is a comma-separated list of the exported tables, all tables are exported if empty
+	Tables string `envconfig:"optional"`
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	slog.SetDefault(logger)
+
+	slog.Info("Starting KEB export job")
+
+	var cfg Config
+	err := envconfig.InitWithPrefix(&cfg, "APP")
+	fatalOnError(err)
+	if cfg.BatchSize <= 0 {
+		fatalOnError(fmt.Errorf("batch size must be greater than 0"))
+	}
+
+	var names []string
+	if cfg.Tables != "" {
+		names = strings.Split(cfg.Tables, ",")
+	}
+	tables, err := export.SelectTables(names)
+	fatalOnError(err)
+
+	connection, err := postsql.InitializeDatabase(cfg.Database.ConnectionURL(), connectionRetries)
+	fatalOnError(err)
+	defer func() { _ = connection.Close() }()
+
+	exporter := export.NewExporter(export.NewDBReader(connection.NewSession(nil)), export.Config{
+		OutputDirectory: cfg.OutputDirectory,
+		BatchSize:       cfg.BatchSize,
+	}, logger)
+	exported, err := exporter.Export(tables)
+	for table, count := range exported {
+		slog.Info(fmt.Sprintf("Table %s: %d rows exported", table, count))
+	}
+	fatalOnError(err)
+
+	slog.Info("KEB export job finished successfully")
+}
+
+func fatalOnError(err error) {
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/additionalproperties"
+	"github.com/spf13/cobra"
+)
+
+type AdditionalPropertiesCommand struct {
+	cobraCmd    *cobra.Command
+	path        string
+	requestType string
+	output      string
+}
+
+func NewAdditionalPropertiesCmd() *cobra.Command {
+	cmd := AdditionalPropertiesCommand{}
+	cobraCmd := &cobra.Command{
+		Use:     "additional-properties",
+		Aliases: []string{"ap"},
+		Short:   "Reports the additional properties of the provisioning and update requests.",
+		Long: "Groups the properties which are not supported by KEB by path, plan, and global account, based on the requests files written with the additional properties monitoring enabled. " +
+			"The global accounts in the report would get an error if the unsupported parameters were rejected.",
+		Example: `
+	# Report the additional properties of the provisioning requests copied from the KEB Pod
+	hap additional-properties -d ./additional-properties -t provisioning
+
+	# Report the additional properties of the update requests in JSON
+	hap additional-properties -d ./additional-properties -t update -o json
+		`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmd.Run()
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	cmd.cobraCmd = cobraCmd
+
+	cobraCmd.Flags().StringVarP(&cmd.path, "dir", "d", ".", "The directory with the provisioning-requests.jsonl and update-requests.jsonl files.")
+	cobraCmd.Flags().StringVarP(&cmd.requestType, "type", "t", additionalproperties.RequestTypeProvisioning, "The request type: provisioning or update.")
+	cobraCmd.Flags().StringVarP(&cmd.output, "output", "o", "table", "The output format: table or json.")
+
+	return cobraCmd
+}
+
+func (cmd *AdditionalPropertiesCommand) Run() error {
+	report, err := additionalproperties.AnalyzeFile(cmd.path, cmd.requestType)
+	if err != nil {
+		cmd.cobraCmd.Printf("Error: %s\n", err)
+		return ErrUsage
+	}
+
+	switch cmd.output {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("while marshaling the report: %w", err)
+		}
+		cmd.cobraCmd.Println(string(data))
+	case "table":
+		cmd.printTable(report)
+	default:
+		cmd.cobraCmd.Printf("Error: unsupported output format %q, supported formats are table and json\n", cmd.output)
+		return ErrUsage
+	}
+	return nil
+}
+
+func (cmd *AdditionalPropertiesCommand) printTable(report additionalproperties.Report) {
+	cmd.cobraCmd.Printf("Requests: %d, invalid lines: %d\n\n", report.Requests, report.InvalidLines)
+	if len(report.Properties) == 0 {
+		cmd.cobraCmd.Printf("No additional properties found.\n")
+		return
+	}
+
+	w := tabwriter.NewWriter(cmd.cobraCmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PATH\tREQUESTS\tINSTANCES\tGLOBAL ACCOUNTS\tPLANS\tFIRST SEEN\tLAST SEEN")
+	for _, p := range report.Properties {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\t%s\n", p.Path, p.Requests, p.Instances, len(p.GlobalAccounts),
+			strings.Join(p.Plans, ","), formatTime(p.FirstSeen), formatTime(p.LastSeen))
+	}
+	_ = w.Flush()
+
+	cmd.cobraCmd.Printf("\nGlobal accounts which would get an error if the unsupported parameters were rejected: %d\n\n", len(report.GlobalAccounts))
+	w = tabwriter.NewWriter(cmd.cobraCmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "GLOBAL ACCOUNT\tREQUESTS\tINSTANCES\tPATHS\tLAST SEEN")
+	for _, ga := range report.GlobalAccounts {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", ga.GlobalAccountID, ga.Requests, len(ga.Instances), strings.Join(ga.Paths, ","), formatTime(ga.LastSeen))
+	}
+	_ = w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
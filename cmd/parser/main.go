@@ -17,7 +17,7 @@ func main() {
 
 	rootCmd = &cobra.Command{
 		Use:           "hap",
-		Short:         "A tool for parsing and validation of HAP rules",
+		Short:         "A tool for parsing and validation of HAP rules and residency policies, and for reporting additional properties",
 		Version:       gitCommit,
 		Long:          ``,
 		SilenceErrors: true,
@@ -25,6 +25,8 @@ func main() {
 	}
 
 	rootCmd.AddCommand(NewParseCmd())
+	rootCmd.AddCommand(NewResidencyCmd())
+	rootCmd.AddCommand(NewAdditionalPropertiesCmd())
 
 	err := rootCmd.Execute()
 	if err != nil {
@@ -15,12 +15,14 @@ import (
 
 var ErrUsage = errors.New("UsageError")
 var ErrInvalidRule = errors.New("InvalidRuleError")
+var ErrInvalidPolicy = errors.New("InvalidPolicyError")
 
 type ParseCommand struct {
-	cobraCmd     *cobra.Command
-	rule         string
-	ruleFilePath string
-	match        string
+	cobraCmd      *cobra.Command
+	rule          string
+	ruleFilePath  string
+	match         string
+	plansFilePath string
 }
 
 func NewParseCmd() *cobra.Command {
@@ -56,6 +58,7 @@ func NewParseCmd() *cobra.Command {
 	cobraCmd.Flags().StringVarP(&cmd.rule, "entry", "e", "", "A rule to validate where each rule entry is separated by comma.")
 	cobraCmd.Flags().StringVarP(&cmd.match, "match", "m", "", "Check what rule will be matched and triggered against the provided test data. Only valid entries are taking into account when matching. Data is passed in json format, example: '{\"plan\": \"aws\", \"platformRegion\": \"cf-eu11\"}'.")
 	cobraCmd.Flags().StringVarP(&cmd.ruleFilePath, "file", "f", "", "Read rules from a file pointed to by parameter value. The file must contain a valid yaml list, where each rule entry starts with '-' and is placed in its own line.")
+	cobraCmd.Flags().StringVar(&cmd.plansFilePath, "plans", "", "Register the plans declared in the plans configuration file, so that the rules may use them.")
 	cobraCmd.MarkFlagsOneRequired("entry", "file")
 
 	return cobraCmd
@@ -65,6 +68,12 @@ func (cmd *ParseCommand) Run() error {
 
 	var rulesService *rules.RulesService
 	var err error
+	if cmd.plansFilePath != "" {
+		if _, err := broker.RegisterPlansFromFile(cmd.plansFilePath); err != nil {
+			cmd.cobraCmd.Printf("Error: %s\n", err)
+			return ErrUsage
+		}
+	}
 	allowedPlans := sets.New(broker.AvailablePlans.GetAllPlanNamesAsStrings()...)
 	requiredPlans := sets.New[string]()
 	if cmd.ruleFilePath != "" {
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type ResidencyCommand struct {
+	cobraCmd          *cobra.Command
+	policyFilePath    string
+	providersFilePath string
+	plansFilePath     string
+	rulesFilePath     string
+}
+
+func NewResidencyCmd() *cobra.Command {
+	cmd := ResidencyCommand{}
+	cobraCmd := &cobra.Command{
+		Use:     "residency",
+		Aliases: []string{"r"},
+		Short:   "Validates a residency policy file.",
+		Long:    "Validates a residency policy file against the providers configuration, plans configuration, and HAP rules.",
+		Example: `
+	# Validate the residency policy file format
+	hap residency -f residency-policy.yaml
+
+	# Validate the residency policy against the providers and plans configuration and HAP rules
+	hap residency -f residency-policy.yaml --providers providers.yaml --plans plans.yaml --rules rules.yaml
+		`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmd.Run()
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	cmd.cobraCmd = cobraCmd
+
+	cobraCmd.Flags().StringVarP(&cmd.policyFilePath, "file", "f", "", "Read the residency policy from a file pointed to by parameter value.")
+	cobraCmd.Flags().StringVar(&cmd.providersFilePath, "providers", "", "Validate the policy against the providers configuration file.")
+	cobraCmd.Flags().StringVar(&cmd.plansFilePath, "plans", "", "Validate the policy against the plans configuration file.")
+	cobraCmd.Flags().StringVar(&cmd.rulesFilePath, "rules", "", "Validate the policy against the HAP rules file.")
+	_ = cobraCmd.MarkFlagRequired("file")
+
+	return cobraCmd
+}
+
+func (cmd *ResidencyCommand) Run() error {
+	cmd.cobraCmd.Printf("Parsing residency policy from file: %s\n", cmd.policyFilePath)
+	policies, err := residency.NewPoliciesFromFile(cmd.policyFilePath)
+	if err != nil {
+		cmd.cobraCmd.Printf("Error: %s\n", err)
+		return ErrUsage
+	}
+
+	var errs []error
+	if cmd.providersFilePath != "" {
+		providerSpec, err := configuration.NewProviderSpecFromFile(cmd.providersFilePath)
+		if err != nil {
+			cmd.cobraCmd.Printf("Error: %s\n", err)
+			return ErrUsage
+		}
+		errs = append(errs, policies.ValidateProviders(providerSpec)...)
+	}
+	if cmd.plansFilePath != "" {
+		// the declared plans are registered, so the HAP rules below may use them
+		planSpec, err := broker.RegisterPlansFromFile(cmd.plansFilePath)
+		if err != nil {
+			cmd.cobraCmd.Printf("Error: %s\n", err)
+			return ErrUsage
+		}
+		errs = append(errs, policies.ValidatePlans(planSpec, broker.PlanCloudProviders())...)
+	}
+	if cmd.rulesFilePath != "" {
+		rulesService, err := rules.NewRulesServiceFromFile(cmd.rulesFilePath, sets.New(broker.AvailablePlans.GetAllPlanNamesAsStrings()...), sets.New[string]())
+		if err != nil {
+			cmd.cobraCmd.Printf("Error: %s\n", err)
+			return ErrUsage
+		}
+		errs = append(errs, policies.ValidateHAPRules(rulesService, broker.PlanCloudProviders())...)
+	}
+
+	if len(errs) > 0 {
+		cmd.cobraCmd.Printf("There are errors in your residency policy configuration.\n")
+		for _, err := range errs {
+			cmd.cobraCmd.Printf("%s\n", err)
+		}
+		return ErrInvalidPolicy
+	}
+	cmd.cobraCmd.Printf("Your residency policy configuration is OK.\n")
+	return nil
+}
@@ -4,16 +4,25 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
-	btpmanager "github.com/kyma-project/kyma-environment-broker/internal/btpmanager/credentials"
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/kyma-project/kyma-environment-broker/common/gardener"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/health"
+	"github.com/kyma-project/kyma-environment-broker/internal/runtimereconciler"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/vrischmann/envconfig"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
@@ -21,11 +30,20 @@ import (
 type Config struct {
 	Database               storage.Config
 	Events                 events.Config
+	Gardener               gardener.Config
 	DryRun                 bool   `envconfig:"default=true"`
 	JobEnabled             bool   `envconfig:"default=false"`
 	JobInterval            int    `envconfig:"default=24"`
 	JobReconciliationDelay string `envconfig:"default=0s"`
 	MetricsPort            string `envconfig:"default=8081"`
+	Readiness              health.Config
+
+	PlansConfigurationFilePath string
+
+	BtpManagerSecretEnabled   bool `envconfig:"default=true"`
+	KymaLabelsEnabled         bool `envconfig:"default=false"`
+	RuntimeLabelsEnabled      bool `envconfig:"default=false"`
+	SubscriptionLabelsEnabled bool `envconfig:"default=false"`
 }
 
 const AppPrefix = "runtime_reconciler"
@@ -57,25 +75,74 @@ func main() {
 
 	logs.Info(fmt.Sprintf("runtime-reconciler running as dry run? %t", cfg.DryRun))
 
+	// the labels reconcilers resolve the plan names, so the declared plans must be known
+	_, err = broker.RegisterPlansFromFile(cfg.PlansConfigurationFilePath)
+	fatalOnError(err, logs)
+
 	cipher := storage.NewEncrypter(cfg.Database.SecretKey)
 
-	db, _, err := storage.NewFromConfig(cfg.Database, cfg.Events, cipher)
+	db, dbConn, err := storage.NewFromConfig(cfg.Database, cfg.Events, cipher)
 	fatalOnError(err, logs)
 	logs.Info("runtime-reconciler connected to database")
 
 	metricsRegistry := prometheus.NewRegistry()
 	metricsRegistry.MustRegister(collectors.NewGoCollector())
 
+	readiness := health.NewReadiness(cfg.Readiness, metricsRegistry, AppPrefix, logs)
+	readiness.Add(health.NewDatabaseChecker("database", dbConn))
+
+	err = imv1.AddToScheme(scheme.Scheme)
+	fatalOnError(err, logs)
 	kcpK8sConfig, err := config.GetConfig()
 	fatalOnError(err, logs)
 	kcpK8sClient, err := client.New(kcpK8sConfig, client.Options{})
 	fatalOnError(err, logs)
+	kcpDiscovery, err := discovery.NewDiscoveryClientForConfig(kcpK8sConfig)
+	fatalOnError(err, logs)
+	readiness.AddDependency(health.NewDiscoveryChecker("kcp", kcpDiscovery))
+
+	var reconcilers []runtimereconciler.Reconciler
+	if cfg.BtpManagerSecretEnabled {
+		reconcilers = append(reconcilers, runtimereconciler.NewBTPManagerSecretReconciler(kcpK8sClient, logs))
+	}
+	if cfg.KymaLabelsEnabled {
+		reconcilers = append(reconcilers, runtimereconciler.NewKymaLabelsReconciler(kcpK8sClient))
+	}
+	if cfg.RuntimeLabelsEnabled {
+		reconcilers = append(reconcilers, runtimereconciler.NewRuntimeLabelsReconciler(kcpK8sClient))
+	}
+	if cfg.SubscriptionLabelsEnabled {
+		gardenerClusterConfig, err := gardener.NewGardenerClusterConfig(cfg.Gardener.KubeconfigPath)
+		fatalOnError(err, logs)
+		dynamicGardener, err := dynamic.NewForConfig(gardenerClusterConfig)
+		fatalOnError(err, logs)
+		gardenerDiscovery, err := discovery.NewDiscoveryClientForConfig(gardenerClusterConfig)
+		fatalOnError(err, logs)
+		readiness.AddDependency(health.NewDiscoveryChecker("gardener", gardenerDiscovery))
+		gardenerClient := gardener.NewClient(dynamicGardener, fmt.Sprintf("garden-%v", cfg.Gardener.Project))
+		reconcilers = append(reconcilers, runtimereconciler.NewSubscriptionLabelsReconciler(gardenerClient))
+	}
+	for _, reconciler := range reconcilers {
+		logs.Info(fmt.Sprintf("runtime-reconciler enabled reconciler: %s", reconciler.Name()))
+	}
 
-	btpOperatorManager := btpmanager.NewManager(ctx, kcpK8sClient, db.Instances(), logs, cfg.DryRun)
+	metrics := runtimereconciler.NewMetrics(metricsRegistry, AppPrefix)
+	runner := runtimereconciler.NewRunner(db.Instances(), reconcilers, cfg.DryRun, jobReconciliationDelay, metrics, logs)
+
+	http.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{Registry: metricsRegistry}))
+	http.Handle("/reports", runner.Reports())
+	http.HandleFunc("/healthz", health.LivenessHandler())
+	http.Handle("/readyz", readiness)
+	go func() {
+		err := http.ListenAndServe(fmt.Sprintf(":%s", cfg.MetricsPort), nil)
+		if err != nil {
+			logs.Error(fmt.Sprintf("while serving metrics: %s", err))
+		}
+	}()
 
-	btpManagerCredentialsJob := btpmanager.NewJob(btpOperatorManager, logs, metricsRegistry, cfg.MetricsPort, AppPrefix)
 	logs.Info(fmt.Sprintf("runtime-reconciler created job every %d m", cfg.JobInterval))
-	btpManagerCredentialsJob.Start(cfg.JobInterval, jobReconciliationDelay)
+	err = runner.Start(ctx, cfg.JobInterval)
+	fatalOnError(err, logs)
 
 	<-ctx.Done()
 }
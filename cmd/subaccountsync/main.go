@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/collectors"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/health"
 	"github.com/kyma-project/kyma-environment-broker/internal/kymacustomresource"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -20,6 +22,7 @@ import (
 	kebConfig "github.com/kyma-project/kyma-environment-broker/internal/config"
 	"github.com/vrischmann/envconfig"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -98,6 +101,15 @@ func main() {
 	// create dynamic K8s client
 	dynamicK8sClient := createDynamicK8sClient()
 
+	// register the health endpoints, served together with the metrics
+	readiness := health.NewReadiness(cfg.Readiness, metricsRegistry, AppPrefix, logger)
+	readiness.Add(health.NewDatabaseChecker("database", dbConn))
+	kcpDiscovery, err := discovery.NewDiscoveryClientForConfig(config.GetConfigOrDie())
+	fatalOnError(err)
+	readiness.AddDependency(health.NewDiscoveryChecker("kcp", kcpDiscovery))
+	http.HandleFunc("/healthz", health.LivenessHandler())
+	http.Handle("/readyz", readiness)
+
 	// create service
 	syncService := subsync.NewSyncService(AppPrefix, ctx, cfg, kymaGVR, db, dynamicK8sClient, metricsRegistry)
 	syncService.Run()
@@ -166,6 +166,20 @@ func (rs *RulesService) MatchProvisioningAttributesWithValidRuleset(provisioning
 	return result, found
 }
 
+// MatchEUAccess returns whether the rule matching the given attributes has the EU attribute, and the matched rule.
+func (rs *RulesService) MatchEUAccess(plan, platformRegion, hyperscalerRegion, hyperscaler string) (bool, string, bool) {
+	result, found := rs.MatchProvisioningAttributesWithValidRuleset(&ProvisioningAttributes{
+		Plan:              plan,
+		PlatformRegion:    platformRegion,
+		HyperscalerRegion: hyperscalerRegion,
+		Hyperscaler:       hyperscaler,
+	})
+	if !found {
+		return false, "", false
+	}
+	return result.IsEUAccess(), result.Rule(), true
+}
+
 func toValidRule(rule *Rule, rawRule string, ruleNo int) *ValidRule {
 	vr := &ValidRule{
 		Plan: PatternAttribute{
@@ -0,0 +1,40 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const CursorParam = "cursor"
+
+// Cursor points to the last item of the previous page. The items are ordered by the creation time and the ID,
+// so the next page starts right after the cursor even if items are added or removed in the meantime.
+type Cursor struct {
+	CreatedAt time.Time `json:"createdAt"`
+	ID        string    `json:"id"`
+}
+
+// EncodeCursor returns the opaque, URL-safe form of the cursor returned to the clients
+func EncodeCursor(cursor Cursor) string {
+	content, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	content, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("cursor is malformed")
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(content, &cursor); err != nil || cursor.ID == "" {
+		return Cursor{}, fmt.Errorf("cursor is malformed")
+	}
+	return cursor, nil
+}
+
+// After returns true if the item with the given creation time and ID follows the cursor
+func (c Cursor) After(createdAt time.Time, id string) bool {
+	return createdAt.After(c.CreatedAt) || (createdAt.Equal(c.CreatedAt) && id > c.ID)
+}
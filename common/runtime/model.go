@@ -68,6 +68,8 @@ type RuntimeDTO struct {
 	LicenseType                 *string                   `json:"licenseType,omitempty"`
 	CommercialModel             *string                   `json:"commercialModel,omitempty"`
 	Actions                     []Action                  `json:"actions,omitempty"`
+	Tags                        map[string]string         `json:"tags,omitempty"`
+	ExpiresAt                   *time.Time                `json:"expiresAt,omitempty"`
 }
 
 type CloudProvider string
@@ -389,8 +391,14 @@ type BindingDTO struct {
 type ActionType string
 
 const (
-	PlanUpdateActionType         ActionType = "plan_update"
-	SubaccountMovementActionType ActionType = "subaccount_movement"
+	PlanUpdateActionType             ActionType = "plan_update"
+	SubaccountMovementActionType     ActionType = "subaccount_movement"
+	DriftReconciliationActionType    ActionType = "drift_reconciliation"
+	InstanceTransferActionType       ActionType = "instance_transfer"
+	OperationRetryActionType         ActionType = "operation_retry"
+	InstanceTagsUpdateActionType     ActionType = "instance_tags_update"
+	ExpirationExtensionActionType    ActionType = "expiration_extension"
+	ExpirationNotificationActionType ActionType = "expiration_notification"
 )
 
 type Action struct {
@@ -415,6 +423,20 @@ type RuntimeStatus struct {
 	Update           *UpdateOperationsData `json:"update,omitempty"`
 	Suspension       *OperationsData       `json:"suspension,omitempty"`
 	Unsuspension     *OperationsData       `json:"unsuspension,omitempty"`
+	Kyma             *KymaStatus           `json:"kyma,omitempty"`
+}
+
+// KymaStatus is the summary of the status of the Kyma custom resource reported by lifecycle-manager
+type KymaStatus struct {
+	State   string             `json:"state"`
+	Modules []KymaModuleStatus `json:"modules,omitempty"`
+}
+
+type KymaModuleStatus struct {
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Version string `json:"version,omitempty"`
+	Channel string `json:"channel,omitempty"`
 }
 
 type OperationType string
@@ -454,12 +476,26 @@ type Operation struct {
 	RawParameters                json.RawMessage           `json:"rawParameters,omitempty"`
 	Error                        *kebError.LastError       `json:"error,omitempty"`
 	UpdatedPlanName              string                    `json:"updatedPlanName,omitempty"`
+	StepExecutions               []StepExecution           `json:"stepExecutions,omitempty"`
+}
+
+type StepExecution struct {
+	Stage             string    `json:"stage"`
+	Step              string    `json:"step"`
+	Attempt           int       `json:"attempt"`
+	StartedAt         time.Time `json:"startedAt"`
+	FinishedAt        time.Time `json:"finishedAt"`
+	Result            string    `json:"result"`
+	RetryAfterSeconds int       `json:"retryAfterSeconds,omitempty"`
+	ErrorReason       string    `json:"errorReason,omitempty"`
+	ErrorComponent    string    `json:"errorComponent,omitempty"`
 }
 
 type RuntimesPage struct {
 	Data       []RuntimeDTO `json:"data"`
 	Count      int          `json:"count"`
 	TotalCount int          `json:"totalCount"`
+	NextCursor string       `json:"nextCursor,omitempty"`
 }
 
 const (
@@ -480,6 +516,9 @@ const (
 	BindingsParam        = "bindings"
 	WithBindingsParam    = "with_bindings"
 	ActionsParam         = "actions"
+	StepExecutionsParam  = "step_executions"
+	TagsParam            = "tags"
+	FieldsParam          = "fields"
 )
 
 type OperationDetail string
@@ -0,0 +1,336 @@
+package additionalproperties
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+)
+
+const (
+	RequestTypeProvisioning = "provisioning"
+	RequestTypeUpdate       = "update"
+)
+
+// Request is a line of the requests file, the plan and the timestamp are missing in the lines written by older versions of KEB
+type Request struct {
+	GlobalAccountID string          `json:"globalAccountID"`
+	SubAccountID    string          `json:"subAccountID"`
+	InstanceID      string          `json:"instanceID"`
+	Plan            string          `json:"plan,omitempty"`
+	Timestamp       *time.Time      `json:"timestamp,omitempty"`
+	Payload         json.RawMessage `json:"payload"`
+}
+
+// Report groups the unknown properties of the requests, so it is known which global accounts would get an error
+// if the unsupported parameters were rejected
+type Report struct {
+	RequestType string `json:"requestType"`
+	// Requests is the number of the requests in the file
+	Requests int `json:"requests"`
+	// InvalidLines is the number of lines which cannot be parsed
+	InvalidLines   int                   `json:"invalidLines"`
+	Properties     []PropertyReport      `json:"properties"`
+	GlobalAccounts []GlobalAccountReport `json:"globalAccounts"`
+}
+
+type PropertyReport struct {
+	Path           string     `json:"path"`
+	Requests       int        `json:"requests"`
+	Instances      int        `json:"instances"`
+	Plans          []string   `json:"plans"`
+	GlobalAccounts []string   `json:"globalAccounts"`
+	FirstSeen      *time.Time `json:"firstSeen,omitempty"`
+	LastSeen       *time.Time `json:"lastSeen,omitempty"`
+}
+
+type GlobalAccountReport struct {
+	GlobalAccountID string     `json:"globalAccountID"`
+	Requests        int        `json:"requests"`
+	Instances       []string   `json:"instances"`
+	Paths           []string   `json:"paths"`
+	LastSeen        *time.Time `json:"lastSeen,omitempty"`
+}
+
+// FileName returns the name of the requests file for the request type
+func FileName(requestType string) (string, error) {
+	switch requestType {
+	case RequestTypeProvisioning:
+		return ProvisioningRequestsFileName, nil
+	case RequestTypeUpdate:
+		return UpdateRequestsFileName, nil
+	default:
+		return "", unsupportedRequestType(requestType)
+	}
+}
+
+func unsupportedRequestType(requestType string) error {
+	return fmt.Errorf("unsupported request type %q, supported values are %s and %s", requestType, RequestTypeProvisioning, RequestTypeUpdate)
+}
+
+// AnalyzeFile reads the requests file of the request type from the directory and groups the unknown properties
+func AnalyzeFile(dir, requestType string) (Report, error) {
+	fileName, err := FileName(requestType)
+	if err != nil {
+		return Report{}, err
+	}
+	f, err := os.Open(filepath.Join(dir, fileName))
+	if err != nil {
+		return Report{}, fmt.Errorf("while opening additional properties file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return Analyze(f, requestType)
+}
+
+// Analyze reads the requests line by line and groups the properties, which are not defined in the parameters of the request type
+func Analyze(r io.Reader, requestType string) (Report, error) {
+	var parameters reflect.Type
+	switch requestType {
+	case RequestTypeProvisioning:
+		parameters = reflect.TypeOf(pkg.ProvisioningParametersDTO{})
+	case RequestTypeUpdate:
+		parameters = reflect.TypeOf(internal.UpdatingParametersDTO{})
+	default:
+		return Report{}, unsupportedRequestType(requestType)
+	}
+
+	report := Report{RequestType: requestType, Properties: []PropertyReport{}, GlobalAccounts: []GlobalAccountReport{}}
+	properties := map[string]*propertyStats{}
+	globalAccounts := map[string]*globalAccountStats{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var request Request
+		var payload any
+		if err := json.Unmarshal(line, &request); err != nil {
+			report.InvalidLines++
+			continue
+		}
+		if err := json.Unmarshal(request.Payload, &payload); err != nil {
+			report.InvalidLines++
+			continue
+		}
+		report.Requests++
+
+		paths := unknownPaths(payload, parameters, "")
+		if len(paths) == 0 {
+			continue
+		}
+		ga := globalAccounts[request.GlobalAccountID]
+		if ga == nil {
+			ga = &globalAccountStats{instances: map[string]struct{}{}, paths: map[string]struct{}{}}
+			globalAccounts[request.GlobalAccountID] = ga
+		}
+		ga.requests++
+		ga.instances[request.InstanceID] = struct{}{}
+		ga.lastSeen = later(ga.lastSeen, request.Timestamp)
+
+		for _, path := range paths {
+			ga.paths[path] = struct{}{}
+			stats := properties[path]
+			if stats == nil {
+				stats = &propertyStats{instances: map[string]struct{}{}, plans: map[string]struct{}{}, globalAccounts: map[string]struct{}{}}
+				properties[path] = stats
+			}
+			stats.requests++
+			stats.instances[request.InstanceID] = struct{}{}
+			if request.Plan != "" {
+				stats.plans[request.Plan] = struct{}{}
+			}
+			stats.globalAccounts[request.GlobalAccountID] = struct{}{}
+			stats.firstSeen = earlier(stats.firstSeen, request.Timestamp)
+			stats.lastSeen = later(stats.lastSeen, request.Timestamp)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Report{}, fmt.Errorf("while reading additional properties: %w", err)
+	}
+
+	for path, stats := range properties {
+		report.Properties = append(report.Properties, PropertyReport{
+			Path:           path,
+			Requests:       stats.requests,
+			Instances:      len(stats.instances),
+			Plans:          sortedKeys(stats.plans),
+			GlobalAccounts: sortedKeys(stats.globalAccounts),
+			FirstSeen:      stats.firstSeen,
+			LastSeen:       stats.lastSeen,
+		})
+	}
+	// the most used properties first
+	sort.Slice(report.Properties, func(i, j int) bool {
+		if report.Properties[i].Requests != report.Properties[j].Requests {
+			return report.Properties[i].Requests > report.Properties[j].Requests
+		}
+		return report.Properties[i].Path < report.Properties[j].Path
+	})
+
+	for id, stats := range globalAccounts {
+		report.GlobalAccounts = append(report.GlobalAccounts, GlobalAccountReport{
+			GlobalAccountID: id,
+			Requests:        stats.requests,
+			Instances:       sortedKeys(stats.instances),
+			Paths:           sortedKeys(stats.paths),
+			LastSeen:        stats.lastSeen,
+		})
+	}
+	sort.Slice(report.GlobalAccounts, func(i, j int) bool {
+		return report.GlobalAccounts[i].GlobalAccountID < report.GlobalAccounts[j].GlobalAccountID
+	})
+
+	return report, nil
+}
+
+type propertyStats struct {
+	requests       int
+	instances      map[string]struct{}
+	plans          map[string]struct{}
+	globalAccounts map[string]struct{}
+	firstSeen      *time.Time
+	lastSeen       *time.Time
+}
+
+type globalAccountStats struct {
+	requests  int
+	instances map[string]struct{}
+	paths     map[string]struct{}
+	lastSeen  *time.Time
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unknownPaths returns the paths of the properties of the value, which are not fields of the given type, for example
+// "oidc.foo" or "additionalWorkerNodePools[].bar". Similarly to encoding/json, the names of the fields are case-insensitive.
+func unknownPaths(value any, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// the types with custom unmarshaling define the accepted properties themselves
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var paths []string
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		fields := jsonFields(t)
+		for key, fieldValue := range object {
+			field, found := lookupField(fields, key)
+			if !found {
+				paths = append(paths, join(path, key))
+				continue
+			}
+			paths = append(paths, unknownPaths(fieldValue, field, join(path, key))...)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			return nil
+		}
+		seen := map[string]struct{}{}
+		for _, item := range items {
+			for _, p := range unknownPaths(item, t.Elem(), path+"[]") {
+				if _, duplicated := seen[p]; !duplicated {
+					seen[p] = struct{}{}
+					paths = append(paths, p)
+				}
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// jsonFields returns the types of the fields by their JSON names, including the fields of the embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for n, ft := range jsonFields(embedded) {
+					fields[n] = ft
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func lookupField(fields map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if field, found := fields[key]; found {
+		return field, true
+	}
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func earlier(current, t *time.Time) *time.Time {
+	if t == nil || (current != nil && !t.Before(*current)) {
+		return current
+	}
+	return t
+}
+
+func later(current, t *time.Time) *time.Time {
+	if t == nil || (current != nil && !t.After(*current)) {
+		return current
+	}
+	return t
+}
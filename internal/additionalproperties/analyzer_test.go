@@ -0,0 +1,82 @@
+package additionalproperties
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Run("should group unknown provisioning properties by path and global account", func(t *testing.T) {
+		// given
+		requests := strings.Join([]string{
+			`{"globalAccountID":"ga1","subAccountID":"sa1","instanceID":"id1","plan":"aws","timestamp":"2025-01-01T10:00:00Z","payload":{"name":"c1","region":"eu-central-1","foo":"bar"}}`,
+			`{"globalAccountID":"ga1","subAccountID":"sa1","instanceID":"id2","plan":"azure","timestamp":"2025-01-03T10:00:00Z","payload":{"name":"c2","foo":1,"oidc":{"clientID":"x","extra":true}}}`,
+			`{"globalAccountID":"ga2","subAccountID":"sa2","instanceID":"id3","timestamp":"2025-01-02T10:00:00Z","payload":{"Name":"c3","additionalWorkerNodePools":[{"name":"p1","unknown":1},{"name":"p2","unknown":2}]}}`,
+			`{"globalAccountID":"ga3","subAccountID":"sa3","instanceID":"id4","payload":{"autoScalerMin":"3"}}`,
+			`not a json`,
+		}, "\n")
+
+		// when
+		report, err := Analyze(strings.NewReader(requests), RequestTypeProvisioning)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 4, report.Requests)
+		assert.Equal(t, 1, report.InvalidLines)
+
+		require.Len(t, report.Properties, 3)
+		assert.Equal(t, PropertyReport{
+			Path:           "foo",
+			Requests:       2,
+			Instances:      2,
+			Plans:          []string{"aws", "azure"},
+			GlobalAccounts: []string{"ga1"},
+			FirstSeen:      fixTime("2025-01-01T10:00:00Z"),
+			LastSeen:       fixTime("2025-01-03T10:00:00Z"),
+		}, report.Properties[0])
+		assert.Equal(t, "additionalWorkerNodePools[].unknown", report.Properties[1].Path)
+		assert.Equal(t, 1, report.Properties[1].Requests)
+		assert.Empty(t, report.Properties[1].Plans)
+		assert.Equal(t, "oidc.extra", report.Properties[2].Path)
+
+		require.Len(t, report.GlobalAccounts, 2)
+		assert.Equal(t, GlobalAccountReport{
+			GlobalAccountID: "ga1",
+			Requests:        2,
+			Instances:       []string{"id1", "id2"},
+			Paths:           []string{"foo", "oidc.extra"},
+			LastSeen:        fixTime("2025-01-03T10:00:00Z"),
+		}, report.GlobalAccounts[0])
+		assert.Equal(t, "ga2", report.GlobalAccounts[1].GlobalAccountID)
+	})
+
+	t.Run("should use the update parameters", func(t *testing.T) {
+		// given
+		requests := `{"globalAccountID":"ga1","subAccountID":"sa1","instanceID":"id1","payload":{"machineType":"m6i.large","region":"eu-central-1"}}`
+
+		// when
+		report, err := Analyze(strings.NewReader(requests), RequestTypeUpdate)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, report.Properties, 1)
+		assert.Equal(t, "region", report.Properties[0].Path)
+	})
+
+	t.Run("should reject unsupported request type", func(t *testing.T) {
+		// when
+		_, err := Analyze(strings.NewReader(""), "deprovisioning")
+
+		// then
+		assert.EqualError(t, err, `unsupported request type "deprovisioning", supported values are provisioning and update`)
+	})
+}
+
+func fixTime(value string) *time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return &t
+}
@@ -37,6 +37,25 @@ func NewHandler(logger *slog.Logger, additionalPropertiesPath string) *Handler {
 
 func (h *Handler) AttachRoutes(router *httputil.Router) {
 	router.HandleFunc("/additional_properties", h.getAdditionalProperties)
+	router.HandleFunc("/additional_properties/report", h.getReport)
+}
+
+// getReport returns the unknown properties of the requests grouped by path and global account
+func (h *Handler) getReport(w http.ResponseWriter, req *http.Request) {
+	requestType := req.URL.Query().Get("requestType")
+	if _, err := FileName(requestType); err != nil {
+		httputil.WriteResponse(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	report, err := AnalyzeFile(h.additionalPropertiesPath, requestType)
+	if err != nil {
+		h.logger.Error("Failed to analyze additional properties", "error", err)
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while analyzing additional properties: %s", err.Error()))
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, report)
 }
 
 func (h *Handler) getAdditionalProperties(w http.ResponseWriter, req *http.Request) {
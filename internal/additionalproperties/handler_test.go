@@ -114,6 +114,58 @@ func TestGetAdditionalProperties(t *testing.T) {
 	})
 }
 
+func TestGetAdditionalPropertiesReport(t *testing.T) {
+	tempDir := t.TempDir()
+
+	provisioningFile := filepath.Join(tempDir, ProvisioningRequestsFileName)
+	provisioningContent := `{"globalAccountID":"ga1","subAccountID":"sa1","instanceID":"id1","plan":"aws","payload":{"name":"c1","key":"provisioning1"}}`
+	err := os.WriteFile(provisioningFile, []byte(provisioningContent), 0644)
+	require.NoError(t, err)
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	handler := NewHandler(log, tempDir)
+
+	router := httputil.NewRouter()
+	handler.AttachRoutes(router)
+
+	t.Run("returns provisioning report", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/additional_properties/report?requestType=provisioning", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var report Report
+		err := json.Unmarshal(resp.Body.Bytes(), &report)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Requests)
+		require.Len(t, report.Properties, 1)
+		assert.Equal(t, "key", report.Properties[0].Path)
+		assert.Equal(t, []string{"aws"}, report.Properties[0].Plans)
+		require.Len(t, report.GlobalAccounts, 1)
+		assert.Equal(t, "ga1", report.GlobalAccounts[0].GlobalAccountID)
+	})
+
+	t.Run("returns error for invalid requestType", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/additional_properties/report?requestType=invalid", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("returns error for missing file", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/additional_properties/report?requestType=update", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusInternalServerError, resp.Code)
+	})
+}
+
 func TestGetAdditionalProperties_Paging(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -197,6 +197,85 @@ WHERE o.type IN ('provision', 'update')
 	return result, nil
 }
 
+// FetchStepStatsInRange returns the execution statistics of every step recorded in the step_executions table
+// within tr, ordered by the total time spent in the step, descending.
+func (r *DBReader) FetchStepStatsInRange(tr TimeRange) ([]StepStat, error) {
+	where := "WHERE 1 = 1"
+	args := []interface{}{}
+	if !tr.From.IsZero() {
+		where += " AND s.started_at >= ?"
+		args = append(args, tr.From)
+	}
+	if !tr.To.IsZero() {
+		where += " AND s.started_at < ?"
+		args = append(args, tr.To)
+	}
+	q := fmt.Sprintf(`
+WITH s AS (
+  SELECT s.stage, s.step, s.result, s.error_reason,
+         EXTRACT(EPOCH FROM (s.finished_at - s.started_at)) AS duration
+  FROM step_executions s
+  %[1]s
+),
+reasons AS (
+  SELECT stage, step, error_reason, COUNT(*) AS cnt,
+         ROW_NUMBER() OVER (PARTITION BY stage, step ORDER BY COUNT(*) DESC, error_reason) AS rn
+  FROM s
+  WHERE error_reason <> ''
+  GROUP BY stage, step, error_reason
+)
+SELECT s.stage, s.step,
+       COUNT(*) AS executions,
+       COUNT(*) FILTER (WHERE s.result = 'failed') AS failures,
+       COUNT(*) FILTER (WHERE s.result = 'retry') AS retries,
+       AVG(s.duration) AS avg_duration,
+       PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.duration) AS p50_duration,
+       PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY s.duration) AS p95_duration,
+       MAX(s.duration) AS max_duration,
+       COALESCE(MAX(r.error_reason), '') AS top_error_reason,
+       COALESCE(MAX(r.cnt), 0) AS top_error_reason_count
+FROM s
+LEFT JOIN reasons r ON r.stage = s.stage AND r.step = s.step AND r.rn = 1
+GROUP BY s.stage, s.step
+ORDER BY SUM(s.duration) DESC`, where)
+
+	var rows []struct {
+		Stage               string  `db:"stage"`
+		Step                string  `db:"step"`
+		Executions          int     `db:"executions"`
+		Failures            int     `db:"failures"`
+		Retries             int     `db:"retries"`
+		AvgDuration         float64 `db:"avg_duration"`
+		P50Duration         float64 `db:"p50_duration"`
+		P95Duration         float64 `db:"p95_duration"`
+		MaxDuration         float64 `db:"max_duration"`
+		TopErrorReason      string  `db:"top_error_reason"`
+		TopErrorReasonCount int     `db:"top_error_reason_count"`
+	}
+	_, err := r.session.SelectBySql(q, args...).Load(&rows)
+	if err != nil {
+		return nil, fmt.Errorf("fetching step stats: %w", err)
+	}
+
+	result := make([]StepStat, len(rows))
+	for i, row := range rows {
+		result[i] = StepStat{
+			Stage:               row.Stage,
+			Step:                row.Step,
+			Executions:          row.Executions,
+			Failures:            row.Failures,
+			Retries:             row.Retries,
+			AvgDurationSec:      row.AvgDuration,
+			P50DurationSec:      row.P50Duration,
+			P95DurationSec:      row.P95Duration,
+			MaxDurationSec:      row.MaxDuration,
+			TopErrorReason:      row.TopErrorReason,
+			TopErrorReasonCount: row.TopErrorReasonCount,
+		}
+	}
+	return result, nil
+}
+
 func parseProvisioningParameters(raw string) (internal.ProvisioningParameters, error) {
 	if raw == "" {
 		return internal.ProvisioningParameters{}, fmt.Errorf("empty provisioning_parameters")
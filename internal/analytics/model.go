@@ -53,3 +53,18 @@ type StatsResponse struct {
 	Plans          []string            `json:"plans"`
 	RegionsByPlan  map[string][]string `json:"regions_by_plan"`
 }
+
+// StepStat holds execution statistics of a single step, based on the recorded step executions.
+type StepStat struct {
+	Stage               string  `json:"stage"`
+	Step                string  `json:"step"`
+	Executions          int     `json:"executions"`
+	Failures            int     `json:"failures"`
+	Retries             int     `json:"retries"`
+	AvgDurationSec      float64 `json:"avg_duration_sec"`
+	P50DurationSec      float64 `json:"p50_duration_sec"`
+	P95DurationSec      float64 `json:"p95_duration_sec"`
+	MaxDurationSec      float64 `json:"max_duration_sec"`
+	TopErrorReason      string  `json:"top_error_reason,omitempty"`
+	TopErrorReasonCount int     `json:"top_error_reason_count,omitempty"`
+}
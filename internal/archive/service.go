@@ -15,6 +15,9 @@ type Service struct {
 	operations storage.Operations
 	archived   storage.InstancesArchived
 
+	stepExecutions storage.StepExecutions
+	instanceTags   storage.InstanceTags
+
 	dryRun          bool
 	performDeletion bool
 	batchSize       int
@@ -25,6 +28,8 @@ func NewService(db storage.BrokerStorage, dryRun bool, performDeletion bool, bat
 		instances:       db.Instances(),
 		operations:      db.Operations(),
 		archived:        db.InstancesArchived(),
+		stepExecutions:  db.StepExecutions(),
+		instanceTags:    db.InstanceTags(),
 		dryRun:          dryRun,
 		performDeletion: performDeletion,
 		batchSize:       batchSize,
@@ -81,6 +86,16 @@ func (s *Service) Run() (int, int, error) {
 			}
 		}
 
+		// the tags are deleted before the operations, so the instance is processed again if the deletion fails
+		if !s.dryRun && s.performDeletion {
+			logger.Debug("Deleting instance tags")
+			err = s.instanceTags.DeleteByInstanceID(instanceId)
+			if err != nil {
+				logger.Error(fmt.Sprintf("Unable to delete instance tags: %s", err.Error()))
+				continue
+			}
+		}
+
 		for _, operation := range operations {
 			logger := logger.With("operationID", operation.ID).With("type", operation.Type)
 
@@ -99,6 +114,13 @@ func (s *Service) Run() (int, int, error) {
 			// If the deletion of operation fails, it can be retried, because such instance ID will be fetched by
 			// the next run of ListDeletedInstanceIDs() method.
 
+			logger.Debug("Deleting step executions")
+			err = s.stepExecutions.DeleteByOperationID(operation.ID)
+			if err != nil {
+				logger.Error(fmt.Sprintf("Unable to delete step executions: %s", err.Error()))
+				continue
+			}
+
 			logger.Debug("Deleting operation")
 			err = s.operations.DeleteByID(operation.ID)
 			if err != nil {
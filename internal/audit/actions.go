@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	ActionProvision        = "provision"
+	ActionUpdate           = "update"
+	ActionDeprovision      = "deprovision"
+	ActionBind             = "bind"
+	ActionUnbind           = "unbind"
+	ActionExpire           = "expire"
+	ActionExtendExpiration = "extendExpiration"
+	ActionTransfer         = "transfer"
+	ActionRetryOperation   = "retryOperation"
+	ActionUpdateTags       = "updateTags"
+	ActionGetKubeconfig    = "getKubeconfig"
+	// ActionOther is recorded for the mutating calls which are not classified by the rules
+	ActionOther = "other"
+)
+
+type rule struct {
+	method   string
+	segments []string
+	action   string
+}
+
+// rules classify the calls which are recorded in the audit log. The OSB API is served with and without the region prefix.
+var rules = []rule{
+	newRule(http.MethodPut, "/oauth/v2/service_instances/{instance_id}", ActionProvision),
+	newRule(http.MethodPatch, "/oauth/v2/service_instances/{instance_id}", ActionUpdate),
+	newRule(http.MethodDelete, "/oauth/v2/service_instances/{instance_id}", ActionDeprovision),
+	newRule(http.MethodPut, "/oauth/v2/service_instances/{instance_id}/service_bindings/{binding_id}", ActionBind),
+	newRule(http.MethodDelete, "/oauth/v2/service_instances/{instance_id}/service_bindings/{binding_id}", ActionUnbind),
+	newRule(http.MethodPut, "/oauth/{region}/v2/service_instances/{instance_id}", ActionProvision),
+	newRule(http.MethodPatch, "/oauth/{region}/v2/service_instances/{instance_id}", ActionUpdate),
+	newRule(http.MethodDelete, "/oauth/{region}/v2/service_instances/{instance_id}", ActionDeprovision),
+	newRule(http.MethodPut, "/oauth/{region}/v2/service_instances/{instance_id}/service_bindings/{binding_id}", ActionBind),
+	newRule(http.MethodDelete, "/oauth/{region}/v2/service_instances/{instance_id}/service_bindings/{binding_id}", ActionUnbind),
+	newRule(http.MethodPut, "/expire/service_instance/{instance_id}", ActionExpire),
+	newRule(http.MethodPut, "/expire/service_instance/{instance_id}/extension", ActionExtendExpiration),
+	newRule(http.MethodPost, "/transfer/service_instance/{instance_id}", ActionTransfer),
+	newRule(http.MethodPost, "/operations/{operation_id}/retry", ActionRetryOperation),
+	newRule(http.MethodPatch, "/instances/{instance_id}/tags", ActionUpdateTags),
+	newRule(http.MethodGet, "/kubeconfig/{instance_id}", ActionGetKubeconfig),
+}
+
+func newRule(method, path, action string) rule {
+	return rule{method: method, segments: strings.Split(strings.Trim(path, "/"), "/"), action: action}
+}
+
+// classify returns the audited action of the call and the ID of the instance it refers to.
+// The returned action is empty if the call is not audited.
+func classify(method, path string) (string, string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, r := range rules {
+		if instanceID, ok := r.match(method, segments); ok {
+			return r.action, instanceID
+		}
+	}
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return ActionOther, ""
+	}
+	return "", ""
+}
+
+func (r rule) match(method string, segments []string) (string, bool) {
+	if r.method != method || len(r.segments) != len(segments) {
+		return "", false
+	}
+	instanceID := ""
+	for i, s := range r.segments {
+		switch {
+		case s == "{instance_id}":
+			instanceID = segments[i]
+		case strings.HasPrefix(s, "{"):
+		case s != segments[i]:
+			return "", false
+		}
+	}
+	return instanceID, true
+}
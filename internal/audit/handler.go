@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+const (
+	InstanceIDParam = "instance_id"
+	ActionParam     = "action"
+	PrincipalParam  = "principal"
+	FromParam       = "from"
+	ToParam         = "to"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler interface {
+	AttachRoutes(r router)
+}
+
+type Page struct {
+	Data       []internal.AuditEntry `json:"data"`
+	Count      int                   `json:"count"`
+	TotalCount int                   `json:"totalCount"`
+}
+
+type handler struct {
+	auditLog storage.AuditLog
+	maxPage  int
+	log      *slog.Logger
+}
+
+// NewHandler creates the handler of the endpoints which return and verify the audit log
+func NewHandler(auditLog storage.AuditLog, maxPage int, log *slog.Logger) Handler {
+	return &handler{
+		auditLog: auditLog,
+		maxPage:  maxPage,
+		log:      log.With("service", "AuditEndpoint"),
+	}
+}
+
+func (h *handler) AttachRoutes(r router) {
+	r.HandleFunc("GET /audit", h.listEntries)
+	r.HandleFunc("GET /audit/verify", h.verify)
+}
+
+func (h *handler) listEntries(w http.ResponseWriter, req *http.Request) {
+	pageSize, page, err := pagination.ExtractPaginationConfigFromRequest(req, h.maxPage)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
+	query := req.URL.Query()
+	filter := dbmodel.AuditFilter{
+		InstanceID: query.Get(InstanceIDParam),
+		Action:     query.Get(ActionParam),
+		Principal:  query.Get(PrincipalParam),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	if filter.From, err = parseTime(query.Get(FromParam)); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while parsing %s parameter: %w", FromParam, err))
+		return
+	}
+	if filter.To, err = parseTime(query.Get(ToParam)); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while parsing %s parameter: %w", ToParam, err))
+		return
+	}
+
+	entries, totalCount, err := h.auditLog.List(filter)
+	if err != nil {
+		h.log.Error(fmt.Sprintf("while listing audit entries: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	httputil.WriteResponse(w, http.StatusOK, Page{
+		Data:       entries,
+		Count:      len(entries),
+		TotalCount: totalCount,
+	})
+}
+
+func (h *handler) verify(w http.ResponseWriter, req *http.Request) {
+	result, err := Verify(h.auditLog)
+	if err != nil {
+		h.log.Error(fmt.Sprintf("while verifying audit log: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !result.Valid {
+		h.log.Warn(fmt.Sprintf("audit log verification failed: %s", result.Error))
+	}
+	httputil.WriteResponse(w, http.StatusOK, result)
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	timestamp := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i, action := range []string{ActionProvision, ActionUpdate, ActionProvision} {
+		_, err := db.AuditLog().Append(internal.AuditEntry{
+			ID:         fmt.Sprintf("audit-%d", i+1),
+			Timestamp:  timestamp.Add(time.Duration(i) * time.Hour),
+			Action:     action,
+			InstanceID: fmt.Sprintf("inst-%d", i%2+1),
+			Principal:  internal.AuditPrincipal{Subject: fmt.Sprintf("user-%d", i%2+1)},
+			Outcome:    internal.AuditOutcomeSuccess,
+		})
+		require.NoError(t, err)
+	}
+
+	router := httputil.NewRouter()
+	NewHandler(db.AuditLog(), 100, slog.Default()).AttachRoutes(router)
+
+	t.Run("should return the filtered entries, the newest first", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit?action=provision", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, w.Code)
+		var page Page
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.Equal(t, 2, page.TotalCount)
+		require.Len(t, page.Data, 2)
+		assert.Equal(t, "audit-3", page.Data[0].ID)
+		assert.Equal(t, "audit-1", page.Data[1].ID)
+	})
+
+	t.Run("should filter the entries by the principal and time range", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit?principal=user-1&from=2026-01-01T11:00:00Z&to=2026-01-01T13:00:00Z", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, w.Code)
+		var page Page
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, "audit-3", page.Data[0].ID)
+	})
+
+	t.Run("should reject the malformed time range", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit?from=yesterday", nil))
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should verify the hash chain", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit/verify", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, w.Code)
+		var result VerificationResult
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		assert.Equal(t, VerificationResult{Valid: true, Entries: 3}, result)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("should detect the modified entry", func(t *testing.T) {
+		// given
+		auditLog := &fakeAuditLog{AuditLog: storage.NewMemoryStorage().AuditLog()}
+		for _, id := range []string{"audit-1", "audit-2", "audit-3"} {
+			_, err := auditLog.Append(internal.AuditEntry{ID: id, Action: ActionProvision})
+			require.NoError(t, err)
+		}
+		auditLog.tamper = func(entry *internal.AuditEntry) {
+			if entry.Sequence == 2 {
+				entry.Principal.Subject = "someone-else"
+			}
+		}
+
+		// when
+		result, err := Verify(auditLog)
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(2), result.FirstInvalidSequence)
+		assert.Equal(t, "entry 2 does not match its hash", result.Error)
+	})
+}
+
+type fakeAuditLog struct {
+	storage.AuditLog
+	tamper func(entry *internal.AuditEntry)
+}
+
+func (f *fakeAuditLog) ListAfter(sequence int64, limit int) ([]internal.AuditEntry, error) {
+	entries, err := f.AuditLog.ListAfter(sequence, limit)
+	if f.tamper != nil {
+		for i := range entries {
+			f.tamper(&entries[i])
+		}
+	}
+	return entries, err
+}
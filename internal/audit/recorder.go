@@ -0,0 +1,263 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/authorization"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+)
+
+const (
+	originatingIdentityHeader = "X-Broker-API-Originating-Identity"
+	redactedValue             = "***"
+)
+
+// The key type is not exported to prevent collisions with context keys
+// defined in other packages.
+type key int
+
+const (
+	// handledKey is the context key which marks the calls already handled by the recorder.
+	handledKey key = iota + 1
+)
+
+// requestIDHeaders are checked in order, the same headers are used by the OSB API to correlate the requests
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-ID", "X-Vcap-Request-Id"}
+
+// sensitiveKeys are the parts of the parameter names whose values are never written to the audit log
+var sensitiveKeys = []string{"password", "secret", "token", "kubeconfig", "credential", "privatekey"}
+
+type Config struct {
+	Enabled bool `envconfig:"default=false"`
+	// FilePath is the path of the file to which the entries are additionally appended as JSON lines
+	FilePath string `envconfig:"optional"`
+	// MaxParametersSize is the maximum size in bytes of the request body recorded as the parameters of the entry
+	MaxParametersSize int64 `envconfig:"default=65536"`
+}
+
+type Recorder struct {
+	config   Config
+	auditLog storage.AuditLog
+	log      *slog.Logger
+
+	fileMu sync.Mutex
+	file   io.Writer
+}
+
+func NewRecorder(config Config, auditLog storage.AuditLog, log *slog.Logger) (*Recorder, error) {
+	r := &Recorder{
+		config:   config,
+		auditLog: auditLog,
+		log:      log.With("service", "AuditRecorder"),
+	}
+	if config.FilePath != "" {
+		file, err := os.OpenFile(config.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("while opening audit log file %s: %w", config.FilePath, err)
+		}
+		r.file = file
+	}
+	return r, nil
+}
+
+// Handler records the audited calls served by the next handler. The entry is recorded after the call is served,
+// so it contains the outcome. Failures of the recording are logged and do not affect the response.
+// The call is recorded once, even if the handler is applied to both the router and its sub-router.
+func (r *Recorder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if handled, _ := req.Context().Value(handledKey).(bool); handled {
+			next.ServeHTTP(w, req)
+			return
+		}
+		req = req.WithContext(context.WithValue(req.Context(), handledKey, true))
+
+		action, instanceID := classify(req.Method, req.URL.Path)
+		if action == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		parameters := r.readParameters(req)
+		rec := httputil.NewResponseRecorder(w)
+		next.ServeHTTP(rec, req)
+
+		statusCode := rec.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+		outcome := internal.AuditOutcomeSuccess
+		if statusCode >= http.StatusBadRequest {
+			outcome = internal.AuditOutcomeFailure
+		}
+		r.Record(internal.AuditEntry{
+			ID:         uuid.NewString(),
+			Timestamp:  time.Now(),
+			Action:     action,
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			RequestID:  requestID(req),
+			Principal:  principal(req),
+			InstanceID: instanceID,
+			Parameters: parameters,
+			StatusCode: statusCode,
+			Outcome:    outcome,
+		})
+	})
+}
+
+// Record appends the entry to the audit log and to the file, if configured
+func (r *Recorder) Record(entry internal.AuditEntry) {
+	logger := r.log.With("action", entry.Action, "requestID", entry.RequestID)
+	appended, err := r.auditLog.Append(entry)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to append audit entry: %s", err))
+		appended = entry
+	}
+	if r.file == nil {
+		return
+	}
+
+	line, err := json.Marshal(appended)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to marshal audit entry: %s", err))
+		return
+	}
+	r.fileMu.Lock()
+	defer r.fileMu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		logger.Error(fmt.Sprintf("unable to write audit entry to file: %s", err))
+	}
+}
+
+// readParameters returns the sanitized request body and restores the body for the next handler.
+// Bodies which are not JSON objects or exceed the maximum size are not recorded.
+func (r *Recorder) readParameters(req *http.Request) json.RawMessage {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, r.config.MaxParametersSize+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil || int64(len(body)) > r.config.MaxParametersSize {
+		return nil
+	}
+	return sanitize(body)
+}
+
+func sanitize(body []byte) json.RawMessage {
+	var parameters map[string]any
+	if err := json.Unmarshal(body, &parameters); err != nil {
+		return nil
+	}
+	redact(parameters)
+	sanitized, err := json.Marshal(parameters)
+	if err != nil {
+		return nil
+	}
+	return sanitized
+}
+
+func redact(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			if isSensitive(key) {
+				v[key] = redactedValue
+				continue
+			}
+			redact(nested)
+		}
+	case []any:
+		for _, nested := range v {
+			redact(nested)
+		}
+	}
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func requestID(req *http.Request) string {
+	for _, header := range requestIDHeaders {
+		if id := req.Header.Get(header); id != "" {
+			return id
+		}
+	}
+	return uuid.NewString()
+}
+
+// principal returns the identity of the caller. The claims verified by the authorization of KEB are taken from the context.
+// Otherwise, the bearer token is verified by the API gateway in front of the broker, so the claims are read without
+// verification and the principal is marked as not verified.
+func principal(req *http.Request) internal.AuditPrincipal {
+	p := internal.AuditPrincipal{UserAgent: req.UserAgent()}
+	p.Platform, p.Identity = originatingIdentity(req.Header.Get(originatingIdentityHeader))
+
+	if claims, found := authorization.ClaimsFromContext(req.Context()); found {
+		p.Subject = claims.Subject
+		p.ClientID = claims.ClientID
+		p.Verified = true
+		return p
+	}
+	token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return p
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return p
+	}
+	p.Subject, _ = claims["sub"].(string)
+	p.ClientID, _ = claims["client_id"].(string)
+	if p.ClientID == "" {
+		p.ClientID, _ = claims["azp"].(string)
+	}
+	return p
+}
+
+// originatingIdentity decodes the OSB header "<platform> <base64 encoded JSON>" and returns the platform and the user.
+// The user is taken from the well-known properties of the platforms, otherwise the whole decoded value is returned.
+func originatingIdentity(header string) (string, string) {
+	platform, value, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found {
+		return platform, ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return platform, ""
+	}
+	var properties map[string]any
+	if err := json.Unmarshal(decoded, &properties); err != nil {
+		return platform, string(decoded)
+	}
+	for _, key := range []string{"user_id", "username", "email"} {
+		if user, ok := properties[key].(string); ok && user != "" {
+			return platform, user
+		}
+	}
+	return platform, string(decoded)
+}
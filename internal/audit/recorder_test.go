@@ -0,0 +1,222 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/authorization"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("should record the provisioning request with the principal and sanitized parameters", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		recorder := fixRecorder(t, Config{Enabled: true, MaxParametersSize: 1024}, db)
+		var receivedBody string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			receivedBody = string(body)
+			w.WriteHeader(http.StatusAccepted)
+		})
+		body := `{"parameters":{"name":"my-cluster","oidc":{"clientSecret":"s3cr3t"}}}`
+		req := httptest.NewRequest(http.MethodPut, "/oauth/cf-eu10/v2/service_instances/inst-1", strings.NewReader(body))
+		req.Header.Set("X-Request-Id", "req-1")
+		req.Header.Set("User-Agent", "cf-cli")
+		req.Header.Set(originatingIdentityHeader, "cloudfoundry "+base64.StdEncoding.EncodeToString([]byte(`{"user_id":"john"}`)))
+		req.Header.Set("Authorization", "Bearer "+fixToken(t, jwt.MapClaims{"sub": "john@example.com", "client_id": "sm-client"}))
+
+		// when
+		w := httptest.NewRecorder()
+		recorder.Handler(next).ServeHTTP(w, req)
+
+		// then
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, body, receivedBody)
+		entries, err := db.AuditLog().ListAfter(0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		entry := entries[0]
+		assert.Equal(t, ActionProvision, entry.Action)
+		assert.Equal(t, "inst-1", entry.InstanceID)
+		assert.Equal(t, "req-1", entry.RequestID)
+		assert.Equal(t, http.StatusAccepted, entry.StatusCode)
+		assert.Equal(t, internal.AuditOutcomeSuccess, entry.Outcome)
+		assert.Equal(t, internal.AuditPrincipal{
+			Platform:  "cloudfoundry",
+			Identity:  "john",
+			Subject:   "john@example.com",
+			ClientID:  "sm-client",
+			UserAgent: "cf-cli",
+			Verified:  false,
+		}, entry.Principal)
+		assert.JSONEq(t, `{"parameters":{"name":"my-cluster","oidc":{"clientSecret":"***"}}}`, string(entry.Parameters))
+	})
+
+	t.Run("should record the claims verified by the authorizer", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		recorder := fixRecorder(t, Config{Enabled: true, MaxParametersSize: 1024}, db)
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		req := httptest.NewRequest(http.MethodPut, "/expire/service_instance/inst-1", nil)
+		req.Header.Set("Authorization", "Bearer "+fixToken(t, jwt.MapClaims{"sub": "forged", "client_id": "forged"}))
+		req = req.WithContext(authorization.AddClaimsToContext(req.Context(), authorization.Claims{Subject: "john", ClientID: "admin-cli"}))
+
+		// when
+		recorder.Handler(next).ServeHTTP(httptest.NewRecorder(), req)
+
+		// then
+		entries, err := db.AuditLog().ListAfter(0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "john", entries[0].Principal.Subject)
+		assert.Equal(t, "admin-cli", entries[0].Principal.ClientID)
+		assert.True(t, entries[0].Principal.Verified)
+	})
+
+	t.Run("should record the call once if the recorder is applied to the router and the sub-router", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		recorder := fixRecorder(t, Config{Enabled: true, MaxParametersSize: 1024}, db)
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		subRouter := recorder.Handler(next)
+
+		// when
+		recorder.Handler(http.StripPrefix("/oauth", subRouter)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/oauth/v2/service_instances/inst-1", nil))
+
+		// then
+		entries, err := db.AuditLog().ListAfter(0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, ActionDeprovision, entries[0].Action)
+	})
+
+	t.Run("should record the failed call and skip the calls which are not audited", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		recorder := fixRecorder(t, Config{Enabled: true, MaxParametersSize: 1024}, db)
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		// when
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/runtimes", nil),
+			httptest.NewRequest(http.MethodGet, "/oauth/v2/service_instances/inst-1", nil),
+			httptest.NewRequest(http.MethodDelete, "/oauth/v2/service_instances/inst-1/service_bindings/binding-1", nil),
+			httptest.NewRequest(http.MethodGet, "/kubeconfig/inst-1", nil),
+		} {
+			recorder.Handler(next).ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		// then
+		entries, err := db.AuditLog().ListAfter(0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, ActionUnbind, entries[0].Action)
+		assert.Equal(t, internal.AuditOutcomeFailure, entries[0].Outcome)
+		assert.Equal(t, http.StatusNotFound, entries[0].StatusCode)
+		assert.NotEmpty(t, entries[0].RequestID)
+		assert.Equal(t, ActionGetKubeconfig, entries[1].Action)
+		assert.Equal(t, internal.AuditOutcomeSuccess, entries[1].Outcome)
+		assert.Equal(t, entries[0].Hash, entries[1].PreviousHash)
+	})
+
+	t.Run("should not record the parameters exceeding the maximum size", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		recorder := fixRecorder(t, Config{Enabled: true, MaxParametersSize: 10}, db)
+		body := `{"name":"my-cluster"}`
+		var receivedBody string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			content, _ := io.ReadAll(r.Body)
+			receivedBody = string(content)
+		})
+
+		// when
+		recorder.Handler(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPatch, "/oauth/v2/service_instances/inst-1", strings.NewReader(body)))
+
+		// then
+		assert.Equal(t, body, receivedBody)
+		entries, err := db.AuditLog().ListAfter(0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, ActionUpdate, entries[0].Action)
+		assert.Nil(t, entries[0].Parameters)
+	})
+
+	t.Run("should append the entries to the file", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		path := filepath.Join(t.TempDir(), "audit.log")
+		recorder := fixRecorder(t, Config{Enabled: true, FilePath: path, MaxParametersSize: 1024}, db)
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+		// when
+		recorder.Handler(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/expire/service_instance/inst-1", nil))
+		recorder.Handler(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/transfer/service_instance/inst-1", nil))
+
+		// then
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+		var actions []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var entry internal.AuditEntry
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			assert.Equal(t, entry.Hash, entry.ComputeHash())
+			actions = append(actions, entry.Action)
+		}
+		assert.Equal(t, []string{ActionExpire, ActionTransfer}, actions)
+	})
+}
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		method, path, action, instanceID string
+	}{
+		{http.MethodPut, "/oauth/v2/service_instances/inst-1", ActionProvision, "inst-1"},
+		{http.MethodPatch, "/oauth/cf-eu10/v2/service_instances/inst-1", ActionUpdate, "inst-1"},
+		{http.MethodDelete, "/oauth/v2/service_instances/inst-1", ActionDeprovision, "inst-1"},
+		{http.MethodPut, "/oauth/cf-eu10/v2/service_instances/inst-1/service_bindings/b-1", ActionBind, "inst-1"},
+		{http.MethodPut, "/expire/service_instance/inst-1/extension", ActionExtendExpiration, "inst-1"},
+		{http.MethodPost, "/operations/op-1/retry", ActionRetryOperation, ""},
+		{http.MethodPatch, "/instances/inst-1/tags", ActionUpdateTags, "inst-1"},
+		{http.MethodPost, "/upgrade", ActionOther, ""},
+		{http.MethodGet, "/oauth/v2/service_instances/inst-1/last_operation", "", ""},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			action, instanceID := classify(tc.method, tc.path)
+			assert.Equal(t, tc.action, action)
+			assert.Equal(t, tc.instanceID, instanceID)
+		})
+	}
+}
+
+func fixRecorder(t *testing.T, cfg Config, db storage.BrokerStorage) *Recorder {
+	recorder, err := NewRecorder(cfg, db.AuditLog(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return recorder
+}
+
+func fixToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("key"))
+	require.NoError(t, err)
+	return token
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+)
+
+const verifyBatchSize = 500
+
+type VerificationResult struct {
+	Valid   bool `json:"valid"`
+	Entries int  `json:"entries"`
+	// FirstInvalidSequence is the sequence of the first entry which was modified or does not follow the previous entry
+	FirstInvalidSequence int64  `json:"firstInvalidSequence,omitempty"`
+	Error                string `json:"error,omitempty"`
+}
+
+// Verify walks the whole audit log and checks if every entry matches its hash and is linked to the previous entry
+func Verify(auditLog storage.AuditLog) (VerificationResult, error) {
+	result := VerificationResult{Valid: true}
+	var lastSequence int64
+	previousHash := ""
+	for {
+		entries, err := auditLog.ListAfter(lastSequence, verifyBatchSize)
+		if err != nil {
+			return VerificationResult{}, fmt.Errorf("while listing audit entries after %d: %w", lastSequence, err)
+		}
+		for _, entry := range entries {
+			result.Entries++
+			switch {
+			case entry.Sequence != lastSequence+1:
+				return invalid(result, lastSequence+1, fmt.Sprintf("entry %d is missing", lastSequence+1)), nil
+			case entry.PreviousHash != previousHash:
+				return invalid(result, entry.Sequence, fmt.Sprintf("entry %d is not linked to the previous entry", entry.Sequence)), nil
+			case entry.Hash != entry.ComputeHash():
+				return invalid(result, entry.Sequence, fmt.Sprintf("entry %d does not match its hash", entry.Sequence)), nil
+			}
+			lastSequence = entry.Sequence
+			previousHash = entry.Hash
+		}
+		if len(entries) < verifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+func invalid(result VerificationResult, sequence int64, reason string) VerificationResult {
+	result.Valid = false
+	result.FirstInvalidSequence = sequence
+	result.Error = reason
+	return result
+}
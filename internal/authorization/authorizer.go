@@ -0,0 +1,153 @@
+package authorization
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+)
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type Config struct {
+	Enabled bool `envconfig:"default=false"`
+	// IssuerURL is compared with the iss claim of the token, the claim is not checked if empty
+	IssuerURL string `envconfig:"optional"`
+	KeysURL   string `envconfig:"optional"`
+	// Audience is compared with the aud claim of the token, the claim is not checked if empty
+	Audience         string        `envconfig:"optional"`
+	GroupsClaim      string        `envconfig:"default=groups"`
+	JWKSCacheTTL     time.Duration `envconfig:"default=1h"`
+	PoliciesFilePath string        `envconfig:"optional"`
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.KeysURL == "" {
+		return fmt.Errorf("keys URL must be set if the authorization is enabled")
+	}
+	if c.PoliciesFilePath == "" {
+		return fmt.Errorf("policies file path must be set if the authorization is enabled")
+	}
+	return nil
+}
+
+// Claims are the verified claims of the caller's token
+type Claims struct {
+	Subject string
+	// ClientID is taken from the client_id claim or, if it is not set, from the azp claim
+	ClientID string
+	Groups   []string
+	raw      jwt.MapClaims
+}
+
+// Has returns true if the claim is equal to the value or is a list containing the value
+func (c Claims) Has(name, value string) bool {
+	switch claim := c.raw[name].(type) {
+	case string:
+		return claim == value
+	case []any:
+		return slices.Contains(claim, any(value))
+	}
+	return false
+}
+
+type Authorizer struct {
+	config   Config
+	policies *Policies
+	keySet   *KeySet
+	parser   *jwt.Parser
+	log      *slog.Logger
+}
+
+func NewAuthorizer(config Config, policies *Policies, log *slog.Logger) *Authorizer {
+	return &Authorizer{
+		config:   config,
+		policies: policies,
+		keySet:   NewKeySet(config.KeysURL, config.JWKSCacheTTL),
+		parser:   jwt.NewParser(jwt.WithValidMethods(signingMethods)),
+		log:      log.With("service", "Authorizer"),
+	}
+}
+
+// Middleware verifies the token of the calls to the protected paths and checks if any policy of the caller allows the call.
+// The query of the allowed call is restricted to the scopes of the policy. It can be used in the httputil.Router.
+func (a *Authorizer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the CORS preflight requests do not contain the token
+		if req.Method == http.MethodOptions || !a.policies.Protects(req.URL.Path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		claims, err := a.verify(req)
+		if err != nil {
+			a.log.Info(fmt.Sprintf("unauthorized call: method=%s url=%s: %s", req.Method, req.URL.Path, err))
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			httputil.WriteErrorResponse(w, http.StatusUnauthorized, fmt.Errorf("invalid or missing bearer token"))
+			return
+		}
+		query, allowed := a.policies.Authorize(claims, req)
+		if !allowed {
+			a.log.Info(fmt.Sprintf("forbidden call: method=%s url=%s subject=%s", req.Method, req.URL.String(), claims.Subject))
+			httputil.WriteErrorResponse(w, http.StatusForbidden, fmt.Errorf("the call is not allowed by any authorization policy"))
+			return
+		}
+
+		authorized := req.Clone(AddClaimsToContext(req.Context(), claims))
+		authorized.URL.RawQuery = query.Encode()
+		next.ServeHTTP(w, authorized)
+	})
+}
+
+func (a *Authorizer) verify(req *http.Request) (Claims, error) {
+	token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return Claims{}, errors.New("bearer token is missing")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.keySet.Key(kid)
+	})
+	if err != nil {
+		return Claims{}, err
+	}
+	// the parser accepts the tokens without the exp claim, which would never expire
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return Claims{}, errors.New("token does not define the expiration time")
+	}
+	if a.config.IssuerURL != "" && !claims.VerifyIssuer(a.config.IssuerURL, true) {
+		return Claims{}, fmt.Errorf("unexpected issuer %v", claims["iss"])
+	}
+	if a.config.Audience != "" && !claims.VerifyAudience(a.config.Audience, true) {
+		return Claims{}, fmt.Errorf("unexpected audience %v", claims["aud"])
+	}
+
+	result := Claims{raw: claims}
+	result.Subject, _ = claims["sub"].(string)
+	result.ClientID, _ = claims["client_id"].(string)
+	if result.ClientID == "" {
+		result.ClientID, _ = claims["azp"].(string)
+	}
+	switch groups := claims[a.config.GroupsClaim].(type) {
+	case string:
+		result.Groups = []string{groups}
+	case []any:
+		for _, group := range groups {
+			if g, ok := group.(string); ok {
+				result.Groups = append(result.Groups, g)
+			}
+		}
+	}
+	return result, nil
+}
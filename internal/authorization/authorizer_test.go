@@ -0,0 +1,199 @@
+package authorization
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/kyma-project/kyma-environment-broker/internal/authorization/testissuer"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const policiesYAML = `
+policies:
+  - name: operators
+    groups: [runtimeAdmin, runtimeOperator]
+    endpoints:
+      - path: /runtimes
+        methods: [get]
+      - path: /kubeconfig/*
+        methods: [GET]
+  - name: metrics-collector
+    claims:
+      client_id: kmc
+    endpoints:
+      - path: /runtimes
+        methods: [GET]
+        scopes:
+          account: [ga-1, ga-2]
+`
+
+func TestMiddleware(t *testing.T) {
+	issuer, err := testissuer.New()
+	require.NoError(t, err)
+	defer issuer.Close()
+
+	policies, err := NewPolicies(strings.NewReader(policiesYAML))
+	require.NoError(t, err)
+	authorizer := NewAuthorizer(Config{
+		Enabled:      true,
+		IssuerURL:    issuer.URL(),
+		KeysURL:      issuer.KeysURL(),
+		Audience:     "keb",
+		GroupsClaim:  "groups",
+		JWKSCacheTTL: time.Hour,
+	}, policies, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var receivedQuery string
+	var receivedClaims *Claims
+	router := httputil.NewRouter()
+	router.Use(authorizer.Middleware)
+	router.HandleFunc("GET /runtimes", func(w http.ResponseWriter, r *http.Request) {
+		receivedQuery = r.URL.RawQuery
+		if claims, found := ClaimsFromContext(r.Context()); found {
+			receivedClaims = &claims
+		}
+	})
+	router.HandleFunc("GET /kubeconfig/{instance_id}", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("PUT /expire/service_instance/{instance_id}", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("GET /info/runtimes", func(w http.ResponseWriter, r *http.Request) {
+		if claims, found := ClaimsFromContext(r.Context()); found {
+			receivedClaims = &claims
+		}
+	})
+
+	fixToken := func(claims jwt.MapClaims) string {
+		claims["aud"] = "keb"
+		token, err := issuer.Token(claims)
+		require.NoError(t, err)
+		return token
+	}
+	call := func(method, target, token string) *httptest.ResponseRecorder {
+		receivedQuery = ""
+		receivedClaims = nil
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should allow the call of the operator", func(t *testing.T) {
+		token := fixToken(jwt.MapClaims{"sub": "john", "groups": []string{"runtimeViewer", "runtimeOperator"}})
+
+		assert.Equal(t, http.StatusOK, call(http.MethodGet, "/runtimes?plan=aws", token).Code)
+		assert.Equal(t, "plan=aws", receivedQuery)
+		require.NotNil(t, receivedClaims)
+		assert.Equal(t, "john", receivedClaims.Subject)
+		assert.Equal(t, []string{"runtimeViewer", "runtimeOperator"}, receivedClaims.Groups)
+		assert.Equal(t, http.StatusOK, call(http.MethodGet, "/kubeconfig/inst-1", token).Code)
+	})
+
+	t.Run("should forbid the call not allowed by any policy", func(t *testing.T) {
+		operator := fixToken(jwt.MapClaims{"sub": "john", "groups": "runtimeOperator"})
+		viewer := fixToken(jwt.MapClaims{"sub": "anna", "groups": []string{"runtimeViewer"}})
+
+		assert.Equal(t, http.StatusForbidden, call(http.MethodPut, "/expire/service_instance/inst-1", operator).Code)
+		assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/runtimes", viewer).Code)
+	})
+
+	t.Run("should restrict the call to the scope of the policy", func(t *testing.T) {
+		token := fixToken(jwt.MapClaims{"sub": "kmc", "client_id": "kmc"})
+
+		assert.Equal(t, http.StatusOK, call(http.MethodGet, "/runtimes?state=succeeded", token).Code)
+		assert.Equal(t, "account=ga-1&account=ga-2&state=succeeded", receivedQuery)
+		require.NotNil(t, receivedClaims)
+		assert.Equal(t, "kmc", receivedClaims.ClientID)
+		assert.Equal(t, http.StatusOK, call(http.MethodGet, "/runtimes?account=ga-2", token).Code)
+		assert.Equal(t, "account=ga-2", receivedQuery)
+		assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/runtimes?account=ga-3", token).Code)
+		assert.Empty(t, receivedQuery)
+	})
+
+	t.Run("should reject the missing or invalid token", func(t *testing.T) {
+		expired := fixToken(jwt.MapClaims{"groups": "runtimeAdmin", "exp": time.Now().Add(-time.Minute).Unix()})
+		withoutExpiration := fixToken(jwt.MapClaims{"groups": "runtimeAdmin", "exp": nil})
+		otherAudience, err := issuer.Token(jwt.MapClaims{"groups": "runtimeAdmin", "aud": "other"})
+		require.NoError(t, err)
+		otherIssuer := fixToken(jwt.MapClaims{"groups": "runtimeAdmin", "iss": "https://issuer.example.com"})
+		unsigned, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"groups": "runtimeAdmin", "aud": "keb", "iss": issuer.URL()}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		for name, token := range map[string]string{
+			"missing":        "",
+			"malformed":      "not-a-token",
+			"expired":        expired,
+			"no expiration":  withoutExpiration,
+			"other audience": otherAudience,
+			"other issuer":   otherIssuer,
+			"wrong key":      unsigned,
+		} {
+			w := call(http.MethodGet, "/runtimes", token)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+			assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"), name)
+		}
+	})
+
+	t.Run("should not require the token for the paths which are not protected", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, call(http.MethodGet, "/info/runtimes", "").Code)
+		assert.Nil(t, receivedClaims)
+	})
+}
+
+func TestNewPolicies(t *testing.T) {
+	t.Run("should set the default protected paths", func(t *testing.T) {
+		policies, err := NewPolicies(strings.NewReader(""))
+		require.NoError(t, err)
+
+		assert.Equal(t, DefaultProtectedPaths, policies.ProtectedPaths)
+		assert.True(t, policies.Protects("/runtimes"))
+		assert.True(t, policies.Protects("/expire/service_instance/inst-1"))
+		assert.False(t, policies.Protects("/runtimesx"))
+		assert.False(t, policies.Protects("/oauth/v2/catalog"))
+	})
+
+	for name, tc := range map[string]struct {
+		policies    string
+		expectedErr string
+	}{
+		"missing name": {
+			policies:    "policies: [{endpoints: [{path: /runtimes}]}]",
+			expectedErr: "policy name must not be empty",
+		},
+		"duplicated name": {
+			policies:    "policies: [{name: a, endpoints: [{path: /runtimes}]}, {name: a, endpoints: [{path: /events}]}]",
+			expectedErr: "policy a is defined more than once",
+		},
+		"no endpoints": {
+			policies:    "policies: [{name: a}]",
+			expectedErr: "policy a must define at least one endpoint",
+		},
+		"relative path": {
+			policies:    "policies: [{name: a, endpoints: [{path: runtimes}]}]",
+			expectedErr: `policy a: endpoint path "runtimes" must start with /`,
+		},
+		"empty scope": {
+			policies:    "policies: [{name: a, endpoints: [{path: /runtimes, scopes: {account: []}}]}]",
+			expectedErr: "policy a: scope account of endpoint /runtimes must define at least one value",
+		},
+		"relative protected path": {
+			policies:    "protectedPaths: [runtimes]",
+			expectedErr: `protected path "runtimes" must start with /`,
+		},
+	} {
+		t.Run("should reject the policies with "+name, func(t *testing.T) {
+			_, err := NewPolicies(strings.NewReader(tc.policies))
+
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package authorization
+
+import (
+	"context"
+)
+
+// The key type is not exported to prevent collisions with context keys
+// defined in other packages.
+type key int
+
+const (
+	// claimsKey is the context key for the verified claims of the caller.
+	claimsKey key = iota + 1
+)
+
+// AddClaimsToContext returns a copy of the context with the verified claims of the caller
+func AddClaimsToContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext returns the verified claims of the caller, which are set by the Authorizer middleware
+// for the calls to the protected paths.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(Claims)
+	return claims, ok
+}
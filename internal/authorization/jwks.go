@@ -0,0 +1,212 @@
+package authorization
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval limits how often the keys are fetched, so the tokens signed with unknown keys
+// or an unavailable issuer do not cause a request to the issuer for every call
+const minRefreshInterval = 10 * time.Second
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet provides the public keys of the issuer fetched from the JWKS endpoint. The keys are cached for the configured time,
+// and fetched again earlier if the token is signed with an unknown key, for example, after the keys were rotated.
+type KeySet struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+	inflight    *fetchCall
+}
+
+// fetchCall is the fetch of the keys in progress, ok is set before done is closed
+type fetchCall struct {
+	done chan struct{}
+	ok   bool
+}
+
+func NewKeySet(url string, ttl time.Duration) *KeySet {
+	return &KeySet{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Key returns the key with the given ID. The ID can be empty if the issuer has only one key.
+// If the issuer is not available, the previously fetched keys are used.
+func (k *KeySet) Key(kid string) (any, error) {
+	k.mu.Lock()
+	expired := k.keys == nil || k.now().Sub(k.fetchedAt) > k.ttl
+	k.mu.Unlock()
+
+	if expired {
+		k.refresh()
+	}
+	if key, found := k.find(kid); found {
+		return key, nil
+	}
+	if k.refresh() {
+		if key, found := k.find(kid); found {
+			return key, nil
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.keys == nil && k.lastErr != nil {
+		return nil, k.lastErr
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (k *KeySet) find(kid string) (any, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+	key, found := k.keys[kid]
+	return key, found
+}
+
+// refresh fetches the keys unless they were fetched recently and returns true if the keys were fetched successfully.
+// The keys are fetched without holding the lock, the concurrent callers wait for the result of the fetch in progress.
+func (k *KeySet) refresh() bool {
+	k.mu.Lock()
+	if call := k.inflight; call != nil {
+		k.mu.Unlock()
+		<-call.done
+		return call.ok
+	}
+	if !k.lastAttempt.IsZero() && k.now().Sub(k.lastAttempt) < minRefreshInterval {
+		k.mu.Unlock()
+		return false
+	}
+	k.lastAttempt = k.now()
+	call := &fetchCall{done: make(chan struct{})}
+	k.inflight = call
+	k.mu.Unlock()
+
+	keys, err := k.fetch()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err != nil {
+		k.lastErr = err
+	} else {
+		k.keys = keys
+		k.fetchedAt = k.now()
+		k.lastErr = nil
+		call.ok = true
+	}
+	k.inflight = nil
+	close(call.done)
+	return call.ok
+}
+
+func (k *KeySet) fetch() (map[string]any, error) {
+	resp, err := k.client.Get(k.url)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching keys from %s: %w", k.url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("while fetching keys from %s: unexpected status code %d", k.url, resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("while decoding keys from %s: %w", k.url, err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// the keys of the unsupported types cannot be used to verify the tokens
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (j jsonWebKey) publicKey() (any, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("malformed key parameter: %w", err)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
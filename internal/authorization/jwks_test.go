@@ -0,0 +1,141 @@
+package authorization
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet(t *testing.T) {
+	// given
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var published atomic.Value
+	published.Store(map[string]*rsa.PrivateKey{"key-1": key1})
+	var fetches atomic.Int32
+	available := atomic.Bool{}
+	available.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		keys := []map[string]string{{"kty": "oct", "kid": "symmetric"}}
+		for kid, key := range published.Load().(map[string]*rsa.PrivateKey) {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	keySet := NewKeySet(server.URL, time.Hour)
+	keySet.now = func() time.Time { return now }
+
+	t.Run("should fetch the keys once and cache them", func(t *testing.T) {
+		for range 3 {
+			key, err := keySet.Key("key-1")
+			require.NoError(t, err)
+			assert.Equal(t, &key1.PublicKey, key)
+		}
+		assert.Equal(t, int32(1), fetches.Load())
+	})
+
+	t.Run("should fetch the rotated keys if the key is unknown", func(t *testing.T) {
+		published.Store(map[string]*rsa.PrivateKey{"key-1": key1, "key-2": key2})
+
+		_, err := keySet.Key("key-2")
+		assert.EqualError(t, err, `unknown signing key "key-2"`)
+
+		now = now.Add(minRefreshInterval + time.Second)
+		key, err := keySet.Key("key-2")
+		require.NoError(t, err)
+		assert.Equal(t, &key2.PublicKey, key)
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+
+	t.Run("should use the cached keys if the issuer is not available", func(t *testing.T) {
+		available.Store(false)
+		now = now.Add(2 * time.Hour)
+
+		key, err := keySet.Key("key-1")
+		require.NoError(t, err)
+		assert.Equal(t, &key1.PublicKey, key)
+		assert.Equal(t, int32(3), fetches.Load())
+	})
+
+	t.Run("should return the error if the keys cannot be fetched", func(t *testing.T) {
+		_, err := NewKeySet(server.URL, time.Hour).Key("key-1")
+
+		assert.ErrorContains(t, err, "unexpected status code 503")
+	})
+}
+
+func TestKeySet_ConcurrentFetch(t *testing.T) {
+	// given
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer server.Close()
+	keySet := NewKeySet(server.URL, time.Hour)
+
+	// when
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := keySet.Key("key-1")
+			errs <- err
+		}()
+	}
+	<-started
+
+	// then
+	_, found := keySet.find("key-1")
+	assert.False(t, found, "the lock must not be held while the keys are fetched")
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), fetches.Load())
+}
@@ -0,0 +1,194 @@
+package authorization
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProtectedPaths are the endpoints which require the token if the policies do not define the protected paths
+var DefaultProtectedPaths = []string{"/runtimes", "/events", "/kubeconfig", "/expire", "/additional_properties", "/audit",
+	"/transfer", "/operations", "/instances", "/quota", "/modules"}
+
+type Policies struct {
+	// ProtectedPaths are the path prefixes of the endpoints which require the token
+	ProtectedPaths []string `yaml:"protectedPaths"`
+	Policies       []Policy `yaml:"policies"`
+}
+
+// Policy allows the callers whose token contains any of the groups and all the claims to call the endpoints
+type Policy struct {
+	Name      string            `yaml:"name"`
+	Groups    []string          `yaml:"groups"`
+	Claims    map[string]string `yaml:"claims"`
+	Endpoints []Endpoint        `yaml:"endpoints"`
+}
+
+type Endpoint struct {
+	// Methods are the allowed HTTP methods, all methods are allowed if empty
+	Methods []string `yaml:"methods"`
+	// Path is the pattern of the path, where "*" matches a single path segment
+	Path string `yaml:"path"`
+	// Scopes restrict the values of the query parameters. If the request does not contain the parameter,
+	// the parameter is set to the allowed values, so the endpoint returns only the data in the scope.
+	Scopes map[string][]string `yaml:"scopes"`
+}
+
+func NewPoliciesFromFile(filePath string) (*Policies, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("while opening authorization policies file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return NewPolicies(file)
+}
+
+func NewPolicies(r io.Reader) (*Policies, error) {
+	policies := &Policies{}
+	if err := yaml.NewDecoder(r).Decode(policies); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("while decoding authorization policies: %w", err)
+	}
+	if err := policies.validate(); err != nil {
+		return nil, err
+	}
+	if len(policies.ProtectedPaths) == 0 {
+		policies.ProtectedPaths = DefaultProtectedPaths
+	}
+	for i := range policies.Policies {
+		for j := range policies.Policies[i].Endpoints {
+			endpoint := &policies.Policies[i].Endpoints[j]
+			for k := range endpoint.Methods {
+				endpoint.Methods[k] = strings.ToUpper(endpoint.Methods[k])
+			}
+		}
+	}
+	return policies, nil
+}
+
+func (p *Policies) validate() error {
+	for _, protectedPath := range p.ProtectedPaths {
+		if !strings.HasPrefix(protectedPath, "/") {
+			return fmt.Errorf("protected path %q must start with /", protectedPath)
+		}
+	}
+	names := map[string]struct{}{}
+	for _, policy := range p.Policies {
+		if policy.Name == "" {
+			return fmt.Errorf("policy name must not be empty")
+		}
+		if _, exists := names[policy.Name]; exists {
+			return fmt.Errorf("policy %s is defined more than once", policy.Name)
+		}
+		names[policy.Name] = struct{}{}
+		if len(policy.Endpoints) == 0 {
+			return fmt.Errorf("policy %s must define at least one endpoint", policy.Name)
+		}
+		for _, endpoint := range policy.Endpoints {
+			if err := endpoint.validate(); err != nil {
+				return fmt.Errorf("policy %s: %w", policy.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (e Endpoint) validate() error {
+	if !strings.HasPrefix(e.Path, "/") {
+		return fmt.Errorf("endpoint path %q must start with /", e.Path)
+	}
+	if _, err := path.Match(e.Path, ""); err != nil {
+		return fmt.Errorf("endpoint path %q is malformed: %w", e.Path, err)
+	}
+	for param, allowed := range e.Scopes {
+		// an empty list would remove the restriction of the parameter
+		if len(allowed) == 0 {
+			return fmt.Errorf("scope %s of endpoint %s must define at least one value", param, e.Path)
+		}
+	}
+	return nil
+}
+
+// Protects returns true if the path requires the token
+func (p *Policies) Protects(requestPath string) bool {
+	for _, protectedPath := range p.ProtectedPaths {
+		prefix := strings.TrimSuffix(protectedPath, "/")
+		if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize returns the query of the request restricted to the scopes of the endpoint which allows the call.
+// The endpoints without scopes are preferred. Returns false if no policy of the caller allows the call.
+func (p *Policies) Authorize(claims Claims, req *http.Request) (url.Values, bool) {
+	var scoped []Endpoint
+	for _, policy := range p.Policies {
+		if !policy.appliesTo(claims) {
+			continue
+		}
+		for _, endpoint := range policy.Endpoints {
+			if !endpoint.matches(req.Method, req.URL.Path) {
+				continue
+			}
+			if len(endpoint.Scopes) == 0 {
+				return req.URL.Query(), true
+			}
+			scoped = append(scoped, endpoint)
+		}
+	}
+	for _, endpoint := range scoped {
+		if query, ok := endpoint.restrict(req.URL.Query()); ok {
+			return query, true
+		}
+	}
+	return nil, false
+}
+
+func (p Policy) appliesTo(claims Claims) bool {
+	if len(p.Groups) > 0 && !slices.ContainsFunc(p.Groups, func(group string) bool { return slices.Contains(claims.Groups, group) }) {
+		return false
+	}
+	for name, value := range p.Claims {
+		if !claims.Has(name, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e Endpoint) matches(method, requestPath string) bool {
+	if len(e.Methods) > 0 && !slices.Contains(e.Methods, method) {
+		return false
+	}
+	matched, _ := path.Match(e.Path, requestPath)
+	return matched
+}
+
+func (e Endpoint) restrict(query url.Values) (url.Values, bool) {
+	restricted := url.Values{}
+	for param, values := range query {
+		restricted[param] = values
+	}
+	for param, allowed := range e.Scopes {
+		values := query[param]
+		if len(values) == 0 {
+			restricted[param] = allowed
+			continue
+		}
+		for _, value := range values {
+			if !slices.Contains(allowed, value) {
+				return nil, false
+			}
+		}
+	}
+	return restricted, true
+}
@@ -0,0 +1,82 @@
+// Package testissuer provides a local OpenID issuer, which serves the JWKS endpoint and signs the tokens,
+// so the authorization can be tested without an external identity provider.
+package testissuer
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const keyID = "test-key"
+
+type Issuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+// New starts the issuer, which must be closed after the test
+func New() (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	issuer := &Issuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /oauth2/certs", issuer.serveKeys)
+	issuer.server = httptest.NewServer(mux)
+	return issuer, nil
+}
+
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+func (i *Issuer) KeysURL() string {
+	return i.server.URL + "/oauth2/certs"
+}
+
+// Token returns the token signed by the issuer. The iss and exp claims are set unless they are provided,
+// and the claims with the nil value are removed.
+func (i *Issuer) Token(claims jwt.MapClaims) (string, error) {
+	signed := jwt.MapClaims{
+		"iss": i.URL(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(signed, name)
+			continue
+		}
+		signed[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, signed)
+	token.Header["kid"] = keyID
+	return token.SignedString(i.key)
+}
+
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+func (i *Issuer) serveKeys(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+		}},
+	})
+}
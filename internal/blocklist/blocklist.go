@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // PlanValidator validates plan names. Implemented by broker.AvailablePlansType
@@ -16,18 +17,26 @@ type PlanValidator interface {
 	IsPlanName(name string) bool
 }
 
+// TagsProvider returns the tags of an instance. Implemented by storage.InstanceTags.
+type TagsProvider interface {
+	GetByInstanceID(instanceID string) (map[string]string, error)
+}
+
 // OperationContext carries the attributes of an incoming operation used when
 // matching blocking rules. Add fields here to extend filtering capabilities
 // (e.g. SubAccountID for SA!= support in the future).
 type OperationContext struct {
 	PlanName        string
 	GlobalAccountID string
+	// InstanceID is used to get the instance tags, it is empty for provisioning
+	InstanceID string
 }
 
 // Rule holds a parsed blocking rule.
 //
 // Compact string format: '"message"' or '"message","plan=val1,val2"'
 // or '"message","plan=val1,val2","GA!=<id1>,<id2>"'
+// or '"message","tags=<selector>"'
 //
 // The message is a double-quoted string as the first token. The optional
 // tokens are double-quoted key=value or key!=value strings.
@@ -35,9 +44,10 @@ type OperationContext struct {
 // The message may contain the {plan} placeholder.
 type Rule struct {
 	Message               string
-	Plan                  string   // comma-separated list; empty = match all plans
-	IncludeGlobalAccounts []string // GA= values; nil = no filter
-	ExcludeGlobalAccounts []string // GA!= values; nil = no exclusion
+	Plan                  string          // comma-separated list; empty = match all plans
+	IncludeGlobalAccounts []string        // GA= values; nil = no filter
+	ExcludeGlobalAccounts []string        // GA!= values; nil = no exclusion
+	TagSelector           labels.Selector // tags= selector; nil = no filter
 }
 
 // parseRule parses a compact rule string. Tokens are comma-separated quoted
@@ -48,11 +58,13 @@ type Rule struct {
 //	'"message","plan=aws,gcp"'
 //	'"message","plan=trial","GA!=12345"'
 //	'"message","plan=trial","GA=12345"'
+//	'"message","tags=customer-tier=gold,!incident"'
 //
 // Supported filter tokens:
 //   - plan=<name1>,<name2>  — match specific plans (comma-separated)
 //   - GA=<globalAccountID>  — match only the specified GlobalAccount
 //   - GA!=<globalAccountID> — exclude a GlobalAccount from being blocked
+//   - tags=<selector>       — match instances with tags selected by the label selector
 func parseRule(s string) (Rule, error) {
 	if strings.TrimSpace(s) == "" {
 		return Rule{}, nil // empty string is a no-op, caller must skip
@@ -73,6 +85,18 @@ func parseRule(s string) (Rule, error) {
 		return Rule{}, nil // no filters — no-op, caller must skip
 	}
 	for _, tok := range tokens[1:] {
+		// The tag selector may contain both "=" and "!=", so it is parsed as a whole.
+		if val, found := strings.CutPrefix(strings.TrimSpace(tok), "tags="); found {
+			if strings.TrimSpace(val) == "" {
+				return Rule{}, fmt.Errorf("empty tags filter in rule %q", s)
+			}
+			selector, err := labels.Parse(val)
+			if err != nil {
+				return Rule{}, fmt.Errorf("invalid tags filter in rule %q: %w", s, err)
+			}
+			r.TagSelector = selector
+			continue
+		}
 		// Check for negation operator (!=) before equality (=) to avoid
 		// misparse: "GA!=X" with IndexByte('=') would yield key="GA!".
 		if bangIdx := strings.Index(tok, "!="); bangIdx != -1 {
@@ -114,7 +138,7 @@ func parseRule(s string) (Rule, error) {
 			}
 			r.IncludeGlobalAccounts = parts
 		default:
-			return Rule{}, fmt.Errorf("unknown key %q in rule %q (allowed: \"plan=\", \"GA=\", \"GA!=\")", key, s)
+			return Rule{}, fmt.Errorf("unknown key %q in rule %q (allowed: \"plan=\", \"GA=\", \"GA!=\", \"tags=\")", key, s)
 		}
 	}
 	if (len(r.IncludeGlobalAccounts) > 0 || len(r.ExcludeGlobalAccounts) > 0) && r.Plan == "" {
@@ -219,6 +243,7 @@ type OperationBlocklist struct {
 	Deprovision ruleList `yaml:"deprovision"`
 
 	planValidator PlanValidator
+	tagsProvider  TagsProvider
 }
 
 // WithPlanValidator returns a copy of the blocklist with the given PlanValidator set.
@@ -251,6 +276,13 @@ func (b OperationBlocklist) WithPlanValidator(v PlanValidator) (OperationBlockli
 	return b, nil
 }
 
+// WithTagsProvider returns a copy of the blocklist which gets the instance tags for rules with the tags= filter
+// from the given provider. Without the provider, instances are treated as having no tags.
+func (b OperationBlocklist) WithTagsProvider(p TagsProvider) OperationBlocklist {
+	b.tagsProvider = p
+	return b
+}
+
 // ReadFromFile loads an OperationBlocklist from a YAML file.
 // The file contains the blocklist fields directly (no outer key):
 //
@@ -275,44 +307,89 @@ func ReadFromFile(path string) (OperationBlocklist, error) {
 		}
 		return OperationBlocklist{}, fmt.Errorf("while reading operation blocklist: %w", err)
 	}
+	for _, r := range bl.Provision {
+		if r.TagSelector != nil {
+			return OperationBlocklist{}, fmt.Errorf("tags filter is not supported in provision rules, the instance has no tags before it is provisioned")
+		}
+	}
 	return bl, nil
 }
 
 // CheckProvision returns a non-nil error when a provision rule matches ctx.
 func (b *OperationBlocklist) CheckProvision(ctx OperationContext) error {
-	return checkRules(b.Provision, b.planValidator, ctx)
+	return b.checkRules(b.Provision, ctx)
 }
 
 // CheckUpdate returns a non-nil error when an update rule matches ctx.
 func (b *OperationBlocklist) CheckUpdate(ctx OperationContext) error {
-	return checkRules(b.Update, b.planValidator, ctx)
+	return b.checkRules(b.Update, ctx)
 }
 
 // CheckPlanUpgrade returns a non-nil error when a planUpgrade rule matches ctx.
 func (b *OperationBlocklist) CheckPlanUpgrade(ctx OperationContext) error {
-	return checkRules(b.PlanUpgrade, b.planValidator, ctx)
+	return b.checkRules(b.PlanUpgrade, ctx)
 }
 
 // CheckDeprovision returns a non-nil error when a deprovision rule matches ctx.
 func (b *OperationBlocklist) CheckDeprovision(ctx OperationContext) error {
-	return checkRules(b.Deprovision, b.planValidator, ctx)
+	return b.checkRules(b.Deprovision, ctx)
+}
+
+// BlockedError is returned when a blocking rule matches the operation. Other errors returned by the checks mean that
+// a rule could not be evaluated, for example, because the instance tags could not be fetched.
+type BlockedError struct {
+	message string
 }
 
-// checkRules iterates rules and returns an error for the first matching one.
-func checkRules(rules []Rule, pv PlanValidator, ctx OperationContext) error {
+func (e *BlockedError) Error() string {
+	return e.message
+}
+
+// IsBlocked returns true if the error was returned because a blocking rule matched the operation.
+func IsBlocked(err error) bool {
+	var blocked *BlockedError
+	return errors.As(err, &blocked)
+}
+
+// checkRules iterates rules and returns a BlockedError for the first matching one.
+// The instance tags are fetched only once and only when a rule with the tags= filter is checked.
+// When the tags cannot be fetched, the remaining rules are still checked, and the error is returned only if no rule matches.
+func (b *OperationBlocklist) checkRules(rules []Rule, ctx OperationContext) error {
+	var tags labels.Set
+	var tagsErr error
+	getTags := func() (labels.Set, error) {
+		if tags != nil || tagsErr != nil || b.tagsProvider == nil || ctx.InstanceID == "" {
+			return tags, tagsErr
+		}
+		fetched, err := b.tagsProvider.GetByInstanceID(ctx.InstanceID)
+		if err != nil {
+			tagsErr = err
+			return nil, err
+		}
+		tags = labels.Set(fetched)
+		return tags, nil
+	}
 	for _, r := range rules {
-		if matchesRule(r, pv, ctx) {
-			return fmt.Errorf("%s", formatMessage(r.Message, ctx))
+		matched, err := matchesRule(r, b.planValidator, ctx, getTags)
+		if err != nil {
+			continue
+		}
+		if matched {
+			return &BlockedError{message: formatMessage(r.Message, ctx)}
 		}
 	}
+	if tagsErr != nil {
+		return fmt.Errorf("while checking the operation blocklist: unable to get instance tags: %w", tagsErr)
+	}
 	return nil
 }
 
 // matchesRule returns true when all of the rule's filters match the context.
 // Each guard returns false when its condition excludes this operation from the rule.
-func matchesRule(r Rule, pv PlanValidator, ctx OperationContext) bool {
+// The tags filter is checked last, so the tags are not fetched when other filters exclude the operation.
+func matchesRule(r Rule, pv PlanValidator, ctx OperationContext, getTags func() (labels.Set, error)) (bool, error) {
 	if r.Plan != "" && !matchesPlan(pv, r.Plan, ctx.PlanName) {
-		return false
+		return false, nil
 	}
 	if len(r.IncludeGlobalAccounts) > 0 {
 		matched := false
@@ -323,17 +400,26 @@ func matchesRule(r Rule, pv PlanValidator, ctx OperationContext) bool {
 			}
 		}
 		if !matched {
-			return false
+			return false, nil
 		}
 	}
 	if len(r.ExcludeGlobalAccounts) > 0 {
 		for _, ga := range r.ExcludeGlobalAccounts {
 			if strings.EqualFold(ga, ctx.GlobalAccountID) {
-				return false
+				return false, nil
 			}
 		}
 	}
-	return true
+	if r.TagSelector != nil {
+		tags, err := getTags()
+		if err != nil {
+			return false, err
+		}
+		if !r.TagSelector.Matches(tags) {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 // matchesPlan checks whether rulePlan (comma-separated list) contains operationPlan.
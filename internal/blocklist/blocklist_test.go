@@ -1,6 +1,7 @@
 package blocklist_test
 
 import (
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -469,3 +470,95 @@ func TestParseRule_EmptyPlanSegment(t *testing.T) {
 	_, err := blocklist.ReadFromFile(path)
 	assert.Error(t, err)
 }
+
+// --- tags filter ---
+
+type fakeTagsProvider struct {
+	tags  map[string]map[string]string
+	err   error
+	calls int
+}
+
+func (f *fakeTagsProvider) GetByInstanceID(instanceID string) (map[string]string, error) {
+	f.calls++
+	return f.tags[instanceID], f.err
+}
+
+func TestTags_SelectorMatchesInstanceTags(t *testing.T) {
+	bl, err := parseInline("update", `"blocked during incident","plan=aws","tags=customer-tier in (gold),incident"`)
+	require.NoError(t, err)
+	bl = bl.WithTagsProvider(&fakeTagsProvider{tags: map[string]map[string]string{
+		"gold-incident": {"customer-tier": "gold", "incident": "1234"},
+		"gold":          {"customer-tier": "gold"},
+	}})
+
+	assert.EqualError(t, bl.CheckUpdate(blocklist.OperationContext{PlanName: "aws", InstanceID: "gold-incident"}), "blocked during incident")
+	assert.NoError(t, bl.CheckUpdate(blocklist.OperationContext{PlanName: "aws", InstanceID: "gold"}))
+	assert.NoError(t, bl.CheckUpdate(blocklist.OperationContext{PlanName: "gcp", InstanceID: "gold-incident"}))
+}
+
+func TestTags_WithoutPlan(t *testing.T) {
+	bl, err := parseInline("deprovision", `"protected","tags=protected=true"`)
+	require.NoError(t, err)
+	bl = bl.WithTagsProvider(&fakeTagsProvider{tags: map[string]map[string]string{"inst-1": {"protected": "true"}}})
+
+	assert.EqualError(t, bl.CheckDeprovision(blocklist.OperationContext{PlanName: "aws", InstanceID: "inst-1"}), "protected")
+	assert.NoError(t, bl.CheckDeprovision(blocklist.OperationContext{PlanName: "aws", InstanceID: "inst-2"}))
+}
+
+func TestTags_FetchedOnlyWhenOtherFiltersMatch(t *testing.T) {
+	bl, err := parseInline("update", `"first","plan=gcp","tags=incident"`, `"second","plan=gcp","tags=protected"`)
+	require.NoError(t, err)
+	provider := &fakeTagsProvider{tags: map[string]map[string]string{"inst-1": {"protected": "true"}}}
+	bl = bl.WithTagsProvider(provider)
+
+	assert.NoError(t, bl.CheckUpdate(blocklist.OperationContext{PlanName: "aws", InstanceID: "inst-1"}))
+	assert.Equal(t, 0, provider.calls)
+	assert.EqualError(t, bl.CheckUpdate(blocklist.OperationContext{PlanName: "gcp", InstanceID: "inst-1"}), "second")
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestTags_ProviderErrorIsNotBlocked(t *testing.T) {
+	bl, err := parseInline("update", `"blocked","tags=incident"`)
+	require.NoError(t, err)
+	bl = bl.WithTagsProvider(&fakeTagsProvider{err: errors.New("connection refused")})
+
+	err = bl.CheckUpdate(blocklist.OperationContext{PlanName: "aws", InstanceID: "inst-1"})
+	assert.ErrorContains(t, err, "connection refused")
+	assert.False(t, blocklist.IsBlocked(err))
+}
+
+func TestTags_ProviderErrorDoesNotHideOtherRules(t *testing.T) {
+	bl, err := parseInline("update", `"tagged","tags=incident"`, `"by plan","plan=aws"`)
+	require.NoError(t, err)
+	bl = bl.WithTagsProvider(&fakeTagsProvider{err: errors.New("connection refused")})
+
+	err = bl.CheckUpdate(blocklist.OperationContext{PlanName: "aws", InstanceID: "inst-1"})
+	assert.EqualError(t, err, "by plan")
+	assert.True(t, blocklist.IsBlocked(err))
+}
+
+func TestTags_NoProviderMeansNoTags(t *testing.T) {
+	bl, err := parseInline("update", `"blocked","tags=incident"`, `"untagged","tags=!customer-tier"`)
+	require.NoError(t, err)
+
+	assert.EqualError(t, bl.CheckUpdate(blocklist.OperationContext{PlanName: "aws", InstanceID: "inst-1"}), "untagged")
+}
+
+func TestTags_InvalidSelectorIsError(t *testing.T) {
+	path := writeYAML(t, "update: '\"msg\",\"tags=customer-tier in (gold\"'\n")
+	_, err := blocklist.ReadFromFile(path)
+	assert.Error(t, err)
+}
+
+func TestTags_EmptySelectorIsError(t *testing.T) {
+	path := writeYAML(t, "update: '\"msg\",\"tags=\"'\n")
+	_, err := blocklist.ReadFromFile(path)
+	assert.Error(t, err)
+}
+
+func TestTags_ProvisionRuleIsError(t *testing.T) {
+	path := writeYAML(t, "provision: '\"msg\",\"tags=incident\"'\n")
+	_, err := blocklist.ReadFromFile(path)
+	assert.Error(t, err)
+}
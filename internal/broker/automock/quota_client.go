@@ -2,16 +2,20 @@
 
 package automock
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // QuotaClient is an autogenerated mock type for the QuotaClient type
 type QuotaClient struct {
 	mock.Mock
 }
 
-// GetQuota provides a mock function with given fields: subAccountID, planName
-func (_m *QuotaClient) GetQuota(subAccountID string, planName string) (int, error) {
-	ret := _m.Called(subAccountID, planName)
+// GetQuota provides a mock function with given fields: ctx, subAccountID, planName
+func (_m *QuotaClient) GetQuota(ctx context.Context, subAccountID string, planName string) (int, error) {
+	ret := _m.Called(ctx, subAccountID, planName)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetQuota")
@@ -19,17 +23,17 @@ func (_m *QuotaClient) GetQuota(subAccountID string, planName string) (int, erro
 
 	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (int, error)); ok {
-		return rf(subAccountID, planName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int, error)); ok {
+		return rf(ctx, subAccountID, planName)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) int); ok {
-		r0 = rf(subAccountID, planName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int); ok {
+		r0 = rf(ctx, subAccountID, planName)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(subAccountID, planName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, subAccountID, planName)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	broker "github.com/kyma-project/kyma-environment-broker/internal/broker/bindings"
@@ -94,6 +95,7 @@ func (b *UnbindEndpoint) unbind(ctx context.Context, instanceID, bindingID strin
 		return domain.UnbindSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("failed to delete binding resources for binding %s and instance %s: %v", bindingID, instanceID, err), http.StatusInternalServerError, fmt.Sprintf("failed to delete resources for binding %s and instance %s: %v", bindingID, instanceID, err))
 	}
 	b.log.Info(fmt.Sprintf("Successfully removed binding %s for instance %s", bindingID, instanceID))
+	events.Infof(instanceID, lastOperation.ID, "Binding %s deleted", bindingID)
 
 	return domain.UnbindSpec{
 		IsAsync: false,
@@ -70,6 +70,11 @@ type Config struct {
 
 	SyncEmptyUpdateResponseEnabled bool `envconfig:"default=false"`
 
+	// enables the OSB maintenance_info of the plans and instances.
+	MaintenanceInfoEnabled bool `envconfig:"default=false"`
+	// the catalog revision used as the patch version of the maintenance_info, increased when the Kyma channel of a plan changes.
+	MaintenanceInfoRevision int `envconfig:"default=0"`
+
 	AdditionalVolumeSizeGIPlans   StringList `envconfig:"optional"`
 	AdditionalVolumeSizeGiMaxSize int        `envconfig:"default=1000"`
 
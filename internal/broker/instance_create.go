@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
@@ -24,16 +25,17 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
 	error2 "github.com/kyma-project/kyma-environment-broker/internal/error"
-	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
 	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
 	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/kyma-environment-broker/internal/networking"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 	"github.com/kyma-project/kyma-environment-broker/internal/subscriptions"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 	"github.com/kyma-project/kyma-environment-broker/internal/validator"
 	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
 
@@ -71,7 +73,18 @@ type (
 	}
 
 	QuotaClient interface {
-		GetQuota(subAccountID, planName string) (int, error)
+		GetQuota(ctx context.Context, subAccountID, planName string) (int, error)
+	}
+
+	// QuotaEnforcer is implemented by quota clients which validate the global account and directory limits in addition to the subaccount quota.
+	// The quota is reserved for the instance until the returned release function is called, so that concurrent requests cannot exceed it.
+	QuotaEnforcer interface {
+		Reserve(ctx context.Context, instanceID, globalAccountID, subAccountID, planID string, update bool) (release func(), err error)
+	}
+
+	// ModuleValidator validates the requested Kyma modules against the modules available in KCP
+	ModuleValidator interface {
+		Validate(ctx context.Context, modules *pkg.ModulesDTO) error
 	}
 )
 
@@ -106,6 +119,8 @@ type ProvisionEndpoint struct {
 	gardenerClient         *gardener.Client
 	factory                hyperscalers.Factory
 	operationBlocklist     blocklist.OperationBlocklist
+	moduleValidator        ModuleValidator
+	residencyPolicies      *residency.Policies
 }
 
 const (
@@ -140,6 +155,7 @@ func NewProvision(brokerConfig Config,
 	gardenerClient *gardener.Client,
 	factory hyperscalers.Factory,
 	operationBlocklist blocklist.OperationBlocklist,
+	moduleValidator ModuleValidator,
 ) *ProvisionEndpoint {
 	enabledPlanIDs := map[string]struct{}{}
 	for _, planName := range brokerConfig.EnablePlans {
@@ -174,9 +190,17 @@ func NewProvision(brokerConfig Config,
 		gardenerClient:          gardenerClient,
 		factory:                 factory,
 		operationBlocklist:      operationBlocklist,
+		moduleValidator:         moduleValidator,
+		residencyPolicies:       residency.DefaultPolicies(),
 	}
 }
 
+// WithResidencyPolicies replaces the default residency policies the provisioning requests are validated against.
+func (b *ProvisionEndpoint) WithResidencyPolicies(policies *residency.Policies) *ProvisionEndpoint {
+	b.residencyPolicies = policies
+	return b
+}
+
 // Provision creates a new service instance
 //
 //	PUT /v2/service_instances/{instance_id}
@@ -211,7 +235,7 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "while extracting context")
 	}
 	if b.config.MonitorAdditionalProperties {
-		b.monitorAdditionalProperties(instanceID, ersContext, details.RawParameters)
+		b.monitorAdditionalProperties(instanceID, details.PlanID, ersContext, details.RawParameters)
 	}
 	provisioningParameters := internal.ProvisioningParameters{
 		PlanID:           details.PlanID,
@@ -221,8 +245,14 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		PlatformRegion:   region,
 		PlatformProvider: platformProvider,
 	}
+	planMaintenanceInfo := b.schemaService.MaintenanceInfo(AvailablePlans.GetPlanNameOrEmpty(PlanIDType(details.PlanID)))
+	if _, err := validateMaintenanceInfo(details.MaintenanceInfo, planMaintenanceInfo); err != nil {
+		logger.Warn(fmt.Sprintf("maintenance_info conflict: %s", err))
+		return domain.ProvisionedServiceSpec{}, err
+	}
+	provisioningParameters.MaintenanceInfo = planMaintenanceInfo
 	// TODO: remove once we implemented proper filtering of parameters - removing parameters that are not supported by the plan
-	if details.PlanID == TrialPlanID {
+	if IsTrialPlan(details.PlanID) {
 		provisioningParameters.Parameters.MachineType = nil
 		provisioningParameters.Parameters.AutoScalerMin = nil
 		provisioningParameters.Parameters.AutoScalerMax = nil
@@ -258,13 +288,21 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		return b.handleExistingOperation(existingOperation, provisioningParameters)
 	}
 
+	release, err := b.reserveQuota(ctx, instanceID, provisioningParameters)
+	if err != nil {
+		errMsg := fmt.Sprintf("[instanceID: %s] %s", instanceID, err)
+		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
+	}
+	defer release()
+
 	shootName := gardener.CreateShootName()
 	shootDomainSuffix := strings.Trim(b.shootDomain, ".")
 
 	dashboardURL := b.createDashboardURL(details.PlanID, instanceID)
 
 	// create and save new operation
-	operation, err := internal.NewProvisioningOperationWithID(operationID, instanceID, provisioningParameters)
+	operation, err := internal.NewProvisioningOperationWithID(operationID, instanceID, provisioningParameters,
+		b.residencyPolicies.IsEUAccess(provisioningParameters.PlatformRegion))
 	if err != nil {
 		logger.Error(fmt.Sprintf("cannot create new operation: %s", err))
 		return domain.ProvisionedServiceSpec{}, fmt.Errorf("cannot create new operation")
@@ -276,6 +314,7 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 	operation.ShootDNSProviders = b.shootDnsProviders
 	operation.DashboardURL = dashboardURL
 	operation.RawParameters = details.RawParameters
+	operation.TraceContext = tracing.Inject(ctx)
 	logger.Info(fmt.Sprintf("Runtime ShootDomain: %s", operation.ShootDomain))
 
 	err = b.operationsStorage.InsertOperation(operation.Operation)
@@ -345,7 +384,11 @@ func valueOfBoolPtr(ptr *bool) bool {
 func (b *ProvisionEndpoint) validate(ctx context.Context, details domain.ProvisionDetails, provisioningParameters internal.ProvisioningParameters, logger *slog.Logger) error {
 	planName := AvailablePlans.GetPlanNameOrEmpty(PlanIDType(provisioningParameters.PlanID))
 	if err := b.operationBlocklist.CheckProvision(blocklist.OperationContext{PlanName: planName, GlobalAccountID: provisioningParameters.ErsContext.GlobalAccountID}); err != nil {
-		return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+		if blocklist.IsBlocked(err) {
+			return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+		}
+		logger.Error(fmt.Sprintf("unable to check the operation blocklist: %s", err))
+		return fmt.Errorf("unable to process the provisioning request")
 	}
 
 	if b.config.RestrictToAllowedGlobalAccounts && !b.config.AllowedGlobalAccounts.Contains(provisioningParameters.ErsContext.GlobalAccountID) {
@@ -367,8 +410,8 @@ func (b *ProvisionEndpoint) validate(ctx context.Context, details domain.Provisi
 		return fmt.Errorf("while obtaining plan defaults: %w", err)
 	}
 
-	if b.config.CheckQuotaLimit && whitelist.IsNotWhitelisted(provisioningParameters.ErsContext.SubAccountID, b.quotaWhitelist) {
-		if err := validateQuotaLimit(b.instanceStorage, b.quotaClient, provisioningParameters.ErsContext.SubAccountID, provisioningParameters.PlanID, false); err != nil {
+	if _, enforced := b.quotaClient.(QuotaEnforcer); !enforced && b.config.CheckQuotaLimit && whitelist.IsNotWhitelisted(provisioningParameters.ErsContext.SubAccountID, b.quotaWhitelist) {
+		if err := validateQuotaLimit(ctx, b.instanceStorage, b.quotaClient, provisioningParameters.ErsContext.SubAccountID, provisioningParameters.PlanID, false); err != nil {
 			return err
 		}
 	}
@@ -381,6 +424,10 @@ func (b *ProvisionEndpoint) validate(ctx context.Context, details domain.Provisi
 		return err
 	}
 
+	if err := b.residencyPolicies.Check(provisioningParameters.PlatformRegion, residencyRequest(values.ProviderType, values.Region, parameters)); err != nil {
+		return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	}
+
 	if err := b.validateNetworking(parameters); err != nil {
 		return err
 	}
@@ -437,8 +484,14 @@ func (b *ProvisionEndpoint) validate(ctx context.Context, details domain.Provisi
 		return fmt.Errorf("while validating input parameters: %s", validator.FormatError(err))
 	}
 
+	if b.moduleValidator != nil {
+		if err := b.moduleValidator.Validate(ctx, parameters.Modules); err != nil {
+			return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+		}
+	}
+
 	// EU Access
-	if isEuRestrictedAccess(ctx) {
+	if b.isEuRestrictedAccess(ctx) {
 		logger.Info("EU Access restricted instance creation")
 	}
 
@@ -529,9 +582,13 @@ func (b *ProvisionEndpoint) validateGvisorAccess(parameters pkg.ProvisioningPara
 
 func (b *ProvisionEndpoint) validateFreePlanConstraints(details domain.ProvisionDetails, provisioningParameters internal.ProvisioningParameters, logger *slog.Logger) error {
 	if IsFreemiumPlan(details.PlanID) && b.config.OnlyOneFreePerGA && whitelist.IsNotWhitelisted(provisioningParameters.ErsContext.GlobalAccountID, b.freemiumWhiteList) {
-		count, err := b.instanceArchivedStorage.TotalNumberOfInstancesArchivedForGlobalAccountID(provisioningParameters.ErsContext.GlobalAccountID, FreemiumPlanID)
-		if err != nil {
-			return fmt.Errorf("while checking if a free Kyma instance existed for given global account: %w", err)
+		count := 0
+		for _, planID := range FreemiumPlanIDs() {
+			archived, err := b.instanceArchivedStorage.TotalNumberOfInstancesArchivedForGlobalAccountID(provisioningParameters.ErsContext.GlobalAccountID, planID)
+			if err != nil {
+				return fmt.Errorf("while checking if a free Kyma instance existed for given global account: %w", err)
+			}
+			count += archived
 		}
 		if count > 0 {
 			logger.Info("Provisioning Free SKR rejected, such instance was already created for this Global Account")
@@ -540,10 +597,10 @@ func (b *ProvisionEndpoint) validateFreePlanConstraints(details domain.Provision
 
 		instanceFilter := dbmodel.InstanceFilter{
 			GlobalAccountIDs: []string{provisioningParameters.ErsContext.GlobalAccountID},
-			PlanIDs:          []string{FreemiumPlanID},
+			PlanIDs:          FreemiumPlanIDs(),
 			States:           []dbmodel.InstanceState{dbmodel.InstanceSucceeded},
 		}
-		_, _, count, err = b.instanceStorage.List(instanceFilter)
+		_, _, count, err := b.instanceStorage.List(instanceFilter)
 		if err != nil {
 			return fmt.Errorf("while checking if a free Kyma instance existed for given global account: %w", err)
 		}
@@ -673,22 +730,26 @@ func validateIngressFiltering(provisioningParameters internal.ProvisioningParame
 	return nil
 }
 
-func isEuRestrictedAccess(ctx context.Context) bool {
+func residencyRequest(providerType, region string, parameters pkg.ProvisioningParametersDTO) residency.Request {
+	machineTypes := []string{valueOfPtr(parameters.MachineType)}
+	for _, pool := range parameters.AdditionalWorkerNodePools {
+		machineTypes = append(machineTypes, pool.MachineType)
+	}
+	return residency.Request{
+		Provider:     pkg.CloudProviderFromString(providerType),
+		Region:       region,
+		MachineTypes: machineTypes,
+		Features:     residency.FeaturesFromProvisioningParameters(parameters),
+	}
+}
+
+func (b *ProvisionEndpoint) isEuRestrictedAccess(ctx context.Context) bool {
 	platformRegion, _ := middleware.RegionFromContext(ctx)
-	return euaccess.IsEURestrictedAccess(platformRegion)
+	return b.residencyPolicies.IsEUAccess(platformRegion)
 }
 
 func supportsAdditionalWorkerNodePools(planID string) bool {
-	var unsupportedPlans = []string{
-		FreemiumPlanID,
-		TrialPlanID,
-	}
-	for _, unsupportedPlan := range unsupportedPlans {
-		if planID == unsupportedPlan {
-			return false
-		}
-	}
-	return true
+	return !IsFreemiumPlan(planID) && !IsTrialPlan(planID)
 }
 
 func AreNamesUnique(pools []pkg.AdditionalWorkerNodePool) bool {
@@ -1053,14 +1114,14 @@ func (b *ProvisionEndpoint) validateOverlappingWithSeeds(nodes, services, pods *
 	return err
 }
 
-func (b *ProvisionEndpoint) monitorAdditionalProperties(instanceID string, ersContext internal.ERSContext, rawParameters json.RawMessage) {
+func (b *ProvisionEndpoint) monitorAdditionalProperties(instanceID, planID string, ersContext internal.ERSContext, rawParameters json.RawMessage) {
 	var parameters pkg.ProvisioningParametersDTO
 	decoder := json.NewDecoder(bytes.NewReader(rawParameters))
 	decoder.DisallowUnknownFields()
 	if err := decoder.Decode(&parameters); err == nil {
 		return
 	}
-	if err := insertRequest(instanceID, filepath.Join(b.config.AdditionalPropertiesPath, additionalproperties.ProvisioningRequestsFileName), ersContext, rawParameters); err != nil {
+	if err := insertRequest(instanceID, planID, filepath.Join(b.config.AdditionalPropertiesPath, additionalproperties.ProvisioningRequestsFileName), ersContext, rawParameters); err != nil {
 		b.log.Error(fmt.Sprintf("failed to save provisioning request with additional properties: %v", err))
 	}
 }
@@ -1104,14 +1165,17 @@ func (b *ProvisionEndpoint) validateACL(parameters internal.ProvisioningParamete
 	return params.AccessControlList.Validate()
 }
 
-func insertRequest(instanceID, filePath string, ersContext internal.ERSContext, rawParameters json.RawMessage) error {
-	payload := map[string]interface{}{
-		"globalAccountID": ersContext.GlobalAccountID,
-		"subAccountID":    ersContext.SubAccountID,
-		"instanceID":      instanceID,
-		"payload":         rawParameters,
+func insertRequest(instanceID, planID, filePath string, ersContext internal.ERSContext, rawParameters json.RawMessage) error {
+	now := time.Now().UTC()
+	request := additionalproperties.Request{
+		GlobalAccountID: ersContext.GlobalAccountID,
+		SubAccountID:    ersContext.SubAccountID,
+		InstanceID:      instanceID,
+		Plan:            AvailablePlans.GetPlanNameOrEmpty(PlanIDType(planID)),
+		Timestamp:       &now,
+		Payload:         rawParameters,
 	}
-	data, err := json.Marshal(payload)
+	data, err := json.Marshal(request)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
@@ -1138,7 +1202,17 @@ func validateOverlapping(n1 net.IPNet, n2 net.IPNet) error {
 	return nil
 }
 
-func validateQuotaLimit(instanceStorage storage.Instances, quotaClient QuotaClient, subAccountID, planID string, update bool) error {
+// reserveQuota reserves the quota for the instance until it is stored if the quota client is a QuotaEnforcer.
+// Other quota clients are handled by validateQuotaLimit.
+func (b *ProvisionEndpoint) reserveQuota(ctx context.Context, instanceID string, parameters internal.ProvisioningParameters) (func(), error) {
+	enforcer, enforced := b.quotaClient.(QuotaEnforcer)
+	if !enforced || !b.config.CheckQuotaLimit || whitelist.IsWhitelisted(parameters.ErsContext.SubAccountID, b.quotaWhitelist) {
+		return func() {}, nil
+	}
+	return enforcer.Reserve(ctx, instanceID, parameters.ErsContext.GlobalAccountID, parameters.ErsContext.SubAccountID, parameters.PlanID, false)
+}
+
+func validateQuotaLimit(ctx context.Context, instanceStorage storage.Instances, quotaClient QuotaClient, subAccountID, planID string, update bool) error {
 	instanceFilter := dbmodel.InstanceFilter{
 		SubAccountIDs: []string{subAccountID},
 		PlanIDs:       []string{planID},
@@ -1155,7 +1229,7 @@ func validateQuotaLimit(instanceStorage storage.Instances, quotaClient QuotaClie
 
 	if usedQuota > 0 || update {
 		planName := AvailablePlans.GetPlanNameOrEmpty(PlanIDType(planID))
-		assignedQuota, err := quotaClient.GetQuota(subAccountID, planName)
+		assignedQuota, err := quotaClient.GetQuota(ctx, subAccountID, planName)
 		if err != nil {
 			return fmt.Errorf("Failed to get assigned quota for plan %s: %w.", planName, err)
 		}
@@ -44,6 +44,7 @@ type fakeProvisionEndpointBuilder struct {
 	gardenerClient         *gardener.Client
 	factory                hyperscalers.Factory
 	operationBlocklist     blocklist.OperationBlocklist
+	moduleValidator        ModuleValidator
 }
 
 func NewFakeProvisionEndpointBuilder() *fakeProvisionEndpointBuilder {
@@ -160,6 +161,11 @@ func (b *fakeProvisionEndpointBuilder) WithOperationBlocklist(bl blocklist.Opera
 	return b
 }
 
+func (b *fakeProvisionEndpointBuilder) WithModuleValidator(v ModuleValidator) *fakeProvisionEndpointBuilder {
+	b.moduleValidator = v
+	return b
+}
+
 func (b *fakeProvisionEndpointBuilder) Build() *ProvisionEndpoint {
 	return NewProvision(
 		b.brokerConfig,
@@ -184,6 +190,7 @@ func (b *fakeProvisionEndpointBuilder) Build() *ProvisionEndpoint {
 		b.gardenerClient,
 		b.factory,
 		b.operationBlocklist,
+		b.moduleValidator,
 	)
 }
 
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
@@ -24,6 +25,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	kcMock "github.com/kyma-project/kyma-environment-broker/internal/kubeconfig/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/kymamodules"
 	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
@@ -2236,6 +2238,8 @@ func TestAdditionalProperties(t *testing.T) {
 		assert.Equal(t, "any-global-account-id", entry["globalAccountID"])
 		assert.Equal(t, subAccountID, entry["subAccountID"])
 		assert.Equal(t, instanceID, entry["instanceID"])
+		assert.Equal(t, broker.AWSPlanName, entry["plan"])
+		assert.NotEmpty(t, entry["timestamp"])
 	})
 
 	t.Run("file should contain two requests with additional properties", func(t *testing.T) {
@@ -2595,7 +2599,7 @@ func TestQuotaLimitCheck(t *testing.T) {
 		assert.NoError(t, err)
 
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.AzurePlanName).Return(1, nil)
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.AzurePlanName).Return(1, nil)
 
 		provisionEndpoint := broker.NewFakeProvisionEndpointBuilder().
 			WithConfig(broker.Config{
@@ -2641,7 +2645,7 @@ func TestQuotaLimitCheck(t *testing.T) {
 		assert.NoError(t, err)
 
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.AzurePlanName).Return(2, nil)
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.AzurePlanName).Return(2, nil)
 
 		provisionEndpoint := broker.NewFakeProvisionEndpointBuilder().
 			WithConfig(broker.Config{
@@ -2687,7 +2691,7 @@ func TestQuotaLimitCheck(t *testing.T) {
 		assert.NoError(t, err)
 
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.AzurePlanName).Return(0, fmt.Errorf("error message"))
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.AzurePlanName).Return(0, fmt.Errorf("error message"))
 
 		provisionEndpoint := broker.NewFakeProvisionEndpointBuilder().
 			WithConfig(broker.Config{
@@ -2733,7 +2737,7 @@ func TestQuotaLimitCheck(t *testing.T) {
 		assert.NoError(t, err)
 
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.AzurePlanName).Return(1, nil)
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.AzurePlanName).Return(1, nil)
 
 		provisionEndpoint := broker.NewFakeProvisionEndpointBuilder().
 			WithConfig(broker.Config{
@@ -3732,3 +3736,85 @@ func TestProvisionInternalOnlyMachinesForExternalCustomer(t *testing.T) {
 		})
 	}
 }
+
+func TestProvisionModulesValidation(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	catalog := kymamodules.NewCatalog(kymamodules.Config{CacheTTL: time.Hour}, &fixModulesSource{modules: []kymamodules.Module{
+		{Name: "btp-operator", Channels: []string{"fast", "regular"}},
+		{Name: "serverless", Channels: []string{"regular"}},
+	}}, log)
+
+	for tn, tc := range map[string]struct {
+		modules       string
+		expectedError string
+	}{
+		"should accept available modules": {
+			modules: `{"list": [{"name": "btp-operator", "channel": "fast"}, {"name": "serverless"}]}`,
+		},
+		"should accept default modules": {
+			modules: `{"default": true}`,
+		},
+		"should reject module with typo": {
+			modules:       `{"list": [{"name": "btp-operater"}]}`,
+			expectedError: `module "btp-operater" is not available, did you mean "btp-operator"?`,
+		},
+		"should reject channel not available for module": {
+			modules:       `{"list": [{"name": "serverless", "channel": "fast"}]}`,
+			expectedError: `channel "fast" is not available for module "serverless"`,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+			queue := &automock.Queue{}
+			queue.On("Add", mock.AnythingOfType("string"))
+			kcBuilder := &kcMock.KcBuilder{}
+			kcBuilder.On("GetServerURL", "").Return("", fmt.Errorf("error"))
+
+			provisionEndpoint := broker.NewFakeProvisionEndpointBuilder().
+				WithConfig(broker.Config{EnablePlans: []string{"azure"}, URL: brokerURL}).
+				WithGardenerConfig(fixGardenerConfig()).
+				WithInfrastructureManager(imConfigFixture).
+				WithStorage(memoryStorage).
+				WithQueue(queue).
+				WithLogger(log).
+				WithDashboardConfig(dashboardConfig).
+				WithKubeconfigBuilder(kcBuilder).
+				WithSchemaService(newSchemaService(t)).
+				WithConfigurationProvider(newProviderSpec(t)).
+				WithValuesProvider(fixValueProvider(t)).
+				WithModuleValidator(catalog).
+				Build()
+
+			// when
+			_, err := provisionEndpoint.Provision(
+				fixRequestContext(t, "req-region"),
+				instanceID,
+				domain.ProvisionDetails{
+					ServiceID:     serviceID,
+					PlanID:        broker.AzurePlanID,
+					RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "region": "%s", "modules": %s}`, clusterName, clusterRegion, tc.modules)),
+					RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, userID)),
+				}, true)
+
+			// then
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+			apiErr, ok := err.(*apiresponses.FailureResponse)
+			require.True(t, ok)
+			assert.Equal(t, http.StatusBadRequest, apiErr.ValidatedStatusCode(nil))
+		})
+	}
+}
+
+type fixModulesSource struct {
+	modules []kymamodules.Module
+}
+
+func (s *fixModulesSource) Modules(_ context.Context) ([]kymamodules.Module, error) {
+	return s.modules, nil
+}
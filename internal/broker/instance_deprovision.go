@@ -13,6 +13,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
 	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
 )
@@ -88,8 +89,12 @@ func (b *DeprovisionEndpoint) Deprovision(ctx context.Context, instanceID string
 
 	// create and save new operation
 	planName := AvailablePlans.GetPlanNameOrEmpty(PlanIDType(instance.ServicePlanID))
-	if err := b.operationBlocklist.CheckDeprovision(blocklist.OperationContext{PlanName: planName, GlobalAccountID: instance.GlobalAccountID}); err != nil {
-		return domain.DeprovisionServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	if err := b.operationBlocklist.CheckDeprovision(blocklist.OperationContext{PlanName: planName, GlobalAccountID: instance.GlobalAccountID, InstanceID: instance.InstanceID}); err != nil {
+		if blocklist.IsBlocked(err) {
+			return domain.DeprovisionServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+		}
+		// deprovisioning must not be blocked by a rule which cannot be evaluated
+		logger.Warn(fmt.Sprintf("unable to check the operation blocklist, deprovisioning is not blocked: %s", err))
 	}
 
 	operationID := uuid.New().String()
@@ -102,6 +107,7 @@ func (b *DeprovisionEndpoint) Deprovision(ctx context.Context, instanceID string
 	if v := ctx.Value(userAgentKey); v != nil {
 		operation.UserAgent = v.(string)
 	}
+	operation.TraceContext = tracing.Inject(ctx)
 	err = b.operationsStorage.InsertDeprovisioningOperation(operation)
 	if err != nil {
 		logger.Error(fmt.Sprintf("cannot save operation: %s", err))
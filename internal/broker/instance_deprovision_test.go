@@ -2,6 +2,7 @@ package broker
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
@@ -193,4 +194,35 @@ func TestDeprovisionBlocklist(t *testing.T) {
 		// then
 		require.NoError(t, err)
 	})
+
+	t.Run("deprovision is not blocked when instance tags cannot be fetched", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		instance := fixture.FixInstance(instanceID)
+		instance.ServicePlanID = AzurePlanID
+		require.NoError(t, memoryStorage.Instances().Insert(instance))
+
+		queue := &automock.Queue{}
+		queue.On("Add", mock.AnythingOfType("string"))
+
+		path := writeBlocklistYAML(t, `deprovision: '"protected","tags=protected"'`)
+		bl, err := blocklist.ReadFromFile(path)
+		require.NoError(t, err)
+		bl = bl.WithTagsProvider(failingTagsProvider{})
+
+		svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, fixLogger(), bl)
+
+		// when
+		_, err = svc.Deprovision(context.TODO(), instanceID, domain.DeprovisionDetails{}, true)
+
+		// then
+		require.NoError(t, err)
+		queue.AssertNumberOfCalls(t, "Add", 1)
+	})
+}
+
+type failingTagsProvider struct{}
+
+func (failingTagsProvider) GetByInstanceID(string) (map[string]string, error) {
+	return nil, fmt.Errorf("connection refused")
 }
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
 
@@ -17,14 +18,23 @@ import (
 	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
 )
 
+// kymaStatusAttribute is the key of the instance metadata attribute with the status of the Kyma custom resource
+const kymaStatusAttribute = "kyma_status"
+
 type GetInstanceEndpoint struct {
 	config            Config
 	instancesStorage  storage.Instances
 	operationsStorage storage.Provisioning
 	kcBuilder         kubeconfig.KcBuilder
+	kymaStatus        KymaStatusProvider
 	log               *slog.Logger
 }
 
+// KymaStatusProvider returns the status of the Kyma custom resource of the instance
+type KymaStatusProvider interface {
+	Status(instanceID string) (*pkg.KymaStatus, bool)
+}
+
 func NewGetInstance(cfg Config,
 	instancesStorage storage.Instances,
 	operationsStorage storage.Provisioning,
@@ -40,6 +50,12 @@ func NewGetInstance(cfg Config,
 	}
 }
 
+// WithKymaStatus makes the endpoint return the status of the Kyma custom resource in the instance metadata attributes
+func (b *GetInstanceEndpoint) WithKymaStatus(provider KymaStatusProvider) *GetInstanceEndpoint {
+	b.kymaStatus = provider
+	return b
+}
+
 // GetInstance fetches information about a service instance
 // GET /v2/service_instances/{instance_id}
 func (b *GetInstanceEndpoint) GetInstance(_ context.Context, instanceID string, _ domain.FetchInstanceDetails) (domain.GetInstanceDetailsSpec, error) {
@@ -81,14 +97,23 @@ func (b *GetInstanceEndpoint) GetInstance(_ context.Context, instanceID string,
 		},
 	}
 
-	if instance.ServicePlanID == TrialPlanID {
+	if IsTrialPlan(instance.ServicePlanID) {
 		spec.Metadata.Labels = ResponseLabelsWithExpirationInfo(*instance, b.config.URL, b.config.TrialDocsURL, trialDocsKey, trialExpireDuration, trialExpiryDetailsKey, trialExpiredInfoFormat, b.kcBuilder)
 	}
 
-	if instance.ServicePlanID == FreemiumPlanID {
+	if IsFreemiumPlan(instance.ServicePlanID) {
 		spec.Metadata.Labels = ResponseLabelsWithExpirationInfo(*instance, b.config.URL, b.config.FreeDocsURL, freeDocsKey, b.config.FreeExpirationPeriod, freeExpiryDetailsKey, freeExpiredInfoFormat, b.kcBuilder)
 	}
 
+	if b.kymaStatus != nil {
+		if status, found := b.kymaStatus.Status(instanceID); found {
+			if spec.Metadata.Attributes == nil {
+				spec.Metadata.Attributes = map[string]any{}
+			}
+			spec.Metadata.Attributes[kymaStatusAttribute] = status
+		}
+	}
+
 	return spec, nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker/automock"
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
@@ -256,6 +257,51 @@ func TestGetEndpoint_GetExpiredFreeInstanceWithExpirationDetails(t *testing.T) {
 	assert.Contains(t, response.Metadata.Labels, "Available plans documentation")
 }
 
+func TestGetEndpoint_GetInstanceWithKymaStatus(t *testing.T) {
+	// given
+	st := storage.NewMemoryStorage()
+	cfg := broker.Config{
+		URL: "https://test-broker.local",
+	}
+
+	const (
+		instanceID  = "cluster-test"
+		operationID = "operationID"
+	)
+	op := fixture.FixProvisioningOperation(operationID, instanceID)
+
+	instance := fixture.FixInstance(instanceID)
+	kcBuilder := &kcMock.KcBuilder{}
+	kcBuilder.On("GetServerURL", instance.RuntimeID).Return("https://api.ac0d8d9.kyma-dev.shoot.canary.k8s-hana.ondemand.com", nil)
+
+	err := st.Operations().InsertOperation(op)
+	require.NoError(t, err)
+
+	err = st.Instances().Insert(instance)
+	require.NoError(t, err)
+
+	kymaStatus := &pkg.KymaStatus{
+		State:   "Error",
+		Modules: []pkg.KymaModuleStatus{{Name: "serverless", State: "Error", Version: "1.5.0", Channel: "regular"}},
+	}
+	svc := broker.NewGetInstance(cfg, st.Instances(), st.Operations(), kcBuilder, fixLogger()).
+		WithKymaStatus(fixKymaStatusProvider{instanceID: kymaStatus})
+
+	// when
+	response, err := svc.GetInstance(context.Background(), instanceID, domain.FetchInstanceDetails{})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, kymaStatus, response.Metadata.Attributes["kyma_status"])
+}
+
+type fixKymaStatusProvider map[string]*pkg.KymaStatus
+
+func (p fixKymaStatusProvider) Status(instanceID string) (*pkg.KymaStatus, bool) {
+	status, found := p[instanceID]
+	return status, found
+}
+
 func fixLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/kymastatus"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
@@ -16,6 +18,7 @@ import (
 type LastOperationEndpoint struct {
 	operationStorage  storage.Operations
 	instancesArchived storage.InstancesArchived
+	kymaStatus        KymaStatusProvider
 
 	log *slog.Logger
 }
@@ -28,6 +31,13 @@ func NewLastOperation(os storage.Operations, ia storage.InstancesArchived, log *
 	}
 }
 
+// WithKymaStatus makes the endpoint add the state of the Kyma custom resource to the description of the succeeded
+// provisioning and update operations when the Kyma resource is not Ready
+func (b *LastOperationEndpoint) WithKymaStatus(provider KymaStatusProvider) *LastOperationEndpoint {
+	b.kymaStatus = provider
+	return b
+}
+
 // LastOperation fetches last operation state for a service instance
 //
 //	GET /v2/service_instances/{instance_id}/last_operation
@@ -54,7 +64,7 @@ func (b *LastOperationEndpoint) LastOperation(ctx context.Context, instanceID st
 		}
 		return domain.LastOperation{
 			State:       mapStateToOSBCompliantState(lastOp.State),
-			Description: lastOp.Description,
+			Description: b.describe(*lastOp),
 		}, nil
 	}
 
@@ -77,10 +87,34 @@ func (b *LastOperationEndpoint) LastOperation(ctx context.Context, instanceID st
 
 	return domain.LastOperation{
 		State:       mapStateToOSBCompliantState(operation.State),
-		Description: operation.Description,
+		Description: b.describe(*operation),
 	}, nil
 }
 
+// describe returns the description of the operation, extended with the state of the Kyma custom resource
+// if the provisioning or update succeeded, but the Kyma resource is not Ready
+func (b *LastOperationEndpoint) describe(operation internal.Operation) string {
+	if b.kymaStatus == nil || operation.State != domain.Succeeded {
+		return operation.Description
+	}
+	if operation.Type != internal.OperationTypeProvision && operation.Type != internal.OperationTypeUpdate {
+		return operation.Description
+	}
+	status, found := b.kymaStatus.Status(operation.InstanceID)
+	if !found || status.State == kymastatus.StateReady {
+		return operation.Description
+	}
+
+	description := fmt.Sprintf("The Kyma resource is not %s, current state: %s", kymastatus.StateReady, status.State)
+	if notReady := kymastatus.NotReadyModules(status); len(notReady) > 0 {
+		description = fmt.Sprintf("%s, modules not ready: %s", description, strings.Join(notReady, ", "))
+	}
+	if operation.Description == "" {
+		return description
+	}
+	return fmt.Sprintf("%s. %s", strings.TrimSuffix(operation.Description, "."), description)
+}
+
 func (b *LastOperationEndpoint) responseFromInstanceArchived(instanceID string, logger *slog.Logger) (domain.LastOperation, error) {
 	_, err := b.instancesArchived.GetByInstanceID(instanceID)
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
@@ -200,6 +201,50 @@ func TestLastOperation_LastOperation(t *testing.T) {
 			Description: "Provisioning description",
 		}, response)
 	})
+	t.Run("Should add the state of the Kyma resource which is not Ready", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Operations().InsertOperation(fixOperation())
+		assert.NoError(t, err)
+
+		lastOperationEndpoint := broker.NewLastOperation(memoryStorage.Operations(), memoryStorage.InstancesArchived(), fixLogger()).
+			WithKymaStatus(fixKymaStatusProvider{instID: {
+				State: "Error",
+				Modules: []pkg.KymaModuleStatus{
+					{Name: "btp-operator", State: "Ready"},
+					{Name: "serverless", State: "Error"},
+				},
+			}})
+
+		// when
+		response, err := lastOperationEndpoint.LastOperation(context.TODO(), instID, domain.PollDetails{OperationData: ""})
+		assert.NoError(t, err)
+
+		// then
+		assert.Equal(t, domain.LastOperation{
+			State:       domain.Succeeded,
+			Description: operationDescription + ". The Kyma resource is not Ready, current state: Error, modules not ready: serverless (Error)",
+		}, response)
+	})
+	t.Run("Should not change the description when the Kyma resource is Ready", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		err := memoryStorage.Operations().InsertOperation(fixOperation())
+		assert.NoError(t, err)
+
+		lastOperationEndpoint := broker.NewLastOperation(memoryStorage.Operations(), memoryStorage.InstancesArchived(), fixLogger()).
+			WithKymaStatus(fixKymaStatusProvider{instID: {State: "Ready"}})
+
+		// when
+		response, err := lastOperationEndpoint.LastOperation(context.TODO(), instID, domain.PollDetails{OperationData: operationID})
+		assert.NoError(t, err)
+
+		// then
+		assert.Equal(t, domain.LastOperation{
+			State:       domain.Succeeded,
+			Description: operationDescription,
+		}, response)
+	})
 }
 
 func fixOperation() internal.Operation {
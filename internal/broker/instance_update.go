@@ -23,8 +23,10 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 	"github.com/kyma-project/kyma-environment-broker/internal/validator"
 	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
 
@@ -78,6 +80,7 @@ type UpdateEndpoint struct {
 
 	syncEmptyUpdateResponseEnabled bool
 	operationBlocklist             blocklist.OperationBlocklist
+	residencyPolicies              *residency.Policies
 }
 
 func NewUpdate(cfg Config,
@@ -133,9 +136,16 @@ func NewUpdate(cfg Config,
 		factory:                                  factory,
 		syncEmptyUpdateResponseEnabled:           cfg.SyncEmptyUpdateResponseEnabled,
 		operationBlocklist:                       operationBlocklist,
+		residencyPolicies:                        residency.DefaultPolicies(),
 	}
 }
 
+// WithResidencyPolicies replaces the default residency policies the update requests are validated against.
+func (b *UpdateEndpoint) WithResidencyPolicies(policies *residency.Policies) *UpdateEndpoint {
+	b.residencyPolicies = policies
+	return b
+}
+
 // Update modifies an existing service instance
 //
 //	PATCH /v2/service_instances/{instance_id}
@@ -171,8 +181,12 @@ func (b *UpdateEndpoint) update(ctx context.Context, instanceID string, details
 	logger.Info(fmt.Sprintf("Plan ID/Name: %s/%s", instance.ServicePlanID, AvailablePlans.GetPlanNameOrEmpty(PlanIDType(instance.ServicePlanID))))
 
 	planName := AvailablePlans.GetPlanNameOrEmpty(PlanIDType(instance.ServicePlanID))
-	if err := b.operationBlocklist.CheckUpdate(blocklist.OperationContext{PlanName: planName, GlobalAccountID: instance.GlobalAccountID}); err != nil {
-		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	if err := b.operationBlocklist.CheckUpdate(blocklist.OperationContext{PlanName: planName, GlobalAccountID: instance.GlobalAccountID, InstanceID: instance.InstanceID}); err != nil {
+		if blocklist.IsBlocked(err) {
+			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+		}
+		logger.Error(fmt.Sprintf("unable to check the operation blocklist: %s", err))
+		return domain.UpdateServiceSpec{}, fmt.Errorf("unable to process the update")
 	}
 
 	var ersContext internal.ERSContext
@@ -184,7 +198,7 @@ func (b *UpdateEndpoint) update(ctx context.Context, instanceID string, details
 	logger.Info(fmt.Sprintf("Global account ID: %s active: %s", instance.GlobalAccountID, ptr.BoolAsString(ersContext.Active)))
 	logger.Info(fmt.Sprintf("Received context: %s", marshallRawContext(hideSensitiveDataFromRawContext(details.RawContext))))
 	if b.config.MonitorAdditionalProperties {
-		b.monitorAdditionalProperties(instanceID, ersContext, details.RawParameters)
+		b.monitorAdditionalProperties(instanceID, instance.ServicePlanID, ersContext, details.RawParameters)
 	}
 	// validation of incoming input
 	if err := b.validateWithJsonSchemaValidator(details, instance); err != nil {
@@ -286,12 +300,32 @@ func (b *UpdateEndpoint) validateWithJsonSchemaValidator(details domain.UpdateDe
 func shouldUpdate(instance *internal.Instance, details domain.UpdateDetails, ersContext internal.ERSContext) bool {
 	return len(details.RawParameters) != 0 ||
 		details.PlanID != instance.ServicePlanID ||
+		maintenanceInfoChanged(instance.Parameters.MaintenanceInfo, details.MaintenanceInfo) ||
 		ersContext.ERSUpdate()
 }
 
 func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, previousInstance, instance *internal.Instance, details domain.UpdateDetails, lastProvisioningOperation *internal.ProvisioningOperation, asyncAllowed bool, ersContext internal.ERSContext, logger *slog.Logger) (domain.UpdateServiceSpec, error) {
+	targetPlanID := instance.ServicePlanID
+	if details.PlanID != "" {
+		targetPlanID = details.PlanID
+	}
+	maintenanceInfo, err := validateMaintenanceInfo(details.MaintenanceInfo, b.schemaService.MaintenanceInfo(AvailablePlans.GetPlanNameOrEmpty(PlanIDType(targetPlanID))))
+	if err != nil {
+		logger.Warn(fmt.Sprintf("maintenance_info conflict: %s", err))
+		return domain.UpdateServiceSpec{}, err
+	}
+	details.MaintenanceInfo = maintenanceInfo
+
 	if !shouldUpdate(instance, details, ersContext) {
 		logger.Debug("Parameters not provided, skipping processing update parameters")
+		if adoptMaintenanceInfo(instance.Parameters.MaintenanceInfo, details.MaintenanceInfo) {
+			logger.Info(fmt.Sprintf("Maintenance info %s stored for the instance without maintenance info", details.MaintenanceInfo.Version))
+			instance.Parameters.MaintenanceInfo = details.MaintenanceInfo
+			if _, err := b.instanceStorage.Update(*instance); err != nil {
+				logger.Error(fmt.Sprintf("unable to store maintenance info: %s", err))
+				return domain.UpdateServiceSpec{}, fmt.Errorf("unable to process the update")
+			}
+		}
 		return domain.UpdateServiceSpec{
 			IsAsync:       false,
 			DashboardURL:  dashboard.ProvideURL(instance, lastProvisioningOperation),
@@ -328,6 +362,10 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, previousIn
 		return domain.UpdateServiceSpec{}, err
 	}
 
+	if err := b.validateResidencyPolicy(instance.Parameters.PlatformRegion, providerValues, params); err != nil {
+		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	}
+
 	err = b.validateOIDC(params, instance, logger)
 	if err != nil {
 		return domain.UpdateServiceSpec{}, err
@@ -349,6 +387,7 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, previousIn
 	operation := internal.NewUpdateOperation(operationID, instance, params)
 	operation.ProviderValues = &providerValues
 	operation.RawParameters = details.RawParameters
+	operation.TraceContext = tracing.Inject(ctx)
 
 	if err := operation.ProvisioningParameters.Parameters.AutoScalerParameters.Validate(providerValues.DefaultAutoScalerMin, providerValues.DefaultAutoScalerMax); err != nil {
 		logger.Error(fmt.Sprintf("invalid autoscaler parameters: %s", err.Error()))
@@ -381,7 +420,7 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, previousIn
 
 	operation.PreviousParameters = previousInstance.Parameters
 
-	updateStorage, err := b.updateInstanceAndOperationParameters(instance, &params, &operation, details, ersContext, logger)
+	updateStorage, err := b.updateInstanceAndOperationParameters(ctx, instance, &params, &operation, details, ersContext, logger)
 	if err != nil {
 		return domain.UpdateServiceSpec{}, err
 	}
@@ -460,6 +499,18 @@ func (b *UpdateEndpoint) validateMachineType(ctx context.Context, providerValues
 	return nil
 }
 
+func (b *UpdateEndpoint) validateResidencyPolicy(platformRegion string, providerValues internal.ProviderValues, params internal.UpdatingParametersDTO) error {
+	// the region cannot be changed by an update, so only the updatable parameters are checked
+	return b.residencyPolicies.Check(platformRegion, residencyRequest(providerValues.ProviderType, "", pkg.ProvisioningParametersDTO{
+		MachineType:               params.MachineType,
+		AdditionalWorkerNodePools: params.AdditionalWorkerNodePools,
+		IngressFiltering:          params.IngressFiltering,
+		AccessControlList:         params.AccessControlList,
+		Gvisor:                    params.Gvisor,
+		AuditLogAccess:            params.AuditLogAccess,
+	}))
+}
+
 func (b *UpdateEndpoint) validateOIDC(params internal.UpdatingParametersDTO, instance *internal.Instance, logger *slog.Logger) error {
 	if params.OIDC.IsProvided() {
 		if err := params.OIDC.Validate(instance.Parameters.Parameters.OIDC); err != nil {
@@ -509,7 +560,7 @@ func (b *UpdateEndpoint) unmarshalParams(details domain.UpdateDetails, logger *s
 	return params, nil
 }
 func (b *UpdateEndpoint) ZeroFieldsForTrialPlan(details domain.UpdateDetails, params *internal.UpdatingParametersDTO) {
-	if details.PlanID == TrialPlanID {
+	if IsTrialPlan(details.PlanID) {
 		params.MachineType = nil
 		params.AutoScalerMin = nil
 		params.AutoScalerMax = nil
@@ -675,6 +726,10 @@ func (b *UpdateEndpoint) shouldSkipNewOperation(previousInstance, currentInstanc
 		logger.Info("Plan changed, cannot skip new operation")
 		return false, nil
 	}
+	if maintenanceInfoChanged(previousInstance.Parameters.MaintenanceInfo, currentInstance.Parameters.MaintenanceInfo) {
+		logger.Info("Maintenance info changed, cannot skip new operation")
+		return false, nil
+	}
 	if previousInstance.GlobalAccountID != currentInstance.GlobalAccountID {
 		logger.Info("GlobalAccountID changed, cannot skip new operation")
 		return false, nil
@@ -803,14 +858,14 @@ func (b *UpdateEndpoint) getJsonSchemaValidator(provider pkg.CloudProvider, plan
 	return validator.NewFromSchema(plan.Schemas.Instance.Update.Parameters)
 }
 
-func (b *UpdateEndpoint) monitorAdditionalProperties(instanceID string, ersContext internal.ERSContext, rawParameters json.RawMessage) {
+func (b *UpdateEndpoint) monitorAdditionalProperties(instanceID, planID string, ersContext internal.ERSContext, rawParameters json.RawMessage) {
 	var parameters internal.UpdatingParametersDTO
 	decoder := json.NewDecoder(bytes.NewReader(rawParameters))
 	decoder.DisallowUnknownFields()
 	if err := decoder.Decode(&parameters); err == nil {
 		return
 	}
-	if err := insertRequest(instanceID, filepath.Join(b.config.AdditionalPropertiesPath, additionalproperties.UpdateRequestsFileName), ersContext, rawParameters); err != nil {
+	if err := insertRequest(instanceID, planID, filepath.Join(b.config.AdditionalPropertiesPath, additionalproperties.UpdateRequestsFileName), ersContext, rawParameters); err != nil {
 		b.log.Error(fmt.Sprintf("failed to save update request with additional properties: %v", err))
 	}
 }
@@ -839,7 +894,7 @@ func (b *UpdateEndpoint) responseWithoutNewOperation(instance *internal.Instance
 	}, nil
 }
 
-func (b *UpdateEndpoint) updateInstanceAndOperationParameters(instance *internal.Instance, params *internal.UpdatingParametersDTO, operation *internal.Operation, details domain.UpdateDetails, ersContext internal.ERSContext, logger *slog.Logger) ([]string, error) {
+func (b *UpdateEndpoint) updateInstanceAndOperationParameters(ctx context.Context, instance *internal.Instance, params *internal.UpdatingParametersDTO, operation *internal.Operation, details domain.UpdateDetails, ersContext internal.ERSContext, logger *slog.Logger) ([]string, error) {
 	var updateStorage []string
 	if details.PlanID != "" && details.PlanID != instance.ServicePlanID {
 		logger.Info(fmt.Sprintf("Plan change requested: %s -> %s", instance.ServicePlanID, details.PlanID))
@@ -847,11 +902,15 @@ func (b *UpdateEndpoint) updateInstanceAndOperationParameters(instance *internal
 		sourcePlanName := AvailablePlans.GetPlanNameOrEmpty(PlanIDType(instance.ServicePlanID))
 		targetPlanName := AvailablePlans.GetPlanNameOrEmpty(PlanIDType(details.PlanID))
 
-		if err := b.operationBlocklist.CheckPlanUpgrade(blocklist.OperationContext{PlanName: targetPlanName, GlobalAccountID: instance.GlobalAccountID}); err != nil {
-			return nil, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+		if err := b.operationBlocklist.CheckPlanUpgrade(blocklist.OperationContext{PlanName: targetPlanName, GlobalAccountID: instance.GlobalAccountID, InstanceID: instance.InstanceID}); err != nil {
+			if blocklist.IsBlocked(err) {
+				return nil, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+			}
+			logger.Error(fmt.Sprintf("unable to check the operation blocklist: %s", err))
+			return nil, fmt.Errorf("unable to process the update")
 		}
 
-		err := b.isPlanChangePossible(instance, sourcePlanName, targetPlanName, logger, details, ersContext)
+		err := b.isPlanChangePossible(ctx, instance, sourcePlanName, targetPlanName, logger, details, ersContext)
 		if err != nil {
 			return nil, err
 		}
@@ -919,10 +978,23 @@ func (b *UpdateEndpoint) updateInstanceAndOperationParameters(instance *internal
 		updateStorage = append(updateStorage, "Cluster Name")
 	}
 
+	if maintenanceInfoChanged(instance.Parameters.MaintenanceInfo, details.MaintenanceInfo) {
+		logger.Info(fmt.Sprintf("Maintenance info change requested: %s", details.MaintenanceInfo.Version))
+		instance.Parameters.MaintenanceInfo = details.MaintenanceInfo
+		operation.ProvisioningParameters.MaintenanceInfo = details.MaintenanceInfo
+		operation.UpdatedMaintenanceInfo = details.MaintenanceInfo
+		updateStorage = append(updateStorage, "Maintenance Info")
+	} else if adoptMaintenanceInfo(instance.Parameters.MaintenanceInfo, details.MaintenanceInfo) {
+		logger.Info(fmt.Sprintf("Maintenance info %s stored for the instance without maintenance info", details.MaintenanceInfo.Version))
+		instance.Parameters.MaintenanceInfo = details.MaintenanceInfo
+		operation.ProvisioningParameters.MaintenanceInfo = details.MaintenanceInfo
+		updateStorage = append(updateStorage, "Maintenance Info")
+	}
+
 	return updateStorage, nil
 }
 
-func (b *UpdateEndpoint) isPlanChangePossible(instance *internal.Instance, sourcePlanName string, targetPlanName string, logger *slog.Logger, details domain.UpdateDetails, ersContext internal.ERSContext) error {
+func (b *UpdateEndpoint) isPlanChangePossible(ctx context.Context, instance *internal.Instance, sourcePlanName string, targetPlanName string, logger *slog.Logger, details domain.UpdateDetails, ersContext internal.ERSContext) error {
 	if !b.config.EnablePlanUpgrades || !b.planSpec.IsUpgradableBetween(sourcePlanName, targetPlanName) {
 		logger.Info("Plan change not allowed.")
 		errMsg := fmt.Sprintf("plan upgrade from %s (planID: %s) to %s (planID: %s) is not allowed", sourcePlanName, instance.ServicePlanID, targetPlanName, details.PlanID)
@@ -930,7 +1002,14 @@ func (b *UpdateEndpoint) isPlanChangePossible(instance *internal.Instance, sourc
 	}
 
 	if b.config.CheckQuotaLimit && whitelist.IsNotWhitelisted(ersContext.SubAccountID, b.quotaWhitelist) {
-		if err := validateQuotaLimit(b.instanceStorage, b.quotaClient, ersContext.SubAccountID, details.PlanID, true); err != nil {
+		if enforcer, enforced := b.quotaClient.(QuotaEnforcer); enforced {
+			// the instance is already stored, so the quota does not have to stay reserved
+			release, err := enforcer.Reserve(ctx, instance.InstanceID, instance.GlobalAccountID, ersContext.SubAccountID, details.PlanID, true)
+			if err != nil {
+				return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+			}
+			release()
+		} else if err := validateQuotaLimit(ctx, b.instanceStorage, b.quotaClient, ersContext.SubAccountID, details.PlanID, true); err != nil {
 			return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 		}
 	}
@@ -1,6 +1,7 @@
 package broker
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -67,7 +68,7 @@ func TestPersistenceOnUpdate_Gvisor(t *testing.T) {
 	details := domain.UpdateDetails{}
 
 	// when
-	_, err := endpoint.updateInstanceAndOperationParameters(instance, params, operation, details, internal.ERSContext{}, fixLogger())
+	_, err := endpoint.updateInstanceAndOperationParameters(context.Background(), instance, params, operation, details, internal.ERSContext{}, fixLogger())
 
 	// then
 	require.NoError(t, err)
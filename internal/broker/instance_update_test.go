@@ -2145,7 +2145,7 @@ func TestQuotaLimitCheckDuringUpdate(t *testing.T) {
 		err = st.Operations().InsertProvisioningOperation(provisioningOperation)
 		require.NoError(t, err)
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.BuildRuntimeAWSPlanName).Return(1, nil)
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.BuildRuntimeAWSPlanName).Return(1, nil)
 		svc := broker.NewUpdate(broker.Config{
 			EnablePlanUpgrades: true,
 			CheckQuotaLimit:    true,
@@ -2187,7 +2187,7 @@ func TestQuotaLimitCheckDuringUpdate(t *testing.T) {
 		})
 		require.NoError(t, err)
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.BuildRuntimeAWSPlanName).Return(1, nil)
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.BuildRuntimeAWSPlanName).Return(1, nil)
 		svc := broker.NewUpdate(broker.Config{
 			EnablePlanUpgrades: true,
 			CheckQuotaLimit:    true,
@@ -2229,7 +2229,7 @@ func TestQuotaLimitCheckDuringUpdate(t *testing.T) {
 		})
 		require.NoError(t, err)
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.BuildRuntimeAWSPlanName).Return(2, nil)
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.BuildRuntimeAWSPlanName).Return(2, nil)
 		svc := broker.NewUpdate(broker.Config{
 			EnablePlanUpgrades: true,
 			CheckQuotaLimit:    true,
@@ -2271,7 +2271,7 @@ func TestQuotaLimitCheckDuringUpdate(t *testing.T) {
 		})
 		require.NoError(t, err)
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.BuildRuntimeAWSPlanName).Return(0, fmt.Errorf("error message"))
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.BuildRuntimeAWSPlanName).Return(0, fmt.Errorf("error message"))
 		svc := broker.NewUpdate(broker.Config{
 			EnablePlanUpgrades: true,
 			CheckQuotaLimit:    true,
@@ -2313,7 +2313,7 @@ func TestQuotaLimitCheckDuringUpdate(t *testing.T) {
 		})
 		require.NoError(t, err)
 		quotaClient := &automock.QuotaClient{}
-		quotaClient.On("GetQuota", subAccountID, broker.BuildRuntimeAWSPlanName).Return(1, nil)
+		quotaClient.On("GetQuota", mock.Anything, subAccountID, broker.BuildRuntimeAWSPlanName).Return(1, nil)
 		svc := broker.NewUpdate(broker.Config{
 			EnablePlanUpgrades: true,
 			CheckQuotaLimit:    true,
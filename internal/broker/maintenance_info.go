@@ -0,0 +1,68 @@
+package broker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
+)
+
+const (
+	MaintenanceInfoKubernetesVersionKey = "kubernetesVersion"
+	MaintenanceInfoKymaChannelKey       = "kymaChannel"
+)
+
+// NewMaintenanceInfo returns the OSB maintenance_info for the Kubernetes version and the Kyma channel.
+// The version is the major and minor Kubernetes version with the catalog revision as the patch version, so a newer
+// Kubernetes version or a higher revision always has a higher precedence. The revision must be increased whenever
+// the Kyma channel of a plan changes, as the channel itself cannot be ordered.
+func NewMaintenanceInfo(kubernetesVersion, channel string, revision int) *domain.MaintenanceInfo {
+	major, minor := "0", "0"
+	parts := strings.SplitN(kubernetesVersion, ".", 3)
+	if len(parts) > 0 && parts[0] != "" {
+		major = parts[0]
+	}
+	if len(parts) > 1 && parts[1] != "" {
+		minor = parts[1]
+	}
+	return &domain.MaintenanceInfo{
+		Public: map[string]string{
+			MaintenanceInfoKubernetesVersionKey: kubernetesVersion,
+			MaintenanceInfoKymaChannelKey:       channel,
+		},
+		Version:     fmt.Sprintf("%s.%s.%d", major, minor, revision),
+		Description: fmt.Sprintf("Kubernetes %s, Kyma %s channel", kubernetesVersion, channel),
+	}
+}
+
+// validateMaintenanceInfo checks the maintenance_info sent by the platform against the maintenance_info of the plan.
+// Only the version is compared, as platforms are not required to send the other fields.
+// It returns the maintenance_info of the plan or nil if it was not sent.
+func validateMaintenanceInfo(requested, planMaintenanceInfo *domain.MaintenanceInfo) (*domain.MaintenanceInfo, error) {
+	if requested == nil {
+		return nil, nil
+	}
+	if planMaintenanceInfo == nil {
+		return nil, apiresponses.ErrMaintenanceInfoNilConflict
+	}
+	if planMaintenanceInfo.Version != requested.Version {
+		return nil, apiresponses.ErrMaintenanceInfoConflict
+	}
+	return planMaintenanceInfo, nil
+}
+
+// maintenanceInfoChanged returns true only if both maintenance_info are set and their versions differ.
+// An instance without maintenance_info, for example provisioned before the feature was enabled, is not upgraded,
+// but adopts the requested maintenance_info, see adoptMaintenanceInfo.
+func maintenanceInfoChanged(current, target *domain.MaintenanceInfo) bool {
+	if current == nil || target == nil {
+		return false
+	}
+	return current.Version != target.Version
+}
+
+// adoptMaintenanceInfo returns true if the instance has no maintenance_info yet and the platform sent one.
+func adoptMaintenanceInfo(current, target *domain.MaintenanceInfo) bool {
+	return current == nil && target != nil
+}
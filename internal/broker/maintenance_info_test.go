@@ -0,0 +1,101 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaintenanceInfo(t *testing.T) {
+	// when
+	maintenanceInfo := NewMaintenanceInfo("1.31", "regular", 0)
+
+	// then
+	assert.Equal(t, &domain.MaintenanceInfo{
+		Public: map[string]string{
+			MaintenanceInfoKubernetesVersionKey: "1.31",
+			MaintenanceInfoKymaChannelKey:       "regular",
+		},
+		Version:     "1.31.0",
+		Description: "Kubernetes 1.31, Kyma regular channel",
+	}, maintenanceInfo)
+}
+
+func TestNewMaintenanceInfo_Revision(t *testing.T) {
+	// when
+	maintenanceInfo := NewMaintenanceInfo("1.31.2", "fast", 3)
+
+	// then
+	assert.Equal(t, "1.31.3", maintenanceInfo.Version)
+	assert.Equal(t, "1.31.2", maintenanceInfo.Public[MaintenanceInfoKubernetesVersionKey])
+	assert.Equal(t, "fast", maintenanceInfo.Public[MaintenanceInfoKymaChannelKey])
+}
+
+func TestValidateMaintenanceInfo(t *testing.T) {
+	planMaintenanceInfo := NewMaintenanceInfo("1.31", "regular", 0)
+
+	t.Run("should accept missing maintenance_info", func(t *testing.T) {
+		// when
+		maintenanceInfo, err := validateMaintenanceInfo(nil, planMaintenanceInfo)
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, maintenanceInfo)
+	})
+
+	t.Run("should accept maintenance_info of the plan", func(t *testing.T) {
+		// when
+		maintenanceInfo, err := validateMaintenanceInfo(NewMaintenanceInfo("1.31", "regular", 0), planMaintenanceInfo)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, planMaintenanceInfo, maintenanceInfo)
+	})
+
+	t.Run("should accept maintenance_info with the version only", func(t *testing.T) {
+		// when
+		maintenanceInfo, err := validateMaintenanceInfo(&domain.MaintenanceInfo{Version: "1.31.0"}, planMaintenanceInfo)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, planMaintenanceInfo, maintenanceInfo)
+	})
+
+	t.Run("should reject outdated maintenance_info", func(t *testing.T) {
+		// when
+		_, err := validateMaintenanceInfo(NewMaintenanceInfo("1.30", "regular", 0), planMaintenanceInfo)
+
+		// then
+		assert.Equal(t, apiresponses.ErrMaintenanceInfoConflict, err)
+	})
+
+	t.Run("should reject maintenance_info if the plan does not have it", func(t *testing.T) {
+		// when
+		_, err := validateMaintenanceInfo(planMaintenanceInfo, nil)
+
+		// then
+		assert.Equal(t, apiresponses.ErrMaintenanceInfoNilConflict, err)
+	})
+}
+
+func TestMaintenanceInfoChanged(t *testing.T) {
+	current := NewMaintenanceInfo("1.30", "regular", 0)
+
+	assert.False(t, maintenanceInfoChanged(current, nil))
+	assert.False(t, maintenanceInfoChanged(current, NewMaintenanceInfo("1.30", "regular", 0)))
+	assert.True(t, maintenanceInfoChanged(current, NewMaintenanceInfo("1.31", "regular", 0)))
+	assert.True(t, maintenanceInfoChanged(current, NewMaintenanceInfo("1.30", "fast", 1)))
+	assert.False(t, maintenanceInfoChanged(nil, current))
+	assert.False(t, maintenanceInfoChanged(nil, nil))
+}
+
+func TestAdoptMaintenanceInfo(t *testing.T) {
+	current := NewMaintenanceInfo("1.30", "regular", 0)
+
+	assert.True(t, adoptMaintenanceInfo(nil, current))
+	assert.False(t, adoptMaintenanceInfo(current, NewMaintenanceInfo("1.31", "regular", 0)))
+	assert.False(t, adoptMaintenanceInfo(nil, nil))
+}
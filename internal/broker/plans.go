@@ -1,9 +1,13 @@
 package broker
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/labstack/gommon/log"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
 )
@@ -53,6 +57,140 @@ var PlanIDsMapping = map[PlanNameType]PlanIDType{
 	BuildRuntimeAlicloudPlanName: BuildRuntimeAlicloudPlanID,
 }
 
+// declaredPlans holds the plans declared in the plans configuration file, by plan ID.
+var declaredPlans = map[PlanIDType]configuration.PlanDefinition{}
+
+// plansRegistration serializes RegisterPlans. The plan maps are read without locking, so the plans are registered only once, before serving.
+var (
+	plansRegistration sync.Mutex
+	plansRegistered   bool
+)
+
+// RegisterPlans adds the plans declared in the plans configuration file to the available plans.
+// A built-in plan may be declared to set its schema toggles and defaults, but its ID, provider and kind cannot be changed.
+// It must be called once at startup, before the available plans are used. All definitions are validated before any plan is registered.
+func RegisterPlans(definitions []configuration.PlanDefinition) error {
+	plansRegistration.Lock()
+	defer plansRegistration.Unlock()
+	if plansRegistered {
+		return fmt.Errorf("plans are already registered")
+	}
+
+	declared := map[PlanIDType]configuration.PlanDefinition{}
+	added := map[PlanNameType]PlanIDType{}
+	for _, definition := range definitions {
+		planName, planID := PlanNameType(definition.Name), PlanIDType(definition.ID)
+		if existingID, found := AvailablePlans.nameToID[planName]; found {
+			if _, duplicated := declared[existingID]; duplicated {
+				return fmt.Errorf("plan %s is declared more than once", definition.Name)
+			}
+			if err := validateBuiltInDefinition(definition, existingID); err != nil {
+				return err
+			}
+			declared[existingID] = definition
+			continue
+		}
+		if name, found := AvailablePlans.idToName[planID]; found {
+			return fmt.Errorf("plan %s: ID %s is already used by plan %s", definition.Name, definition.ID, name)
+		}
+		if _, duplicated := declared[planID]; duplicated {
+			return fmt.Errorf("plan %s: ID %s is declared more than once", definition.Name, definition.ID)
+		}
+		declared[planID] = definition
+		added[planName] = planID
+	}
+
+	for planName, planID := range added {
+		PlanIDsMapping[planName] = planID
+		AvailablePlans.idToName[planID] = planName
+		AvailablePlans.nameToID[planName] = planID
+	}
+	for planID, definition := range declared {
+		declaredPlans[planID] = definition
+	}
+	plansRegistered = true
+	return nil
+}
+
+// RegisterPlansFromFile reads the plans configuration file and registers the declared plans, see RegisterPlans.
+// Every binary which resolves plan names or kinds with AvailablePlans must call it at startup, otherwise the declared plans are unknown.
+func RegisterPlansFromFile(filePath string) (*configuration.PlanSpecifications, error) {
+	plansSpec, err := configuration.NewPlanSpecificationsFromFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("while reading the plans configuration: %w", err)
+	}
+	if err := RegisterPlans(plansSpec.Definitions()); err != nil {
+		return nil, fmt.Errorf("while registering the plans: %w", err)
+	}
+	return plansSpec, nil
+}
+
+func validateBuiltInDefinition(definition configuration.PlanDefinition, builtInID PlanIDType) error {
+	if PlanIDType(definition.ID) != builtInID {
+		return fmt.Errorf("plan %s: ID %s does not match the built-in plan ID %s", definition.Name, definition.ID, builtInID)
+	}
+	kind := configuration.PlanKindStandard
+	switch {
+	case IsTrialPlan(string(builtInID)):
+		kind = configuration.PlanKindTrial
+	case IsFreemiumPlan(string(builtInID)):
+		kind = configuration.PlanKindFree
+	}
+	if definition.Kind != kind {
+		return fmt.Errorf("plan %s: kind %s does not match the built-in plan kind %s", definition.Name, definition.Kind, kind)
+	}
+	if provider, found := PlanCloudProvider(definition.Name); found && pkg.CloudProviderFromString(definition.Provider) != provider {
+		return fmt.Errorf("plan %s: provider %s does not match the built-in plan provider %s", definition.Name, definition.Provider, provider)
+	}
+	return nil
+}
+
+// DeclaredPlan returns the definition of the plan declared in the plans configuration file.
+func DeclaredPlan(planID string) (configuration.PlanDefinition, bool) {
+	definition, found := declaredPlans[PlanIDType(planID)]
+	return definition, found
+}
+
+// PlanCloudProvider returns the cloud provider of a plan with a fixed provider. Trial and free plans are not included.
+func PlanCloudProvider(planName string) (pkg.CloudProvider, bool) {
+	if definition, found := declaredPlanByName(planName); found && definition.Kind == configuration.PlanKindStandard {
+		return pkg.CloudProviderFromString(definition.Provider), true
+	}
+	switch planName {
+	case AWSPlanName, BuildRuntimeAWSPlanName, PreviewPlanName:
+		return pkg.AWS, true
+	case GCPPlanName, BuildRuntimeGCPPlanName:
+		return pkg.GCP, true
+	case AzurePlanName, BuildRuntimeAzurePlanName, AzureLitePlanName:
+		return pkg.Azure, true
+	case SapConvergedCloudPlanName:
+		return pkg.SapConvergedCloud, true
+	case AlicloudPlanName, BuildRuntimeAlicloudPlanName:
+		return pkg.Alicloud, true
+	default:
+		return "", false
+	}
+}
+
+func declaredPlanByName(planName string) (configuration.PlanDefinition, bool) {
+	planID, found := AvailablePlans.nameToID[PlanNameType(planName)]
+	if !found {
+		return configuration.PlanDefinition{}, false
+	}
+	return DeclaredPlan(string(planID))
+}
+
+// PlanCloudProviders returns cloud providers of all plans with a fixed provider.
+func PlanCloudProviders() map[string]pkg.CloudProvider {
+	providers := map[string]pkg.CloudProvider{}
+	for planName := range PlanIDsMapping {
+		if provider, found := PlanCloudProvider(string(planName)); found {
+			providers[string(planName)] = provider
+		}
+	}
+	return providers
+}
+
 type PlanIDType string
 type PlanNameType string
 
@@ -259,7 +397,7 @@ func IsTrialPlan(planID string) bool {
 	case TrialPlanID:
 		return true
 	default:
-		return false
+		return isDeclaredPlanOfKind(planID, configuration.PlanKindTrial)
 	}
 }
 
@@ -268,10 +406,27 @@ func IsFreemiumPlan(planID string) bool {
 	case FreemiumPlanID:
 		return true
 	default:
-		return false
+		return isDeclaredPlanOfKind(planID, configuration.PlanKindFree)
 	}
 }
 
+// FreemiumPlanIDs returns the IDs of the built-in free plan and the declared free plans.
+func FreemiumPlanIDs() []string {
+	planIDs := []string{FreemiumPlanID}
+	for planID, definition := range declaredPlans {
+		if definition.Kind == configuration.PlanKindFree && planID != FreemiumPlanID {
+			planIDs = append(planIDs, string(planID))
+		}
+	}
+	sort.Strings(planIDs)
+	return planIDs
+}
+
+func isDeclaredPlanOfKind(planID, kind string) bool {
+	definition, found := DeclaredPlan(planID)
+	return found && definition.Kind == kind
+}
+
 func filter(items *[]interface{}, included map[string]interface{}) interface{} {
 	output := make([]interface{}, 0)
 	for i := 0; i < len(*items); i++ {
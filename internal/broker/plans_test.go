@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"maps"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
@@ -27,10 +29,12 @@ const (
 	freeAzurePlanName = "free-azure"
 )
 
+var builtInPlans = maps.Clone(PlanIDsMapping)
+
 func TestSchemaService_Alicloud(t *testing.T) {
 	schemaService := createSchemaService(t)
 
-	create, update, _ := schemaService.AlicloudSchemas(platformRegionEU40)
+	create, update, _ := schemaService.planSchemas(pkg.Alicloud, AlicloudPlanName, platformRegionEU40)
 	validateSchema(t, Marshal(create), "alicloud/alicloud-schema-additional-params-ingress.json")
 	validateSchema(t, Marshal(update), "alicloud/update-alicloud-schema-additional-params-ingress.json")
 }
@@ -38,10 +42,10 @@ func TestSchemaService_Alicloud(t *testing.T) {
 func TestSchemaService_Azure(t *testing.T) {
 	schemaService := createSchemaService(t)
 
-	create, _, _ := schemaService.AzureSchemas("cf-ch20")
+	create, _, _ := schemaService.planSchemas(pkg.Azure, AzurePlanName, "cf-ch20")
 	validateSchema(t, Marshal(create), "azure/azure-schema-additional-params-ingress-eu.json")
 
-	create, update, _ := schemaService.AzureSchemas(platformRegionUS21)
+	create, update, _ := schemaService.planSchemas(pkg.Azure, AzurePlanName, platformRegionUS21)
 	validateSchema(t, Marshal(create), "azure/azure-schema-additional-params-ingress.json")
 	validateSchema(t, Marshal(update), "azure/update-azure-schema-additional-params-ingress.json")
 }
@@ -49,10 +53,10 @@ func TestSchemaService_Azure(t *testing.T) {
 func TestSchemaService_Aws(t *testing.T) {
 	schemaService := createSchemaService(t)
 
-	create, _, _ := schemaService.AWSSchemas("cf-eu11")
+	create, _, _ := schemaService.planSchemas(pkg.AWS, AWSPlanName, "cf-eu11")
 	validateSchema(t, Marshal(create), "aws/aws-schema-additional-params-ingress-eu.json")
 
-	create, update, _ := schemaService.AWSSchemas(platformRegionUS11)
+	create, update, _ := schemaService.planSchemas(pkg.AWS, AWSPlanName, platformRegionUS11)
 	validateSchema(t, Marshal(create), "aws/aws-schema-additional-params-ingress.json")
 	validateSchema(t, Marshal(update), "aws/update-aws-schema-additional-params-ingress.json")
 }
@@ -60,7 +64,7 @@ func TestSchemaService_Aws(t *testing.T) {
 func TestSchemaService_Gcp(t *testing.T) {
 	schemaService := createSchemaService(t)
 
-	create, update, _ := schemaService.GCPSchemas(platformRegionUS11)
+	create, update, _ := schemaService.planSchemas(pkg.GCP, GCPPlanName, platformRegionUS11)
 	validateSchema(t, Marshal(create), "gcp/gcp-schema-additional-params-ingress.json")
 	validateSchema(t, Marshal(update), "gcp/update-gcp-schema-additional-params-ingress.json")
 }
@@ -68,7 +72,7 @@ func TestSchemaService_Gcp(t *testing.T) {
 func TestSchemaService_SapConvergedCloud(t *testing.T) {
 	schemaService := createSchemaService(t)
 
-	create, update, _ := schemaService.SapConvergedCloudSchemas(platformRegionEU20)
+	create, update, _ := schemaService.planSchemas(pkg.SapConvergedCloud, SapConvergedCloudPlanName, platformRegionEU20)
 	validateSchema(t, Marshal(create), "sap-converged-cloud/sap-converged-cloud-schema-additional-params-ingress.json")
 	validateSchema(t, Marshal(update), "sap-converged-cloud/update-sap-converged-cloud-schema-additional-params-ingress.json")
 }
@@ -76,20 +80,20 @@ func TestSchemaService_SapConvergedCloud(t *testing.T) {
 func TestSchemaService_FreeAWS(t *testing.T) {
 	schemaService := createSchemaService(t)
 
-	got := schemaService.FreeSchema(pkg.AWS, platformRegionUS21, false)
+	got := schemaService.freeSchema(FreemiumPlanName, pkg.AWS, platformRegionUS21, false)
 	validateSchema(t, Marshal(got), "aws/free-aws-schema-additional-params-ingress.json")
 
-	got = schemaService.FreeSchema(pkg.AWS, "cf-eu11", false)
+	got = schemaService.freeSchema(FreemiumPlanName, pkg.AWS, "cf-eu11", false)
 	validateSchema(t, Marshal(got), "aws/free-aws-schema-additional-params-ingress-eu.json")
 }
 
 func TestSchemaService_FreeAzure(t *testing.T) {
 	schemaService := createSchemaService(t)
 
-	got := schemaService.FreeSchema(pkg.Azure, platformRegionUS21, false)
+	got := schemaService.freeSchema(FreemiumPlanName, pkg.Azure, platformRegionUS21, false)
 	validateSchema(t, Marshal(got), "azure/free-azure-schema-additional-params-ingress.json")
 
-	got = schemaService.FreeSchema(pkg.Azure, "cf-ch20", false)
+	got = schemaService.freeSchema(FreemiumPlanName, pkg.Azure, "cf-ch20", false)
 	validateSchema(t, Marshal(got), "azure/free-azure-schema-additional-params-ingress-eu.json")
 }
 
@@ -107,7 +111,7 @@ func TestSchemaService_AzureLite(t *testing.T) {
 func TestSchemaService_Trial(t *testing.T) {
 	schemaService := createSchemaService(t)
 
-	got := schemaService.TrialSchema(false)
+	got := schemaService.trialSchema(TrialPlanName, false)
 	validateSchema(t, Marshal(got), "azure/azure-trial-schema-additional-params-ingress.json")
 }
 
@@ -257,44 +261,88 @@ func planSchemaCases(svc *SchemaService, planNames ...string) []struct {
 } {
 	registry := map[string]planSchemaEntry{
 		AWSPlanName: {
-			create: func() *map[string]interface{} { s, _, _ := svc.AWSSchemas(platformRegionUS11); return s },
-			update: func() *map[string]interface{} { _, s, _ := svc.AWSSchemas(platformRegionUS11); return s },
+			create: func() *map[string]interface{} {
+				s, _, _ := svc.planSchemas(pkg.AWS, AWSPlanName, platformRegionUS11)
+				return s
+			},
+			update: func() *map[string]interface{} {
+				_, s, _ := svc.planSchemas(pkg.AWS, AWSPlanName, platformRegionUS11)
+				return s
+			},
 		},
 		AzurePlanName: {
-			create: func() *map[string]interface{} { s, _, _ := svc.AzureSchemas(platformRegionUS21); return s },
-			update: func() *map[string]interface{} { _, s, _ := svc.AzureSchemas(platformRegionUS21); return s },
+			create: func() *map[string]interface{} {
+				s, _, _ := svc.planSchemas(pkg.Azure, AzurePlanName, platformRegionUS21)
+				return s
+			},
+			update: func() *map[string]interface{} {
+				_, s, _ := svc.planSchemas(pkg.Azure, AzurePlanName, platformRegionUS21)
+				return s
+			},
 		},
 		AzureLitePlanName: {
 			create: func() *map[string]interface{} { s, _, _ := svc.AzureLiteSchemas(platformRegionUS21); return s },
 			update: func() *map[string]interface{} { _, s, _ := svc.AzureLiteSchemas(platformRegionUS21); return s },
 		},
 		GCPPlanName: {
-			create: func() *map[string]interface{} { s, _, _ := svc.GCPSchemas(platformRegionUS11); return s },
-			update: func() *map[string]interface{} { _, s, _ := svc.GCPSchemas(platformRegionUS11); return s },
+			create: func() *map[string]interface{} {
+				s, _, _ := svc.planSchemas(pkg.GCP, GCPPlanName, platformRegionUS11)
+				return s
+			},
+			update: func() *map[string]interface{} {
+				_, s, _ := svc.planSchemas(pkg.GCP, GCPPlanName, platformRegionUS11)
+				return s
+			},
 		},
 		SapConvergedCloudPlanName: {
-			create: func() *map[string]interface{} { s, _, _ := svc.SapConvergedCloudSchemas(platformRegionEU20); return s },
-			update: func() *map[string]interface{} { _, s, _ := svc.SapConvergedCloudSchemas(platformRegionEU20); return s },
+			create: func() *map[string]interface{} {
+				s, _, _ := svc.planSchemas(pkg.SapConvergedCloud, SapConvergedCloudPlanName, platformRegionEU20)
+				return s
+			},
+			update: func() *map[string]interface{} {
+				_, s, _ := svc.planSchemas(pkg.SapConvergedCloud, SapConvergedCloudPlanName, platformRegionEU20)
+				return s
+			},
 		},
 		AlicloudPlanName: {
-			create: func() *map[string]interface{} { s, _, _ := svc.AlicloudSchemas(platformRegionEU40); return s },
-			update: func() *map[string]interface{} { _, s, _ := svc.AlicloudSchemas(platformRegionEU40); return s },
+			create: func() *map[string]interface{} {
+				s, _, _ := svc.planSchemas(pkg.Alicloud, AlicloudPlanName, platformRegionEU40)
+				return s
+			},
+			update: func() *map[string]interface{} {
+				_, s, _ := svc.planSchemas(pkg.Alicloud, AlicloudPlanName, platformRegionEU40)
+				return s
+			},
 		},
 		PreviewPlanName: {
-			create: func() *map[string]interface{} { s, _, _ := svc.PreviewSchemas(platformRegionUS11); return s },
-			update: func() *map[string]interface{} { _, s, _ := svc.PreviewSchemas(platformRegionUS11); return s },
+			create: func() *map[string]interface{} {
+				s, _, _ := svc.planSchemas(pkg.AWS, PreviewPlanName, platformRegionUS11)
+				return s
+			},
+			update: func() *map[string]interface{} {
+				_, s, _ := svc.planSchemas(pkg.AWS, PreviewPlanName, platformRegionUS11)
+				return s
+			},
 		},
 		freeAWSPlanName: {
-			create: func() *map[string]interface{} { return svc.FreeSchema(pkg.AWS, platformRegionUS21, false) },
-			update: func() *map[string]interface{} { return svc.FreeSchema(pkg.AWS, platformRegionUS21, true) },
+			create: func() *map[string]interface{} {
+				return svc.freeSchema(FreemiumPlanName, pkg.AWS, platformRegionUS21, false)
+			},
+			update: func() *map[string]interface{} {
+				return svc.freeSchema(FreemiumPlanName, pkg.AWS, platformRegionUS21, true)
+			},
 		},
 		freeAzurePlanName: {
-			create: func() *map[string]interface{} { return svc.FreeSchema(pkg.Azure, platformRegionUS21, false) },
-			update: func() *map[string]interface{} { return svc.FreeSchema(pkg.Azure, platformRegionUS21, true) },
+			create: func() *map[string]interface{} {
+				return svc.freeSchema(FreemiumPlanName, pkg.Azure, platformRegionUS21, false)
+			},
+			update: func() *map[string]interface{} {
+				return svc.freeSchema(FreemiumPlanName, pkg.Azure, platformRegionUS21, true)
+			},
 		},
 		TrialPlanName: {
-			create: func() *map[string]interface{} { return svc.TrialSchema(false) },
-			update: func() *map[string]interface{} { return svc.TrialSchema(true) },
+			create: func() *map[string]interface{} { return svc.trialSchema(TrialPlanName, false) },
+			update: func() *map[string]interface{} { return svc.trialSchema(TrialPlanName, true) },
 		},
 	}
 
@@ -559,7 +607,7 @@ func TestSchemaService_MachineTypeDisplayNamesEnrichedWithDiskSize(t *testing.T)
 	svc.kcrVolumeProvider = &fixedVolumeSizeProvider{sizes: kcrVolumeSizes}
 
 	t.Run("aws regular machine type display name includes disk size", func(t *testing.T) {
-		create, _, available := svc.AWSSchemas(platformRegionUS11)
+		create, _, available := svc.planSchemas(pkg.AWS, AWSPlanName, platformRegionUS11)
 		require.True(t, available)
 		require.NotNil(t, create)
 
@@ -576,7 +624,7 @@ func TestSchemaService_MachineTypeDisplayNamesEnrichedWithDiskSize(t *testing.T)
 	})
 
 	t.Run("gpu machine type with trailing asterisk has disk size inside parentheses", func(t *testing.T) {
-		create, _, available := svc.AWSSchemas(platformRegionUS11)
+		create, _, available := svc.planSchemas(pkg.AWS, AWSPlanName, platformRegionUS11)
 		require.True(t, available)
 		require.NotNil(t, create)
 
@@ -599,7 +647,7 @@ func TestSchemaService_MachineTypeDisplayNamesEnrichedWithDiskSize(t *testing.T)
 	})
 
 	t.Run("gcp regular machine type display name includes disk size", func(t *testing.T) {
-		create, _, available := svc.GCPSchemas(platformRegionUS11)
+		create, _, available := svc.planSchemas(pkg.GCP, GCPPlanName, platformRegionUS11)
 		require.True(t, available)
 		require.NotNil(t, create)
 
@@ -617,7 +665,7 @@ func TestSchemaService_MachineTypeDisplayNamesEnrichedWithDiskSize(t *testing.T)
 
 	t.Run("display names unchanged when kcrVolumeSizes is nil", func(t *testing.T) {
 		svcNil := createSchemaService(t)
-		create, _, available := svcNil.AWSSchemas(platformRegionUS11)
+		create, _, available := svcNil.planSchemas(pkg.AWS, AWSPlanName, platformRegionUS11)
 		require.True(t, available)
 		require.NotNil(t, create)
 
@@ -756,3 +804,142 @@ type fixedVolumeSizeProvider struct {
 func (f *fixedVolumeSizeProvider) CloudProviderVolumeSizes(_ context.Context) (map[pkg.CloudProvider]map[string]int, error) {
 	return f.sizes, nil
 }
+
+func TestRegisterPlans(t *testing.T) {
+	t.Run("should register declared plans", func(t *testing.T) {
+		// given
+		plans, err := configuration.NewPlanSpecifications(strings.NewReader(`
+sovereign-aws:
+  id: 5f9a8c1e-3b0d-4f43-9a63-1d2c7e8f0a11
+  provider: aws
+  upgradableToPlans: [aws]
+  schema:
+    ingressFiltering: true
+    disableHAZones: true
+    autoScalerMinimum: 3
+    autoScalerMaximum: 20
+  defaults:
+    autoScalerMin: 3
+    autoScalerMax: 6
+  regularMachines: [m6i.large]
+  regions:
+    default: [eu-central-1]
+sovereign-trial:
+  id: 0d7c1f6e-8a1b-4a5e-9f3c-2b6d4e8a9c10
+  kind: trial
+`))
+		require.NoError(t, err)
+		registerPlans(t, plans.Definitions())
+		provider, err := configuration.NewProviderSpecFromFile("testdata/providers.yaml")
+		require.NoError(t, err)
+		schemaService := NewSchemaService(provider, plans, nil, Config{}, StringList{}, &fixture.FakeChannelResolver{}, nil)
+
+		// when
+		servicePlans := schemaService.Plans(PlansConfig{}, platformRegionUS11, pkg.AWS)
+
+		// then
+		planID, found := AvailablePlans.GetPlanIDByName("sovereign-aws")
+		require.True(t, found)
+		cloudProvider, found := PlanCloudProvider("sovereign-aws")
+		assert.True(t, found)
+		assert.Equal(t, pkg.AWS, cloudProvider)
+		assert.True(t, IsTrialPlan("0d7c1f6e-8a1b-4a5e-9f3c-2b6d4e8a9c10"))
+		assert.Contains(t, servicePlans, "0d7c1f6e-8a1b-4a5e-9f3c-2b6d4e8a9c10")
+
+		servicePlan, found := servicePlans[string(planID)]
+		require.True(t, found)
+		assert.Equal(t, "sovereign-aws", servicePlan.Name)
+		props := servicePlan.Schemas.Instance.Create.Parameters["properties"].(map[string]interface{})
+		assert.Contains(t, props, "ingressFiltering")
+		autoScalerMin := props["autoScalerMin"].(map[string]interface{})
+		assert.Equal(t, 3, int(autoScalerMin["minimum"].(float64)))
+		assert.Equal(t, 20, int(autoScalerMin["maximum"].(float64)))
+		assert.Equal(t, 3, int(autoScalerMin["default"].(float64)))
+		pools := props["additionalWorkerNodePools"].(map[string]interface{})["items"].(map[string]interface{})["properties"].(map[string]interface{})
+		assert.NotContains(t, pools, "haZones")
+	})
+
+	t.Run("should register plans only once", func(t *testing.T) {
+		// given
+		plans, err := configuration.NewPlanSpecifications(strings.NewReader("sovereign-gcp:\n  id: 2c1e7d4a-6b3f-4e8a-9d5c-7f0a1b2c3d4e\n  provider: gcp\n"))
+		require.NoError(t, err)
+		registerPlans(t, plans.Definitions())
+
+		// when
+		err = RegisterPlans(plans.Definitions())
+
+		// then
+		assert.EqualError(t, err, "plans are already registered")
+	})
+
+	t.Run("should not register any plan if a definition is invalid", func(t *testing.T) {
+		// given
+		plans, err := configuration.NewPlanSpecifications(strings.NewReader("sovereign-gcp:\n  id: 2c1e7d4a-6b3f-4e8a-9d5c-7f0a1b2c3d4e\n  provider: gcp\nother:\n  id: " + AzurePlanID + "\n  provider: azure\n"))
+		require.NoError(t, err)
+
+		// when
+		err = RegisterPlans(plans.Definitions())
+		t.Cleanup(func() { unregisterPlans(plans.Definitions()) })
+
+		// then
+		assert.EqualError(t, err, "plan other: ID "+AzurePlanID+" is already used by plan azure")
+		_, found := AvailablePlans.GetPlanIDByName("sovereign-gcp")
+		assert.False(t, found)
+	})
+
+	for name, tc := range map[string]struct {
+		input string
+		err   string
+	}{
+		"built-in plan with a different ID": {
+			input: "aws:\n  id: 11111111-1111-1111-1111-111111111111\n  provider: aws\n",
+			err:   "plan aws: ID 11111111-1111-1111-1111-111111111111 does not match the built-in plan ID " + AWSPlanID,
+		},
+		"built-in plan with a different kind": {
+			input: "trial:\n  id: " + TrialPlanID + "\n  provider: aws\n",
+			err:   "plan trial: kind standard does not match the built-in plan kind trial",
+		},
+		"ID of another plan": {
+			input: "other:\n  id: " + AzurePlanID + "\n  provider: azure\n",
+			err:   "plan other: ID " + AzurePlanID + " is already used by plan azure",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			plans, err := configuration.NewPlanSpecifications(strings.NewReader(tc.input))
+			require.NoError(t, err)
+
+			// when
+			err = RegisterPlans(plans.Definitions())
+			t.Cleanup(func() { unregisterPlans(plans.Definitions()) })
+
+			// then
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func registerPlans(t *testing.T, definitions []configuration.PlanDefinition) {
+	require.NoError(t, RegisterPlans(definitions))
+	t.Cleanup(func() { unregisterPlans(definitions) })
+}
+
+func unregisterPlans(definitions []configuration.PlanDefinition) {
+	plansRegistration.Lock()
+	defer plansRegistration.Unlock()
+	plansRegistered = false
+	for _, definition := range definitions {
+		planName := PlanNameType(definition.Name)
+		if builtInID, builtIn := builtInPlans[planName]; builtIn {
+			delete(declaredPlans, builtInID)
+			continue
+		}
+		if AvailablePlans.nameToID[planName] != PlanIDType(definition.ID) {
+			continue
+		}
+		delete(declaredPlans, PlanIDType(definition.ID))
+		delete(PlanIDsMapping, planName)
+		delete(AvailablePlans.idToName, PlanIDType(definition.ID))
+		delete(AvailablePlans.nameToID, planName)
+	}
+}
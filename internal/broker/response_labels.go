@@ -61,7 +61,7 @@ func ResponseLabelsWithExpirationInfo(
 ) map[string]any {
 	labels := ResponseLabels(instance, brokerURL, kubeconfigBuilder)
 
-	expireTime := instance.CreatedAt.Add(expireDuration)
+	expireTime := instance.ExpirationTime(expireDuration)
 	hoursLeft := calculateHoursLeft(expireTime)
 	if instance.IsExpired() {
 		delete(labels, kubeconfigURLKey)
@@ -10,6 +10,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -104,6 +105,23 @@ func TestResponseLabels(t *testing.T) {
 		assert.Equal(t, serverURL, labels["APIServerURL"])
 	})
 
+	t.Run("should return labels with expire info for instance with extended expiration", func(t *testing.T) {
+		// given
+		instance := fixture.FixInstance("instanceID")
+		instance.CreatedAt = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		instance.ExpiresAt = ptr.Time(time.Now().Add(10 * 24 * time.Hour))
+		kcBuilder := &automock.KcBuilder{}
+		kcBuilder.On("GetServerURL", instance.RuntimeID).Return(serverURL, nil)
+
+		expectedMsg := fmt.Sprintf(notExpiredInfoFormat, "in 10 days")
+
+		// when
+		labels := ResponseLabelsWithExpirationInfo(instance, "https://example.com", "https://trial.docs.local", trialDocsKey, trialExpireDuration, trialExpiryDetailsKey, trialExpiredInfoFormat, kcBuilder)
+
+		// then
+		assert.Equal(t, expectedMsg, labels[trialExpiryDetailsKey])
+	})
+
 	t.Run("should return labels with expire info for expired instance", func(t *testing.T) {
 		// given
 		instance := fixture.FixInstance("instanceID")
@@ -8,6 +8,7 @@ import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
 )
 
@@ -27,6 +28,12 @@ type SchemaService struct {
 	channelResolver config.ChannelResolver
 
 	kcrVolumeProvider VolumeSizeProvider
+
+	// kubernetesVersion is set only if maintenance_info is enabled
+	kubernetesVersion       string
+	maintenanceInfoRevision int
+
+	residencyPolicies *residency.Policies
 }
 
 func NewSchemaService(providerSpec *configuration.ProviderSpec, planSpec *configuration.PlanSpecifications, defaultOIDCConfig *pkg.OIDCConfigDTO, cfg Config, ingressFilteringPlans StringList, channelResolver config.ChannelResolver, kcrVolumeProvider VolumeSizeProvider) *SchemaService {
@@ -38,24 +45,42 @@ func NewSchemaService(providerSpec *configuration.ProviderSpec, planSpec *config
 		ingressFilteringPlans: ingressFilteringPlans,
 		channelResolver:       channelResolver,
 		kcrVolumeProvider:     kcrVolumeProvider,
+		residencyPolicies:     residency.DefaultPolicies(),
+	}
+}
+
+// WithResidencyPolicies replaces the default residency policies used to filter regions and machine types in the plan schemas.
+func (s *SchemaService) WithResidencyPolicies(policies *residency.Policies) *SchemaService {
+	s.residencyPolicies = policies
+	return s
+}
+
+// WithMaintenanceInfo enables the OSB maintenance_info of the plans, built from the Kubernetes version, the Kyma channel of the plan
+// and the catalog revision.
+func (s *SchemaService) WithMaintenanceInfo(kubernetesVersion string, revision int) *SchemaService {
+	s.kubernetesVersion = kubernetesVersion
+	s.maintenanceInfoRevision = revision
+	return s
+}
+
+// MaintenanceInfo returns the maintenance_info of the plan or nil if maintenance_info is not enabled.
+func (s *SchemaService) MaintenanceInfo(planName string) *domain.MaintenanceInfo {
+	if s == nil || s.kubernetesVersion == "" {
+		return nil
+	}
+	channel := "regular"
+	if s.channelResolver != nil {
+		if planChannel, err := s.channelResolver.GetChannelForPlan(planName); err == nil && planChannel != "" {
+			channel = planChannel
+		}
 	}
+	return NewMaintenanceInfo(s.kubernetesVersion, channel, s.maintenanceInfoRevision)
 }
 
 func (s *SchemaService) Validate() error {
 	for planName, regions := range s.planSpec.AllRegionsByPlan() {
-		var provider pkg.CloudProvider
-		switch planName {
-		case AWSPlanName, BuildRuntimeAWSPlanName, PreviewPlanName:
-			provider = pkg.AWS
-		case GCPPlanName, BuildRuntimeGCPPlanName:
-			provider = pkg.GCP
-		case AzurePlanName, BuildRuntimeAzurePlanName, AzureLitePlanName:
-			provider = pkg.Azure
-		case SapConvergedCloudPlanName:
-			provider = pkg.SapConvergedCloud
-		case AlicloudPlanName, BuildRuntimeAlicloudPlanName:
-			provider = pkg.Alicloud
-		default:
+		provider, found := PlanCloudProvider(planName)
+		if !found {
 			continue
 		}
 		for _, region := range regions {
@@ -72,50 +97,36 @@ func (s *SchemaService) Plans(plans PlansConfig, platformRegion string, cp pkg.C
 
 	outputPlans := map[string]domain.ServicePlan{}
 
-	if createSchema, updateSchema, available := s.AWSSchemas(platformRegion); available {
-		outputPlans[AWSPlanID] = s.defaultServicePlan(AWSPlanID, AWSPlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.GCPSchemas(platformRegion); available {
-		outputPlans[GCPPlanID] = s.defaultServicePlan(GCPPlanID, GCPPlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.AzureSchemas(platformRegion); available {
-		outputPlans[AzurePlanID] = s.defaultServicePlan(AzurePlanID, AzurePlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.SapConvergedCloudSchemas(platformRegion); available {
-		outputPlans[SapConvergedCloudPlanID] = s.defaultServicePlan(SapConvergedCloudPlanID, SapConvergedCloudPlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.AlicloudSchemas(platformRegion); available {
-		outputPlans[AlicloudPlanID] = s.defaultServicePlan(AlicloudPlanID, AlicloudPlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.PreviewSchemas(platformRegion); available {
-		outputPlans[PreviewPlanID] = s.defaultServicePlan(PreviewPlanID, PreviewPlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.BuildRuntimeAWSSchemas(platformRegion); available {
-		outputPlans[BuildRuntimeAWSPlanID] = s.defaultServicePlan(BuildRuntimeAWSPlanID, BuildRuntimeAWSPlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.BuildRuntimeGcpSchemas(platformRegion); available {
-		outputPlans[BuildRuntimeGCPPlanID] = s.defaultServicePlan(BuildRuntimeGCPPlanID, BuildRuntimeGCPPlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.BuildRuntimeAzureSchemas(platformRegion); available {
-		outputPlans[BuildRuntimeAzurePlanID] = s.defaultServicePlan(BuildRuntimeAzurePlanID, BuildRuntimeAzurePlanName, plans, createSchema, updateSchema)
-	}
-	if createSchema, updateSchema, available := s.BuildRuntimeAlicloudSchemas(platformRegion); available {
-		outputPlans[BuildRuntimeAlicloudPlanID] = s.defaultServicePlan(BuildRuntimeAlicloudPlanID, BuildRuntimeAlicloudPlanName, plans, createSchema, updateSchema)
-	}
-	if azureLiteCreateSchema, azureLiteUpdateSchema, available := s.AzureLiteSchemas(platformRegion); available {
-		outputPlans[AzureLitePlanID] = s.defaultServicePlan(AzureLitePlanID, AzureLitePlanName, plans, azureLiteCreateSchema, azureLiteUpdateSchema)
-	}
-	if freemiumCreateSchema, freemiumUpdateSchema, available := s.FreeSchemas(cp, platformRegion); available {
-		outputPlans[FreemiumPlanID] = s.defaultServicePlan(FreemiumPlanID, FreemiumPlanName, plans, freemiumCreateSchema, freemiumUpdateSchema)
+	for _, planID := range AvailablePlans.GetAllPlanIDs() {
+		id := string(planID)
+		name := AvailablePlans.GetPlanNameOrEmpty(planID)
+		createSchema, updateSchema, available := s.planSchemasByID(id, name, platformRegion, cp)
+		if available {
+			outputPlans[id] = s.defaultServicePlan(id, name, plans, createSchema, updateSchema)
+		}
 	}
 
-	trialCreateSchema := s.TrialSchema(false)
-	trialUpdateSchema := s.TrialSchema(true)
-	outputPlans[TrialPlanID] = s.defaultServicePlan(TrialPlanID, TrialPlanName, plans, trialCreateSchema, trialUpdateSchema)
-
 	return outputPlans
 }
 
+// planSchemasByID returns the schemas of the plan based on its kind and provider, so that plans declared in the plans configuration file
+// do not need dedicated schema methods.
+func (s *SchemaService) planSchemasByID(planID, planName, platformRegion string, cp pkg.CloudProvider) (create, update *map[string]interface{}, available bool) {
+	switch {
+	case planID == AzureLitePlanID:
+		return s.AzureLiteSchemas(platformRegion)
+	case IsTrialPlan(planID):
+		return s.trialSchema(planName, false), s.trialSchema(planName, true), true
+	case IsFreemiumPlan(planID):
+		return s.freeSchema(planName, cp, platformRegion, false), s.freeSchema(planName, cp, platformRegion, true), true
+	}
+	provider, found := PlanCloudProvider(planName)
+	if !found {
+		return nil, nil, false
+	}
+	return s.planSchemas(provider, planName, platformRegion)
+}
+
 func (s *SchemaService) defaultServicePlan(id, name string, plans PlansConfig, createParams, updateParams *map[string]interface{}) domain.ServicePlan {
 	updatable := s.planSpec.IsUpgradable(name) && s.cfg.EnablePlanUpgrades
 	servicePlan := domain.ServicePlan{
@@ -133,22 +144,23 @@ func (s *SchemaService) defaultServicePlan(id, name string, plans PlansConfig, c
 				},
 			},
 		},
-		PlanUpdatable: &updatable,
+		PlanUpdatable:   &updatable,
+		MaintenanceInfo: s.MaintenanceInfo(name),
 	}
 
 	return servicePlan
 }
 
 func (s *SchemaService) planSchemas(cp pkg.CloudProvider, planName, platformRegion string) (create, update *map[string]interface{}, available bool) {
-	regions := s.planSpec.Regions(planName, platformRegion)
+	regions := s.residencyPolicies.FilterRegions(platformRegion, cp, s.planSpec.Regions(planName, platformRegion))
 	if len(regions) == 0 {
 		return nil, nil, false
 	}
-	machines := s.planSpec.RegularMachines(planName)
+	machines := s.residencyPolicies.FilterMachineTypes(platformRegion, s.planSpec.RegularMachines(planName))
 	if len(machines) == 0 {
 		return nil, nil, false
 	}
-	regularAndAdditionalMachines := append(machines, s.planSpec.AdditionalMachines(planName)...)
+	regularAndAdditionalMachines := append(machines, s.residencyPolicies.FilterMachineTypes(platformRegion, s.planSpec.AdditionalMachines(planName))...)
 	flags := s.createFlags(planName)
 
 	createProperties := NewProvisioningProperties(
@@ -185,53 +197,17 @@ func (s *SchemaService) planSchemas(cp pkg.CloudProvider, planName, platformRegi
 		createProperties.AccessControlList = ACLProperty()
 		updateProperties.AccessControlList = ACLProperty()
 	}
+	if definition, found := declaredPlanByName(planName); found {
+		applyDeclaredSchema(&createProperties, definition, false)
+		applyDeclaredSchema(&updateProperties, definition, true)
+	}
 	return createSchemaWithProperties(createProperties, s.defaultOIDCConfig, false, requiredSchemaProperties(), flags),
 		createSchemaWithProperties(updateProperties, s.defaultOIDCConfig, true, requiredSchemaProperties(), flags), true
 }
 
-func (s *SchemaService) AzureSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.Azure, AzurePlanName, platformRegion)
-}
-
-func (s *SchemaService) BuildRuntimeAzureSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.Azure, BuildRuntimeAzurePlanName, platformRegion)
-}
-
-func (s *SchemaService) AWSSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.AWS, AWSPlanName, platformRegion)
-}
-
-func (s *SchemaService) BuildRuntimeAWSSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.AWS, BuildRuntimeAWSPlanName, platformRegion)
-}
-
-func (s *SchemaService) GCPSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.GCP, GCPPlanName, platformRegion)
-}
-
-func (s *SchemaService) BuildRuntimeGcpSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.GCP, BuildRuntimeGCPPlanName, platformRegion)
-}
-
-func (s *SchemaService) PreviewSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.AWS, PreviewPlanName, platformRegion)
-}
-
-func (s *SchemaService) SapConvergedCloudSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.SapConvergedCloud, SapConvergedCloudPlanName, platformRegion)
-}
-
-func (s *SchemaService) AlicloudSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.Alicloud, AlicloudPlanName, platformRegion)
-}
-
-func (s *SchemaService) BuildRuntimeAlicloudSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	return s.planSchemas(pkg.Alicloud, BuildRuntimeAlicloudPlanName, platformRegion)
-}
-
 func (s *SchemaService) AzureLiteSchema(platformRegion string, regions []string, update bool) *map[string]interface{} {
 	flags := s.createFlags(AzureLitePlanName)
-	machines := s.planSpec.RegularMachines(AzureLitePlanName)
+	machines := s.residencyPolicies.FilterMachineTypes(platformRegion, s.planSpec.RegularMachines(AzureLitePlanName))
 	displayNames := s.machineDisplayNames(pkg.Azure, machines)
 
 	properties := NewProvisioningProperties(
@@ -276,7 +252,7 @@ func (s *SchemaService) AzureLiteSchema(platformRegion string, regions []string,
 }
 
 func (s *SchemaService) AzureLiteSchemas(platformRegion string) (create, update *map[string]interface{}, available bool) {
-	regions := s.planSpec.Regions(AzureLitePlanName, platformRegion)
+	regions := s.residencyPolicies.FilterRegions(platformRegion, pkg.Azure, s.planSpec.Regions(AzureLitePlanName, platformRegion))
 	if len(regions) == 0 {
 		return nil, nil, false
 	}
@@ -284,18 +260,18 @@ func (s *SchemaService) AzureLiteSchemas(platformRegion string) (create, update
 		s.AzureLiteSchema(platformRegion, regions, true), true
 }
 
-func (s *SchemaService) FreeSchema(provider pkg.CloudProvider, platformRegion string, update bool) *map[string]interface{} {
+func (s *SchemaService) freeSchema(planName string, provider pkg.CloudProvider, platformRegion string, update bool) *map[string]interface{} {
 	var regions []string
 	var regionsDisplayNames map[string]string
 	switch provider {
 	case pkg.Azure:
-		regions = s.planSpec.Regions(AzurePlanName, platformRegion)
+		regions = s.residencyPolicies.FilterRegions(platformRegion, pkg.Azure, s.planSpec.Regions(AzurePlanName, platformRegion))
 		regionsDisplayNames = s.providerSpec.RegionDisplayNames(pkg.Azure, regions)
 	default: // AWS and other BTP regions
-		regions = s.planSpec.Regions(AWSPlanName, platformRegion)
+		regions = s.residencyPolicies.FilterRegions(platformRegion, pkg.AWS, s.planSpec.Regions(AWSPlanName, platformRegion))
 		regionsDisplayNames = s.providerSpec.RegionDisplayNames(pkg.AWS, regions)
 	}
-	flags := s.createFlags(FreemiumPlanName)
+	flags := s.createFlags(planName)
 	flags.auditLogAccess = false
 
 	properties := ProvisioningProperties{
@@ -309,13 +285,13 @@ func (s *SchemaService) FreeSchema(provider pkg.CloudProvider, platformRegion st
 			EnumDisplayName: regionsDisplayNames,
 		},
 	}
-	if s.cfg.IsACLEnabledForPlanName(FreemiumPlanName) {
+	if s.cfg.IsACLEnabledForPlanName(planName) {
 		properties.AccessControlList = ACLProperty()
 	}
 	if !update {
 		defaultChannel := "regular"
 		if s.channelResolver != nil {
-			defaultChannel, _ = s.channelResolver.GetChannelForPlan(FreemiumPlanName)
+			defaultChannel, _ = s.channelResolver.GetChannelForPlan(planName)
 		}
 		properties.Networking = NewNetworkingSchema(flags.rejectUnsupportedParameters, s.providerSpec, provider, s.cfg.DualStackDocsURL)
 		properties.Modules = NewModulesSchema(flags.rejectUnsupportedParameters, defaultChannel)
@@ -324,14 +300,8 @@ func (s *SchemaService) FreeSchema(provider pkg.CloudProvider, platformRegion st
 	return createSchemaWithProperties(properties, s.defaultOIDCConfig, update, requiredSchemaProperties(), flags)
 }
 
-func (s *SchemaService) FreeSchemas(provider pkg.CloudProvider, platformRegion string) (create, update *map[string]interface{}, available bool) {
-	create = s.FreeSchema(provider, platformRegion, false)
-	update = s.FreeSchema(provider, platformRegion, true)
-	return create, update, true
-}
-
-func (s *SchemaService) TrialSchema(update bool) *map[string]interface{} {
-	flags := s.createFlags(TrialPlanName)
+func (s *SchemaService) trialSchema(planName string, update bool) *map[string]interface{} {
+	flags := s.createFlags(planName)
 	flags.auditLogAccess = false
 
 	properties := ProvisioningProperties{
@@ -339,14 +309,14 @@ func (s *SchemaService) TrialSchema(update bool) *map[string]interface{} {
 			Name: NameProperty(update),
 		},
 	}
-	if s.cfg.IsACLEnabledForPlanName(TrialPlanName) {
+	if s.cfg.IsACLEnabledForPlanName(planName) {
 		properties.AccessControlList = ACLProperty()
 	}
 
 	if !update {
 		defaultChannel := "regular"
 		if s.channelResolver != nil {
-			defaultChannel, _ = s.channelResolver.GetChannelForPlan(TrialPlanName)
+			defaultChannel, _ = s.channelResolver.GetChannelForPlan(planName)
 		}
 		properties.Modules = NewModulesSchema(flags.rejectUnsupportedParameters, defaultChannel)
 	}
@@ -355,17 +325,43 @@ func (s *SchemaService) TrialSchema(update bool) *map[string]interface{} {
 }
 
 func (s *SchemaService) createFlags(planName string) ControlFlagsObject {
+	definition, _ := declaredPlanByName(planName)
 	return NewControlFlagsObject(
-		s.ingressFilteringPlans.Contains(planName),
+		s.ingressFilteringPlans.Contains(planName) || definition.Schema.IngressFiltering,
 		s.cfg.GvisorEnabled,
 		s.cfg.RejectUnsupportedParameters,
 		s.cfg.WorkerPoolLabelsAnnotationsEnabled,
-		s.cfg.AdditionalVolumeSizeGIPlans.Contains(planName),
+		s.cfg.AdditionalVolumeSizeGIPlans.Contains(planName) || definition.Schema.AdditionalVolumeSizeGi,
 		s.cfg.AdditionalVolumeSizeGiMaxSize,
 		s.cfg.AuditLogAccess,
 	)
 }
 
+// applyDeclaredSchema applies the schema toggles and defaults of a plan declared in the plans configuration file.
+func applyDeclaredSchema(properties *ProvisioningProperties, definition configuration.PlanDefinition, update bool) {
+	if definition.Schema.AutoScalerMinimum > 0 {
+		properties.AutoScalerMin.Minimum = definition.Schema.AutoScalerMinimum
+		properties.AutoScalerMax.Minimum = definition.Schema.AutoScalerMinimum
+	}
+	if definition.Schema.AutoScalerMaximum > 0 {
+		properties.AutoScalerMin.Maximum = definition.Schema.AutoScalerMaximum
+		properties.AutoScalerMax.Maximum = definition.Schema.AutoScalerMaximum
+	}
+	if definition.Schema.DisableHAZones && properties.AdditionalWorkerNodePools != nil {
+		properties.AdditionalWorkerNodePools.Items.Properties.HAZones = nil
+		properties.AdditionalWorkerNodePools.Items.ControlsOrder = removeString(properties.AdditionalWorkerNodePools.Items.ControlsOrder, "haZones")
+		properties.AdditionalWorkerNodePools.Items.Required = removeString(properties.AdditionalWorkerNodePools.Items.Required, "haZones")
+	}
+	if !update {
+		if definition.Defaults.AutoScalerMin > 0 {
+			properties.AutoScalerMin.Default = definition.Defaults.AutoScalerMin
+		}
+		if definition.Defaults.AutoScalerMax > 0 {
+			properties.AutoScalerMax.Default = definition.Defaults.AutoScalerMax
+		}
+	}
+}
+
 func (s *SchemaService) RandomZones(cp pkg.CloudProvider, region string, zonesCount int) []string {
 	return s.providerSpec.RandomZones(cp, region, zonesCount)
 }
@@ -1,7 +1,9 @@
 package broker
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -45,17 +47,18 @@ func AttachRoutes(router *httputil.Router, serviceBroker domain.ServiceBroker, l
 	router.Use(middleware.AddRegionToContext(defaultRequestRegion))
 	router.Use(middleware.AddProviderToContext())
 
+	getInstance := getInstanceWithMaintenanceInfo(apiHandler.GetInstance, logger)
 	for _, prefix := range prefixes {
-		registerRoutesAndHandlers(router, &apiHandler, deprovision, createBindingTimeout, prefix)
+		registerRoutesAndHandlers(router, &apiHandler, getInstance, deprovision, createBindingTimeout, prefix)
 	}
 
 	return router
 }
 
-func registerRoutesAndHandlers(router *httputil.Router, apiHandler *handlers.APIHandler, deprovisionFunc func(w http.ResponseWriter, req *http.Request), createBindingTimeout time.Duration, pathPrefix string) {
+func registerRoutesAndHandlers(router *httputil.Router, apiHandler *handlers.APIHandler, getInstanceFunc, deprovisionFunc func(w http.ResponseWriter, req *http.Request), createBindingTimeout time.Duration, pathPrefix string) {
 	router.HandleFunc(buildPathPattern(http.MethodGet, pathPrefix, "/v2/catalog"), apiHandler.Catalog)
 
-	router.HandleFunc(buildPathPattern(http.MethodGet, pathPrefix, "/v2/service_instances/{instance_id}"), apiHandler.GetInstance)
+	router.HandleFunc(buildPathPattern(http.MethodGet, pathPrefix, "/v2/service_instances/{instance_id}"), getInstanceFunc)
 	router.HandleFunc(buildPathPattern(http.MethodPut, pathPrefix, "/v2/service_instances/{instance_id}"), apiHandler.Provision)
 	router.HandleFunc(buildPathPattern(http.MethodDelete, pathPrefix, "/v2/service_instances/{instance_id}"), deprovisionFunc)
 	router.HandleFunc(buildPathPattern(http.MethodGet, pathPrefix, "/v2/service_instances/{instance_id}/last_operation"), apiHandler.LastOperation)
@@ -71,3 +74,72 @@ func registerRoutesAndHandlers(router *httputil.Router, apiHandler *handlers.API
 func buildPathPattern(httpMethod, prefix, path string) string {
 	return fmt.Sprintf("%s %s%s", httpMethod, prefix, path)
 }
+
+// getInstanceWithMaintenanceInfo adds the top-level maintenance_info field (OSB API 2.17), which is not supported by brokerapi,
+// to the response of the GetInstance handler. The maintenance_info is copied from the instance parameters.
+func getInstanceWithMaintenanceInfo(getInstance http.HandlerFunc, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		response := &bufferedResponseWriter{header: w.Header(), status: http.StatusOK}
+		getInstance(response, req)
+
+		body := response.body.Bytes()
+		if response.status == http.StatusOK {
+			withMaintenanceInfo, err := addMaintenanceInfo(body)
+			if err != nil {
+				logger.Warn(fmt.Sprintf("unable to add maintenance_info to the instance response: %s", err))
+			} else {
+				body = withMaintenanceInfo
+			}
+		}
+		w.WriteHeader(response.status)
+		if _, err := w.Write(body); err != nil {
+			logger.Error(fmt.Sprintf("while writing the instance response: %s", err))
+		}
+	}
+}
+
+func addMaintenanceInfo(body []byte) ([]byte, error) {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	var parameters struct {
+		MaintenanceInfo json.RawMessage `json:"maintenance_info"`
+	}
+	if raw, found := response["parameters"]; found {
+		if err := json.Unmarshal(raw, &parameters); err != nil {
+			return nil, err
+		}
+	}
+	if len(parameters.MaintenanceInfo) == 0 || string(parameters.MaintenanceInfo) == "null" {
+		return body, nil
+	}
+	response["maintenance_info"] = parameters.MaintenanceInfo
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(response); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// bufferedResponseWriter keeps the response body, so it can be changed before it is written
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
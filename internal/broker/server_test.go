@@ -0,0 +1,62 @@
+package broker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetInstanceWithMaintenanceInfo(t *testing.T) {
+	t.Run("should return the maintenance_info of the instance in the top-level field", func(t *testing.T) {
+		// given
+		handler := getInstanceWithMaintenanceInfo(fixGetInstanceHandler(http.StatusOK,
+			`{"service_id":"s","plan_id":"p","parameters":{"maintenance_info":{"version":"1.31.0"}}}`), fixLogger())
+		recorder := httptest.NewRecorder()
+
+		// when
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/v2/service_instances/instance-id", nil))
+
+		// then
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"service_id":"s","plan_id":"p","parameters":{"maintenance_info":{"version":"1.31.0"}},"maintenance_info":{"version":"1.31.0"}}`, recorder.Body.String())
+	})
+
+	t.Run("should not change the response without maintenance_info", func(t *testing.T) {
+		// given
+		body := `{"service_id":"s","plan_id":"p","parameters":{}}`
+		handler := getInstanceWithMaintenanceInfo(fixGetInstanceHandler(http.StatusOK, body), fixLogger())
+		recorder := httptest.NewRecorder()
+
+		// when
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/v2/service_instances/instance-id", nil))
+
+		// then
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, body, recorder.Body.String())
+	})
+
+	t.Run("should not change the error response", func(t *testing.T) {
+		// given
+		body := `{"description":"instance with instanceID instance-id does not exist"}`
+		handler := getInstanceWithMaintenanceInfo(fixGetInstanceHandler(http.StatusNotFound, body), fixLogger())
+		recorder := httptest.NewRecorder()
+
+		// when
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/v2/service_instances/instance-id", nil))
+
+		// then
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, body, recorder.Body.String())
+	})
+}
+
+func fixGetInstanceHandler(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
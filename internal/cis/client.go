@@ -11,6 +11,7 @@ import (
 	"time"
 
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -50,7 +51,7 @@ func NewClient(ctx context.Context, config Config, log *slog.Logger) *Client {
 	}
 
 	return &Client{
-		httpClient: httpClientOAuth,
+		httpClient: tracing.WrapClient(httpClientOAuth),
 		config:     config,
 		log:        log,
 	}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 
 	"golang.org/x/oauth2/clientcredentials"
 )
@@ -41,7 +42,7 @@ func NewClientV2(ctx context.Context, config Config, log *slog.Logger) *ClientV2
 	}
 
 	return &ClientV2{
-		httpClient: httpClientOAuth,
+		httpClient: tracing.WrapClient(httpClientOAuth),
 		config:     config,
 		log:        log,
 	}
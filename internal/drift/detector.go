@@ -0,0 +1,276 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// actionValueMaxLength is the size of the old_value and new_value columns of the actions table
+	actionValueMaxLength = 255
+	instancesPageSize    = 100
+)
+
+var kymaGVK = schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "Kyma"}
+
+type Config struct {
+	Enabled       bool          `envconfig:"default=false"`
+	Interval      time.Duration `envconfig:"default=1h"`
+	ReconcileBack bool          `envconfig:"default=false"`
+	// Delay between checking consecutive instances to limit the load on KCP
+	Delay time.Duration `envconfig:"default=0s"`
+}
+
+type Detector struct {
+	cfg          Config
+	instances    storage.Instances
+	operations   storage.Operations
+	actions      storage.Actions
+	kcpClient    client.Client
+	providerSpec *configuration.ProviderSpec
+	metrics      *Metrics
+	log          *slog.Logger
+}
+
+type Result struct {
+	Checked    int
+	Drifted    int
+	Reconciled int
+	Errors     int
+}
+
+func NewDetector(cfg Config, db storage.BrokerStorage, kcpClient client.Client, providerSpec *configuration.ProviderSpec, metrics *Metrics, log *slog.Logger) *Detector {
+	return &Detector{
+		cfg:          cfg,
+		instances:    db.Instances(),
+		operations:   db.Operations(),
+		actions:      db.Actions(),
+		kcpClient:    kcpClient,
+		providerSpec: providerSpec,
+		metrics:      metrics,
+		log:          log,
+	}
+}
+
+// Start runs the drift detection periodically until the context is done.
+func (d *Detector) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(d.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.run(ctx)
+			}
+		}
+	}()
+}
+
+func (d *Detector) run(ctx context.Context) {
+	d.log.Info("drift detection started")
+	result, err := d.DetectAll(ctx)
+	if err != nil {
+		d.log.Error(fmt.Sprintf("drift detection failed: %s", err))
+		return
+	}
+	d.log.Info(fmt.Sprintf("drift detection finished: %d instances checked, %d drifted, %d reconciled, %d errors",
+		result.Checked, result.Drifted, result.Reconciled, result.Errors))
+}
+
+// DetectAll compares the Runtime and Kyma CRs of all reconcilable instances with the state expected by KEB.
+// Instances are read page by page, and only instances whose last operation finished are fetched.
+// Deprovisioned or suspended instances and instances with a failed provisioning do not have CRs to compare.
+func (d *Detector) DetectAll(ctx context.Context) (Result, error) {
+	result := Result{}
+	driftedFields := map[Drift]int{}
+	filter := dbmodel.InstanceFilter{
+		Page:     1,
+		PageSize: instancesPageSize,
+		States:   []dbmodel.InstanceState{dbmodel.InstanceSucceeded, dbmodel.InstanceError},
+	}
+	for {
+		instances, _, _, err := d.instances.List(filter)
+		if err != nil {
+			return result, fmt.Errorf("while listing instances: %w", err)
+		}
+
+		for _, instance := range instances {
+			if instance.RuntimeID == "" {
+				continue
+			}
+			if err := d.wait(ctx); err != nil {
+				return result, err
+			}
+
+			result.Checked++
+			drifts, reconciled, err := d.detectForInstance(ctx, instance)
+			if err != nil {
+				d.log.Error(fmt.Sprintf("while detecting drift for instance %s: %s", instance.InstanceID, err))
+				d.metrics.errors.Inc()
+				result.Errors++
+				continue
+			}
+			if len(drifts) > 0 {
+				result.Drifted++
+			}
+			if reconciled {
+				result.Reconciled++
+			}
+			for _, drift := range drifts {
+				driftedFields[Drift{Resource: drift.Resource, Field: drift.Field}]++
+			}
+		}
+
+		if len(instances) < instancesPageSize {
+			break
+		}
+		last := instances[len(instances)-1]
+		filter.After = &pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.InstanceID}
+	}
+
+	d.metrics.setDriftedFields(driftedFields)
+	d.metrics.checked.Set(float64(result.Checked))
+	return result, nil
+}
+
+// wait delays checking the next instance to limit the load on KCP
+func (d *Detector) wait(ctx context.Context) error {
+	if d.cfg.Delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d.cfg.Delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// lastFinishedOperationID returns the ID of the last operation if it is finished and the instance is not deprovisioned.
+// The instance is listed before its CRs are compared, so the state of the last operation is checked again before drifts are reported.
+func (d *Detector) lastFinishedOperationID(instance internal.Instance) (string, bool, error) {
+	lastOp, err := d.operations.GetLastOperationWithAllStates(instance.InstanceID)
+	if err != nil {
+		return "", false, fmt.Errorf("while getting last operation: %w", err)
+	}
+	if !lastOp.IsFinished() || lastOp.Type == internal.OperationTypeDeprovision {
+		return "", false, nil
+	}
+	return lastOp.ID, true, nil
+}
+
+func (d *Detector) detectForInstance(ctx context.Context, instance internal.Instance) ([]Drift, bool, error) {
+	runtime := &imv1.Runtime{}
+	err := d.kcpClient.Get(ctx, client.ObjectKey{
+		Name:      instance.InstanceDetails.GetRuntimeResourceName(),
+		Namespace: instance.InstanceDetails.GetRuntimeResourceNamespace(),
+	}, runtime)
+	switch {
+	case errors.IsNotFound(err):
+		runtime = nil
+	case err != nil:
+		return nil, false, fmt.Errorf("while getting Runtime CR: %w", err)
+	}
+
+	kyma := &unstructured.Unstructured{}
+	kyma.SetGroupVersionKind(kymaGVK)
+	err = d.kcpClient.Get(ctx, client.ObjectKey{
+		Name:      d.kymaName(instance),
+		Namespace: instance.InstanceDetails.GetRuntimeResourceNamespace(),
+	}, kyma)
+	switch {
+	case errors.IsNotFound(err):
+		kyma = nil
+	case err != nil:
+		return nil, false, fmt.Errorf("while getting Kyma CR: %w", err)
+	}
+
+	var runtimeDrifts, kymaDrifts []Drift
+	if runtime != nil {
+		runtimeDrifts = CompareRuntime(instance, runtime, d.providerSpec)
+	}
+	if kyma != nil {
+		kymaDrifts = CompareKymaLabels(instance, kyma.GetLabels())
+	}
+	drifts := append(runtimeDrifts, kymaDrifts...)
+	if len(drifts) == 0 {
+		return nil, false, nil
+	}
+	operationID, finished, err := d.lastFinishedOperationID(instance)
+	if err != nil {
+		return nil, false, err
+	}
+	if !finished {
+		d.log.Info(fmt.Sprintf("skipping drift of instance %s, an operation was started in the meantime", instance.InstanceID))
+		return nil, false, nil
+	}
+	for _, drift := range drifts {
+		d.log.Info(fmt.Sprintf("drift detected for instance %s: %s %s expected %q, actual %q", instance.InstanceID, drift.Resource, drift.Field, drift.Expected, drift.Actual))
+		events.Infof(instance.InstanceID, operationID, "Drift detected in %s CR field %s: expected %q, actual %q", drift.Resource, drift.Field, drift.Expected, drift.Actual)
+	}
+
+	if !d.cfg.ReconcileBack {
+		return drifts, false, nil
+	}
+
+	if len(runtimeDrifts) > 0 {
+		ApplyRuntime(runtime, runtimeDrifts)
+		if err := d.kcpClient.Update(ctx, runtime); err != nil {
+			return drifts, false, fmt.Errorf("while reconciling Runtime CR: %w", err)
+		}
+		d.recordReconciliation(instance, operationID, runtimeDrifts)
+	}
+	if len(kymaDrifts) > 0 {
+		kyma.SetLabels(ApplyKymaLabels(kyma.GetLabels(), kymaDrifts))
+		if err := d.kcpClient.Update(ctx, kyma); err != nil {
+			return drifts, false, fmt.Errorf("while reconciling Kyma CR: %w", err)
+		}
+		d.recordReconciliation(instance, operationID, kymaDrifts)
+	}
+	return drifts, true, nil
+}
+
+func (d *Detector) recordReconciliation(instance internal.Instance, operationID string, drifts []Drift) {
+	for _, drift := range drifts {
+		d.metrics.reconciled.WithLabelValues(drift.Resource, drift.Field).Inc()
+		events.Infof(instance.InstanceID, operationID, "Drift reconciled in %s CR field %s", drift.Resource, drift.Field)
+		message := fmt.Sprintf("%s CR field %s reconciled back to the state expected by KEB", drift.Resource, drift.Field)
+		if err := d.actions.InsertAction(pkg.DriftReconciliationActionType, instance.InstanceID, message, truncate(drift.Actual), truncate(drift.Expected)); err != nil {
+			d.log.Error(fmt.Sprintf("while inserting drift reconciliation action for instance %s: %s", instance.InstanceID, err))
+		}
+	}
+}
+
+func (d *Detector) kymaName(instance internal.Instance) string {
+	if instance.InstanceDetails.KymaResourceName != "" {
+		return instance.InstanceDetails.KymaResourceName
+	}
+	return instance.InstanceDetails.GetRuntimeResourceName()
+}
+
+func truncate(value string) string {
+	if len(value) > actionValueMaxLength {
+		return value[:actionValueMaxLength]
+	}
+	return value
+}
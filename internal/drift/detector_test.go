@@ -0,0 +1,265 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	gardener "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const instanceID = "instance-id"
+
+func TestCompareRuntime(t *testing.T) {
+	instance := fixture.FixInstance(instanceID)
+
+	t.Run("should not detect drift for Runtime CR in sync", func(t *testing.T) {
+		// given
+		runtime := fixRuntime(instance)
+
+		// when
+		drifts := CompareRuntime(instance, runtime, &configuration.ProviderSpec{})
+
+		// then
+		assert.Empty(t, drifts)
+	})
+
+	t.Run("should detect drifted fields", func(t *testing.T) {
+		// given
+		runtime := fixRuntime(instance)
+		runtime.Spec.Shoot.Provider.Workers[0].Machine.Type = "Standard_D4_v3"
+		runtime.Spec.Shoot.Provider.Workers[0].Maximum = 20
+		runtime.Spec.Security.Administrators = []string{"admin@example.com"}
+		runtime.Labels[customresources.GlobalAccountIdLabel] = "other-ga"
+
+		// when
+		drifts := CompareRuntime(instance, runtime, &configuration.ProviderSpec{})
+
+		// then
+		assert.ElementsMatch(t, []Drift{
+			{Resource: RuntimeResource, Field: "metadata.labels." + customresources.GlobalAccountIdLabel, Expected: fixture.GlobalAccountId, Actual: "other-ga"},
+			{Resource: RuntimeResource, Field: "spec.shoot.provider.workers[0].machine.type", Expected: "Standard_D8_v3", Actual: "Standard_D4_v3"},
+			{Resource: RuntimeResource, Field: "spec.shoot.provider.workers[0].maximum", Expected: "10", Actual: "20"},
+			{Resource: RuntimeResource, Field: "spec.security.administrators", Expected: instance.Parameters.ErsContext.UserID, Actual: "admin@example.com"},
+		}, drifts)
+	})
+
+	t.Run("should not compare fields which are not defined in the instance", func(t *testing.T) {
+		// given
+		instance := fixture.FixInstance(instanceID)
+		instance.Parameters.Parameters.MachineType = nil
+		instance.Parameters.Parameters.AutoScalerMin = nil
+		runtime := fixRuntime(instance)
+		runtime.Spec.Shoot.Provider.Workers[0].Machine.Type = "Standard_D4_v3"
+		runtime.Spec.Shoot.Provider.Workers[0].Minimum = 1
+
+		// when
+		drifts := CompareRuntime(instance, runtime, &configuration.ProviderSpec{})
+
+		// then
+		assert.Empty(t, drifts)
+	})
+
+	t.Run("should apply expected values", func(t *testing.T) {
+		// given
+		runtime := fixRuntime(instance)
+		runtime.Spec.Shoot.Provider.Workers[0].Minimum = 1
+		runtime.Spec.Shoot.Region = "northeurope"
+		delete(runtime.Labels, customresources.SubaccountIdLabel)
+		drifts := CompareRuntime(instance, runtime, &configuration.ProviderSpec{})
+		require.Len(t, drifts, 3)
+
+		// when
+		ApplyRuntime(runtime, drifts)
+
+		// then
+		assert.Empty(t, CompareRuntime(instance, runtime, &configuration.ProviderSpec{}))
+	})
+}
+
+func TestDetector(t *testing.T) {
+	err := imv1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+
+	t.Run("should detect drift without reconciling", func(t *testing.T) {
+		// given
+		db, instance := fixStorage(t, domain.Succeeded)
+		runtime := fixRuntime(instance)
+		runtime.Spec.Shoot.Provider.Workers[0].Maximum = 20
+		kyma := fixKyma(instance)
+		kyma.SetLabels(map[string]string{customresources.InstanceIdLabel: instance.InstanceID})
+		kcpClient := fake.NewClientBuilder().WithObjects(runtime, kyma).Build()
+		metrics := NewMetrics(prometheus.NewRegistry())
+		detector := NewDetector(Config{}, db, kcpClient, &configuration.ProviderSpec{}, metrics, fixLogger())
+
+		// when
+		result, err := detector.DetectAll(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Result{Checked: 1, Drifted: 1}, result)
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.drifted.WithLabelValues(RuntimeResource, "spec.shoot.provider.workers[0].maximum")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.drifted.WithLabelValues(KymaResource, "metadata.labels."+customresources.GlobalAccountIdLabel)))
+
+		actual := &imv1.Runtime{}
+		require.NoError(t, kcpClient.Get(context.Background(), client.ObjectKeyFromObject(runtime), actual))
+		assert.Equal(t, int32(20), actual.Spec.Shoot.Provider.Workers[0].Maximum)
+
+		actions, err := db.Actions().ListActionsByInstanceID(instance.InstanceID)
+		require.NoError(t, err)
+		assert.Empty(t, actions)
+	})
+
+	t.Run("should reconcile drift back and record actions", func(t *testing.T) {
+		// given
+		db, instance := fixStorage(t, domain.Succeeded)
+		runtime := fixRuntime(instance)
+		runtime.Spec.Shoot.Provider.Workers[0].Maximum = 20
+		kyma := fixKyma(instance)
+		kyma.SetLabels(map[string]string{customresources.InstanceIdLabel: instance.InstanceID})
+		kcpClient := fake.NewClientBuilder().WithObjects(runtime, kyma).Build()
+		metrics := NewMetrics(prometheus.NewRegistry())
+		detector := NewDetector(Config{ReconcileBack: true}, db, kcpClient, &configuration.ProviderSpec{}, metrics, fixLogger())
+
+		// when
+		result, err := detector.DetectAll(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Result{Checked: 1, Drifted: 1, Reconciled: 1}, result)
+
+		actual := &imv1.Runtime{}
+		require.NoError(t, kcpClient.Get(context.Background(), client.ObjectKeyFromObject(runtime), actual))
+		assert.Equal(t, int32(10), actual.Spec.Shoot.Provider.Workers[0].Maximum)
+
+		actualKyma := fixKyma(instance)
+		require.NoError(t, kcpClient.Get(context.Background(), client.ObjectKeyFromObject(kyma), actualKyma))
+		assert.Empty(t, CompareKymaLabels(instance, actualKyma.GetLabels()))
+
+		actions, err := db.Actions().ListActionsByInstanceID(instance.InstanceID)
+		require.NoError(t, err)
+		require.NotEmpty(t, actions)
+		for _, action := range actions {
+			assert.Equal(t, pkg.DriftReconciliationActionType, action.Type)
+		}
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.reconciled.WithLabelValues(RuntimeResource, "spec.shoot.provider.workers[0].maximum")))
+	})
+
+	t.Run("should skip instance with operation in progress", func(t *testing.T) {
+		// given
+		db, instance := fixStorage(t, domain.InProgress)
+		runtime := fixRuntime(instance)
+		runtime.Spec.Shoot.Provider.Workers[0].Maximum = 20
+		kcpClient := fake.NewClientBuilder().WithObjects(runtime).Build()
+		detector := NewDetector(Config{ReconcileBack: true}, db, kcpClient, &configuration.ProviderSpec{}, NewMetrics(prometheus.NewRegistry()), fixLogger())
+
+		// when
+		result, err := detector.DetectAll(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Result{}, result)
+	})
+
+	t.Run("should check instances from all pages", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		for i := 0; i < instancesPageSize+1; i++ {
+			id := fmt.Sprintf("instance-%03d", i)
+			require.NoError(t, db.Instances().Insert(fixture.FixInstance(id)))
+			require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation("operation-"+id, id)))
+		}
+		detector := NewDetector(Config{}, db, fake.NewClientBuilder().Build(), &configuration.ProviderSpec{}, NewMetrics(prometheus.NewRegistry()), fixLogger())
+
+		// when
+		result, err := detector.DetectAll(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Result{Checked: instancesPageSize + 1}, result)
+	})
+
+	t.Run("should stop waiting between instances when the context is done", func(t *testing.T) {
+		// given
+		db, _ := fixStorage(t, domain.Succeeded)
+		detector := NewDetector(Config{Delay: time.Hour}, db, fake.NewClientBuilder().Build(), &configuration.ProviderSpec{}, NewMetrics(prometheus.NewRegistry()), fixLogger())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// when
+		_, err := detector.DetectAll(ctx)
+
+		// then
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func fixStorage(t *testing.T, lastOperationState domain.LastOperationState) (storage.BrokerStorage, internal.Instance) {
+	db := storage.NewMemoryStorage()
+	instance := fixture.FixInstance(instanceID)
+	require.NoError(t, db.Instances().Insert(instance))
+	operation := fixture.FixProvisioningOperation("operation-id", instanceID)
+	operation.State = lastOperationState
+	require.NoError(t, db.Operations().InsertOperation(operation))
+	return db, instance
+}
+
+func fixRuntime(instance internal.Instance) *imv1.Runtime {
+	return &imv1.Runtime{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.InstanceDetails.GetRuntimeResourceName(),
+			Namespace: instance.InstanceDetails.GetRuntimeResourceNamespace(),
+			Labels:    expectedLabels(instance),
+		},
+		Spec: imv1.RuntimeSpec{
+			Shoot: imv1.RuntimeShoot{
+				Region: instance.ProviderRegion,
+				Provider: imv1.Provider{
+					Workers: []gardener.Worker{
+						{
+							Machine: gardener.Machine{Type: *instance.Parameters.Parameters.MachineType},
+							Minimum: 3,
+							Maximum: 10,
+						},
+					},
+				},
+			},
+			Security: imv1.Security{
+				Administrators: []string{instance.Parameters.ErsContext.UserID},
+			},
+		},
+	}
+}
+
+func fixKyma(instance internal.Instance) *unstructured.Unstructured {
+	kyma := &unstructured.Unstructured{}
+	kyma.SetGroupVersionKind(kymaGVK)
+	kyma.SetName(instance.InstanceDetails.KymaResourceName)
+	kyma.SetNamespace(instance.InstanceDetails.GetRuntimeResourceNamespace())
+	return kyma
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+}
@@ -0,0 +1,244 @@
+package drift
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+)
+
+const (
+	RuntimeResource = "Runtime"
+	KymaResource    = "Kyma"
+)
+
+// Drift describes a single field of a custom resource which differs from the state expected by KEB.
+type Drift struct {
+	Resource string
+	Field    string
+	Expected string
+	Actual   string
+}
+
+// runtimeField renders the expected value of a Runtime CR field from the instance, reads the live value, and reconciles it back.
+// The expected function returns false when the instance does not define the field, so it is not compared.
+type runtimeField struct {
+	name     string
+	expected func(instance internal.Instance, providerSpec *configuration.ProviderSpec) (string, bool)
+	actual   func(runtime *imv1.Runtime) string
+	apply    func(runtime *imv1.Runtime, value string)
+}
+
+var runtimeFields = []runtimeField{
+	{
+		name: "spec.shoot.region",
+		expected: func(instance internal.Instance, _ *configuration.ProviderSpec) (string, bool) {
+			return instance.ProviderRegion, instance.ProviderRegion != ""
+		},
+		actual: func(runtime *imv1.Runtime) string { return runtime.Spec.Shoot.Region },
+		apply:  func(runtime *imv1.Runtime, value string) { runtime.Spec.Shoot.Region = value },
+	},
+	{
+		name: "spec.shoot.provider.workers[0].machine.type",
+		expected: func(instance internal.Instance, providerSpec *configuration.ProviderSpec) (string, bool) {
+			machineType := instance.Parameters.Parameters.MachineType
+			if machineType == nil || *machineType == "" {
+				return "", false
+			}
+			return providerSpec.ResolveMachineType(instance.Provider, *machineType), true
+		},
+		actual: func(runtime *imv1.Runtime) string {
+			if len(runtime.Spec.Shoot.Provider.Workers) == 0 {
+				return ""
+			}
+			return runtime.Spec.Shoot.Provider.Workers[0].Machine.Type
+		},
+		apply: func(runtime *imv1.Runtime, value string) {
+			if len(runtime.Spec.Shoot.Provider.Workers) > 0 {
+				runtime.Spec.Shoot.Provider.Workers[0].Machine.Type = value
+			}
+		},
+	},
+	{
+		name: "spec.shoot.provider.workers[0].minimum",
+		expected: func(instance internal.Instance, _ *configuration.ProviderSpec) (string, bool) {
+			return optionalInt(instance.Parameters.Parameters.AutoScalerMin)
+		},
+		actual: func(runtime *imv1.Runtime) string {
+			if len(runtime.Spec.Shoot.Provider.Workers) == 0 {
+				return ""
+			}
+			return strconv.Itoa(int(runtime.Spec.Shoot.Provider.Workers[0].Minimum))
+		},
+		apply: func(runtime *imv1.Runtime, value string) {
+			if v, err := strconv.Atoi(value); err == nil && len(runtime.Spec.Shoot.Provider.Workers) > 0 {
+				runtime.Spec.Shoot.Provider.Workers[0].Minimum = int32(v)
+			}
+		},
+	},
+	{
+		name: "spec.shoot.provider.workers[0].maximum",
+		expected: func(instance internal.Instance, _ *configuration.ProviderSpec) (string, bool) {
+			return optionalInt(instance.Parameters.Parameters.AutoScalerMax)
+		},
+		actual: func(runtime *imv1.Runtime) string {
+			if len(runtime.Spec.Shoot.Provider.Workers) == 0 {
+				return ""
+			}
+			return strconv.Itoa(int(runtime.Spec.Shoot.Provider.Workers[0].Maximum))
+		},
+		apply: func(runtime *imv1.Runtime, value string) {
+			if v, err := strconv.Atoi(value); err == nil && len(runtime.Spec.Shoot.Provider.Workers) > 0 {
+				runtime.Spec.Shoot.Provider.Workers[0].Maximum = int32(v)
+			}
+		},
+	},
+	{
+		name: "spec.security.administrators",
+		expected: func(instance internal.Instance, _ *configuration.ProviderSpec) (string, bool) {
+			administrators := instance.Parameters.Parameters.RuntimeAdministrators
+			if len(administrators) == 0 {
+				// default admin set from UserID in ERSContext, see CreateRuntimeResourceStep
+				if instance.Parameters.ErsContext.UserID == "" {
+					return "", false
+				}
+				administrators = []string{instance.Parameters.ErsContext.UserID}
+			}
+			return joinSorted(administrators), true
+		},
+		actual: func(runtime *imv1.Runtime) string { return joinSorted(runtime.Spec.Security.Administrators) },
+		apply: func(runtime *imv1.Runtime, value string) {
+			runtime.Spec.Security.Administrators = strings.Split(value, ",")
+		},
+	},
+}
+
+// expectedLabels returns labels which KEB sets on both the Runtime CR and the Kyma CR.
+func expectedLabels(instance internal.Instance) map[string]string {
+	labels := map[string]string{
+		customresources.InstanceIdLabel:      instance.InstanceID,
+		customresources.RuntimeIdLabel:       instance.RuntimeID,
+		customresources.GlobalAccountIdLabel: instance.GlobalAccountID,
+		customresources.SubaccountIdLabel:    instance.SubAccountID,
+		customresources.PlanIdLabel:          instance.ServicePlanID,
+	}
+	// the plan name is unknown if the plan is not registered, the label is not overwritten with an empty value then
+	if planName := broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(instance.ServicePlanID)); planName != "" {
+		labels[customresources.PlanNameLabel] = planName
+	}
+	if instance.Parameters.PlatformRegion != "" {
+		labels[customresources.PlatformRegionLabel] = instance.Parameters.PlatformRegion
+	}
+	if instance.ProviderRegion != "" {
+		labels[customresources.RegionLabel] = instance.ProviderRegion
+	}
+	return labels
+}
+
+// CompareRuntime returns fields of the Runtime CR which differ from the state rendered from the instance.
+func CompareRuntime(instance internal.Instance, runtime *imv1.Runtime, providerSpec *configuration.ProviderSpec) []Drift {
+	drifts := compareLabels(RuntimeResource, expectedLabels(instance), runtime.GetLabels())
+	for _, field := range runtimeFields {
+		expected, defined := field.expected(instance, providerSpec)
+		if !defined {
+			continue
+		}
+		if actual := field.actual(runtime); actual != expected {
+			drifts = append(drifts, Drift{Resource: RuntimeResource, Field: field.name, Expected: expected, Actual: actual})
+		}
+	}
+	return drifts
+}
+
+// CompareRuntimeLabels returns labels of the Runtime CR which differ from the state rendered from the instance.
+func CompareRuntimeLabels(instance internal.Instance, labels map[string]string) []Drift {
+	return compareLabels(RuntimeResource, expectedLabels(instance), labels)
+}
+
+// CompareKymaLabels returns labels of the Kyma CR which differ from the state rendered from the instance.
+func CompareKymaLabels(instance internal.Instance, labels map[string]string) []Drift {
+	return compareLabels(KymaResource, expectedLabels(instance), labels)
+}
+
+// ApplyRuntime sets the expected values of drifted fields on the Runtime CR.
+func ApplyRuntime(runtime *imv1.Runtime, drifts []Drift) {
+	labels := runtime.GetLabels()
+	for _, drift := range drifts {
+		if drift.Resource != RuntimeResource {
+			continue
+		}
+		if label, ok := labelName(drift.Field); ok {
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[label] = drift.Expected
+			continue
+		}
+		for _, field := range runtimeFields {
+			if field.name == drift.Field {
+				field.apply(runtime, drift.Expected)
+			}
+		}
+	}
+	runtime.SetLabels(labels)
+}
+
+// ApplyKymaLabels sets the expected values of drifted labels on the Kyma CR labels.
+func ApplyKymaLabels(labels map[string]string, drifts []Drift) map[string]string {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for _, drift := range drifts {
+		if label, ok := labelName(drift.Field); ok && drift.Resource == KymaResource {
+			labels[label] = drift.Expected
+		}
+	}
+	return labels
+}
+
+func compareLabels(resource string, expected, actual map[string]string) []Drift {
+	var drifts []Drift
+	for _, label := range sortedKeys(expected) {
+		if actual[label] != expected[label] {
+			drifts = append(drifts, Drift{Resource: resource, Field: labelField(label), Expected: expected[label], Actual: actual[label]})
+		}
+	}
+	return drifts
+}
+
+const labelFieldPrefix = "metadata.labels."
+
+func labelField(label string) string {
+	return labelFieldPrefix + label
+}
+
+func labelName(field string) (string, bool) {
+	return strings.CutPrefix(field, labelFieldPrefix)
+}
+
+func optionalInt(value *int) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	return strconv.Itoa(*value), true
+}
+
+func joinSorted(values []string) string {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,61 @@
+package drift
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// exposed metrics:
+// - kcp_keb_v2_drift_checked_instances
+// - kcp_keb_v2_drift_detected_fields
+// - kcp_keb_v2_drift_reconciled_fields_total
+// - kcp_keb_v2_drift_detection_errors_total
+
+const (
+	prometheusNamespace = "kcp"
+	prometheusSubsystem = "keb_v2"
+)
+
+type Metrics struct {
+	checked    prometheus.Gauge
+	drifted    *prometheus.GaugeVec
+	reconciled *prometheus.CounterVec
+	errors     prometheus.Counter
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		checked: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "drift_checked_instances",
+			Help:      "Number of instances checked during the last drift detection run.",
+		}),
+		drifted: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "drift_detected_fields",
+			Help:      "Number of instances with a drifted custom resource field detected during the last drift detection run.",
+		}, []string{"resource", "field"}),
+		reconciled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "drift_reconciled_fields_total",
+			Help:      "Total number of drifted custom resource fields reconciled back to the state expected by KEB.",
+		}, []string{"resource", "field"}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "drift_detection_errors_total",
+			Help:      "Total number of errors which occurred while detecting drift for an instance.",
+		}),
+	}
+	reg.MustRegister(m.checked, m.drifted, m.reconciled, m.errors)
+	return m
+}
+
+func (m *Metrics) setDriftedFields(fields map[Drift]int) {
+	m.drifted.Reset()
+	for field, count := range fields {
+		m.drifted.WithLabelValues(field.Resource, field.Field).Set(float64(count))
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
 )
 
 type ProvisioningParameters struct {
@@ -17,6 +19,9 @@ type ProvisioningParameters struct {
 	PlatformRegion string `json:"platform_region"`
 
 	PlatformProvider pkg.CloudProvider `json:"platform_provider"`
+
+	// MaintenanceInfo is the OSB maintenance_info of the instance, nil if maintenance_info is not enabled
+	MaintenanceInfo *domain.MaintenanceInfo `json:"maintenance_info,omitempty"`
 }
 
 func (p ProvisioningParameters) IsEqual(input ProvisioningParameters) bool {
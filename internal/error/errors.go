@@ -49,6 +49,7 @@ const (
 	LifeCycleManagerDependency      Component = "lifecycle-manager"
 	BtpManagerDependency            Component = "btp-manager"
 	AccountPoolDependency           Component = "account-pool"
+	StepHookDependency              Component = "step-hook"
 )
 
 func (err LastError) GetReason() Reason {
@@ -0,0 +1,156 @@
+package expiration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type ExtensionConfig struct {
+	Enabled bool `envconfig:"default=false"`
+	// MaxDuration limits the extension of the expiration with a single request
+	MaxDuration time.Duration `envconfig:"default=720h"`
+	// TrialExpirationPeriod and FreeExpirationPeriod must be equal to the expiration periods of the trial and free cleanup jobs,
+	// they are used to calculate the expiration time of the instances which were not extended yet
+	TrialExpirationPeriod time.Duration `envconfig:"default=336h"`
+	FreeExpirationPeriod  time.Duration `envconfig:"default=2160h"`
+}
+
+// Periods returns the expiration periods of the trial and free plans
+func (c ExtensionConfig) Periods() Periods {
+	return Periods{Trial: c.TrialExpirationPeriod, Free: c.FreeExpirationPeriod}
+}
+
+type extensionRequest struct {
+	// Duration is added to the current expiration time of the instance, for example 168h
+	Duration string `json:"duration"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type extensionResponse struct {
+	InstanceID        string    `json:"instanceID"`
+	PreviousExpiresAt time.Time `json:"previousExpiresAt"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+type extensionHandler struct {
+	cfg       ExtensionConfig
+	instances storage.Instances
+	actions   storage.Actions
+	now       func() time.Time
+	log       *slog.Logger
+}
+
+func NewExtensionHandler(cfg ExtensionConfig, instances storage.Instances, actions storage.Actions, log *slog.Logger) Handler {
+	return &extensionHandler{
+		cfg:       cfg,
+		instances: instances,
+		actions:   actions,
+		now:       time.Now,
+		log:       log.With("service", "ExpirationExtensionEndpoint"),
+	}
+}
+
+func (h *extensionHandler) AttachRoutes(r router) {
+	r.HandleFunc("PUT /expire/service_instance/{instance_id}/extension", h.extendExpiration)
+}
+
+func (h *extensionHandler) extendExpiration(w http.ResponseWriter, req *http.Request) {
+	instanceID := req.PathValue("instance_id")
+	logger := h.log.With("instanceID", instanceID)
+
+	var body extensionRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while decoding request body: %w", err))
+		return
+	}
+	duration, err := h.validateDuration(body.Duration)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	instance, err := h.instances.GetByID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		logger.Error(fmt.Sprintf("unable to get instance: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	period, expiring := h.cfg.Periods().ForPlan(instance.ServicePlanID)
+	if !expiring {
+		msg := fmt.Sprintf("unsupported plan: %s", broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(instance.ServicePlanID)))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.New(msg))
+		return
+	}
+	if instance.IsExpired() {
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("instance expired at %s and cannot be extended", instance.ExpiredAt.Format(time.RFC3339)))
+		return
+	}
+
+	// the extension of the instance which is about to be expired by the expirator starts now
+	previous := instance.ExpirationTime(period)
+	extended := previous
+	if now := h.now(); extended.Before(now) {
+		extended = now
+	}
+	extended = extended.Add(duration).UTC()
+	instance.ExpiresAt = ptr.Time(extended)
+
+	_, err = h.instances.Update(*instance)
+	switch {
+	case dberr.IsConflict(err):
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("instance was modified in the meantime: %w", err))
+		return
+	case err != nil:
+		logger.Error(fmt.Sprintf("unable to update the instance expiration time: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	message := fmt.Sprintf("Expiration extended by %s", duration)
+	if body.Reason != "" {
+		message = fmt.Sprintf("%s: %s", message, body.Reason)
+	}
+	if err := h.actions.InsertAction(pkg.ExpirationExtensionActionType, instanceID, message, previous.UTC().Format(time.RFC3339), extended.Format(time.RFC3339)); err != nil {
+		logger.Error(fmt.Sprintf("while inserting action %q for instance ID %s: %v", pkg.ExpirationExtensionActionType, instanceID, err))
+	}
+	logger.Info(fmt.Sprintf("expiration extended from %s to %s", previous.UTC().Format(time.RFC3339), extended.Format(time.RFC3339)))
+
+	httputil.WriteResponse(w, http.StatusOK, extensionResponse{
+		InstanceID:        instanceID,
+		PreviousExpiresAt: previous.UTC(),
+		ExpiresAt:         extended,
+	})
+}
+
+func (h *extensionHandler) validateDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, errors.New("duration must be set")
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("duration must be positive, got %s", duration)
+	}
+	if duration > h.cfg.MaxDuration {
+		return 0, fmt.Errorf("duration %s exceeds the maximum extension %s", duration, h.cfg.MaxDuration)
+	}
+	return duration, nil
+}
@@ -0,0 +1,176 @@
+package expiration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/expiration"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extensionPathFormat = "/expire/service_instance/%s/extension"
+
+func TestExpirationExtension(t *testing.T) {
+	router := httputil.NewRouter()
+	db := storage.NewMemoryStorage()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	handler := expiration.NewExtensionHandler(expiration.ExtensionConfig{
+		Enabled:               true,
+		MaxDuration:           720 * time.Hour,
+		TrialExpirationPeriod: 10 * 24 * time.Hour,
+		FreeExpirationPeriod:  30 * 24 * time.Hour,
+	}, db.Instances(), db.Actions(), logger)
+	handler.AttachRoutes(router)
+
+	t.Run("should extend the expiration of trial instance", func(t *testing.T) {
+		// given
+		instance := fixture.FixInstance("trial-instance")
+		instance.ServicePlanID = broker.TrialPlanID
+		instance.CreatedAt = time.Now().Add(-24 * time.Hour).UTC()
+		require.NoError(t, db.Instances().Insert(instance))
+		previous := instance.CreatedAt.Add(10 * 24 * time.Hour)
+
+		// when
+		resp := extend(router, instance.InstanceID, `{"duration": "168h", "reason": "customer request"}`)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Equal(t, previous.Format(time.RFC3339Nano), body["previousExpiresAt"])
+
+		actual, err := db.Instances().GetByID(instance.InstanceID)
+		require.NoError(t, err)
+		require.NotNil(t, actual.ExpiresAt)
+		assert.True(t, previous.Add(168*time.Hour).Equal(*actual.ExpiresAt))
+
+		actions, err := db.Actions().ListActionsByInstanceID(instance.InstanceID)
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, pkg.ExpirationExtensionActionType, actions[0].Type)
+		assert.Equal(t, "Expiration extended by 168h0m0s: customer request", actions[0].Message)
+		assert.Equal(t, previous.Format(time.RFC3339), actions[0].OldValue)
+		assert.Equal(t, actual.ExpiresAt.Format(time.RFC3339), actions[0].NewValue)
+	})
+
+	t.Run("should extend the previously extended expiration of free instance", func(t *testing.T) {
+		// given
+		instance := fixture.FixInstance("free-instance")
+		instance.ServicePlanID = broker.FreemiumPlanID
+		previous := time.Now().Add(48 * time.Hour).UTC()
+		instance.ExpiresAt = ptr.Time(previous)
+		require.NoError(t, db.Instances().Insert(instance))
+
+		// when
+		resp := extend(router, instance.InstanceID, `{"duration": "24h"}`)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		actual, err := db.Instances().GetByID(instance.InstanceID)
+		require.NoError(t, err)
+		assert.True(t, previous.Add(24*time.Hour).Equal(*actual.ExpiresAt))
+	})
+
+	t.Run("should extend the passed expiration from now", func(t *testing.T) {
+		// given
+		instance := fixture.FixInstance("overdue-instance")
+		instance.ServicePlanID = broker.TrialPlanID
+		instance.CreatedAt = time.Now().Add(-20 * 24 * time.Hour)
+		require.NoError(t, db.Instances().Insert(instance))
+
+		// when
+		resp := extend(router, instance.InstanceID, `{"duration": "24h"}`)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		actual, err := db.Instances().GetByID(instance.InstanceID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), *actual.ExpiresAt, time.Minute)
+	})
+
+	for tn, tc := range map[string]struct {
+		planID       string
+		expired      bool
+		body         string
+		expectedCode int
+	}{
+		"should reject not expiring plan": {
+			planID:       broker.AzurePlanID,
+			body:         `{"duration": "24h"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		"should reject expired instance": {
+			planID:       broker.TrialPlanID,
+			expired:      true,
+			body:         `{"duration": "24h"}`,
+			expectedCode: http.StatusConflict,
+		},
+		"should reject missing duration": {
+			planID:       broker.TrialPlanID,
+			body:         `{"reason": "customer request"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		"should reject negative duration": {
+			planID:       broker.TrialPlanID,
+			body:         `{"duration": "-24h"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		"should reject duration exceeding the maximum": {
+			planID:       broker.TrialPlanID,
+			body:         `{"duration": "1000h"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			instanceID := strings.ReplaceAll(tn, " ", "-")
+			instance := fixture.FixInstance(instanceID)
+			instance.ServicePlanID = tc.planID
+			if tc.expired {
+				instance.ExpiredAt = ptr.Time(time.Now())
+			}
+			require.NoError(t, db.Instances().Insert(instance))
+
+			// when
+			resp := extend(router, instanceID, tc.body)
+
+			// then
+			assert.Equal(t, tc.expectedCode, resp.Code)
+			actual, err := db.Instances().GetByID(instanceID)
+			require.NoError(t, err)
+			assert.Nil(t, actual.ExpiresAt)
+		})
+	}
+
+	t.Run("should receive 404 Not Found response", func(t *testing.T) {
+		// when
+		resp := extend(router, "not-existing", `{"duration": "24h"}`)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func extend(router *httputil.Router, instanceID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf(extensionPathFormat, instanceID), strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
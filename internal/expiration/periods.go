@@ -0,0 +1,27 @@
+package expiration
+
+import (
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+)
+
+// Periods are the periods after which the instances of the trial and free plans expire, counted from the creation of the instance.
+// The expirator, the expiration extension, and the runtimes endpoint use the same periods, configured with the expiration periods
+// of the trial and free cleanup jobs.
+type Periods struct {
+	Trial time.Duration
+	Free  time.Duration
+}
+
+// ForPlan returns the expiration period of the plan, false if the instances of the plan do not expire
+func (p Periods) ForPlan(planID string) (time.Duration, bool) {
+	switch {
+	case broker.IsTrialPlan(planID):
+		return p.Trial, true
+	case broker.IsFreemiumPlan(planID):
+		return p.Free, true
+	default:
+		return 0, false
+	}
+}
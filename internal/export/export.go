@@ -0,0 +1,231 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	watermarkFileName = "_watermark.json"
+	partitionLayout   = "2006-01-02"
+	runIDLayout       = "20060102T150405Z"
+)
+
+type Config struct {
+	OutputDirectory string
+	BatchSize       int
+}
+
+// Watermarks stores the cursor of the last exported row per table
+type Watermarks map[string]Cursor
+
+// Exporter incrementally exports the tables to newline-delimited JSON files partitioned by the date of the watermark column:
+// <output directory>/<table>/date=<YYYY-MM-DD>/part-<run ID>.ndjson
+// The watermarks are stored in the output directory after every batch, so an interrupted export continues from the last stored batch.
+type Exporter struct {
+	reader Reader
+	cfg    Config
+	log    *slog.Logger
+	now    func() time.Time
+}
+
+func NewExporter(reader Reader, cfg Config, log *slog.Logger) *Exporter {
+	return &Exporter{
+		reader: reader,
+		cfg:    cfg,
+		log:    log,
+		now:    time.Now,
+	}
+}
+
+// Export exports the rows added or changed since the last run and returns the number of exported rows per table.
+func (e *Exporter) Export(tables []Table) (map[string]int, error) {
+	if err := os.MkdirAll(e.cfg.OutputDirectory, 0o755); err != nil {
+		return nil, fmt.Errorf("while creating output directory: %w", err)
+	}
+	watermarks, err := e.loadWatermarks()
+	if err != nil {
+		return nil, err
+	}
+	runID := e.now().UTC().Format(runIDLayout)
+
+	exported := make(map[string]int, len(tables))
+	for _, table := range tables {
+		count, err := e.exportTable(table, runID, watermarks)
+		exported[table.Name] = count
+		if err != nil {
+			return exported, fmt.Errorf("while exporting table %s: %w", table.Name, err)
+		}
+		e.log.Info(fmt.Sprintf("Exported %d rows of table %s, watermark: %s", count, table.Name, watermarks[table.Name].Timestamp.Format(time.RFC3339Nano)))
+	}
+	return exported, nil
+}
+
+func (e *Exporter) exportTable(table Table, runID string, watermarks Watermarks) (count int, err error) {
+	writer := newPartitionWriter(filepath.Join(e.cfg.OutputDirectory, table.Name), runID)
+	defer func() {
+		err = errors.Join(err, writer.Close())
+	}()
+
+	cursor := watermarks[table.Name]
+	for {
+		records, err := e.reader.Read(table, cursor, e.cfg.BatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, record := range records {
+			cursor, err = cursorOf(table, record)
+			if err != nil {
+				return count, err
+			}
+			if table.transform != nil {
+				if err := table.transform(record); err != nil {
+					return count, fmt.Errorf("while transforming row %s: %w", cursor.ID, err)
+				}
+			}
+			if err := writer.Write(cursor.Timestamp, record); err != nil {
+				return count, err
+			}
+		}
+		if len(records) == 0 {
+			return count, nil
+		}
+		// the watermark is stored only when the batch is persisted
+		if err := writer.Sync(); err != nil {
+			return count, err
+		}
+		count += len(records)
+		watermarks[table.Name] = cursor
+		if err := e.saveWatermarks(watermarks); err != nil {
+			return count, err
+		}
+		if len(records) < e.cfg.BatchSize {
+			return count, nil
+		}
+	}
+}
+
+func cursorOf(table Table, record Record) (Cursor, error) {
+	timestamp, ok := record[table.WatermarkColumn].(time.Time)
+	if !ok {
+		return Cursor{}, fmt.Errorf("column %s is not a timestamp", table.WatermarkColumn)
+	}
+	id, ok := record[table.IDColumn].(string)
+	if !ok {
+		return Cursor{}, fmt.Errorf("column %s is not a string", table.IDColumn)
+	}
+	return Cursor{Timestamp: timestamp, ID: id}, nil
+}
+
+func (e *Exporter) watermarkPath() string {
+	return filepath.Join(e.cfg.OutputDirectory, watermarkFileName)
+}
+
+func (e *Exporter) loadWatermarks() (Watermarks, error) {
+	watermarks := Watermarks{}
+	content, err := os.ReadFile(e.watermarkPath())
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return watermarks, nil
+	case err != nil:
+		return nil, fmt.Errorf("while reading watermarks: %w", err)
+	}
+	if err := json.Unmarshal(content, &watermarks); err != nil {
+		return nil, fmt.Errorf("while unmarshalling watermarks: %w", err)
+	}
+	return watermarks, nil
+}
+
+// saveWatermarks replaces the watermark file atomically, so it is never left half-written
+func (e *Exporter) saveWatermarks(watermarks Watermarks) error {
+	content, err := json.MarshalIndent(watermarks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("while marshalling watermarks: %w", err)
+	}
+	tmp := e.watermarkPath() + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("while writing watermarks: %w", err)
+	}
+	if err := os.Rename(tmp, e.watermarkPath()); err != nil {
+		return fmt.Errorf("while replacing watermarks: %w", err)
+	}
+	return nil
+}
+
+type partitionFile struct {
+	file   *os.File
+	buffer *bufio.Writer
+}
+
+// partitionWriter writes the records of one table to one file per date partition
+type partitionWriter struct {
+	directory string
+	runID     string
+	files     map[string]*partitionFile
+}
+
+func newPartitionWriter(directory, runID string) *partitionWriter {
+	return &partitionWriter{
+		directory: directory,
+		runID:     runID,
+		files:     make(map[string]*partitionFile),
+	}
+}
+
+func (w *partitionWriter) Write(timestamp time.Time, record Record) error {
+	partition := "date=" + timestamp.UTC().Format(partitionLayout)
+	f, found := w.files[partition]
+	if !found {
+		directory := filepath.Join(w.directory, partition)
+		if err := os.MkdirAll(directory, 0o755); err != nil {
+			return fmt.Errorf("while creating partition directory: %w", err)
+		}
+		file, err := os.OpenFile(filepath.Join(directory, fmt.Sprintf("part-%s.ndjson", w.runID)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("while opening partition file: %w", err)
+		}
+		f = &partitionFile{file: file, buffer: bufio.NewWriter(file)}
+		w.files[partition] = f
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("while marshalling record: %w", err)
+	}
+	if _, err := f.buffer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("while writing record: %w", err)
+	}
+	return nil
+}
+
+func (w *partitionWriter) Sync() error {
+	for partition, f := range w.files {
+		if err := f.buffer.Flush(); err != nil {
+			return fmt.Errorf("while flushing partition %s: %w", partition, err)
+		}
+		if err := f.file.Sync(); err != nil {
+			return fmt.Errorf("while syncing partition %s: %w", partition, err)
+		}
+	}
+	return nil
+}
+
+func (w *partitionWriter) Close() error {
+	var errs []error
+	for partition, f := range w.files {
+		if err := f.buffer.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("while flushing partition %s: %w", partition, err))
+		}
+		if err := f.file.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("while closing partition %s: %w", partition, err))
+		}
+	}
+	w.files = make(map[string]*partitionFile)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,212 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	day1 = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	day2 = time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+)
+
+// fakeReader serves the rows sorted by the watermark and ID columns, as the database does
+type fakeReader struct {
+	rows map[string][]Record
+	err  error
+}
+
+func (f *fakeReader) Read(table Table, after Cursor, limit int) ([]Record, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	rows := f.rows[table.Name]
+	sort.Slice(rows, func(i, j int) bool {
+		ci, _ := cursorOf(table, rows[i])
+		cj, _ := cursorOf(table, rows[j])
+		return ci.Timestamp.Before(cj.Timestamp) || (ci.Timestamp.Equal(cj.Timestamp) && ci.ID < cj.ID)
+	})
+	var result []Record
+	for _, row := range rows {
+		c, _ := cursorOf(table, row)
+		if c.Timestamp.Before(after.Timestamp) || (c.Timestamp.Equal(after.Timestamp) && c.ID <= after.ID) {
+			continue
+		}
+		copied := Record{}
+		for k, v := range row {
+			copied[k] = v
+		}
+		result = append(result, copied)
+		if len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func TestExporter_Export(t *testing.T) {
+	t.Run("should export rows to date partitions and store the watermark", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		reader := &fakeReader{rows: map[string][]Record{
+			"actions": {
+				{"id": "a1", "created_at": day1, "message": "first"},
+				{"id": "a2", "created_at": day1, "message": "second"},
+				{"id": "a3", "created_at": day2, "message": "third"},
+			},
+		}}
+		exporter := fixExporter(reader, dir, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC))
+
+		// when
+		exported, err := exporter.Export(fixTables(t, "actions"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"actions": 3}, exported)
+		assert.Equal(t, []string{"a1", "a2"}, readIDs(t, filepath.Join(dir, "actions", "date=2025-01-01", "part-20250103T000000Z.ndjson")))
+		assert.Equal(t, []string{"a3"}, readIDs(t, filepath.Join(dir, "actions", "date=2025-01-02", "part-20250103T000000Z.ndjson")))
+
+		watermarks, err := exporter.loadWatermarks()
+		require.NoError(t, err)
+		assert.Equal(t, "a3", watermarks["actions"].ID)
+		assert.True(t, day2.Equal(watermarks["actions"].Timestamp))
+	})
+
+	t.Run("should export only rows added since the last run", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		reader := &fakeReader{rows: map[string][]Record{
+			"actions": {{"id": "a1", "created_at": day1}},
+		}}
+		_, err := fixExporter(reader, dir, day1).Export(fixTables(t, "actions"))
+		require.NoError(t, err)
+		reader.rows["actions"] = append(reader.rows["actions"], Record{"id": "a2", "created_at": day2})
+
+		// when
+		exported, err := fixExporter(reader, dir, day2).Export(fixTables(t, "actions"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"actions": 1}, exported)
+		assert.Equal(t, []string{"a2"}, readIDs(t, filepath.Join(dir, "actions", "date=2025-01-02", "part-20250102T100000Z.ndjson")))
+	})
+
+	t.Run("should sanitize operations", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		reader := &fakeReader{rows: map[string][]Record{
+			"operations": {{
+				"id":                      "op-1",
+				"updated_at":              day1,
+				"provisioning_parameters": `{"plan_id":"p1","ers_context":{"globalaccount_id":"ga","sm_operator_credentials":{"clientid":"secret"}},"parameters":{"name":"c","kubeconfig":"secret","targetSecret":"secret"}}`,
+				"data":                    `{"rawParameters":{"name":"c","kubeconfig":"secret"},"last_error":{"message":"failed"},"runtime_id":"r1"}`,
+			}},
+		}}
+
+		// when
+		_, err := fixExporter(reader, dir, day1).Export(fixTables(t, "operations"))
+
+		// then
+		require.NoError(t, err)
+		records := readRecords(t, filepath.Join(dir, "operations", "date=2025-01-01", "part-20250101T100000Z.ndjson"))
+		require.Len(t, records, 1)
+		assert.NotContains(t, records[0], "data")
+		assert.JSONEq(t, `{"plan_id":"p1","ers_context":{"globalaccount_id":"ga"},"parameters":{"name":"c"}}`, string(records[0]["provisioning_parameters"]))
+		assert.JSONEq(t, `{"name":"c"}`, string(records[0]["raw_parameters"]))
+		assert.JSONEq(t, `{"message":"failed"}`, string(records[0]["last_error"]))
+	})
+
+	t.Run("should not move the watermark when reading fails", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		exporter := fixExporter(&fakeReader{err: errors.New("connection refused")}, dir, day1)
+
+		// when
+		_, err := exporter.Export(fixTables(t, "events"))
+
+		// then
+		assert.ErrorContains(t, err, "connection refused")
+		watermarks, err := exporter.loadWatermarks()
+		require.NoError(t, err)
+		assert.Empty(t, watermarks)
+	})
+}
+
+func TestSelectTables(t *testing.T) {
+	t.Run("should return all tables by default", func(t *testing.T) {
+		// when
+		tables, err := SelectTables(nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, tables, 5)
+	})
+
+	t.Run("should reject unknown table", func(t *testing.T) {
+		// when
+		_, err := SelectTables([]string{"bindings"})
+
+		// then
+		assert.Error(t, err)
+	})
+}
+
+func TestSelectQuery(t *testing.T) {
+	// given
+	table, found := tableByName("events")
+	require.True(t, found)
+
+	// when
+	query := selectQuery(table)
+
+	// then
+	assert.Equal(t, "SELECT id, level, instance_id, operation_id, message, created_at FROM events WHERE (created_at, id) > (?, ?) ORDER BY created_at, id LIMIT ?", query)
+}
+
+func fixExporter(reader Reader, dir string, now time.Time) *Exporter {
+	exporter := NewExporter(reader, Config{OutputDirectory: dir, BatchSize: 2}, slog.Default())
+	exporter.now = func() time.Time { return now }
+	return exporter
+}
+
+func fixTables(t *testing.T, names ...string) []Table {
+	tables, err := SelectTables(names)
+	require.NoError(t, err)
+	return tables
+}
+
+func readRecords(t *testing.T, path string) []map[string]json.RawMessage {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	var records []map[string]json.RawMessage
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func readIDs(t *testing.T, path string) []string {
+	var ids []string
+	for _, record := range readRecords(t, path) {
+		var id string
+		require.NoError(t, json.Unmarshal(record["id"], &id))
+		ids = append(ids, id)
+	}
+	return ids
+}
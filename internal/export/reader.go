@@ -0,0 +1,74 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocraft/dbr"
+)
+
+// Cursor points to the last exported row of the table
+type Cursor struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        string    `json:"id"`
+}
+
+// Reader returns the rows of the table which follow the cursor, in the order of the watermark and ID columns.
+type Reader interface {
+	Read(table Table, after Cursor, limit int) ([]Record, error)
+}
+
+// DBReader reads the rows directly from the KEB database.
+type DBReader struct {
+	session *dbr.Session
+}
+
+func NewDBReader(session *dbr.Session) *DBReader {
+	return &DBReader{session: session}
+}
+
+func (r *DBReader) Read(table Table, after Cursor, limit int) ([]Record, error) {
+	rows, err := r.session.SelectBySql(selectQuery(table), after.Timestamp, after.ID, limit).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("while reading table %s: %w", table.Name, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("while reading columns of table %s: %w", table.Name, err)
+	}
+	var records []Record
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("while scanning row of table %s: %w", table.Name, err)
+		}
+		record := make(Record, len(columns))
+		for i, column := range columns {
+			// text and enum columns are returned as bytes
+			if b, ok := values[i].([]byte); ok {
+				record[column] = string(b)
+				continue
+			}
+			record[column] = values[i]
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("while reading rows of table %s: %w", table.Name, err)
+	}
+	return records, nil
+}
+
+func selectQuery(table Table) string {
+	return fmt.Sprintf("SELECT %s FROM %s WHERE (%s, %s) > (?, ?) ORDER BY %s, %s LIMIT ?",
+		strings.Join(table.Columns, ", "), table.Name,
+		table.WatermarkColumn, table.IDColumn,
+		table.WatermarkColumn, table.IDColumn)
+}
@@ -0,0 +1,162 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Record is a single exported row, keyed by the column name
+type Record map[string]any
+
+// Table describes which columns of the table are exported and how the export progress is tracked.
+// The rows are read in the order of the watermark column and the ID column, so the last exported row is the watermark of the next run.
+type Table struct {
+	Name            string
+	Columns         []string
+	WatermarkColumn string
+	IDColumn        string
+	// transform removes or converts the columns which must not be exported as they are read
+	transform func(Record) error
+}
+
+var Tables = []Table{
+	{
+		Name: "instances",
+		Columns: []string{"instance_id", "runtime_id", "global_account_id", "subscription_global_account_id", "sub_account_id",
+			"service_plan_id", "service_plan_name", "provider", "provider_region", "provisioning_parameters",
+			"created_at", "updated_at", "deleted_at", "expired_at"},
+		WatermarkColumn: "updated_at",
+		IDColumn:        "instance_id",
+		transform:       transformInstance,
+	},
+	{
+		Name: "instances_archived",
+		Columns: []string{"instance_id", "global_account_id", "subaccount_id", "subscription_global_account_id", "plan_id", "plan_name",
+			"subaccount_region", "region", "provider", "last_runtime_id", "internal_user", "shoot_name",
+			"provisioning_started_at", "provisioning_finished_at", "provisioning_state",
+			"first_deprovisioning_started_at", "first_deprovisioning_finished_at", "last_deprovisioning_finished_at"},
+		WatermarkColumn: "last_deprovisioning_finished_at",
+		IDColumn:        "instance_id",
+	},
+	{
+		Name: "operations",
+		Columns: []string{"id", "instance_id", "target_operation_id", "type", "state", "description", "finished_stages",
+			"provisioning_parameters", "data", "created_at", "updated_at"},
+		WatermarkColumn: "updated_at",
+		IDColumn:        "id",
+		transform:       transformOperation,
+	},
+	{
+		Name:            "events",
+		Columns:         []string{"id", "level", "instance_id", "operation_id", "message", "created_at"},
+		WatermarkColumn: "created_at",
+		IDColumn:        "id",
+	},
+	{
+		Name:            "actions",
+		Columns:         []string{"id", "type", "instance_id", "message", "old_value", "new_value", "created_at"},
+		WatermarkColumn: "created_at",
+		IDColumn:        "id",
+	},
+}
+
+// SelectTables returns the tables with the given names or all tables if no names are given
+func SelectTables(names []string) ([]Table, error) {
+	if len(names) == 0 {
+		return Tables, nil
+	}
+	var selected []Table
+	for _, name := range names {
+		table, found := tableByName(strings.TrimSpace(name))
+		if !found {
+			return nil, fmt.Errorf("unknown table %s", name)
+		}
+		selected = append(selected, table)
+	}
+	return selected, nil
+}
+
+func tableByName(name string) (Table, bool) {
+	for _, table := range Tables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+	return Table{}, false
+}
+
+func transformInstance(record Record) error {
+	parameters, err := sanitizeProvisioningParameters(record["provisioning_parameters"])
+	if err != nil {
+		return fmt.Errorf("while sanitizing provisioning parameters: %w", err)
+	}
+	record["provisioning_parameters"] = parameters
+	return nil
+}
+
+func transformOperation(record Record) error {
+	if err := transformInstance(record); err != nil {
+		return err
+	}
+
+	// the operation data contains internal details, only the raw parameters and the last error are exported
+	data, _ := record["data"].(string)
+	delete(record, "data")
+	if data == "" {
+		return nil
+	}
+	var details struct {
+		RawParameters json.RawMessage `json:"rawParameters"`
+		LastError     json.RawMessage `json:"last_error"`
+	}
+	if err := json.Unmarshal([]byte(data), &details); err != nil {
+		return fmt.Errorf("while unmarshalling operation data: %w", err)
+	}
+	if len(details.RawParameters) > 0 {
+		record["raw_parameters"] = sanitizeParameters(details.RawParameters)
+	}
+	if len(details.LastError) > 0 {
+		record["last_error"] = details.LastError
+	}
+	return nil
+}
+
+// sanitizeProvisioningParameters removes the credentials from the provisioning parameters stored in the database
+func sanitizeProvisioningParameters(value any) (json.RawMessage, error) {
+	raw, _ := value.(string)
+	if raw == "" {
+		return nil, nil
+	}
+	var parameters map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &parameters); err != nil {
+		return nil, err
+	}
+	if ersContext, found := parameters["ers_context"]; found {
+		parameters["ers_context"] = removeFields(ersContext, "sm_operator_credentials")
+	}
+	if params, found := parameters["parameters"]; found {
+		parameters["parameters"] = sanitizeParameters(params)
+	}
+	return json.Marshal(parameters)
+}
+
+// sanitizeParameters strips the same fields as the runtimes endpoint does from the raw parameters
+func sanitizeParameters(raw json.RawMessage) json.RawMessage {
+	return removeFields(raw, "targetSecret", "kubeconfig")
+}
+
+func removeFields(raw json.RawMessage, fields ...string) json.RawMessage {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return raw
+	}
+	for _, field := range fields {
+		delete(m, field)
+	}
+	out, err := json.Marshal(m)
+	if err != nil {
+		return raw
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/discovery"
+)
+
+// Checker checks if a dependency of the application is ready
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// NewChecker creates the checker with the given check function
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return &checker{name: name, check: check}
+}
+
+func (c *checker) Name() string {
+	return c.name
+}
+
+func (c *checker) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// Pinger is implemented by the database connection
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// NewDatabaseChecker checks the connection to the database
+func NewDatabaseChecker(name string, pinger Pinger) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		if err := pinger.PingContext(ctx); err != nil {
+			return fmt.Errorf("while pinging the database: %w", err)
+		}
+		return nil
+	})
+}
+
+// NewDiscoveryChecker checks the access to the Kubernetes API server, for example KCP or Gardener
+func NewDiscoveryChecker(name string, client discovery.ServerVersionInterface) Checker {
+	return NewChecker(name, func(_ context.Context) error {
+		if _, err := client.ServerVersion(); err != nil {
+			return fmt.Errorf("while getting the server version: %w", err)
+		}
+		return nil
+	})
+}
+
+type cachedChecker struct {
+	Checker
+	ttl time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// NewCachedChecker runs the check at most once per ttl and returns the last result in between,
+// so the probes do not call the external APIs, for example KCP or Gardener, on every request
+func NewCachedChecker(checker Checker, ttl time.Duration) Checker {
+	return &cachedChecker{Checker: checker, ttl: ttl}
+}
+
+func (c *cachedChecker) Check(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.ttl {
+		return c.err
+	}
+	c.err = c.Checker.Check(ctx)
+	c.checkedAt = time.Now()
+	return c.err
+}
+
+// Gate is not ready until it is opened, for example when the configuration is loaded and validated
+type Gate struct {
+	name   string
+	reason string
+	open   atomic.Bool
+}
+
+func NewGate(name, reason string) *Gate {
+	return &Gate{name: name, reason: reason}
+}
+
+func (g *Gate) Open() {
+	g.open.Store(true)
+}
+
+func (g *Gate) Name() string {
+	return g.name
+}
+
+func (g *Gate) Check(_ context.Context) error {
+	if !g.open.Load() {
+		return errors.New(g.reason)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	StatusReady    = "ready"
+	StatusNotReady = "not ready"
+	// StatusDegraded means that an external dependency failed, but the application is still ready
+	StatusDegraded = "degraded"
+)
+
+type Config struct {
+	// Timeout of every readiness check, must be shorter than the timeout of the readiness probe
+	Timeout time.Duration `envconfig:"default=2s"`
+	// DependencyCacheTTL is the period for which the result of the external dependency checks is cached
+	DependencyCacheTTL time.Duration `envconfig:"default=30s"`
+}
+
+// Report is the response of the /readyz endpoint
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type CheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Readiness runs the checkers and reports the application ready only if all checks pass.
+// The external dependencies do not fail the readiness, they are reported as degraded.
+type Readiness struct {
+	timeout            time.Duration
+	dependencyCacheTTL time.Duration
+	log                *slog.Logger
+
+	mu           sync.RWMutex
+	checkers     []Checker
+	dependencies []Checker
+
+	status   *prometheus.GaugeVec
+	duration *prometheus.GaugeVec
+}
+
+func NewReadiness(cfg Config, reg prometheus.Registerer, namespace string, log *slog.Logger) *Readiness {
+	r := &Readiness{
+		timeout:            cfg.Timeout,
+		dependencyCacheTTL: cfg.DependencyCacheTTL,
+		log:                log.With("component", "readiness"),
+		status: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "readiness_check_status",
+			Help:      "Result of the last readiness check, 1 if the check passed, 0 otherwise. The gating label is false for the external dependencies, which do not fail the readiness.",
+		}, []string{"check", "gating"}),
+		duration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "readiness_check_duration_seconds",
+			Help:      "Duration of the last readiness check.",
+		}, []string{"check"}),
+	}
+	reg.MustRegister(r.status, r.duration)
+	return r
+}
+
+// Add registers the checkers, which can be added when the dependencies are created
+func (r *Readiness) Add(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checkers...)
+}
+
+// AddDependency registers the checkers of the external dependencies, for example KCP or Gardener. Their results are cached
+// for the configured period, and a failed check reports the application as degraded, but does not fail the readiness.
+func (r *Readiness) AddDependency(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, checker := range checkers {
+		r.dependencies = append(r.dependencies, NewCachedChecker(checker, r.dependencyCacheTTL))
+	}
+}
+
+// Check runs all checkers in parallel, every check is limited by the configured timeout
+func (r *Readiness) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := make([]Checker, 0, len(r.checkers)+len(r.dependencies))
+	checkers = append(checkers, r.checkers...)
+	checkers = append(checkers, r.dependencies...)
+	gating := len(r.checkers)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, checker, i < gating)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: results}
+	for _, result := range results {
+		switch {
+		case result.Status == StatusNotReady:
+			report.Status = StatusNotReady
+		case result.Status == StatusDegraded && report.Status == StatusReady:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func (r *Readiness) run(ctx context.Context, checker Checker, gating bool) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// some clients, for example the discovery client, do not accept the context
+		err = fmt.Errorf("check timed out after %s", r.timeout)
+	}
+	elapsed := time.Since(start)
+
+	result := CheckResult{Name: checker.Name(), Status: StatusReady, Duration: elapsed.String()}
+	gatingLabel := fmt.Sprintf("%t", gating)
+	r.duration.WithLabelValues(checker.Name()).Set(elapsed.Seconds())
+	if err != nil {
+		result.Status = StatusNotReady
+		if !gating {
+			result.Status = StatusDegraded
+		}
+		result.Error = err.Error()
+		r.status.WithLabelValues(checker.Name(), gatingLabel).Set(0)
+		r.log.Warn(fmt.Sprintf("readiness check %s failed: %s", checker.Name(), err))
+		return result
+	}
+	r.status.WithLabelValues(checker.Name(), gatingLabel).Set(1)
+	return result
+}
+
+// ServeHTTP responds with 503 if any gating check fails and 200 otherwise, also when the application is degraded.
+// The response contains the result of every check.
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	report := r.Check(req.Context())
+	status := http.StatusOK
+	if report.Status == StatusNotReady {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		r.log.Error(fmt.Sprintf("while encoding readiness report: %s", err))
+	}
+}
@@ -0,0 +1,173 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	t.Run("should be ready when all checks pass", func(t *testing.T) {
+		// given
+		readiness := NewReadiness(Config{Timeout: time.Second}, prometheus.NewRegistry(), "test", fixLogger())
+		gate := NewGate("configuration", "configuration not loaded")
+		gate.Open()
+		readiness.Add(gate, NewChecker("database", func(_ context.Context) error { return nil }))
+
+		// when
+		rr := httptest.NewRecorder()
+		readiness.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var report Report
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.Equal(t, StatusReady, report.Status)
+		require.Len(t, report.Checks, 2)
+		assert.Equal(t, "configuration", report.Checks[0].Name)
+		assert.Equal(t, StatusReady, report.Checks[0].Status)
+		assert.Equal(t, "database", report.Checks[1].Name)
+		assert.Equal(t, StatusReady, report.Checks[1].Status)
+	})
+
+	t.Run("should not be ready when any check fails", func(t *testing.T) {
+		// given
+		reg := prometheus.NewRegistry()
+		readiness := NewReadiness(Config{Timeout: time.Second}, reg, "test", fixLogger())
+		readiness.Add(
+			NewGate("configuration", "configuration not loaded"),
+			NewDatabaseChecker("database", fixPinger{err: errors.New("connection refused")}),
+			NewChecker("kcp", func(_ context.Context) error { return nil }),
+		)
+
+		// when
+		rr := httptest.NewRecorder()
+		readiness.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// then
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		var report Report
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.Equal(t, StatusNotReady, report.Status)
+		assert.Equal(t, "configuration not loaded", report.Checks[0].Error)
+		assert.Equal(t, "while pinging the database: connection refused", report.Checks[1].Error)
+		assert.Equal(t, StatusReady, report.Checks[2].Status)
+		assert.Equal(t, 0.0, testutil.ToFloat64(readiness.status.WithLabelValues("database", "true")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(readiness.status.WithLabelValues("kcp", "true")))
+	})
+
+	t.Run("should fail the check which exceeds the timeout", func(t *testing.T) {
+		// given
+		readiness := NewReadiness(Config{Timeout: 10 * time.Millisecond}, prometheus.NewRegistry(), "test", fixLogger())
+		release := make(chan struct{})
+		defer close(release)
+		readiness.Add(NewChecker("gardener", func(_ context.Context) error {
+			<-release
+			return nil
+		}))
+
+		// when
+		report := readiness.Check(context.Background())
+
+		// then
+		assert.Equal(t, StatusNotReady, report.Status)
+		assert.Equal(t, "check timed out after 10ms", report.Checks[0].Error)
+	})
+
+	t.Run("should be ready but degraded when an external dependency fails", func(t *testing.T) {
+		// given
+		readiness := NewReadiness(Config{Timeout: time.Second, DependencyCacheTTL: time.Minute}, prometheus.NewRegistry(), "test", fixLogger())
+		readiness.Add(NewDatabaseChecker("database", fixPinger{}))
+		readiness.AddDependency(NewChecker("gardener", func(_ context.Context) error { return errors.New("connection refused") }))
+
+		// when
+		rr := httptest.NewRecorder()
+		readiness.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var report Report
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.Equal(t, StatusDegraded, report.Status)
+		assert.Equal(t, StatusReady, report.Checks[0].Status)
+		assert.Equal(t, "gardener", report.Checks[1].Name)
+		assert.Equal(t, StatusDegraded, report.Checks[1].Status)
+		assert.Equal(t, "connection refused", report.Checks[1].Error)
+		assert.Equal(t, 1.0, testutil.ToFloat64(readiness.status.WithLabelValues("database", "true")))
+		assert.Equal(t, 0.0, testutil.ToFloat64(readiness.status.WithLabelValues("gardener", "false")))
+	})
+
+	t.Run("should not be ready when a gating check fails and a dependency is degraded", func(t *testing.T) {
+		// given
+		readiness := NewReadiness(Config{Timeout: time.Second, DependencyCacheTTL: time.Minute}, prometheus.NewRegistry(), "test", fixLogger())
+		readiness.AddDependency(NewChecker("kcp", func(_ context.Context) error { return errors.New("connection refused") }))
+		readiness.Add(NewGate("configuration", "configuration not loaded"))
+
+		// when
+		report := readiness.Check(context.Background())
+
+		// then
+		assert.Equal(t, StatusNotReady, report.Status)
+	})
+}
+
+func TestCachedChecker(t *testing.T) {
+	t.Run("should return the cached result within the TTL", func(t *testing.T) {
+		// given
+		calls := 0
+		checker := NewCachedChecker(NewChecker("kcp", func(_ context.Context) error {
+			calls++
+			return errors.New("connection refused")
+		}), time.Minute)
+
+		// when
+		err1 := checker.Check(context.Background())
+		err2 := checker.Check(context.Background())
+
+		// then
+		assert.EqualError(t, err1, "connection refused")
+		assert.EqualError(t, err2, "connection refused")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "kcp", checker.Name())
+	})
+
+	t.Run("should run the check again after the TTL", func(t *testing.T) {
+		// given
+		calls := 0
+		checker := NewCachedChecker(NewChecker("kcp", func(_ context.Context) error {
+			calls++
+			return nil
+		}), time.Millisecond)
+
+		// when
+		require.NoError(t, checker.Check(context.Background()))
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, checker.Check(context.Background()))
+
+		// then
+		assert.Equal(t, 2, calls)
+	})
+}
+
+type fixPinger struct {
+	err error
+}
+
+func (p fixPinger) PingContext(_ context.Context) error {
+	return p.err
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+}
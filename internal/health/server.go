@@ -9,8 +9,9 @@ import (
 )
 
 type Server struct {
-	Address string
-	Log     *slog.Logger
+	Address   string
+	Log       *slog.Logger
+	readiness *Readiness
 }
 
 func NewServer(host, port string, log *slog.Logger) *Server {
@@ -20,9 +21,18 @@ func NewServer(host, port string, log *slog.Logger) *Server {
 	}
 }
 
+// WithReadiness serves the /readyz endpoint, which reports the result of the readiness checks
+func (srv *Server) WithReadiness(readiness *Readiness) *Server {
+	srv.readiness = readiness
+	return srv
+}
+
 func (srv *Server) ServeAsync() {
 	healthRouter := httputil.NewRouter()
-	healthRouter.HandleFunc("/healthz", livenessHandler())
+	healthRouter.HandleFunc("/healthz", LivenessHandler())
+	if srv.readiness != nil {
+		healthRouter.Handle("/readyz", srv.readiness)
+	}
 	go func() {
 		err := http.ListenAndServe(srv.Address, healthRouter)
 		if err != nil {
@@ -31,7 +41,8 @@ func (srv *Server) ServeAsync() {
 	}()
 }
 
-func livenessHandler() func(w http.ResponseWriter, _ *http.Request) {
+// LivenessHandler always responds with 200, because the process is alive when it can respond
+func LivenessHandler() func(w http.ResponseWriter, _ *http.Request) {
 	return func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}
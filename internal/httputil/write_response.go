@@ -1,10 +1,12 @@
 package httputil
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 )
 
 func WriteResponse(w http.ResponseWriter, code int, object interface{}) {
@@ -29,3 +31,37 @@ type errObj struct {
 func WriteErrorResponse(w http.ResponseWriter, code int, err error) {
 	WriteResponse(w, code, errObj{Error: err.Error()})
 }
+
+// WriteResponseWithETag writes the response with the ETag header calculated from the response body.
+// If the request contains the If-None-Match header matching the ETag, only the 304 status code is written.
+func WriteResponseWithETag(w http.ResponseWriter, req *http.Request, code int, object interface{}) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(data))
+	w.Header().Set("ETag", etag)
+	if etagMatches(req.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, err = w.Write(data)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("could not write response %s", string(data)))
+	}
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package httputil_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteResponseWithETag(t *testing.T) {
+	t.Run("should write response with ETag", func(t *testing.T) {
+		// given
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/runtimes", nil)
+
+		// when
+		httputil.WriteResponseWithETag(rw, req, http.StatusOK, fixData())
+
+		// then
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.NotEmpty(t, rw.Header().Get("ETag"))
+		assert.JSONEq(t, `{"field_int": 1, "field_string": "andrzej"}`, rw.Body.String())
+	})
+
+	t.Run("should return not modified when ETag matches", func(t *testing.T) {
+		// given
+		first := httptest.NewRecorder()
+		httputil.WriteResponseWithETag(first, httptest.NewRequest(http.MethodGet, "/runtimes", nil), http.StatusOK, fixData())
+		etag := first.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/runtimes", nil)
+		req.Header.Set("If-None-Match", `"other", `+etag)
+
+		// when
+		httputil.WriteResponseWithETag(rw, req, http.StatusOK, fixData())
+
+		// then
+		assert.Equal(t, http.StatusNotModified, rw.Code)
+		assert.Equal(t, etag, rw.Header().Get("ETag"))
+		assert.Empty(t, rw.Body.String())
+	})
+
+	t.Run("should write response when ETag does not match", func(t *testing.T) {
+		// given
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/runtimes", nil)
+		req.Header.Set("If-None-Match", `"other"`)
+
+		// when
+		httputil.WriteResponseWithETag(rw, req, http.StatusOK, fixData())
+
+		// then
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.NotEmpty(t, rw.Body.String())
+	})
+}
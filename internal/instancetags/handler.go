@@ -0,0 +1,78 @@
+package instancetags
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler interface {
+	AttachRoutes(r router)
+}
+
+type handler struct {
+	service *Service
+	log     *slog.Logger
+}
+
+func NewHandler(service *Service, log *slog.Logger) Handler {
+	return &handler{
+		service: service,
+		log:     log.With("service", "InstanceTagsEndpoint"),
+	}
+}
+
+func (h *handler) AttachRoutes(r router) {
+	r.HandleFunc("GET /instances/{instance_id}/tags", h.getTags)
+	r.HandleFunc("PATCH /instances/{instance_id}/tags", h.patchTags)
+}
+
+func (h *handler) getTags(w http.ResponseWriter, req *http.Request) {
+	instanceID := req.PathValue("instance_id")
+
+	tags, err := h.service.Get(instanceID)
+	switch {
+	case err == nil:
+		httputil.WriteResponse(w, http.StatusOK, tags)
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	default:
+		h.log.Error(fmt.Sprintf("unable to get tags of instance %s: %s", instanceID, err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (h *handler) patchTags(w http.ResponseWriter, req *http.Request) {
+	instanceID := req.PathValue("instance_id")
+	logger := h.log.With("instanceID", instanceID)
+
+	var patch map[string]*string
+	if err := json.NewDecoder(req.Body).Decode(&patch); err != nil {
+		logger.Warn(fmt.Sprintf("unable to decode request body: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("%w: %s", ErrInvalidTags, err))
+		return
+	}
+
+	tags, err := h.service.Patch(instanceID, patch)
+	switch {
+	case err == nil:
+		logger.Info(fmt.Sprintf("Tags updated: %s", describePatch(patch)))
+		httputil.WriteResponse(w, http.StatusOK, tags)
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidTags):
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		logger.Error(fmt.Sprintf("unable to update tags: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
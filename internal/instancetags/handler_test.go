@@ -0,0 +1,156 @@
+package instancetags_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/instancetags"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const requestPathFormat = "/instances/%s/tags"
+
+func TestInstanceTags(t *testing.T) {
+	newHandler := func(db storage.BrokerStorage) http.Handler {
+		router := httputil.NewRouter()
+		instancetags.NewHandler(instancetags.NewService(db, slog.Default()), slog.Default()).AttachRoutes(router)
+		return router
+	}
+
+	t.Run("should set and remove tags", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-1")))
+		require.NoError(t, db.InstanceTags().Patch("inst-1", map[string]*string{"incident": ptr.String("1234"), "team": ptr.String("a")}))
+		router := newHandler(db)
+
+		// when
+		resp := call(router, http.MethodPatch, "inst-1", `{"customer-tier":"gold","incident":null}`)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, map[string]string{"customer-tier": "gold", "team": "a"}, decodeTags(t, resp))
+
+		tags, err := db.InstanceTags().GetByInstanceID("inst-1")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"customer-tier": "gold", "team": "a"}, tags)
+
+		actions, err := db.Actions().ListActionsByInstanceID("inst-1")
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, pkg.InstanceTagsUpdateActionType, actions[0].Type)
+		assert.Equal(t, `Tags changed: ["customer-tier=gold","-incident"]`, actions[0].Message)
+		assert.Equal(t, "incident=1234", actions[0].OldValue)
+		assert.Equal(t, "customer-tier=gold", actions[0].NewValue)
+	})
+
+	t.Run("should not record action when tags do not change", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-1")))
+		router := newHandler(db)
+
+		// when
+		resp := call(router, http.MethodPatch, "inst-1", `{"incident":null}`)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, decodeTags(t, resp))
+		actions, err := db.Actions().ListActionsByInstanceID("inst-1")
+		require.NoError(t, err)
+		assert.Empty(t, actions)
+	})
+
+	t.Run("should get tags", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-1")))
+		require.NoError(t, db.InstanceTags().Patch("inst-1", map[string]*string{"customer-tier": ptr.String("gold")}))
+		router := newHandler(db)
+
+		// when
+		resp := call(router, http.MethodGet, "inst-1", "")
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, map[string]string{"customer-tier": "gold"}, decodeTags(t, resp))
+	})
+
+	for name, body := range map[string]string{
+		"body is not a JSON object": `["gold"]`,
+		"no tags are given":         `{}`,
+		"key is invalid":            `{"customer tier":"gold"}`,
+		"value is invalid":          `{"customer-tier":"gold/silver"}`,
+		"value is not a string":     `{"incident":1234}`,
+	} {
+		t.Run(fmt.Sprintf("should receive 400 Bad Request response when %s", name), func(t *testing.T) {
+			// given
+			db := storage.NewMemoryStorage()
+			require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-1")))
+			router := newHandler(db)
+
+			// when
+			resp := call(router, http.MethodPatch, "inst-1", body)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+		})
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPatch} {
+		t.Run(fmt.Sprintf("should receive 404 Not Found response for %s", method), func(t *testing.T) {
+			// given
+			router := newHandler(storage.NewMemoryStorage())
+
+			// when
+			resp := call(router, method, "not-existing", `{"customer-tier":"gold"}`)
+
+			// then
+			assert.Equal(t, http.StatusNotFound, resp.Code)
+		})
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	t.Run("should parse selector", func(t *testing.T) {
+		// when
+		selector, err := instancetags.ParseSelector("customer-tier in (gold,silver),!incident")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, selector.Matches(labels.Set{"customer-tier": "gold"}))
+		assert.False(t, selector.Matches(labels.Set{"customer-tier": "gold", "incident": "1234"}))
+	})
+
+	t.Run("should reject invalid selector", func(t *testing.T) {
+		// when
+		_, err := instancetags.ParseSelector("customer-tier in (gold")
+
+		// then
+		assert.Error(t, err)
+	})
+}
+
+func call(router http.Handler, method, instanceID, body string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(method, fmt.Sprintf(requestPathFormat, instanceID), strings.NewReader(body)))
+	return resp
+}
+
+func decodeTags(t *testing.T, resp *httptest.ResponseRecorder) map[string]string {
+	var tags map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tags))
+	return tags
+}
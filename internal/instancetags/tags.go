@@ -0,0 +1,146 @@
+package instancetags
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"strings"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	ErrInvalidTags = errors.New("invalid tags")
+)
+
+// the old and new values of actions are limited by the column size
+const maxActionValueLength = 255
+
+type Config struct {
+	Enabled bool `envconfig:"default=false"`
+}
+
+// Service manages the operator-owned tags of instances. The tags follow the syntax of Kubernetes labels,
+// so the instances can be selected by the tags with label selectors.
+type Service struct {
+	instances storage.Instances
+	tags      storage.InstanceTags
+	actions   storage.Actions
+	log       *slog.Logger
+}
+
+func NewService(db storage.BrokerStorage, log *slog.Logger) *Service {
+	return &Service{
+		instances: db.Instances(),
+		tags:      db.InstanceTags(),
+		actions:   db.Actions(),
+		log:       log,
+	}
+}
+
+func (s *Service) Get(instanceID string) (map[string]string, error) {
+	if _, err := s.instances.GetByID(instanceID); err != nil {
+		return nil, err
+	}
+	return s.tags.GetByInstanceID(instanceID)
+}
+
+// Patch applies the JSON merge patch to the tags of the instance: the tags with null values are removed,
+// the other tags are set. It returns the tags of the instance after the change.
+func (s *Service) Patch(instanceID string, patch map[string]*string) (map[string]string, error) {
+	if err := ValidatePatch(patch); err != nil {
+		return nil, err
+	}
+	if _, err := s.instances.GetByID(instanceID); err != nil {
+		return nil, err
+	}
+	oldTags, err := s.tags.GetByInstanceID(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("while getting tags: %w", err)
+	}
+	if err := s.tags.Patch(instanceID, patch); err != nil {
+		return nil, fmt.Errorf("while updating tags: %w", err)
+	}
+	newTags, err := s.tags.GetByInstanceID(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("while getting tags: %w", err)
+	}
+
+	if !maps.Equal(oldTags, newTags) {
+		message := fmt.Sprintf("Tags changed: %s", describePatch(patch))
+		oldValue, newValue := patchedTags(oldTags, patch), patchedTags(newTags, patch)
+		if err := s.actions.InsertAction(pkg.InstanceTagsUpdateActionType, instanceID, message, oldValue, newValue); err != nil {
+			s.log.Error(fmt.Sprintf("while inserting action %q for instance ID %s: %v", pkg.InstanceTagsUpdateActionType, instanceID, err))
+		}
+	}
+	return newTags, nil
+}
+
+// ValidatePatch checks that the keys are valid label keys and the values are valid label values
+func ValidatePatch(patch map[string]*string) error {
+	if len(patch) == 0 {
+		return fmt.Errorf("%w: no tags given", ErrInvalidTags)
+	}
+	for key, value := range patch {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("%w: key %q: %s", ErrInvalidTags, key, strings.Join(errs, "; "))
+		}
+		if value == nil {
+			continue
+		}
+		if errs := validation.IsValidLabelValue(*value); len(errs) > 0 {
+			return fmt.Errorf("%w: value %q of key %q: %s", ErrInvalidTags, *value, key, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// ParseSelector parses the tag selector in the label selector syntax, e.g. "customer-tier=gold,!incident"
+func ParseSelector(selector string) (labels.Selector, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag selector %q: %w", selector, err)
+	}
+	return parsed, nil
+}
+
+// patchedTags returns the patched tags in the label selector syntax
+func patchedTags(tags map[string]string, patch map[string]*string) string {
+	patched := labels.Set{}
+	for key := range patch {
+		if value, found := tags[key]; found {
+			patched[key] = value
+		}
+	}
+	value := patched.String()
+	if len(value) > maxActionValueLength {
+		return value[:maxActionValueLength]
+	}
+	return value
+}
+
+func describePatch(patch map[string]*string) string {
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changes := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if patch[key] == nil {
+			changes = append(changes, fmt.Sprintf("-%s", key))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s=%s", key, *patch[key]))
+	}
+	content, _ := json.Marshal(changes)
+	return string(content)
+}
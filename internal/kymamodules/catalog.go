@@ -0,0 +1,267 @@
+package kymamodules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// exposed metrics:
+// - kcp_keb_v2_modules_validation_skipped_total
+
+const (
+	CustomResourcePolicyCreateAndDelete = "CreateAndDelete"
+	CustomResourcePolicyIgnore          = "Ignore"
+
+	prometheusNamespace = "kcp"
+	prometheusSubsystem = "keb_v2"
+
+	// loadTimeout limits the load of the modules, which is not cancelled by the caller
+	loadTimeout = 30 * time.Second
+	// failedLoadBackoff is the time after a failed load in which the modules are not loaded again
+	failedLoadBackoff = 30 * time.Second
+)
+
+type Config struct {
+	Enabled bool `envconfig:"default=false"`
+	// Source of the available modules: kcp reads the lifecycle-manager resources, configmap reads the ConfigMap
+	Source        string        `envconfig:"default=kcp"`
+	Namespace     string        `envconfig:"default=kcp-system"`
+	ConfigMapName string        `envconfig:"default=kyma-modules"`
+	CacheTTL      time.Duration `envconfig:"default=10m"`
+}
+
+// Catalog caches the modules available in KCP and validates the modules requested by the user
+type Catalog struct {
+	source   Source
+	cacheTTL time.Duration
+	log      *slog.Logger
+	now      func() time.Time
+
+	// validationSkipped counts the validations skipped because the modules are not available, it is nil if the metrics are disabled
+	validationSkipped prometheus.Counter
+
+	mu       sync.Mutex
+	modules  []Module
+	loadedAt time.Time
+	inflight *loadCall
+	// failedAt and loadErr are the time and the error of the last failed load, failedAt is zero after a successful load
+	failedAt time.Time
+	loadErr  error
+}
+
+// loadCall is the load of the modules in progress, modules and err are set before done is closed
+type loadCall struct {
+	done    chan struct{}
+	modules []Module
+	err     error
+}
+
+func NewCatalog(cfg Config, source Source, log *slog.Logger) *Catalog {
+	return &Catalog{
+		source:   source,
+		cacheTTL: cfg.CacheTTL,
+		log:      log.With("component", "ModuleCatalog"),
+		now:      time.Now,
+	}
+}
+
+// WithMetrics registers the metric counting the skipped validations of the modules
+func (c *Catalog) WithMetrics(reg prometheus.Registerer) *Catalog {
+	c.validationSkipped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: prometheusNamespace,
+		Subsystem: prometheusSubsystem,
+		Name:      "modules_validation_skipped_total",
+		Help:      "Total number of validations of the requested modules skipped because the available modules could not be loaded.",
+	})
+	reg.MustRegister(c.validationSkipped)
+	return c
+}
+
+// Modules returns the available modules. The stale modules are returned immediately and refreshed in the background,
+// the callers wait only for the first load. The modules are not loaded again for failedLoadBackoff after a failed load.
+// The modules are loaded without holding the lock, the concurrent callers wait for the result of the load in progress.
+func (c *Catalog) Modules(ctx context.Context) ([]Module, error) {
+	c.mu.Lock()
+	if c.modules != nil {
+		modules := c.modules
+		if c.inflight == nil && c.now().Sub(c.loadedAt) >= c.cacheTTL && !c.backingOff() {
+			call := &loadCall{done: make(chan struct{})}
+			c.inflight = call
+			go c.load(ctx, call)
+		}
+		c.mu.Unlock()
+		return modules, nil
+	}
+	call := c.inflight
+	switch {
+	case call != nil:
+		c.mu.Unlock()
+	case c.backingOff():
+		err := c.loadErr
+		c.mu.Unlock()
+		return nil, err
+	default:
+		call = &loadCall{done: make(chan struct{})}
+		c.inflight = call
+		c.mu.Unlock()
+		c.load(ctx, call)
+	}
+
+	select {
+	case <-call.done:
+		return call.modules, call.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("while waiting for modules: %w", ctx.Err())
+	}
+}
+
+// backingOff returns true if the last load failed less than failedLoadBackoff ago, the caller holds the lock
+func (c *Catalog) backingOff() bool {
+	return !c.failedAt.IsZero() && c.now().Sub(c.failedAt) < failedLoadBackoff
+}
+
+func (c *Catalog) load(ctx context.Context, call *loadCall) {
+	// the result is shared with the concurrent callers, so the load is not cancelled with the context of the caller
+	loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+	defer cancel()
+	modules, err := c.source.Modules(loadCtx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case err != nil && c.modules != nil:
+		c.log.Warn(fmt.Sprintf("unable to refresh modules, using modules loaded at %s: %s", c.loadedAt.Format(time.RFC3339), err))
+		c.failedAt, c.loadErr = c.now(), fmt.Errorf("while loading modules: %w", err)
+		call.modules = c.modules
+	case err != nil:
+		c.failedAt, c.loadErr = c.now(), fmt.Errorf("while loading modules: %w", err)
+		call.err = c.loadErr
+	default:
+		if modules == nil {
+			modules = []Module{}
+		}
+		c.modules = modules
+		c.loadedAt = c.now()
+		c.failedAt, c.loadErr = time.Time{}, nil
+		call.modules = modules
+	}
+	c.inflight = nil
+	close(call.done)
+}
+
+// Validate checks the names, channels and custom resource policies of the modules from the custom list.
+// The modules are not validated when the catalog cannot be loaded, because lifecycle-manager validates them anyway.
+func (c *Catalog) Validate(ctx context.Context, modules *pkg.ModulesDTO) error {
+	if modules == nil || len(modules.List) == 0 {
+		return nil
+	}
+	available, err := c.Modules(ctx)
+	if err != nil {
+		c.skipValidation(err.Error())
+		return nil
+	}
+	if len(available) == 0 {
+		c.skipValidation("no modules available")
+		return nil
+	}
+
+	byName := make(map[string]Module, len(available))
+	for _, m := range available {
+		byName[m.Name] = m
+	}
+
+	var issues []string
+	requested := map[string]struct{}{}
+	for _, m := range modules.List {
+		if _, duplicated := requested[m.Name]; duplicated {
+			issues = append(issues, fmt.Sprintf("module %q is listed more than once", m.Name))
+			continue
+		}
+		requested[m.Name] = struct{}{}
+
+		module, found := byName[m.Name]
+		if !found {
+			issue := fmt.Sprintf("module %q is not available", m.Name)
+			if suggestion := closestName(m.Name, available); suggestion != "" {
+				issue = fmt.Sprintf("%s, did you mean %q?", issue, suggestion)
+			}
+			issues = append(issues, issue)
+			continue
+		}
+
+		channel := valueOrEmpty(m.Channel)
+		if channel == "" {
+			channel = valueOrEmpty(modules.Channel)
+		}
+		if channel != "" && len(module.Channels) > 0 && !slices.Contains(module.Channels, channel) {
+			issues = append(issues, fmt.Sprintf("channel %q is not available for module %q, available channels: %s", channel, m.Name, strings.Join(module.Channels, ", ")))
+		}
+
+		switch policy := valueOrEmpty(m.CustomResourcePolicy); policy {
+		case "", CustomResourcePolicyCreateAndDelete, CustomResourcePolicyIgnore:
+		default:
+			issues = append(issues, fmt.Sprintf("customResourcePolicy %q of module %q is not supported, supported values: %s, %s", policy, m.Name, CustomResourcePolicyCreateAndDelete, CustomResourcePolicyIgnore))
+		}
+	}
+
+	if len(issues) > 0 {
+		return errors.New("invalid modules: " + strings.Join(issues, "; "))
+	}
+	return nil
+}
+
+func (c *Catalog) skipValidation(reason string) {
+	c.log.Warn(fmt.Sprintf("skipping modules validation: %s", reason))
+	if c.validationSkipped != nil {
+		c.validationSkipped.Inc()
+	}
+}
+
+// closestName returns the name of the available module which differs from the given name by at most two characters
+func closestName(name string, available []Module) string {
+	const maxDistance = 2
+	closest, closestDistance := "", maxDistance+1
+	for _, m := range available {
+		if d := distance(strings.ToLower(name), strings.ToLower(m.Name)); d < closestDistance {
+			closest, closestDistance = m.Name, d
+		}
+	}
+	return closest
+}
+
+// distance is the Levenshtein distance between two strings
+func distance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,378 @@
+package kymamodules
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCatalog_Validate(t *testing.T) {
+	catalog := NewCatalog(Config{CacheTTL: time.Hour}, &fixSource{modules: []Module{
+		{Name: "btp-operator", Channels: []string{"fast", "regular"}},
+		{Name: "istio", Channels: []string{"fast", "regular"}},
+		{Name: "serverless", Channels: []string{"regular"}},
+	}}, fixLogger())
+
+	for tn, tc := range map[string]struct {
+		modules       *pkg.ModulesDTO
+		expectedError string
+	}{
+		"should accept default modules": {
+			modules: &pkg.ModulesDTO{Default: ptr.Bool(true)},
+		},
+		"should accept available modules": {
+			modules: &pkg.ModulesDTO{List: []pkg.ModuleDTO{
+				{Name: "btp-operator", Channel: ptr.String("fast"), CustomResourcePolicy: ptr.String("Ignore")},
+				{Name: "istio"},
+			}},
+		},
+		"should suggest the module name": {
+			modules:       &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "btp-operater"}}},
+			expectedError: `invalid modules: module "btp-operater" is not available, did you mean "btp-operator"?`,
+		},
+		"should reject unknown module": {
+			modules:       &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "keda"}}},
+			expectedError: `invalid modules: module "keda" is not available`,
+		},
+		"should reject the channel of the module": {
+			modules:       &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "serverless", Channel: ptr.String("fast")}}},
+			expectedError: `invalid modules: channel "fast" is not available for module "serverless", available channels: regular`,
+		},
+		"should reject the channel of the module list": {
+			modules:       &pkg.ModulesDTO{Channel: ptr.String("fast"), List: []pkg.ModuleDTO{{Name: "istio"}, {Name: "serverless"}}},
+			expectedError: `invalid modules: channel "fast" is not available for module "serverless", available channels: regular`,
+		},
+		"should accept the module channel overriding the channel of the module list": {
+			modules: &pkg.ModulesDTO{Channel: ptr.String("fast"), List: []pkg.ModuleDTO{{Name: "serverless", Channel: ptr.String("regular")}}},
+		},
+		"should reject unsupported custom resource policy": {
+			modules:       &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "istio", CustomResourcePolicy: ptr.String("Delete")}}},
+			expectedError: `invalid modules: customResourcePolicy "Delete" of module "istio" is not supported, supported values: CreateAndDelete, Ignore`,
+		},
+		"should reject duplicated module and report all issues": {
+			modules:       &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "istio"}, {Name: "istio"}, {Name: "keda"}}},
+			expectedError: `invalid modules: module "istio" is listed more than once; module "keda" is not available`,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			err := catalog.Validate(context.Background(), tc.modules)
+
+			// then
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestCatalog_Modules(t *testing.T) {
+	t.Run("should cache the modules", func(t *testing.T) {
+		// given
+		source := &fixSource{modules: []Module{{Name: "istio"}}}
+		catalog := NewCatalog(Config{CacheTTL: time.Hour}, source, fixLogger())
+		now, elapsed := time.Now(), atomic.Int64{}
+		catalog.now = func() time.Time { return now.Add(time.Duration(elapsed.Load())) }
+
+		// when
+		_, err := catalog.Modules(context.Background())
+		require.NoError(t, err)
+		source.set([]Module{{Name: "istio"}, {Name: "keda"}}, nil)
+		cached, err := catalog.Modules(context.Background())
+		require.NoError(t, err)
+		elapsed.Store(int64(time.Hour))
+		stale, err := catalog.Modules(context.Background())
+		require.NoError(t, err)
+
+		// then
+		assert.Len(t, cached, 1)
+		assert.Len(t, stale, 1)
+		assert.Eventually(t, func() bool {
+			refreshed, err := catalog.Modules(context.Background())
+			return err == nil && len(refreshed) == 2
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(2), source.calls.Load())
+	})
+
+	t.Run("should return stale modules without waiting for the refresh", func(t *testing.T) {
+		// given
+		source := &blockingSource{release: make(chan struct{}), modules: []Module{{Name: "istio"}}}
+		close(source.release)
+		catalog := NewCatalog(Config{CacheTTL: 0}, source, fixLogger())
+		_, err := catalog.Modules(context.Background())
+		require.NoError(t, err)
+		source.release = make(chan struct{})
+		defer close(source.release)
+
+		// when
+		modules, err := catalog.Modules(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Module{{Name: "istio"}}, modules)
+		assert.Eventually(t, func() bool { return source.calls.Load() == 2 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should return previously loaded modules when the source fails", func(t *testing.T) {
+		// given
+		source := &fixSource{modules: []Module{{Name: "istio"}}}
+		catalog := NewCatalog(Config{CacheTTL: 0}, source, fixLogger())
+		_, err := catalog.Modules(context.Background())
+		require.NoError(t, err)
+		source.set(nil, errors.New("connection refused"))
+
+		// when
+		modules, err := catalog.Modules(context.Background())
+		require.Eventually(t, func() bool { return source.calls.Load() == 2 }, time.Second, 10*time.Millisecond)
+		afterFailure, afterFailureErr := catalog.Modules(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Module{{Name: "istio"}}, modules)
+		require.NoError(t, afterFailureErr)
+		assert.Equal(t, []Module{{Name: "istio"}}, afterFailure)
+	})
+
+	t.Run("should back off after a failed load", func(t *testing.T) {
+		// given
+		source := &fixSource{err: errors.New("connection refused")}
+		catalog := NewCatalog(Config{CacheTTL: time.Hour}, source, fixLogger())
+		now := time.Now()
+		catalog.now = func() time.Time { return now }
+		_, err := catalog.Modules(context.Background())
+		require.Error(t, err)
+
+		// when
+		_, backoffErr := catalog.Modules(context.Background())
+		catalog.now = func() time.Time { return now.Add(failedLoadBackoff) }
+		source.set([]Module{{Name: "istio"}}, nil)
+		modules, err := catalog.Modules(context.Background())
+
+		// then
+		assert.EqualError(t, backoffErr, "while loading modules: connection refused")
+		require.NoError(t, err)
+		assert.Equal(t, []Module{{Name: "istio"}}, modules)
+		assert.Equal(t, int32(2), source.calls.Load())
+	})
+
+	t.Run("should not refresh the stale modules during the backoff", func(t *testing.T) {
+		// given
+		source := &fixSource{modules: []Module{{Name: "istio"}}}
+		catalog := NewCatalog(Config{CacheTTL: 0}, source, fixLogger())
+		_, err := catalog.Modules(context.Background())
+		require.NoError(t, err)
+		source.set(nil, errors.New("connection refused"))
+		_, err = catalog.Modules(context.Background())
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return source.calls.Load() == 2 }, time.Second, 10*time.Millisecond)
+
+		// when
+		modules, err := catalog.Modules(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Module{{Name: "istio"}}, modules)
+		assert.Never(t, func() bool { return source.calls.Load() > 2 }, 50*time.Millisecond, 10*time.Millisecond)
+	})
+
+	t.Run("should skip the validation when the modules cannot be loaded", func(t *testing.T) {
+		// given
+		catalog := NewCatalog(Config{}, &fixSource{err: errors.New("connection refused")}, fixLogger()).
+			WithMetrics(prometheus.NewRegistry())
+
+		// when
+		err := catalog.Validate(context.Background(), &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "keda"}}})
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), testutil.ToFloat64(catalog.validationSkipped))
+	})
+
+	t.Run("should load the modules once for concurrent callers", func(t *testing.T) {
+		// given
+		source := &blockingSource{release: make(chan struct{}), modules: []Module{{Name: "istio"}}}
+		catalog := NewCatalog(Config{CacheTTL: time.Hour}, source, fixLogger())
+
+		// when
+		const callers = 10
+		var wg sync.WaitGroup
+		results := make([][]Module, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], _ = catalog.Modules(context.Background())
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(source.release)
+		wg.Wait()
+
+		// then
+		assert.Equal(t, int32(1), source.calls.Load())
+		for _, modules := range results {
+			assert.Equal(t, []Module{{Name: "istio"}}, modules)
+		}
+	})
+
+	t.Run("should stop waiting for the modules when the context is cancelled", func(t *testing.T) {
+		// given
+		source := &blockingSource{release: make(chan struct{}), modules: []Module{{Name: "istio"}}}
+		defer close(source.release)
+		catalog := NewCatalog(Config{CacheTTL: time.Hour}, source, fixLogger())
+		go func() { _, _ = catalog.Modules(context.Background()) }()
+		require.Eventually(t, func() bool { return source.calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// when
+		_, err := catalog.Modules(ctx)
+
+		// then
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestKCPSource(t *testing.T) {
+	// given
+	k8sClient := fake.NewClientBuilder().WithScheme(fixScheme()).WithRuntimeObjects(
+		fixModuleReleaseMeta("btp-operator", "fast", "regular"),
+		fixModuleTemplate("btp-operator-1.1.0", "btp-operator", "", nil),
+		fixModuleTemplate("istio-regular", "", "regular", map[string]string{moduleNameLabel: "istio"}),
+		fixModuleTemplate("istio-fast", "", "fast", map[string]string{moduleNameLabel: "istio"}),
+		fixModuleTemplate("keda-0.1.0", "keda", "", map[string]string{betaLabel: "true"}),
+	).Build()
+
+	// when
+	modules, err := NewKCPSource(k8sClient, "kcp-system").Modules(context.Background())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []Module{
+		{Name: "btp-operator", Channels: []string{"fast", "regular"}},
+		{Name: "istio", Channels: []string{"fast", "regular"}},
+		{Name: "keda", Beta: true},
+	}, modules)
+}
+
+func TestConfigMapSource(t *testing.T) {
+	// given
+	k8sClient := fake.NewClientBuilder().WithRuntimeObjects(&coreV1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "kyma-modules", Namespace: "kcp-system"},
+		Data: map[string]string{ConfigMapKey: `
+- name: serverless
+  channels: [regular]
+- name: istio
+  channels: [fast, regular]
+`},
+	}).Build()
+
+	// when
+	modules, err := NewConfigMapSource(k8sClient, "kcp-system", "kyma-modules").Modules(context.Background())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []Module{
+		{Name: "istio", Channels: []string{"fast", "regular"}},
+		{Name: "serverless", Channels: []string{"regular"}},
+	}, modules)
+}
+
+type fixSource struct {
+	mu      sync.Mutex
+	modules []Module
+	err     error
+	calls   atomic.Int32
+}
+
+func (s *fixSource) Modules(_ context.Context) ([]Module, error) {
+	s.calls.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.modules, s.err
+}
+
+func (s *fixSource) set(modules []Module, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modules, s.err = modules, err
+}
+
+// blockingSource returns the modules when the release channel is closed
+type blockingSource struct {
+	release chan struct{}
+	modules []Module
+	calls   atomic.Int32
+}
+
+func (s *blockingSource) Modules(_ context.Context) ([]Module, error) {
+	s.calls.Add(1)
+	<-s.release
+	return s.modules, nil
+}
+
+func fixScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	for _, kind := range []string{"ModuleReleaseMeta", "ModuleTemplate"} {
+		gvk := schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: kind}
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(kind+"List"), &unstructured.UnstructuredList{})
+	}
+	return scheme
+}
+
+func fixModuleReleaseMeta(moduleName string, channels ...string) client.Object {
+	assignments := make([]interface{}, 0, len(channels))
+	for _, channel := range channels {
+		assignments = append(assignments, map[string]interface{}{"channel": channel, "version": "1.1.0"})
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"moduleName": moduleName, "channels": assignments},
+	}}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "ModuleReleaseMeta"})
+	obj.SetName(moduleName)
+	obj.SetNamespace("kcp-system")
+	return obj
+}
+
+func fixModuleTemplate(name, moduleName, channel string, labels map[string]string) client.Object {
+	spec := map[string]interface{}{}
+	if moduleName != "" {
+		spec["moduleName"] = moduleName
+	}
+	if channel != "" {
+		spec["channel"] = channel
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "ModuleTemplate"})
+	obj.SetName(name)
+	obj.SetNamespace("kcp-system")
+	obj.SetLabels(labels)
+	return obj
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+}
@@ -0,0 +1,47 @@
+package kymamodules
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler interface {
+	AttachRoutes(r router)
+}
+
+type ModulesDTO struct {
+	Modules []Module `json:"modules"`
+}
+
+type handler struct {
+	catalog *Catalog
+	log     *slog.Logger
+}
+
+func NewHandler(catalog *Catalog, log *slog.Logger) Handler {
+	return &handler{
+		catalog: catalog,
+		log:     log.With("service", "ModulesEndpoint"),
+	}
+}
+
+func (h *handler) AttachRoutes(r router) {
+	r.HandleFunc("GET /modules", h.getModules)
+}
+
+func (h *handler) getModules(w http.ResponseWriter, req *http.Request) {
+	modules, err := h.catalog.Modules(req.Context())
+	if err != nil {
+		h.log.Error(fmt.Sprintf("unable to get modules: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	httputil.WriteResponse(w, http.StatusOK, ModulesDTO{Modules: modules})
+}
@@ -0,0 +1,48 @@
+package kymamodules
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	t.Run("should return available modules", func(t *testing.T) {
+		// given
+		router := httputil.NewRouter()
+		catalog := NewCatalog(Config{CacheTTL: time.Hour}, &fixSource{modules: []Module{{Name: "istio", Channels: []string{"fast", "regular"}}}}, fixLogger())
+		NewHandler(catalog, fixLogger()).AttachRoutes(router)
+
+		// when
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/modules", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var body ModulesDTO
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Equal(t, []Module{{Name: "istio", Channels: []string{"fast", "regular"}}}, body.Modules)
+	})
+
+	t.Run("should return 503 when modules cannot be loaded", func(t *testing.T) {
+		// given
+		router := httputil.NewRouter()
+		catalog := NewCatalog(Config{}, &fixSource{err: errors.New("connection refused")}, fixLogger())
+		NewHandler(catalog, fixLogger()).AttachRoutes(router)
+
+		// when
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/modules", nil))
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	})
+}
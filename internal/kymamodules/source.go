@@ -0,0 +1,175 @@
+package kymamodules
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	SourceKCP       = "kcp"
+	SourceConfigMap = "configmap"
+
+	// ConfigMapKey is the key of the ConfigMap with the list of the available modules
+	ConfigMapKey = "modules.yaml"
+
+	moduleNameLabel = "operator.kyma-project.io/module-name"
+	betaLabel       = "operator.kyma-project.io/beta"
+	internalLabel   = "operator.kyma-project.io/internal"
+)
+
+var (
+	moduleReleaseMetaGVK = schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "ModuleReleaseMetaList"}
+	moduleTemplateGVK    = schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "ModuleTemplateList"}
+)
+
+// Module is a Kyma module which can be enabled in the Kyma custom resource
+type Module struct {
+	Name     string   `json:"name" yaml:"name"`
+	Channels []string `json:"channels,omitempty" yaml:"channels,omitempty"`
+	Beta     bool     `json:"beta,omitempty" yaml:"beta,omitempty"`
+	Internal bool     `json:"internal,omitempty" yaml:"internal,omitempty"`
+}
+
+// Source lists the modules available in KCP
+type Source interface {
+	Modules(ctx context.Context) ([]Module, error)
+}
+
+// NewSource creates the source configured with the given name
+func NewSource(cfg Config, k8sClient client.Client) (Source, error) {
+	switch cfg.Source {
+	case SourceKCP:
+		return NewKCPSource(k8sClient, cfg.Namespace), nil
+	case SourceConfigMap:
+		return NewConfigMapSource(k8sClient, cfg.Namespace, cfg.ConfigMapName), nil
+	default:
+		return nil, fmt.Errorf("unknown modules source %q, supported sources: %s, %s", cfg.Source, SourceKCP, SourceConfigMap)
+	}
+}
+
+// kcpSource reads the modules from the ModuleReleaseMeta and ModuleTemplate resources managed by lifecycle-manager
+type kcpSource struct {
+	k8sClient client.Client
+	namespace string
+}
+
+func NewKCPSource(k8sClient client.Client, namespace string) Source {
+	return &kcpSource{k8sClient: k8sClient, namespace: namespace}
+}
+
+func (s *kcpSource) Modules(ctx context.Context) ([]Module, error) {
+	modules := map[string]*Module{}
+	module := func(name string) *Module {
+		if _, found := modules[name]; !found {
+			modules[name] = &Module{Name: name}
+		}
+		return modules[name]
+	}
+
+	releaseMetas, err := s.list(ctx, moduleReleaseMetaGVK)
+	if err != nil {
+		return nil, err
+	}
+	for _, releaseMeta := range releaseMetas {
+		name, _, _ := unstructured.NestedString(releaseMeta.Object, "spec", "moduleName")
+		if name == "" {
+			continue
+		}
+		m := module(name)
+		channels, _, _ := unstructured.NestedSlice(releaseMeta.Object, "spec", "channels")
+		for _, channel := range channels {
+			if assignment, ok := channel.(map[string]interface{}); ok {
+				if channelName, ok := assignment["channel"].(string); ok {
+					m.Channels = appendUnique(m.Channels, channelName)
+				}
+			}
+		}
+	}
+
+	templates, err := s.list(ctx, moduleTemplateGVK)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		name, _, _ := unstructured.NestedString(template.Object, "spec", "moduleName")
+		if name == "" {
+			name = template.GetLabels()[moduleNameLabel]
+		}
+		if name == "" {
+			continue
+		}
+		m := module(name)
+		// the channel of the module template is set only by the modules which are not released with ModuleReleaseMeta
+		if channel, _, _ := unstructured.NestedString(template.Object, "spec", "channel"); channel != "" {
+			m.Channels = appendUnique(m.Channels, channel)
+		}
+		m.Beta = m.Beta || template.GetLabels()[betaLabel] == "true"
+		m.Internal = m.Internal || template.GetLabels()[internalLabel] == "true"
+	}
+
+	result := make([]Module, 0, len(modules))
+	for _, m := range modules {
+		sort.Strings(m.Channels)
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (s *kcpSource) list(ctx context.Context, gvk schema.GroupVersionKind) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	err := s.k8sClient.List(ctx, list, client.InNamespace(s.namespace))
+	switch {
+	// the resource is not installed in KCP, for example ModuleReleaseMeta in older versions of lifecycle-manager
+	case meta.IsNoMatchError(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("while listing %s: %w", gvk.Kind, err)
+	}
+	return list.Items, nil
+}
+
+// configMapSource reads the modules from the ConfigMap, which can be used when KEB has no access to the lifecycle-manager resources
+type configMapSource struct {
+	k8sClient client.Client
+	namespace string
+	name      string
+}
+
+func NewConfigMapSource(k8sClient client.Client, namespace, name string) Source {
+	return &configMapSource{k8sClient: k8sClient, namespace: namespace, name: name}
+}
+
+func (s *configMapSource) Modules(ctx context.Context) ([]Module, error) {
+	cm := &coreV1.ConfigMap{}
+	if err := s.k8sClient.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.name}, cm); err != nil {
+		return nil, fmt.Errorf("while reading ConfigMap %s/%s: %w", s.namespace, s.name, err)
+	}
+	data, found := cm.Data[ConfigMapKey]
+	if !found {
+		return nil, fmt.Errorf("key %q missing from ConfigMap %s/%s", ConfigMapKey, s.namespace, s.name)
+	}
+	var modules []Module
+	if err := yaml.Unmarshal([]byte(data), &modules); err != nil {
+		return nil, fmt.Errorf("while unmarshaling modules from ConfigMap %s/%s: %w", s.namespace, s.name, err)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+	return modules, nil
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,139 @@
+package kymastatus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	StateReady      = "Ready"
+	StateProcessing = "Processing"
+	StateError      = "Error"
+	StateWarning    = "Warning"
+	StateDeleting   = "Deleting"
+
+	instanceIDIndex = "instanceID"
+)
+
+var KymaGVR = schema.GroupVersionResource{Group: "operator.kyma-project.io", Version: "v1beta2", Resource: "kymas"}
+
+type Config struct {
+	Enabled      bool          `envconfig:"default=false"`
+	Namespace    string        `envconfig:"default=kcp-system"`
+	ResyncPeriod time.Duration `envconfig:"default=10m"`
+	// WaitForReady makes the provisioning succeed only after the Kyma custom resource is Ready
+	WaitForReady        bool          `envconfig:"default=false"`
+	WaitForReadyTimeout time.Duration `envconfig:"default=30m"`
+}
+
+// Provider returns the status of the Kyma custom resource of the instance
+type Provider interface {
+	Status(instanceID string) (*pkg.KymaStatus, bool)
+}
+
+// Informer caches the Kyma custom resources in KCP, so the status of the Kyma custom resource can be returned
+// with every instance without calling the Kubernetes API
+type Informer struct {
+	informer cache.SharedIndexInformer
+	factory  dynamicinformer.DynamicSharedInformerFactory
+	log      *slog.Logger
+}
+
+func NewInformer(k8sClient dynamic.Interface, cfg Config, log *slog.Logger) (*Informer, error) {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(k8sClient, cfg.ResyncPeriod, cfg.Namespace, nil)
+	informer := factory.ForResource(KymaGVR).Informer()
+	err := informer.AddIndexers(cache.Indexers{instanceIDIndex: func(obj interface{}) ([]string, error) {
+		kyma, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, nil
+		}
+		if instanceID := kyma.GetLabels()[customresources.InstanceIdLabel]; instanceID != "" {
+			return []string{instanceID}, nil
+		}
+		return nil, nil
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("while adding the instance ID index: %w", err)
+	}
+	return &Informer{
+		informer: informer,
+		factory:  factory,
+		log:      log.With("component", "KymaStatusInformer"),
+	}, nil
+}
+
+// Start runs the informer until the context is done and waits until the Kyma custom resources are cached
+func (i *Informer) Start(ctx context.Context) error {
+	i.factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), i.informer.HasSynced) {
+		return fmt.Errorf("unable to sync the Kyma resources cache")
+	}
+	i.log.Info(fmt.Sprintf("Kyma resources cached: %d", len(i.informer.GetStore().ListKeys())))
+	return nil
+}
+
+func (i *Informer) Status(instanceID string) (*pkg.KymaStatus, bool) {
+	objects, err := i.informer.GetIndexer().ByIndex(instanceIDIndex, instanceID)
+	if err != nil {
+		i.log.Warn(fmt.Sprintf("unable to get Kyma resource of instance %s: %s", instanceID, err))
+		return nil, false
+	}
+	if len(objects) == 0 {
+		return nil, false
+	}
+	kyma, ok := objects[0].(*unstructured.Unstructured)
+	if !ok {
+		return nil, false
+	}
+	return FromUnstructured(kyma), true
+}
+
+// FromUnstructured summarizes the status of the Kyma custom resource
+func FromUnstructured(kyma *unstructured.Unstructured) *pkg.KymaStatus {
+	state, _, _ := unstructured.NestedString(kyma.Object, "status", "state")
+	status := &pkg.KymaStatus{State: state}
+
+	modules, _, _ := unstructured.NestedSlice(kyma.Object, "status", "modules")
+	for _, m := range modules {
+		module, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		status.Modules = append(status.Modules, pkg.KymaModuleStatus{
+			Name:    stringField(module, "name"),
+			State:   stringField(module, "state"),
+			Version: stringField(module, "version"),
+			Channel: stringField(module, "channel"),
+		})
+	}
+	sort.Slice(status.Modules, func(i, j int) bool { return status.Modules[i].Name < status.Modules[j].Name })
+	return status
+}
+
+// NotReadyModules returns the modules which are not ready, for example "serverless (Error)"
+func NotReadyModules(status *pkg.KymaStatus) []string {
+	var notReady []string
+	for _, module := range status.Modules {
+		if module.State != StateReady {
+			notReady = append(notReady, fmt.Sprintf("%s (%s)", module.Name, module.State))
+		}
+	}
+	return notReady
+}
+
+func stringField(object map[string]interface{}, field string) string {
+	value, _ := object[field].(string)
+	return value
+}
@@ -0,0 +1,67 @@
+package kymastatus
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestInformer(t *testing.T) {
+	// given
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{KymaGVR: "KymaList"},
+		fixKyma("runtime-1", "instance-1", "Ready", map[string]interface{}{"name": "btp-operator", "state": "Ready", "channel": "regular", "version": "1.1.0"}),
+		fixKyma("runtime-2", "instance-2", "Error",
+			map[string]interface{}{"name": "serverless", "state": "Error", "channel": "fast", "version": "1.2.0"},
+			map[string]interface{}{"name": "btp-operator", "state": "Ready", "channel": "regular", "version": "1.1.0"}),
+	)
+	informer, err := NewInformer(client, Config{Namespace: "kcp-system", ResyncPeriod: time.Minute}, fixLogger())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// when
+	require.NoError(t, informer.Start(ctx))
+
+	// then
+	status, found := informer.Status("instance-2")
+	require.True(t, found)
+	assert.Equal(t, &pkg.KymaStatus{
+		State: "Error",
+		Modules: []pkg.KymaModuleStatus{
+			{Name: "btp-operator", State: "Ready", Version: "1.1.0", Channel: "regular"},
+			{Name: "serverless", State: "Error", Version: "1.2.0", Channel: "fast"},
+		},
+	}, status)
+	assert.Equal(t, []string{"serverless (Error)"}, NotReadyModules(status))
+
+	_, found = informer.Status("not-existing")
+	assert.False(t, found)
+}
+
+func fixKyma(name, instanceID, state string, modules ...interface{}) *unstructured.Unstructured {
+	kyma := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "operator.kyma-project.io/v1beta2",
+		"kind":       "Kyma",
+		"status":     map[string]interface{}{"state": state, "modules": modules},
+	}}
+	kyma.SetName(name)
+	kyma.SetNamespace("kcp-system")
+	kyma.SetLabels(map[string]string{"kyma-project.io/instance-id": instanceID})
+	return kyma
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+}
@@ -1,13 +1,13 @@
 package internal
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
 
-	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
-
 	"github.com/google/uuid"
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
@@ -42,6 +42,8 @@ type Instance struct {
 	UpdatedAt time.Time
 	DeletedAt time.Time
 	ExpiredAt *time.Time
+	// ExpiresAt overrides the expiration time of the trial or free instance calculated from the expiration period of the plan
+	ExpiresAt *time.Time
 
 	Version      int
 	Provider     pkg.CloudProvider
@@ -59,6 +61,14 @@ func (i *Instance) IsExpired() bool {
 	return i.ExpiredAt != nil
 }
 
+// ExpirationTime returns the time when the instance expires, the ExpiresAt set for the instance overrides the given expiration period
+func (i *Instance) ExpirationTime(period time.Duration) time.Time {
+	if i.ExpiresAt != nil {
+		return *i.ExpiresAt
+	}
+	return i.CreatedAt.Add(period)
+}
+
 func (i *Instance) GetSubscriptionGlobalAccoundID() string {
 	if i.SubscriptionGlobalAccountID != "" {
 		return i.SubscriptionGlobalAccountID
@@ -91,6 +101,8 @@ const (
 	OperationTypeUpdate OperationType = "update"
 	// OperationTypeUpgradeCluster means upgrade cluster (shoot) OperationType
 	OperationTypeUpgradeCluster OperationType = "upgradeCluster"
+	// OperationTypeTransfer means transfer of the instance to another global account, it is not returned as the last operation
+	OperationTypeTransfer OperationType = "transfer"
 )
 
 // replacement for orchestration constants
@@ -143,6 +155,9 @@ type Operation struct {
 	// UpdatedPlanID is used to store the plan ID if the plan has been changed, "" if not changed
 	UpdatedPlanID string `json:"updated_plan_id,omitempty"`
 
+	// UpdatedMaintenanceInfo is used to store the maintenance_info requested by the platform, nil if not changed
+	UpdatedMaintenanceInfo *domain.MaintenanceInfo `json:"updated_maintenance_info,omitempty"`
+
 	// UPGRADE KYMA
 	RuntimeOperation            `json:"runtime_operation"`
 	ClusterConfigurationApplied bool `json:"cluster_configuration_applied"`
@@ -160,6 +175,103 @@ type Operation struct {
 
 	// RawParameters stores the verbatim JSON payload submitted by the caller (not modified by merging)
 	RawParameters json.RawMessage `json:"rawParameters,omitempty"`
+
+	// StageTimes stores the start and finish times of the processed stages and the durations of their steps
+	StageTimes []StageTime `json:"stageTimes,omitempty"`
+
+	// HookMetadata stores the metadata returned by the step hooks, by the hook name
+	HookMetadata map[string]map[string]string `json:"hookMetadata,omitempty"`
+
+	// TraceContext stores the W3C trace context of the request which created the operation, so the steps continue its trace
+	TraceContext map[string]string `json:"traceContext,omitempty"`
+}
+
+type StageTime struct {
+	Name       string                   `json:"name"`
+	StartedAt  time.Time                `json:"startedAt"`
+	FinishedAt *time.Time               `json:"finishedAt,omitempty"`
+	Steps      map[string]time.Duration `json:"steps,omitempty"`
+}
+
+type StepExecutionResult string
+
+const (
+	StepExecutionSucceeded StepExecutionResult = "succeeded"
+	StepExecutionRetry     StepExecutionResult = "retry"
+	StepExecutionFailed    StepExecutionResult = "failed"
+)
+
+// StepExecution is a single run of a step of the operation. The attempt is the number of the run of the step within the operation, starting from 1.
+type StepExecution struct {
+	ID             string
+	OperationID    string
+	InstanceID     string
+	Stage          string
+	Step           string
+	Attempt        int
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	Result         StepExecutionResult
+	RetryAfter     time.Duration
+	ErrorReason    string
+	ErrorComponent string
+}
+
+// QuotaReservation is the quota reserved for an instance which is being provisioned or updated to the plan, but is not stored yet.
+type QuotaReservation struct {
+	InstanceID      string
+	GlobalAccountID string
+	SubAccountID    string
+	PlanID          string
+	CreatedAt       time.Time
+}
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+// AuditEntry is a record of a mutating or sensitive API call. The entries are chained: every entry contains the hash
+// of the previous one, so a modified or removed entry breaks the chain.
+type AuditEntry struct {
+	Sequence     int64           `json:"sequence"`
+	ID           string          `json:"id"`
+	Timestamp    time.Time       `json:"timestamp"`
+	Action       string          `json:"action"`
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	RequestID    string          `json:"requestID"`
+	Principal    AuditPrincipal  `json:"principal"`
+	InstanceID   string          `json:"instanceID,omitempty"`
+	Parameters   json.RawMessage `json:"parameters,omitempty"`
+	StatusCode   int             `json:"statusCode"`
+	Outcome      AuditOutcome    `json:"outcome"`
+	PreviousHash string          `json:"previousHash"`
+	Hash         string          `json:"hash"`
+}
+
+// AuditPrincipal identifies who made the call
+type AuditPrincipal struct {
+	// Platform and Identity are decoded from the OSB X-Broker-API-Originating-Identity header
+	Platform string `json:"platform,omitempty"`
+	Identity string `json:"identity,omitempty"`
+	// Subject and ClientID are the claims of the bearer token
+	Subject   string `json:"subject,omitempty"`
+	ClientID  string `json:"clientID,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	// Verified is true if the claims were verified by the authorization of KEB
+	Verified bool `json:"verified"`
+}
+
+// ComputeHash returns the SHA-256 hash of the entry including the hash of the previous entry, the Hash field itself is not hashed
+func (e AuditEntry) ComputeHash() string {
+	e.Hash = ""
+	e.Timestamp = e.Timestamp.UTC()
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
 // ProviderValues contains values which are specific to particular plans (and provisioning parameters)
@@ -355,12 +467,13 @@ type BindingStats struct {
 }
 
 // NewProvisioningOperation creates a fresh (just starting) instance of the ProvisioningOperation
-func NewProvisioningOperation(instanceID string, parameters ProvisioningParameters) (ProvisioningOperation, error) {
-	return NewProvisioningOperationWithID(uuid.New().String(), instanceID, parameters)
+func NewProvisioningOperation(instanceID string, parameters ProvisioningParameters, euAccess bool) (ProvisioningOperation, error) {
+	return NewProvisioningOperationWithID(uuid.New().String(), instanceID, parameters, euAccess)
 }
 
-// NewProvisioningOperationWithID creates a fresh (just starting) instance of the ProvisioningOperation with provided ID
-func NewProvisioningOperationWithID(operationID, instanceID string, parameters ProvisioningParameters) (ProvisioningOperation, error) {
+// NewProvisioningOperationWithID creates a fresh (just starting) instance of the ProvisioningOperation with provided ID.
+// The euAccess flag is resolved by the caller from the residency policy of the platform region.
+func NewProvisioningOperationWithID(operationID, instanceID string, parameters ProvisioningParameters, euAccess bool) (ProvisioningOperation, error) {
 	return ProvisioningOperation{
 		Operation: Operation{
 			ID:                     operationID,
@@ -378,7 +491,7 @@ func NewProvisioningOperationWithID(operationID, instanceID string, parameters P
 			InstanceDetails: InstanceDetails{
 				SubAccountID: parameters.ErsContext.SubAccountID,
 				Kubeconfig:   parameters.Parameters.Kubeconfig,
-				EuAccess:     euaccess.IsEURestrictedAccess(parameters.PlatformRegion),
+				EuAccess:     euAccess,
 			},
 			FinishedStages: make([]string, 0),
 			LastError:      kebError.LastError{},
@@ -484,6 +597,39 @@ func (o *Operation) FinishStage(stageName string) {
 	}
 
 	o.FinishedStages = append(o.FinishedStages, stageName)
+	if stageTime := o.stageTime(stageName); stageTime != nil {
+		finishedAt := time.Now()
+		stageTime.FinishedAt = &finishedAt
+	}
+}
+
+// StartStage records the start time of the stage, if it was not started before.
+func (o *Operation) StartStage(stageName string) {
+	if o.stageTime(stageName) != nil {
+		return
+	}
+	o.StageTimes = append(o.StageTimes, StageTime{Name: stageName, StartedAt: time.Now()})
+}
+
+// AddStepDuration adds the processing time of the step to the started stage.
+func (o *Operation) AddStepDuration(stageName, stepName string, duration time.Duration) {
+	stageTime := o.stageTime(stageName)
+	if stageTime == nil {
+		return
+	}
+	if stageTime.Steps == nil {
+		stageTime.Steps = make(map[string]time.Duration)
+	}
+	stageTime.Steps[stepName] += duration
+}
+
+func (o *Operation) stageTime(stageName string) *StageTime {
+	for i := range o.StageTimes {
+		if o.StageTimes[i].Name == stageName {
+			return &o.StageTimes[i]
+		}
+	}
+	return nil
 }
 
 func (o *Operation) IsStageFinished(stage string) bool {
@@ -2,12 +2,13 @@ package internal
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestFinishStage(t *testing.T) {
-	operation, error := NewProvisioningOperation("1", ProvisioningParameters{})
+	operation, error := NewProvisioningOperation("1", ProvisioningParameters{}, false)
 	assert.NoError(t, error)
 	assert.NotEmpty(t, operation)
 
@@ -45,6 +46,45 @@ func TestFinishStage(t *testing.T) {
 	})
 }
 
+func TestStageTimes(t *testing.T) {
+	operation, err := NewProvisioningOperation("1", ProvisioningParameters{}, false)
+	assert.NoError(t, err)
+
+	operation.StartStage("start")
+	operation.AddStepDuration("start", "step", time.Second)
+	operation.AddStepDuration("start", "step", time.Second)
+	operation.AddStepDuration("not_started", "step", time.Second)
+	operation.StartStage("start")
+	operation.FinishStage("start")
+	operation.StartStage("create_runtime")
+
+	assert.Len(t, operation.StageTimes, 2)
+	assert.Equal(t, "start", operation.StageTimes[0].Name)
+	assert.NotNil(t, operation.StageTimes[0].FinishedAt)
+	assert.Equal(t, map[string]time.Duration{"step": 2 * time.Second}, operation.StageTimes[0].Steps)
+	assert.Equal(t, "create_runtime", operation.StageTimes[1].Name)
+	assert.Nil(t, operation.StageTimes[1].FinishedAt)
+}
+
+func TestAuditEntry_ComputeHash(t *testing.T) {
+	entry := AuditEntry{
+		Sequence:     2,
+		ID:           "audit-2",
+		Timestamp:    time.Date(2026, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600)),
+		Action:       "provision",
+		Principal:    AuditPrincipal{Subject: "john"},
+		PreviousHash: "abc",
+	}
+	hash := entry.ComputeHash()
+
+	entry.Hash = hash
+	assert.Equal(t, hash, entry.ComputeHash(), "the hash must not depend on the stored hash")
+	entry.Timestamp = entry.Timestamp.UTC()
+	assert.Equal(t, hash, entry.ComputeHash(), "the hash must not depend on the time zone")
+	entry.Principal.Subject = "anna"
+	assert.NotEqual(t, hash, entry.ComputeHash())
+}
+
 func countStageOccurrences(operation ProvisioningOperation, stage string) int {
 	foundStages := 0
 	for _, v := range operation.FinishedStages {
@@ -0,0 +1,55 @@
+package operationretry
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler interface {
+	AttachRoutes(r router)
+}
+
+type handler struct {
+	service *Service
+	log     *slog.Logger
+}
+
+func NewHandler(service *Service, log *slog.Logger) Handler {
+	return &handler{
+		service: service,
+		log:     log.With("service", "OperationRetryEndpoint"),
+	}
+}
+
+func (h *handler) AttachRoutes(r router) {
+	r.HandleFunc("POST /operations/{operation_id}/retry", h.retryOperation)
+}
+
+func (h *handler) retryOperation(w http.ResponseWriter, req *http.Request) {
+	operationID := req.PathValue("operation_id")
+	logger := h.log.With("operationID", operationID)
+	logger.Info("Retry of the failed operation triggered")
+
+	result, err := h.service.Retry(operationID)
+	switch {
+	case err == nil:
+		httputil.WriteResponse(w, http.StatusAccepted, result)
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrNotRetryable):
+		logger.Warn(fmt.Sprintf("operation cannot be retried: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusConflict, err)
+	default:
+		logger.Error(fmt.Sprintf("retry failed: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
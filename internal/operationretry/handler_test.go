@@ -0,0 +1,180 @@
+package operationretry_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/operationretry"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	requestPathFormat = "/operations/%s/retry"
+	operationTimeout  = 24 * time.Hour
+)
+
+type fakeQueue struct {
+	added []string
+}
+
+func (f *fakeQueue) Add(processId string) {
+	f.added = append(f.added, processId)
+}
+
+func TestRetry(t *testing.T) {
+	newHandler := func(db storage.BrokerStorage, provisionQueue, updateQueue operationretry.Queue) http.Handler {
+		router := httputil.NewRouter()
+		service := operationretry.NewService(db, provisionQueue, updateQueue, operationTimeout, slog.Default())
+		operationretry.NewHandler(service, slog.Default()).AttachRoutes(router)
+		return router
+	}
+
+	t.Run("should retry failed provisioning from the failed stage", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		operation := fixFailedOperation(t, db, "op-1", "inst-1", internal.OperationTypeProvision)
+		provisionQueue, updateQueue := &fakeQueue{}, &fakeQueue{}
+		router := newHandler(db, provisionQueue, updateQueue)
+
+		// when
+		resp := callRetry(router, "op-1")
+
+		// then
+		require.Equal(t, http.StatusAccepted, resp.Code)
+		var result operationretry.Result
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, "create_runtime", result.RetriedStage)
+		assert.Equal(t, []string{"start"}, result.FinishedStages)
+		assert.Equal(t, "runtime creation failed", result.PreviousError)
+		assert.Equal(t, operation.CreatedAt.Add(operationTimeout).Unix(), result.Deadline.Unix())
+
+		assert.Equal(t, []string{"op-1"}, provisionQueue.added)
+		assert.Empty(t, updateQueue.added)
+
+		retried, err := db.Operations().GetOperationByID("op-1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, retried.State)
+		assert.Equal(t, "Operation retried from stage create_runtime", retried.Description)
+		assert.Empty(t, retried.LastError.Error())
+		assert.Equal(t, []string{"start"}, retried.FinishedStages)
+		require.Len(t, retried.StageTimes, 1)
+		assert.Equal(t, "start", retried.StageTimes[0].Name)
+
+		actions, err := db.Actions().ListActionsByInstanceID("inst-1")
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, pkg.OperationRetryActionType, actions[0].Type)
+		assert.Equal(t, "runtime creation failed", actions[0].OldValue)
+	})
+
+	t.Run("should retry failed update", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		fixFailedOperation(t, db, "op-1", "inst-1", internal.OperationTypeUpdate)
+		provisionQueue, updateQueue := &fakeQueue{}, &fakeQueue{}
+		router := newHandler(db, provisionQueue, updateQueue)
+
+		// when
+		resp := callRetry(router, "op-1")
+
+		// then
+		require.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Empty(t, provisionQueue.added)
+		assert.Equal(t, []string{"op-1"}, updateQueue.added)
+	})
+
+	t.Run("should receive 404 Not Found response", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		router := newHandler(db, &fakeQueue{}, &fakeQueue{})
+
+		// when
+		resp := callRetry(router, "not-existing")
+
+		// then
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	for name, modify := range map[string]func(t *testing.T, db storage.BrokerStorage, operation *internal.Operation){
+		"operation is not failed": func(t *testing.T, db storage.BrokerStorage, operation *internal.Operation) {
+			operation.State = domain.Succeeded
+		},
+		"operation is deprovisioning": func(t *testing.T, db storage.BrokerStorage, operation *internal.Operation) {
+			operation.Type = internal.OperationTypeDeprovision
+		},
+		"operation reached the time limit": func(t *testing.T, db storage.BrokerStorage, operation *internal.Operation) {
+			operation.CreatedAt = time.Now().Add(-operationTimeout - time.Minute)
+		},
+		"newer operation exists": func(t *testing.T, db storage.BrokerStorage, operation *internal.Operation) {
+			newer := fixture.FixOperation("op-2", operation.InstanceID, internal.OperationTypeUpdate)
+			newer.CreatedAt = operation.CreatedAt.Add(time.Minute)
+			require.NoError(t, db.Operations().InsertOperation(newer))
+		},
+		"instance is being deprovisioned": func(t *testing.T, db storage.BrokerStorage, operation *internal.Operation) {
+			instance, err := db.Instances().GetByID(operation.InstanceID)
+			require.NoError(t, err)
+			instance.DeletedAt = time.Now()
+			_, err = db.Instances().Update(*instance)
+			require.NoError(t, err)
+		},
+	} {
+		t.Run(fmt.Sprintf("should receive 409 Conflict response when %s", name), func(t *testing.T) {
+			// given
+			db := storage.NewMemoryStorage()
+			operation := fixFailedOperation(t, db, "op-1", "inst-1", internal.OperationTypeProvision)
+			modify(t, db, &operation)
+			_, err := db.Operations().UpdateOperation(operation)
+			require.NoError(t, err)
+			queue := &fakeQueue{}
+			router := newHandler(db, queue, queue)
+
+			// when
+			resp := callRetry(router, "op-1")
+
+			// then
+			assert.Equal(t, http.StatusConflict, resp.Code)
+			assert.Empty(t, queue.added)
+		})
+	}
+}
+
+func fixFailedOperation(t *testing.T, db storage.BrokerStorage, operationID, instanceID string, operationType internal.OperationType) internal.Operation {
+	require.NoError(t, db.Instances().Insert(fixture.FixInstance(instanceID)))
+
+	createdAt := time.Now().Add(-time.Hour)
+	startFinishedAt := createdAt.Add(time.Minute)
+	operation := fixture.FixOperation(operationID, instanceID, operationType)
+	operation.CreatedAt = createdAt
+	operation.State = domain.Failed
+	operation.Description = "Operation failed"
+	operation.LastError = kebError.LastError{}.SetMessage("runtime creation failed")
+	operation.FinishedStages = []string{"start"}
+	operation.StageTimes = []internal.StageTime{
+		{Name: "start", StartedAt: createdAt, FinishedAt: &startFinishedAt},
+		{Name: "create_runtime", StartedAt: startFinishedAt},
+	}
+	require.NoError(t, db.Operations().InsertOperation(operation))
+
+	stored, err := db.Operations().GetOperationByID(operationID)
+	require.NoError(t, err)
+	return *stored
+}
+
+func callRetry(router http.Handler, operationID string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, fmt.Sprintf(requestPathFormat, operationID), nil))
+	return resp
+}
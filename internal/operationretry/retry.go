@@ -0,0 +1,164 @@
+package operationretry
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+var (
+	ErrNotRetryable = errors.New("operation cannot be retried")
+)
+
+type Config struct {
+	Enabled bool `envconfig:"default=false"`
+}
+
+// Queue schedules the processing of the operation.
+type Queue interface {
+	Add(processId string)
+}
+
+type Result struct {
+	OperationID   string                 `json:"operationID"`
+	InstanceID    string                 `json:"instanceID"`
+	OperationType internal.OperationType `json:"operationType"`
+	// RetriedStage is the stage which failed, it is empty if the operation does not record the stage times
+	RetriedStage   string    `json:"retriedStage,omitempty"`
+	FinishedStages []string  `json:"finishedStages"`
+	PreviousError  string    `json:"previousError,omitempty"`
+	Deadline       time.Time `json:"deadline"`
+}
+
+// Service re-opens failed provisioning and update operations. The stages finished before the failure are not processed again,
+// the processing continues from the stage which failed.
+type Service struct {
+	instances        storage.Instances
+	operations       storage.Operations
+	actions          storage.Actions
+	queues           map[internal.OperationType]Queue
+	operationTimeout time.Duration
+	now              func() time.Time
+	log              *slog.Logger
+}
+
+func NewService(db storage.BrokerStorage, provisionQueue, updateQueue Queue, operationTimeout time.Duration, log *slog.Logger) *Service {
+	return &Service{
+		instances:  db.Instances(),
+		operations: db.Operations(),
+		actions:    db.Actions(),
+		queues: map[internal.OperationType]Queue{
+			internal.OperationTypeProvision: provisionQueue,
+			internal.OperationTypeUpdate:    updateQueue,
+		},
+		operationTimeout: operationTimeout,
+		now:              time.Now,
+		log:              log,
+	}
+}
+
+// Retry resets the failed stage of the operation, marks the operation as in progress and puts it back to the processing queue.
+func (s *Service) Retry(operationID string) (Result, error) {
+	operation, err := s.operations.GetOperationByID(operationID)
+	if err != nil {
+		return Result{}, err
+	}
+	log := s.log.With("operationID", operation.ID, "instanceID", operation.InstanceID)
+
+	if err := s.check(*operation); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		OperationID:    operation.ID,
+		InstanceID:     operation.InstanceID,
+		OperationType:  operation.Type,
+		RetriedStage:   failedStage(*operation),
+		FinishedStages: operation.FinishedStages,
+		PreviousError:  operation.LastError.Error(),
+		Deadline:       operation.CreatedAt.Add(s.operationTimeout),
+	}
+	if result.PreviousError == "" {
+		result.PreviousError = operation.Description
+	}
+
+	// the times of the failed stage are recorded again when the stage is processed
+	operation.StageTimes = slices.DeleteFunc(operation.StageTimes, func(stageTime internal.StageTime) bool {
+		return stageTime.Name == result.RetriedStage
+	})
+	operation.State = domain.InProgress
+	operation.Description = "Operation retried"
+	if result.RetriedStage != "" {
+		operation.Description = fmt.Sprintf("Operation retried from stage %s", result.RetriedStage)
+	}
+	operation.LastError = kebError.LastError{}
+	operation.ExcutedButNotCompleted = nil
+
+	updated, err := s.operations.UpdateOperation(*operation)
+	switch {
+	case dberr.IsConflict(err):
+		return Result{}, fmt.Errorf("%w: operation was modified in the meantime", ErrNotRetryable)
+	case err != nil:
+		return Result{}, fmt.Errorf("while updating operation %s: %w", operation.ID, err)
+	}
+
+	message := fmt.Sprintf("Failed %s operation %s retried from stage %q.", updated.Type, updated.ID, result.RetriedStage)
+	if err := s.actions.InsertAction(pkg.OperationRetryActionType, updated.InstanceID, message, result.PreviousError, string(domain.InProgress)); err != nil {
+		log.Error(fmt.Sprintf("while inserting action %q for instance ID %s: %v", pkg.OperationRetryActionType, updated.InstanceID, err))
+	}
+	events.Infof(updated.InstanceID, updated.ID, "Operation retried from stage %q", result.RetriedStage)
+
+	s.queues[updated.Type].Add(updated.ID)
+	log.Info(fmt.Sprintf("Failed %s operation retried from stage %q", updated.Type, result.RetriedStage))
+
+	return result, nil
+}
+
+func (s *Service) check(operation internal.Operation) error {
+	if _, supported := s.queues[operation.Type]; !supported {
+		return fmt.Errorf("%w: operations of the %s type cannot be retried", ErrNotRetryable, operation.Type)
+	}
+	if operation.State != domain.Failed {
+		return fmt.Errorf("%w: operation is in the %s state, only failed operations can be retried", ErrNotRetryable, operation.State)
+	}
+	if deadline := operation.CreatedAt.Add(s.operationTimeout); s.now().After(deadline) {
+		return fmt.Errorf("%w: operation reached the time limit at %s", ErrNotRetryable, deadline.Format(time.RFC3339))
+	}
+
+	instance, err := s.instances.GetByID(operation.InstanceID)
+	if err != nil {
+		return err
+	}
+	if !instance.DeletedAt.IsZero() {
+		return fmt.Errorf("%w: instance is being deprovisioned", ErrNotRetryable)
+	}
+	lastOperation, err := s.operations.GetLastOperationWithAllStates(operation.InstanceID)
+	if err != nil {
+		return fmt.Errorf("while getting last operation of instance %s: %w", operation.InstanceID, err)
+	}
+	if lastOperation.ID != operation.ID {
+		return fmt.Errorf("%w: operation %s was created after the failed operation", ErrNotRetryable, lastOperation.ID)
+	}
+	return nil
+}
+
+// failedStage returns the last started stage which was not finished
+func failedStage(operation internal.Operation) string {
+	for i := len(operation.StageTimes) - 1; i >= 0; i-- {
+		if stageTime := operation.StageTimes[i]; !operation.IsStageFinished(stageTime.Name) {
+			return stageTime.Name
+		}
+	}
+	return ""
+}
@@ -7,10 +7,11 @@ import (
 )
 
 type StepProcessed struct {
-	StepName string
-	Duration time.Duration
-	When     time.Duration
-	Error    error
+	StepName  string
+	StartedAt time.Time
+	Duration  time.Duration
+	When      time.Duration
+	Error     error
 }
 
 type ProvisioningStepProcessed struct {
@@ -42,6 +43,7 @@ type ProvisioningSucceeded struct {
 
 type OperationStepProcessed struct {
 	StepProcessed
+	StageName    string
 	OldOperation internal.Operation
 	Operation    internal.Operation
 }
@@ -0,0 +1,212 @@
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Position string
+
+const (
+	PositionBefore Position = "before"
+	PositionAfter  Position = "after"
+)
+
+type FailurePolicy string
+
+const (
+	// FailurePolicyFail fails the operation when the hook fails
+	FailurePolicyFail FailurePolicy = "fail"
+	// FailurePolicyIgnore marks the hook as executed but not completed and continues the operation
+	FailurePolicyIgnore FailurePolicy = "ignore"
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultMaxRetryTime = 10 * time.Minute
+
+	stepNamePrefix = "Hook_"
+)
+
+// Definition declares the hook called at the given point of the operation processing.
+type Definition struct {
+	Name           string        `yaml:"name"`
+	OperationTypes []string      `yaml:"operationTypes"`
+	Plans          []string      `yaml:"plans"`
+	Before         string        `yaml:"before"`
+	After          string        `yaml:"after"`
+	URL            string        `yaml:"url"`
+	TokenFilePath  string        `yaml:"tokenFilePath"`
+	Timeout        time.Duration `yaml:"timeout"`
+	MaxRetryTime   time.Duration `yaml:"maxRetryTime"`
+	FailurePolicy  FailurePolicy `yaml:"failurePolicy"`
+}
+
+// Hooks holds the step hooks definitions read from the configuration.
+type Hooks struct {
+	Definitions []Definition `yaml:"hooks"`
+}
+
+// StepAdder is implemented by the process.StagedManager
+type StepAdder interface {
+	AddStep(stageName string, step process.Step, cnd process.StepCondition) error
+}
+
+func NewHooksFromFile(filePath string) (*Hooks, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("while opening step hooks file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return NewHooks(file)
+}
+
+func NewHooks(r io.Reader) (*Hooks, error) {
+	hooks := &Hooks{}
+	if err := yaml.NewDecoder(r).Decode(hooks); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("while decoding step hooks: %w", err)
+	}
+	if err := hooks.validate(); err != nil {
+		return nil, err
+	}
+	for i := range hooks.Definitions {
+		hooks.Definitions[i].setDefaults()
+	}
+	return hooks, nil
+}
+
+func (h *Hooks) validate() error {
+	operationTypes := []string{string(internal.OperationTypeProvision), string(internal.OperationTypeDeprovision), string(internal.OperationTypeUpdate)}
+	names := map[string]struct{}{}
+	for _, d := range h.Definitions {
+		if d.Name == "" {
+			return fmt.Errorf("hook name must not be empty")
+		}
+		if _, found := names[d.Name]; found {
+			return fmt.Errorf("hook %s: name is not unique", d.Name)
+		}
+		names[d.Name] = struct{}{}
+
+		if len(d.OperationTypes) == 0 {
+			return fmt.Errorf("hook %s: operation types must not be empty", d.Name)
+		}
+		for _, operationType := range d.OperationTypes {
+			if !slices.Contains(operationTypes, operationType) {
+				return fmt.Errorf("hook %s: unknown operation type %s", d.Name, operationType)
+			}
+		}
+		for _, plan := range d.Plans {
+			if !broker.AvailablePlans.IsPlanName(plan) {
+				return fmt.Errorf("hook %s: unknown plan %s", d.Name, plan)
+			}
+		}
+		if (d.Before == "") == (d.After == "") {
+			return fmt.Errorf("hook %s: exactly one of before and after must be set", d.Name)
+		}
+		u, err := url.Parse(d.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("hook %s: URL must be an absolute HTTP or HTTPS URL", d.Name)
+		}
+		if d.Timeout < 0 || d.MaxRetryTime < 0 {
+			return fmt.Errorf("hook %s: timeout and max retry time must not be negative", d.Name)
+		}
+		switch d.FailurePolicy {
+		case "", FailurePolicyFail, FailurePolicyIgnore:
+		default:
+			return fmt.Errorf("hook %s: unknown failure policy %s", d.Name, d.FailurePolicy)
+		}
+	}
+	return nil
+}
+
+func (d *Definition) setDefaults() {
+	if d.Timeout == 0 {
+		d.Timeout = defaultTimeout
+	}
+	if d.MaxRetryTime == 0 {
+		d.MaxRetryTime = defaultMaxRetryTime
+	}
+	if d.FailurePolicy == "" {
+		d.FailurePolicy = FailurePolicyFail
+	}
+}
+
+// StepName returns the name of the step and the stage which run the hook
+func (d Definition) StepName() string {
+	return stepNamePrefix + d.Name
+}
+
+func (d Definition) position() (Position, string) {
+	if d.Before != "" {
+		return PositionBefore, d.Before
+	}
+	return PositionAfter, d.After
+}
+
+func (d Definition) appliesTo(operationType internal.OperationType) bool {
+	return slices.Contains(d.OperationTypes, string(operationType))
+}
+
+// Stages returns the stages with the stages of the hooks for the given operation type inserted before or after the target stages.
+// Hooks declared for the same point are inserted in the order of the definitions.
+func (h *Hooks) Stages(operationType internal.OperationType, stages []string) ([]string, error) {
+	before := map[string][]string{}
+	after := map[string][]string{}
+	for _, d := range h.Definitions {
+		if !d.appliesTo(operationType) {
+			continue
+		}
+		position, target := d.position()
+		if !slices.Contains(stages, target) {
+			return nil, fmt.Errorf("hook %s: stage %s is not defined for the %s operation", d.Name, target, operationType)
+		}
+		if position == PositionBefore {
+			before[target] = append(before[target], d.StepName())
+		} else {
+			after[target] = append(after[target], d.StepName())
+		}
+	}
+
+	result := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		result = append(result, before[stage]...)
+		result = append(result, stage)
+		result = append(result, after[stage]...)
+	}
+	return result, nil
+}
+
+// AddSteps adds the steps calling the hooks for the given operation type. The stages must be defined with the result of the Stages method.
+func (h *Hooks) AddSteps(manager StepAdder, operationType internal.OperationType, operations storage.Operations) error {
+	for _, d := range h.Definitions {
+		if !d.appliesTo(operationType) {
+			continue
+		}
+		if err := manager.AddStep(d.StepName(), NewStep(d, operations), d.condition()); err != nil {
+			return fmt.Errorf("while adding step for hook %s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+func (d Definition) condition() process.StepCondition {
+	if len(d.Plans) == 0 {
+		return nil
+	}
+	return func(operation internal.Operation) bool {
+		planName := broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(operation.ProvisioningParameters.PlanID))
+		return slices.Contains(d.Plans, planName)
+	}
+}
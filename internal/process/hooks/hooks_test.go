@@ -0,0 +1,144 @@
+package hooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const hooksYAML = `
+hooks:
+  - name: cmdb
+    operationTypes: [provision, update]
+    plans: [aws]
+    after: Create_Runtime_Resource
+    url: http://cmdb.example.com/hooks
+    timeout: 5s
+  - name: approval
+    operationTypes: [provision]
+    before: Create_Runtime_Resource
+    url: https://approval.example.com/hooks
+    failurePolicy: ignore
+  - name: inventory
+    operationTypes: [provision]
+    after: Create_Runtime_Resource
+    url: http://inventory.example.com/hooks
+`
+
+func TestNewHooks(t *testing.T) {
+	t.Run("should read hooks and set defaults", func(t *testing.T) {
+		// when
+		hooks, err := NewHooks(strings.NewReader(hooksYAML))
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, hooks.Definitions, 3)
+		assert.Equal(t, 5*time.Second, hooks.Definitions[0].Timeout)
+		assert.Equal(t, defaultMaxRetryTime, hooks.Definitions[0].MaxRetryTime)
+		assert.Equal(t, FailurePolicyFail, hooks.Definitions[0].FailurePolicy)
+		assert.Equal(t, defaultTimeout, hooks.Definitions[1].Timeout)
+		assert.Equal(t, FailurePolicyIgnore, hooks.Definitions[1].FailurePolicy)
+	})
+
+	t.Run("should return no hooks for empty configuration", func(t *testing.T) {
+		// when
+		hooks, err := NewHooks(strings.NewReader(""))
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, hooks.Definitions)
+	})
+
+	for name, content := range map[string]string{
+		"empty name":              "hooks:\n  - operationTypes: [provision]\n    after: Starting\n    url: http://hook\n",
+		"duplicated name":         "hooks:\n  - name: a\n    operationTypes: [provision]\n    after: Starting\n    url: http://hook\n  - name: a\n    operationTypes: [update]\n    after: cluster\n    url: http://hook\n",
+		"missing operation types": "hooks:\n  - name: a\n    after: Starting\n    url: http://hook\n",
+		"unknown operation type":  "hooks:\n  - name: a\n    operationTypes: [upgrade]\n    after: Starting\n    url: http://hook\n",
+		"unknown plan":            "hooks:\n  - name: a\n    operationTypes: [provision]\n    plans: [unknown]\n    after: Starting\n    url: http://hook\n",
+		"both positions":          "hooks:\n  - name: a\n    operationTypes: [provision]\n    before: Starting\n    after: Starting\n    url: http://hook\n",
+		"no position":             "hooks:\n  - name: a\n    operationTypes: [provision]\n    url: http://hook\n",
+		"relative URL":            "hooks:\n  - name: a\n    operationTypes: [provision]\n    after: Starting\n    url: /hook\n",
+		"gRPC URL":                "hooks:\n  - name: a\n    operationTypes: [provision]\n    after: Starting\n    url: grpc://hook\n",
+		"unknown failure policy":  "hooks:\n  - name: a\n    operationTypes: [provision]\n    after: Starting\n    url: http://hook\n    failurePolicy: retry\n",
+	} {
+		t.Run("should reject "+name, func(t *testing.T) {
+			// when
+			_, err := NewHooks(strings.NewReader(content))
+
+			// then
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHooks_Stages(t *testing.T) {
+	hooks, err := NewHooks(strings.NewReader(hooksYAML))
+	require.NoError(t, err)
+
+	t.Run("should insert hook stages before and after the target stage", func(t *testing.T) {
+		// when
+		stages, err := hooks.Stages(internal.OperationTypeProvision, []string{"Starting", "Create_Runtime_Resource", "Apply_Kyma"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Starting", "Hook_approval", "Create_Runtime_Resource", "Hook_cmdb", "Hook_inventory", "Apply_Kyma"}, stages)
+	})
+
+	t.Run("should not change stages of operation type without hooks", func(t *testing.T) {
+		// when
+		stages, err := hooks.Stages(internal.OperationTypeDeprovision, []string{"Init", "Remove_Instance"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Init", "Remove_Instance"}, stages)
+	})
+
+	t.Run("should return error when the target stage is not defined", func(t *testing.T) {
+		// when
+		_, err := hooks.Stages(internal.OperationTypeUpdate, []string{"cluster", "kyma_resource"})
+
+		// then
+		assert.ErrorContains(t, err, "stage Create_Runtime_Resource is not defined")
+	})
+}
+
+func TestHooks_AddSteps(t *testing.T) {
+	// given
+	hooks, err := NewHooks(strings.NewReader(hooksYAML))
+	require.NoError(t, err)
+	manager := &fakeStepAdder{conditions: map[string]process.StepCondition{}}
+
+	// when
+	err = hooks.AddSteps(manager, internal.OperationTypeProvision, nil)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Hook_cmdb", "Hook_approval", "Hook_inventory"}, manager.stages)
+	assert.Nil(t, manager.conditions["Hook_approval"])
+
+	awsOperation := fixture.FixProvisioningOperation("op-1", "inst-1")
+	awsOperation.ProvisioningParameters.PlanID = broker.AWSPlanID
+	trialOperation := fixture.FixProvisioningOperation("op-2", "inst-2")
+	trialOperation.ProvisioningParameters.PlanID = broker.TrialPlanID
+	assert.True(t, manager.conditions["Hook_cmdb"](awsOperation))
+	assert.False(t, manager.conditions["Hook_cmdb"](trialOperation))
+}
+
+type fakeStepAdder struct {
+	stages     []string
+	conditions map[string]process.StepCondition
+}
+
+func (f *fakeStepAdder) AddStep(stageName string, step process.Step, cnd process.StepCondition) error {
+	f.stages = append(f.stages, stageName)
+	f.conditions[step.Name()] = cnd
+	return nil
+}
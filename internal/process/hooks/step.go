@@ -0,0 +1,244 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
+)
+
+type Result string
+
+const (
+	ResultSucceeded Result = "succeeded"
+	ResultRetry     Result = "retry"
+	ResultFailed    Result = "failed"
+)
+
+const (
+	// defaultRetryInterval is used when the hook is not available or does not set the retry-after time
+	defaultRetryInterval = 10 * time.Second
+
+	maxErrorBodyLength = 1024
+)
+
+// Request is sent in the body of the POST request to the hook provider.
+type Request struct {
+	Hook      string    `json:"hook"`
+	Stage     string    `json:"stage"`
+	Position  Position  `json:"position"`
+	Operation Operation `json:"operation"`
+}
+
+// Operation is the operation data passed to the hook provider.
+type Operation struct {
+	ID              string                        `json:"id"`
+	Type            internal.OperationType        `json:"type"`
+	Temporary       bool                          `json:"temporary"`
+	InstanceID      string                        `json:"instanceID"`
+	RuntimeID       string                        `json:"runtimeID"`
+	GlobalAccountID string                        `json:"globalAccountID"`
+	SubAccountID    string                        `json:"subAccountID"`
+	PlanID          string                        `json:"planID"`
+	PlanName        string                        `json:"planName"`
+	PlatformRegion  string                        `json:"platformRegion"`
+	ShootName       string                        `json:"shootName"`
+	ShootDomain     string                        `json:"shootDomain"`
+	CreatedAt       time.Time                     `json:"createdAt"`
+	Parameters      pkg.ProvisioningParametersDTO `json:"parameters"`
+	// Metadata contains the metadata returned by the hooks which were already called for the operation
+	Metadata map[string]map[string]string `json:"metadata,omitempty"`
+}
+
+// Response is expected in the body of the 200 OK response from the hook provider.
+type Response struct {
+	Result            Result            `json:"result"`
+	RetryAfterSeconds int               `json:"retryAfterSeconds,omitempty"`
+	Message           string            `json:"message,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+}
+
+// unavailableError means the hook provider could not process the request, and the call can be repeated
+type unavailableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *unavailableError) Error() string {
+	return e.err.Error()
+}
+
+// Step calls the hook provider over HTTP. The result of the call is translated to the step result,
+// so the hook is retried by the staged manager in the same way as any other step.
+type Step struct {
+	definition       Definition
+	operationManager *process.OperationManager
+	client           *http.Client
+}
+
+func NewStep(definition Definition, operations storage.Operations) *Step {
+	return &Step{
+		definition:       definition,
+		operationManager: process.NewOperationManager(operations, definition.StepName(), kebError.StepHookDependency),
+		client:           tracing.WrapClient(&http.Client{}),
+	}
+}
+
+func (s *Step) Name() string {
+	return s.definition.StepName()
+}
+
+// Run calls the hook with the trace context stored in the operation
+func (s *Step) Run(operation internal.Operation, log *slog.Logger) (internal.Operation, time.Duration, error) {
+	return s.RunWithContext(tracing.Extract(context.Background(), operation.TraceContext), operation, log)
+}
+
+// RunWithContext implements process.ContextStep, the hook provider receives the trace context of the step span
+func (s *Step) RunWithContext(ctx context.Context, operation internal.Operation, log *slog.Logger) (internal.Operation, time.Duration, error) {
+	response, err := s.call(ctx, operation)
+	var unavailable *unavailableError
+	switch {
+	case errors.As(err, &unavailable):
+		log.Warn(fmt.Sprintf("hook %s is not available: %s", s.definition.Name, err))
+		return s.retry(operation, fmt.Sprintf("hook %s is not available", s.definition.Name), err, unavailable.retryAfter, log)
+	case err != nil:
+		return s.fail(operation, fmt.Sprintf("hook %s rejected the request", s.definition.Name), err, log)
+	}
+
+	switch response.Result {
+	case ResultSucceeded:
+		log.Info(fmt.Sprintf("hook %s succeeded: %s", s.definition.Name, response.Message))
+		if len(response.Metadata) == 0 {
+			return operation, 0, nil
+		}
+		return s.operationManager.UpdateOperation(operation, func(op *internal.Operation) {
+			if op.HookMetadata == nil {
+				op.HookMetadata = make(map[string]map[string]string)
+			}
+			op.HookMetadata[s.definition.Name] = response.Metadata
+		}, log)
+	case ResultRetry:
+		retryAfter := time.Duration(response.RetryAfterSeconds) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = defaultRetryInterval
+		}
+		log.Info(fmt.Sprintf("hook %s requested a retry in %s: %s", s.definition.Name, retryAfter, response.Message))
+		return s.retry(operation, fmt.Sprintf("hook %s has not finished", s.definition.Name), fmt.Errorf("%s", response.Message), retryAfter, log)
+	case ResultFailed:
+		return s.fail(operation, fmt.Sprintf("hook %s failed", s.definition.Name), fmt.Errorf("%s", response.Message), log)
+	default:
+		return s.fail(operation, fmt.Sprintf("hook %s returned an unknown result", s.definition.Name), fmt.Errorf("unknown result %q", response.Result), log)
+	}
+}
+
+func (s *Step) retry(operation internal.Operation, description string, err error, retryAfter time.Duration, log *slog.Logger) (internal.Operation, time.Duration, error) {
+	if s.definition.FailurePolicy == FailurePolicyIgnore {
+		return s.operationManager.RetryOperationWithoutFail(operation, s.Name(), description, retryAfter, s.definition.MaxRetryTime, log, err)
+	}
+	return s.operationManager.RetryOperation(operation, description, err, retryAfter, s.definition.MaxRetryTime, log)
+}
+
+func (s *Step) fail(operation internal.Operation, description string, err error, log *slog.Logger) (internal.Operation, time.Duration, error) {
+	if s.definition.FailurePolicy == FailurePolicyIgnore {
+		return s.operationManager.MarkStepAsExecutedButNotCompleted(operation, s.Name(), fmt.Sprintf("%s: %s", description, err), log)
+	}
+	return s.operationManager.OperationFailed(operation, description, err, log)
+}
+
+func (s *Step) call(ctx context.Context, operation internal.Operation) (Response, error) {
+	body, err := json.Marshal(s.request(operation))
+	if err != nil {
+		return Response{}, fmt.Errorf("while encoding hook request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.definition.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.definition.URL, bytes.NewReader(body))
+	if err != nil {
+		return Response{}, fmt.Errorf("while creating hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.definition.TokenFilePath != "" {
+		// the token is read on every call, so a rotated token is used without a restart
+		token, err := os.ReadFile(s.definition.TokenFilePath)
+		if err != nil {
+			return Response{}, &unavailableError{err: fmt.Errorf("while reading hook token: %w", err), retryAfter: defaultRetryInterval}
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Response{}, &unavailableError{err: fmt.Errorf("while calling hook: %w", err), retryAfter: defaultRetryInterval}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests:
+		return Response{}, &unavailableError{err: fmt.Errorf("hook responded with status %d", resp.StatusCode), retryAfter: retryAfter(resp)}
+	case resp.StatusCode != http.StatusOK:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		return Response{}, fmt.Errorf("hook responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return Response{}, fmt.Errorf("while decoding hook response: %w", err)
+	}
+	return response, nil
+}
+
+func (s *Step) request(operation internal.Operation) Request {
+	position, stage := s.definition.position()
+	parameters := operation.ProvisioningParameters.Parameters
+	// the kubeconfig of the own cluster must not leave KEB
+	parameters.Kubeconfig = ""
+
+	return Request{
+		Hook:     s.definition.Name,
+		Stage:    stage,
+		Position: position,
+		Operation: Operation{
+			ID:              operation.ID,
+			Type:            operation.Type,
+			Temporary:       operation.Temporary,
+			InstanceID:      operation.InstanceID,
+			RuntimeID:       operation.RuntimeID,
+			GlobalAccountID: operation.ProvisioningParameters.ErsContext.GlobalAccountID,
+			SubAccountID:    operation.ProvisioningParameters.ErsContext.SubAccountID,
+			PlanID:          operation.ProvisioningParameters.PlanID,
+			PlanName:        broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(operation.ProvisioningParameters.PlanID)),
+			PlatformRegion:  operation.ProvisioningParameters.PlatformRegion,
+			ShootName:       operation.ShootName,
+			ShootDomain:     operation.ShootDomain,
+			CreatedAt:       operation.CreatedAt,
+			Parameters:      parameters,
+			Metadata:        operation.HookMetadata,
+		},
+	}
+}
+
+// retryAfter returns the time from the Retry-After header given in seconds or the default retry interval
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return defaultRetryInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
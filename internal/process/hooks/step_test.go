@@ -0,0 +1,191 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStep_Run(t *testing.T) {
+	t.Run("should send the operation and store the returned metadata", func(t *testing.T) {
+		// given
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("secret-token\n"), 0o600))
+		var received Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			writeResponse(t, w, Response{Result: ResultSucceeded, Metadata: map[string]string{"ticket": "CMDB-1"}})
+		}))
+		defer server.Close()
+		step, db, operation := fixStep(t, Definition{Name: "cmdb", After: "Create_Runtime_Resource", URL: server.URL, TokenFilePath: tokenFile})
+
+		// when
+		operation, when, err := step.Run(operation, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.Equal(t, "cmdb", received.Hook)
+		assert.Equal(t, "Create_Runtime_Resource", received.Stage)
+		assert.Equal(t, PositionAfter, received.Position)
+		assert.Equal(t, "op-1", received.Operation.ID)
+		assert.Equal(t, internal.OperationTypeProvision, received.Operation.Type)
+		assert.Equal(t, "inst-1", received.Operation.InstanceID)
+		assert.Equal(t, "aws", received.Operation.PlanName)
+		assert.Empty(t, received.Operation.Parameters.Kubeconfig)
+
+		stored, err := db.Operations().GetOperationByID("op-1")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]string{"cmdb": {"ticket": "CMDB-1"}}, stored.HookMetadata)
+		assert.Equal(t, stored.HookMetadata, operation.HookMetadata)
+	})
+
+	t.Run("should retry after the time requested by the hook", func(t *testing.T) {
+		// given
+		server := fixServer(t, http.StatusOK, Response{Result: ResultRetry, RetryAfterSeconds: 30, Message: "waiting for approval"})
+		defer server.Close()
+		step, _, operation := fixStep(t, Definition{Name: "approval", Before: "Create_Runtime_Resource", URL: server.URL})
+
+		// when
+		operation, when, err := step.Run(operation, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, when)
+		assert.Equal(t, domain.InProgress, operation.State)
+	})
+
+	for name, status := range map[string]int{
+		"internal server error": http.StatusInternalServerError,
+		"too many requests":     http.StatusTooManyRequests,
+	} {
+		t.Run("should retry when the hook responds with "+name, func(t *testing.T) {
+			// given
+			server := fixServer(t, status, nil)
+			defer server.Close()
+			step, _, operation := fixStep(t, Definition{Name: "cmdb", After: "Create_Runtime_Resource", URL: server.URL})
+
+			// when
+			operation, when, err := step.Run(operation, fixLogger())
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, defaultRetryInterval, when)
+			assert.Equal(t, domain.InProgress, operation.State)
+		})
+	}
+
+	t.Run("should fail the operation when the retry time is exceeded", func(t *testing.T) {
+		// given
+		server := fixServer(t, http.StatusServiceUnavailable, nil)
+		defer server.Close()
+		step, _, operation := fixStep(t, Definition{Name: "cmdb", After: "Create_Runtime_Resource", URL: server.URL, MaxRetryTime: time.Nanosecond})
+		_, _, err := step.Run(operation, fixLogger())
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+
+		// when
+		operation, _, err = step.Run(operation, fixLogger())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, operation.State)
+	})
+
+	t.Run("should fail the operation when the hook fails", func(t *testing.T) {
+		// given
+		server := fixServer(t, http.StatusOK, Response{Result: ResultFailed, Message: "quota exceeded"})
+		defer server.Close()
+		step, _, operation := fixStep(t, Definition{Name: "cmdb", After: "Create_Runtime_Resource", URL: server.URL})
+
+		// when
+		operation, _, err := step.Run(operation, fixLogger())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, operation.State)
+		assert.Equal(t, "quota exceeded", operation.LastError.Error())
+	})
+
+	t.Run("should fail the operation when the hook rejects the request", func(t *testing.T) {
+		// given
+		server := fixServer(t, http.StatusBadRequest, nil)
+		defer server.Close()
+		step, _, operation := fixStep(t, Definition{Name: "cmdb", After: "Create_Runtime_Resource", URL: server.URL})
+
+		// when
+		operation, _, err := step.Run(operation, fixLogger())
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, domain.Failed, operation.State)
+	})
+
+	t.Run("should continue the operation when the hook with the ignore policy fails", func(t *testing.T) {
+		// given
+		server := fixServer(t, http.StatusOK, Response{Result: ResultFailed, Message: "quota exceeded"})
+		defer server.Close()
+		step, _, operation := fixStep(t, Definition{Name: "cmdb", After: "Create_Runtime_Resource", URL: server.URL, FailurePolicy: FailurePolicyIgnore})
+
+		// when
+		operation, when, err := step.Run(operation, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.Equal(t, domain.InProgress, operation.State)
+		assert.Equal(t, []string{"Hook_cmdb"}, operation.ExcutedButNotCompleted)
+	})
+}
+
+func fixStep(t *testing.T, definition Definition) (*Step, storage.BrokerStorage, internal.Operation) {
+	db := storage.NewMemoryStorage()
+	operation := fixture.FixProvisioningOperation("op-1", "inst-1")
+	operation.State = domain.InProgress
+	operation.ProvisioningParameters.PlanID = broker.AWSPlanID
+	operation.ProvisioningParameters.Parameters.Kubeconfig = "kubeconfig"
+	require.NoError(t, db.Operations().InsertOperation(operation))
+
+	definition.OperationTypes = []string{string(internal.OperationTypeProvision)}
+	definition.setDefaults()
+	return NewStep(definition, db.Operations()), db, operation
+}
+
+func fixServer(t *testing.T, status int, response any) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if response == nil {
+			w.WriteHeader(status)
+			_, _ = fmt.Fprint(w, http.StatusText(status))
+			return
+		}
+		writeResponse(t, w, response)
+	}))
+}
+
+func writeResponse(t *testing.T, w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(t, json.NewEncoder(w).Encode(response))
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+}
@@ -0,0 +1,282 @@
+package process
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultPriorityClass = "default"
+
+	// SuspensionOperationType matches the deprovisioning operations which suspend the instance
+	SuspensionOperationType = "suspension"
+)
+
+var queueClassDepthMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "kcp",
+	Subsystem: "keb_v2",
+	Name:      "queue_class_depth",
+	Help:      "Number of items currently waiting in the queue per priority class",
+}, []string{"queue_name", "class"})
+
+var queueClassWaitTimeMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "kcp",
+	Subsystem: "keb_v2",
+	Name:      "queue_class_wait_time_seconds",
+	Help:      "Time items of the priority class wait in the queue before a worker picks them up",
+	Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+}, []string{"queue_name", "class"})
+
+// PriorityRule assigns the class to operations of the given types and plans. An empty list matches everything.
+type PriorityRule struct {
+	OperationTypes []string `yaml:"operationTypes"`
+	Plans          []string `yaml:"plans"`
+	Class          string   `yaml:"class"`
+
+	// planIDs holds the IDs of the plans, operations store only the plan ID
+	planIDs []string
+}
+
+// PlanResolver returns the ID of the plan with the given name. It is implemented with broker.AvailablePlans
+// by the caller to avoid importing the broker package.
+type PlanResolver func(planName string) (planID string, found bool)
+
+// PriorityClasses defines the weights of the priority classes and the rules which assign operations to the classes.
+// A class with the weight 4 gets four times more workers' attention than a class with the weight 1, if both classes have waiting items.
+type PriorityClasses struct {
+	DefaultClass string         `yaml:"defaultClass"`
+	Classes      map[string]int `yaml:"classes"`
+	Rules        []PriorityRule `yaml:"rules"`
+}
+
+// Classifier returns the priority class of the operation.
+type Classifier func(operationID string) string
+
+func NewPriorityClassesFromFile(filePath string, plans PlanResolver) (*PriorityClasses, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("while opening priority classes file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return NewPriorityClasses(file, plans)
+}
+
+func NewPriorityClasses(r io.Reader, plans PlanResolver) (*PriorityClasses, error) {
+	classes := &PriorityClasses{}
+	if err := yaml.NewDecoder(r).Decode(classes); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("while decoding priority classes: %w", err)
+	}
+	if len(classes.Classes) == 0 {
+		if len(classes.Rules) > 0 {
+			return nil, fmt.Errorf("rules defined without classes")
+		}
+		return DefaultPriorityClasses(), nil
+	}
+	if err := classes.validate(plans); err != nil {
+		return nil, err
+	}
+	return classes, nil
+}
+
+// DefaultPriorityClasses returns a single class, which makes the queue process items in the FIFO order.
+func DefaultPriorityClasses() *PriorityClasses {
+	return &PriorityClasses{
+		DefaultClass: DefaultPriorityClass,
+		Classes:      map[string]int{DefaultPriorityClass: 1},
+	}
+}
+
+func (c *PriorityClasses) validate(plans PlanResolver) error {
+	for name, weight := range c.Classes {
+		if weight <= 0 {
+			return fmt.Errorf("class %s: weight must be greater than 0", name)
+		}
+	}
+	if _, found := c.Classes[c.DefaultClass]; !found {
+		return fmt.Errorf("default class %q is not defined", c.DefaultClass)
+	}
+	operationTypes := []string{string(internal.OperationTypeProvision), string(internal.OperationTypeDeprovision), string(internal.OperationTypeUpdate), SuspensionOperationType}
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if _, found := c.Classes[rule.Class]; !found {
+			return fmt.Errorf("rule %d: class %q is not defined", i, rule.Class)
+		}
+		for _, operationType := range rule.OperationTypes {
+			if !slices.Contains(operationTypes, operationType) {
+				return fmt.Errorf("rule %d: unknown operation type %s", i, operationType)
+			}
+		}
+		for _, plan := range rule.Plans {
+			planID, found := plans(plan)
+			if !found {
+				return fmt.Errorf("rule %d: unknown plan %s", i, plan)
+			}
+			rule.planIDs = append(rule.planIDs, planID)
+		}
+	}
+	return nil
+}
+
+// Classify returns the class of the first rule matching the operation or the default class.
+func (c *PriorityClasses) Classify(operation internal.Operation) string {
+	operationType := string(operation.Type)
+	if operation.Type == internal.OperationTypeDeprovision && operation.Temporary {
+		operationType = SuspensionOperationType
+	}
+
+	for _, rule := range c.Rules {
+		if len(rule.OperationTypes) > 0 && !slices.Contains(rule.OperationTypes, operationType) {
+			continue
+		}
+		if len(rule.Plans) > 0 && !slices.Contains(rule.planIDs, operation.ProvisioningParameters.PlanID) {
+			continue
+		}
+		return rule.Class
+	}
+	return c.DefaultClass
+}
+
+// NewOperationClassifier returns the classifier which reads the operation from the storage.
+// The default class is returned if the operation cannot be read.
+func NewOperationClassifier(operations storage.Operations, classes *PriorityClasses, log *slog.Logger) Classifier {
+	return func(operationID string) string {
+		operation, err := operations.GetOperationByID(operationID)
+		if err != nil {
+			log.Warn(fmt.Sprintf("unable to get operation %s to assign the priority class, using class %s: %s", operationID, classes.DefaultClass, err))
+			return classes.DefaultClass
+		}
+		return classes.Classify(*operation)
+	}
+}
+
+// weightedFairQueue implements the workqueue.Queue interface. It keeps a FIFO queue per class and picks the class
+// with the smooth weighted round-robin algorithm, so every class with waiting items gets its share of the workers
+// and a burst in one class cannot starve the others.
+type weightedFairQueue struct {
+	mu sync.Mutex
+
+	classes  *PriorityClasses
+	names    []string
+	items    map[string][]string
+	current  map[string]int
+	assigned map[string]string
+	pushedAt map[string]time.Time
+	length   int
+
+	depthGauges map[string]prometheus.Gauge
+	waitTime    map[string]prometheus.Observer
+}
+
+func newWeightedFairQueue(queueName string, classes *PriorityClasses) *weightedFairQueue {
+	q := &weightedFairQueue{
+		classes:     classes,
+		items:       make(map[string][]string, len(classes.Classes)),
+		current:     make(map[string]int, len(classes.Classes)),
+		assigned:    make(map[string]string),
+		pushedAt:    make(map[string]time.Time),
+		depthGauges: make(map[string]prometheus.Gauge, len(classes.Classes)),
+		waitTime:    make(map[string]prometheus.Observer, len(classes.Classes)),
+	}
+	for name := range classes.Classes {
+		q.names = append(q.names, name)
+		q.depthGauges[name] = queueClassDepthMetric.WithLabelValues(queueName, name)
+		q.waitTime[name] = queueClassWaitTimeMetric.WithLabelValues(queueName, name)
+	}
+	// the order of the classes must be stable to break ties in the same way every time
+	sort.Strings(q.names)
+	return q
+}
+
+// assign sets the class used when the item is pushed to the queue
+func (q *weightedFairQueue) assign(item, class string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.assigned[item] = class
+}
+
+func (q *weightedFairQueue) isAssigned(item string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, found := q.assigned[item]
+	return found
+}
+
+// release removes the class assigned to the item, which is not going to be processed again
+func (q *weightedFairQueue) release(item string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.assigned, item)
+}
+
+func (q *weightedFairQueue) Touch(item string) {}
+
+func (q *weightedFairQueue) Push(item string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	class, found := q.assigned[item]
+	if !found {
+		class = q.classes.DefaultClass
+	}
+	q.items[class] = append(q.items[class], item)
+	q.pushedAt[item] = time.Now()
+	q.length++
+	q.depthGauges[class].Set(float64(len(q.items[class])))
+}
+
+func (q *weightedFairQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.length
+}
+
+// Pop is called by the workqueue only if the queue is not empty
+func (q *weightedFairQueue) Pop() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	selected, total := "", 0
+	for _, name := range q.names {
+		if len(q.items[name]) == 0 {
+			continue
+		}
+		weight := q.classes.Classes[name]
+		q.current[name] += weight
+		total += weight
+		if selected == "" || q.current[name] > q.current[selected] {
+			selected = name
+		}
+	}
+	if selected == "" {
+		return ""
+	}
+	q.current[selected] -= total
+
+	item := q.items[selected][0]
+	q.items[selected][0] = ""
+	q.items[selected] = q.items[selected][1:]
+	q.length--
+	q.depthGauges[selected].Set(float64(len(q.items[selected])))
+	q.waitTime[selected].Observe(time.Since(q.pushedAt[item]).Seconds())
+	delete(q.pushedAt, item)
+	// a class without waiting items does not collect credits for later
+	if len(q.items[selected]) == 0 {
+		q.current[selected] = 0
+	}
+
+	return item
+}
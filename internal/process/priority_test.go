@@ -0,0 +1,161 @@
+package process
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const priorityClassesYAML = `
+defaultClass: paid
+classes:
+  critical: 8
+  paid: 4
+  trial: 1
+rules:
+  - operationTypes: [deprovision]
+    class: critical
+  - plans: [trial, free]
+    class: trial
+`
+
+func fixPlanResolver(planName string) (string, bool) {
+	planID, found := broker.AvailablePlans.GetPlanIDByName(broker.PlanNameType(planName))
+	return string(planID), found
+}
+
+func TestPriorityClasses(t *testing.T) {
+	classes, err := NewPriorityClasses(strings.NewReader(priorityClassesYAML), fixPlanResolver)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		operationType internal.OperationType
+		temporary     bool
+		planID        string
+		expected      string
+	}{
+		"deprovisioning of trial": {operationType: internal.OperationTypeDeprovision, planID: broker.TrialPlanID, expected: "critical"},
+		"suspension of trial":     {operationType: internal.OperationTypeDeprovision, temporary: true, planID: broker.TrialPlanID, expected: "trial"},
+		"provisioning of trial":   {operationType: internal.OperationTypeProvision, planID: broker.TrialPlanID, expected: "trial"},
+		"provisioning of aws":     {operationType: internal.OperationTypeProvision, planID: broker.AWSPlanID, expected: "paid"},
+		"update of free":          {operationType: internal.OperationTypeUpdate, planID: broker.FreemiumPlanID, expected: "trial"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			operation := fixture.FixOperation("op-1", "inst-1", tc.operationType)
+			operation.Temporary = tc.temporary
+			operation.ProvisioningParameters.PlanID = tc.planID
+
+			// when
+			class := classes.Classify(operation)
+
+			// then
+			assert.Equal(t, tc.expected, class)
+		})
+	}
+
+	t.Run("should return a single default class for empty configuration", func(t *testing.T) {
+		// when
+		classes, err := NewPriorityClasses(strings.NewReader(""), fixPlanResolver)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPriorityClasses(), classes)
+	})
+
+	for name, content := range map[string]string{
+		"undefined default class": "defaultClass: other\nclasses:\n  paid: 1\n",
+		"non-positive weight":     "defaultClass: paid\nclasses:\n  paid: 0\n",
+		"undefined rule class":    "defaultClass: paid\nclasses:\n  paid: 1\nrules:\n  - class: other\n",
+		"unknown operation type":  "defaultClass: paid\nclasses:\n  paid: 1\nrules:\n  - operationTypes: [upgrade]\n    class: paid\n",
+		"unknown plan":            "defaultClass: paid\nclasses:\n  paid: 1\nrules:\n  - plans: [unknown]\n    class: paid\n",
+		"rules without classes":   "rules:\n  - class: paid\n",
+	} {
+		t.Run("should reject "+name, func(t *testing.T) {
+			// when
+			_, err := NewPriorityClasses(strings.NewReader(content), fixPlanResolver)
+
+			// then
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOperationClassifier(t *testing.T) {
+	// given
+	classes, err := NewPriorityClasses(strings.NewReader(priorityClassesYAML), fixPlanResolver)
+	require.NoError(t, err)
+	db := storage.NewMemoryStorage()
+	operation := fixture.FixProvisioningOperation("op-1", "inst-1")
+	operation.ProvisioningParameters.PlanID = broker.TrialPlanID
+	require.NoError(t, db.Operations().InsertOperation(operation))
+	classifier := NewOperationClassifier(db.Operations(), classes, fixLogger())
+
+	// when / then
+	assert.Equal(t, "trial", classifier("op-1"))
+	assert.Equal(t, "paid", classifier("not-existing"))
+}
+
+func TestWeightedFairQueue(t *testing.T) {
+	t.Run("should share the items between the classes according to their weights", func(t *testing.T) {
+		// given
+		classes, err := NewPriorityClasses(strings.NewReader(priorityClassesYAML), fixPlanResolver)
+		require.NoError(t, err)
+		q := newWeightedFairQueue("wfq-test-weights", classes)
+		for _, item := range []string{"t1", "t2", "t3", "t4", "t5"} {
+			q.assign(item, "trial")
+			q.Push(item)
+		}
+		for _, item := range []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8"} {
+			q.assign(item, "paid")
+			q.Push(item)
+		}
+
+		// when
+		var popped []string
+		for q.Len() > 0 {
+			popped = append(popped, q.Pop())
+		}
+
+		// then
+		assert.Equal(t, []string{"p1", "p2", "t1", "p3", "p4", "p5", "p6", "t2", "p7", "p8", "t3", "t4", "t5"}, popped)
+	})
+
+	t.Run("should keep the FIFO order within the class", func(t *testing.T) {
+		// given
+		q := newWeightedFairQueue("wfq-test-fifo", DefaultPriorityClasses())
+		for _, item := range []string{"a", "b", "c"} {
+			q.Push(item)
+		}
+
+		// when / then
+		assert.Equal(t, "a", q.Pop())
+		q.Push("d")
+		assert.Equal(t, "b", q.Pop())
+		assert.Equal(t, "c", q.Pop())
+		assert.Equal(t, "d", q.Pop())
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("should use the default class for items without assigned class", func(t *testing.T) {
+		// given
+		classes, err := NewPriorityClasses(strings.NewReader(priorityClassesYAML), fixPlanResolver)
+		require.NoError(t, err)
+		q := newWeightedFairQueue("wfq-test-default", classes)
+		q.assign("c1", "critical")
+		q.release("c1")
+
+		// when
+		q.Push("c1")
+
+		// then
+		assert.Equal(t, []string{"c1"}, q.items["paid"])
+	})
+}
@@ -0,0 +1,80 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebErr "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/kymastatus"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/steps"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const kymaReadyRetryInterval = 30 * time.Second
+
+var kymaGVK = schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "Kyma"}
+
+// CheckKymaReadyStep waits until lifecycle-manager reports the Kyma resource Ready, so the provisioning succeeds
+// only when the modules are installed
+type CheckKymaReadyStep struct {
+	operationManager *process.OperationManager
+	k8sClient        client.Client
+	timeout          time.Duration
+}
+
+var _ process.Step = &CheckKymaReadyStep{}
+
+func NewCheckKymaReadyStep(os storage.Operations, cli client.Client, timeout time.Duration) *CheckKymaReadyStep {
+	step := &CheckKymaReadyStep{k8sClient: cli, timeout: timeout}
+	step.operationManager = process.NewOperationManager(os, step.Name(), kebErr.LifeCycleManagerDependency)
+	return step
+}
+
+func (s *CheckKymaReadyStep) Name() string {
+	return "Check_Kyma_Ready"
+}
+
+func (s *CheckKymaReadyStep) Run(operation internal.Operation, logger *slog.Logger) (internal.Operation, time.Duration, error) {
+	kyma := &unstructured.Unstructured{}
+	kyma.SetGroupVersionKind(kymaGVK)
+	err := s.k8sClient.Get(context.Background(), client.ObjectKey{
+		Namespace: operation.KymaResourceNamespace,
+		Name:      steps.KymaName(operation),
+	}, kyma)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("unable to get Kyma resource: %s", err))
+		return s.operationManager.RetryOperation(operation, "unable to get the Kyma resource", err, kymaReadyRetryInterval, s.timeout, logger)
+	}
+
+	status := kymastatus.FromUnstructured(kyma)
+	if status.State == kymastatus.StateReady {
+		logger.Info("Kyma resource is Ready")
+		return operation, 0, nil
+	}
+
+	description := fmt.Sprintf("Waiting for the Kyma resource to be %s, current state: %s", kymastatus.StateReady, status.State)
+	if notReady := kymastatus.NotReadyModules(status); len(notReady) > 0 {
+		description = fmt.Sprintf("%s, modules not ready: %s", description, strings.Join(notReady, ", "))
+	}
+	logger.Info(description)
+	if operation.Description != description {
+		var backoff time.Duration
+		operation, backoff, _ = s.operationManager.UpdateOperation(operation, func(op *internal.Operation) {
+			op.Description = description
+		}, logger)
+		if backoff != 0 {
+			logger.Error("cannot save the operation")
+			return operation, 5 * time.Second, nil
+		}
+	}
+	return s.operationManager.RetryOperation(operation, description, nil, kymaReadyRetryInterval, s.timeout, logger)
+}
@@ -0,0 +1,79 @@
+package provisioning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckKymaReadyStep(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		state               string
+		modules             []interface{}
+		timeout             time.Duration
+		expectedBackoff     bool
+		expectedState       domain.LastOperationState
+		expectedDescription string
+	}{
+		"should succeed when Kyma is Ready": {
+			state:         "Ready",
+			timeout:       time.Hour,
+			expectedState: domain.InProgress,
+		},
+		"should wait for the modules": {
+			state: "Processing",
+			modules: []interface{}{
+				map[string]interface{}{"name": "serverless", "state": "Error", "channel": "regular", "version": "1.2.0"},
+				map[string]interface{}{"name": "btp-operator", "state": "Ready", "channel": "regular", "version": "1.1.0"},
+			},
+			timeout:             time.Hour,
+			expectedBackoff:     true,
+			expectedState:       domain.InProgress,
+			expectedDescription: "Waiting for the Kyma resource to be Ready, current state: Processing, modules not ready: serverless (Error)",
+		},
+		"should fail after the timeout": {
+			state:         "Error",
+			timeout:       -time.Second,
+			expectedState: domain.Failed,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			operation := fixture.FixOperation("op-id", "inst-id", internal.OperationTypeProvision)
+			operation.KymaResourceNamespace = "kyma-system"
+			operation.KymaResourceName = "runtime-id"
+			operation.State = domain.InProgress
+			st := storage.NewMemoryStorage()
+			require.NoError(t, st.Operations().InsertOperation(operation))
+
+			kyma := &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{"state": tc.state, "modules": tc.modules},
+			}}
+			kyma.SetGroupVersionKind(kymaGVK)
+			kyma.SetName("runtime-id")
+			kyma.SetNamespace("kyma-system")
+			cli := fake.NewClientBuilder().WithRuntimeObjects(kyma).Build()
+
+			step := NewCheckKymaReadyStep(st.Operations(), cli, tc.timeout)
+
+			// when
+			operation, backoff, _ := step.Run(operation, fixLogger())
+
+			// then
+			assert.Equal(t, tc.expectedBackoff, backoff > 0)
+			assert.Equal(t, tc.expectedState, operation.State)
+			if tc.expectedDescription != "" {
+				assert.Equal(t, tc.expectedDescription, operation.Description)
+			}
+		})
+	}
+}
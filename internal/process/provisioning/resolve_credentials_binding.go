@@ -15,6 +15,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 )
 
@@ -26,6 +27,7 @@ type ResolveCredentialsBindingStep struct {
 	stepRetryTuple     internal.RetryTuple
 	mu                 sync.Mutex
 	multiAccountConfig *multiaccount.MultiAccountConfig
+	residencyPolicies  *residency.Policies
 }
 
 func NewResolveCredentialsBindingStep(brokerStorage storage.BrokerStorage, gardenerClient *gardener.Client, rulesService *rules.RulesService, stepRetryTuple internal.RetryTuple, multiAccountConfig *multiaccount.MultiAccountConfig) *ResolveCredentialsBindingStep {
@@ -35,11 +37,18 @@ func NewResolveCredentialsBindingStep(brokerStorage storage.BrokerStorage, garde
 		rulesService:       rulesService,
 		stepRetryTuple:     stepRetryTuple,
 		multiAccountConfig: multiAccountConfig,
+		residencyPolicies:  residency.DefaultPolicies(),
 	}
 	step.operationManager = process.NewOperationManager(brokerStorage.Operations(), step.Name(), kebError.AccountPoolDependency)
 	return step
 }
 
+// WithResidencyPolicies replaces the default residency policies the matched HAP rule is checked against.
+func (s *ResolveCredentialsBindingStep) WithResidencyPolicies(policies *residency.Policies) *ResolveCredentialsBindingStep {
+	s.residencyPolicies = policies
+	return s
+}
+
 func (s *ResolveCredentialsBindingStep) Name() string {
 	return "Resolve_Credentials_Binding"
 }
@@ -86,6 +95,10 @@ func (s *ResolveCredentialsBindingStep) resolveSecretName(operation internal.Ope
 
 	log.Info(fmt.Sprintf("matched rule: %q", parsedRule.Rule()))
 
+	if err := s.residencyPolicies.CheckHAPEUAccess(attr.PlatformRegion, attr.Plan, parsedRule.IsEUAccess(), parsedRule.Rule()); err != nil {
+		return "", err
+	}
+
 	labelSelectorBuilder := subscriptions.NewLabelSelectorFromRuleset(parsedRule)
 	selectorForExistingSubscription := labelSelectorBuilder.BuildForTenantMatching(operation.ProvisioningParameters.ErsContext.GlobalAccountID)
 
@@ -1,10 +1,12 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -27,6 +29,14 @@ type Queue struct {
 	speedFactor       int64
 	workersInUseGauge prometheus.Gauge
 	queueDepthGauge   prometheus.Gauge
+
+	// workers is the number of started workers, runningWorkers is the number of workers which have not stopped
+	workers        atomic.Int64
+	runningWorkers atomic.Int64
+
+	// priority and classifier are set only for queues with priority classes
+	priority   *weightedFairQueue
+	classifier Classifier
 }
 
 var queueWorkersInUseMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
@@ -44,9 +54,31 @@ var queueDepthMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
 }, []string{"queue_name"})
 
 func NewQueue(executor Executor, log *slog.Logger, name string) *Queue {
+	return newQueue(executor, log, name, workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.DefaultTypedControllerRateLimiter[string](), workqueue.TypedRateLimitingQueueConfig[string]{Name: "operations"}))
+}
+
+// NewPriorityQueue creates the queue which assigns the items to the priority classes and shares the workers between the classes according to their weights.
+func NewPriorityQueue(executor Executor, log *slog.Logger, name string, classes *PriorityClasses, classifier Classifier) *Queue {
+	priority := newWeightedFairQueue(name, classes)
+	q := newQueue(executor, log, name, workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.DefaultTypedControllerRateLimiter[string](), workqueue.TypedRateLimitingQueueConfig[string]{
+		Name: "operations",
+		DelayingQueue: workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[string]{
+			Name: "operations",
+			Queue: workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[string]{
+				Name:  "operations",
+				Queue: priority,
+			}),
+		}),
+	}))
+	q.priority = priority
+	q.classifier = classifier
+	return q
+}
+
+func newQueue(executor Executor, log *slog.Logger, name string, queue workqueue.TypedRateLimitingInterface[string]) *Queue {
 	// add queue name field that could be logged later on
 	return &Queue{
-		queue:             workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.DefaultTypedControllerRateLimiter[string](), workqueue.TypedRateLimitingQueueConfig[string]{Name: "operations"}),
+		queue:             queue,
 		executor:          executor,
 		waitGroup:         sync.WaitGroup{},
 		log:               log.With("queueName", name),
@@ -58,6 +90,7 @@ func NewQueue(executor Executor, log *slog.Logger, name string) *Queue {
 }
 
 func (q *Queue) Add(processId string) {
+	q.classify(processId)
 	q.queue.Add(processId)
 	queueLen := q.queue.Len()
 	q.queueDepthGauge.Set(float64(queueLen))
@@ -65,6 +98,7 @@ func (q *Queue) Add(processId string) {
 }
 
 func (q *Queue) AddAfter(processId string, duration time.Duration) {
+	q.classify(processId)
 	q.queue.AddAfter(processId, duration)
 	queueLen := q.queue.Len()
 	q.queueDepthGauge.Set(float64(queueLen))
@@ -79,6 +113,8 @@ func (q *Queue) ShutDown() {
 func (q *Queue) Run(stop <-chan struct{}, workersAmount int) {
 	for i := 0; i < workersAmount; i++ {
 		q.waitGroup.Add(1)
+		q.workers.Add(1)
+		q.runningWorkers.Add(1)
 
 		workerLogger := q.log.With("workerId", i)
 
@@ -87,6 +123,15 @@ func (q *Queue) Run(stop <-chan struct{}, workersAmount int) {
 
 }
 
+// classify assigns the class to the item when it is enqueued for the first time. The class is kept until the item is processed,
+// so the operation is not read from the storage again when the item is added back to the queue.
+func (q *Queue) classify(processId string) {
+	if q.priority == nil || q.priority.isAssigned(processId) {
+		return
+	}
+	q.priority.assign(processId, q.classifier(processId))
+}
+
 // SpeedUp changes speedFactor parameter to reduce time between processing operations.
 // This method should only be used for testing purposes
 func (q *Queue) SpeedUp(speedFactor int64) {
@@ -97,10 +142,23 @@ func (q *Queue) SpeedUp(speedFactor int64) {
 func (q *Queue) createWorker(queue workqueue.TypedRateLimitingInterface[string], process func(id string) (time.Duration, error), stopCh <-chan struct{}, waitGroup *sync.WaitGroup, log *slog.Logger, nameId string) {
 	go func() {
 		wait.Until(q.worker(queue, process, log, nameId), time.Second, stopCh)
+		q.runningWorkers.Add(-1)
 		waitGroup.Done()
 	}()
 }
 
+// CheckWorkers returns an error if the workers are not started or any of the workers stopped, used as the readiness check
+func (q *Queue) CheckWorkers(_ context.Context) error {
+	workers, running := q.workers.Load(), q.runningWorkers.Load()
+	switch {
+	case workers == 0:
+		return fmt.Errorf("workers of the queue %s are not started", q.name)
+	case running < workers:
+		return fmt.Errorf("%d of %d workers of the queue %s stopped", workers-running, workers, q.name)
+	}
+	return nil
+}
+
 func (q *Queue) worker(queue workqueue.TypedRateLimitingInterface[string], process func(key string) (time.Duration, error), log *slog.Logger, workerNameId string) func() {
 	return func() {
 		exit := false
@@ -141,6 +199,9 @@ func (q *Queue) worker(queue workqueue.TypedRateLimitingInterface[string], proce
 				}
 
 				queue.Forget(key)
+				if q.priority != nil {
+					q.priority.release(key)
+				}
 				workerLogger.Info(fmt.Sprintf("item for %s has been processed, no retry, element forgotten", id))
 
 				return false
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -60,6 +61,30 @@ func TestQueueDepthMetric(t *testing.T) {
 	q.waitGroup.Wait()
 }
 
+func TestQueueCheckWorkers(t *testing.T) {
+	// given
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	q := NewQueue(&StdExecutor{logger: func(_ string) {}}, logger, "check-workers")
+
+	// then
+	assert.EqualError(t, q.CheckWorkers(context.Background()), "workers of the queue check-workers are not started")
+
+	// when
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Run(ctx.Done(), 2)
+
+	// then
+	assert.NoError(t, q.CheckWorkers(context.Background()))
+
+	// when
+	cancel()
+	q.ShutDown()
+	q.waitGroup.Wait()
+
+	// then
+	assert.EqualError(t, q.CheckWorkers(context.Background()), "2 of 2 workers of the queue check-workers stopped")
+}
+
 func TestWorkerLogging(t *testing.T) {
 
 	t.Run("should not log duplicated operationID", func(t *testing.T) {
@@ -96,6 +121,63 @@ func TestWorkerLogging(t *testing.T) {
 
 }
 
+func TestPriorityQueue(t *testing.T) {
+	// given
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	classes, err := NewPriorityClasses(strings.NewReader(priorityClassesYAML), fixPlanResolver)
+	require.NoError(t, err)
+
+	var processed []string
+	var mu sync.Mutex
+	var waitForProcessing sync.WaitGroup
+	queue := NewPriorityQueue(&StdExecutor{logger: func(msg string) {
+		mu.Lock()
+		processed = append(processed, msg)
+		mu.Unlock()
+		waitForProcessing.Done()
+	}}, logger, fmt.Sprintf("priority-test-%d", time.Now().UnixNano()), classes, func(operationID string) string {
+		return strings.SplitN(operationID, "-", 2)[0]
+	})
+
+	waitForProcessing.Add(3)
+	queue.Add("trial-1")
+	queue.Add("paid-1")
+	queue.Add("critical-1")
+
+	// when
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.Run(ctx.Done(), 1)
+	waitForProcessing.Wait()
+	cancel()
+	queue.ShutDown()
+	queue.waitGroup.Wait()
+
+	// then
+	assert.Equal(t, []string{"executing operation critical-1", "executing operation paid-1", "executing operation trial-1"}, processed)
+	assert.Empty(t, queue.priority.assigned)
+}
+
+func TestPriorityQueue_ClassifiesItemOnce(t *testing.T) {
+	// given
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	classes, err := NewPriorityClasses(strings.NewReader(priorityClassesYAML), fixPlanResolver)
+	require.NoError(t, err)
+	calls := 0
+	queue := NewPriorityQueue(&StdExecutor{logger: func(string) {}}, logger, fmt.Sprintf("priority-once-test-%d", time.Now().UnixNano()), classes, func(operationID string) string {
+		calls++
+		return "paid"
+	})
+
+	// when
+	queue.Add("op-1")
+	queue.Add("op-1")
+	queue.AddAfter("op-1", time.Millisecond)
+
+	// then
+	assert.Equal(t, 1, calls)
+	queue.ShutDown()
+}
+
 type captureWriter struct {
 	buf *bytes.Buffer
 }
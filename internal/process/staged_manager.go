@@ -12,10 +12,17 @@ import (
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 
 	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/kyma-project/kyma-environment-broker/internal/process"
+
 type StagedManager struct {
 	log              *slog.Logger
 	operationStorage storage.Operations
@@ -43,6 +50,12 @@ type Step interface {
 	Run(operation internal.Operation, logger *slog.Logger) (internal.Operation, time.Duration, error)
 }
 
+// ContextStep is a step which receives the context of the step span, so the calls made by the step continue the trace of the operation
+type ContextStep interface {
+	Step
+	RunWithContext(ctx context.Context, operation internal.Operation, logger *slog.Logger) (internal.Operation, time.Duration, error)
+}
+
 type StepCondition func(operation internal.Operation) bool
 
 type StepWithCondition struct {
@@ -114,6 +127,17 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 
 	logOperation := m.log.With("operationID", operationID, "instanceID", operation.InstanceID, "planID", operation.ProvisioningParameters.PlanID)
 	logOperation.Info(fmt.Sprintf("Start process operation steps for GlobalAccount=%s, ", operation.ProvisioningParameters.ErsContext.GlobalAccountID))
+
+	// every processing of the operation continues the trace of the request which created the operation
+	ctx, span := otel.Tracer(tracerName).Start(tracing.Extract(context.Background(), operation.TraceContext), fmt.Sprintf("process %s operation", operation.Type),
+		trace.WithAttributes(
+			attribute.String("kyma.operation.id", operation.ID),
+			attribute.String("kyma.operation.type", string(operation.Type)),
+			attribute.String("kyma.instance.id", operation.InstanceID),
+			attribute.String("kyma.plan.id", operation.ProvisioningParameters.PlanID),
+		))
+	defer span.End()
+
 	if time.Since(operation.CreatedAt) > m.operationTimeout {
 		timeoutErr := kebError.TimeoutError("operation has reached the time limit", string(kebError.KEBDependency))
 		operation.LastError = timeoutErr
@@ -121,6 +145,7 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 		logOperation.Info(fmt.Sprintf("operation has reached the time limit: operation was created at: %s, timeout: %s elapsed %s",
 			operation.CreatedAt.Format(time.RFC3339Nano), m.operationTimeout.String(), time.Since(operation.CreatedAt).String()))
 		operation.State = domain.Failed
+		span.SetStatus(codes.Error, timeoutErr.Error())
 		_, err = m.operationStorage.UpdateOperation(*operation)
 		if err != nil {
 			logOperation.Info("Unable to save operation with finished the provisioning process")
@@ -139,6 +164,7 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 		if processedOperation.IsStageFinished(stage.name) {
 			continue
 		}
+		processedOperation.StartStage(stage.name)
 
 		for _, step := range stage.steps {
 			logStep := logOperation.With("step", step.Name()).
@@ -149,9 +175,12 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 			}
 			operation.EventInfof("processing step: %v", step.Name())
 
-			processedOperation, when, err = m.runStep(step, processedOperation, logStep)
+			stepStart := time.Now()
+			processedOperation, when, err = m.runStep(ctx, stage.name, step.Step, processedOperation, logStep)
+			processedOperation.AddStepDuration(stage.name, step.Name(), time.Since(stepStart))
 			if err != nil {
 				logStep.Error(fmt.Sprintf("Process operation failed: %s", err))
+				span.SetStatus(codes.Error, err.Error())
 				operation.EventErrorf(err, "step %v processing returned error", step.Name())
 				return 0, err
 			}
@@ -208,12 +237,16 @@ func (m *StagedManager) saveFinishedStage(operation internal.Operation, s *stage
 	return *op, nil
 }
 
-func (m *StagedManager) runStep(step Step, operation internal.Operation, logger *slog.Logger) (processedOperation internal.Operation, backoff time.Duration, err error) {
+func (m *StagedManager) runStep(ctx context.Context, stageName string, step Step, operation internal.Operation, logger *slog.Logger) (processedOperation internal.Operation, backoff time.Duration, err error) {
 	var start time.Time
+	var span trace.Span
 	defer func() {
 		if pErr := recover(); pErr != nil {
 			logger.Info(fmt.Sprintf("panic in RunStep in staged manager: %v", pErr))
 			err = fmt.Errorf("%v", pErr)
+			if span != nil && span.IsRecording() {
+				endStepSpan(span, operation, 0, err)
+			}
 			om := NewOperationManager(m.operationStorage, step.Name(), kebError.KEBDependency)
 			processedOperation, _, _ = om.OperationFailed(operation, "recovered from panic", err, m.log)
 		}
@@ -225,7 +258,15 @@ func (m *StagedManager) runStep(step Step, operation internal.Operation, logger
 		start = time.Now()
 		logger.Info("Start step")
 		stepLogger := logger.With("step", step.Name(), "operationID", processedOperation.ID)
-		processedOperation, backoff, err = step.Run(processedOperation, stepLogger)
+		var stepCtx context.Context
+		stepCtx, span = otel.Tracer(tracerName).Start(ctx, fmt.Sprintf("step %s", step.Name()),
+			trace.WithAttributes(attribute.String("kyma.stage", stageName), attribute.String("kyma.step", step.Name())))
+		if contextStep, ok := step.(ContextStep); ok {
+			processedOperation, backoff, err = contextStep.RunWithContext(stepCtx, processedOperation, stepLogger)
+		} else {
+			processedOperation, backoff, err = step.Run(processedOperation, stepLogger)
+		}
+		endStepSpan(span, processedOperation, backoff, err)
 		if err != nil {
 			logOperation := stepLogger.With("error_component", processedOperation.LastError.GetComponent(), "error_reason", processedOperation.LastError.GetReason())
 			logOperation.Warn(fmt.Sprintf("Last error from step: %s", processedOperation.LastError.Error()))
@@ -238,11 +279,13 @@ func (m *StagedManager) runStep(step Step, operation internal.Operation, logger
 
 		m.publisher.Publish(context.TODO(), OperationStepProcessed{
 			StepProcessed: StepProcessed{
-				StepName: step.Name(),
-				Duration: time.Since(start),
-				When:     backoff,
-				Error:    err,
+				StepName:  step.Name(),
+				StartedAt: start,
+				Duration:  time.Since(start),
+				When:      backoff,
+				Error:     err,
 			},
+			StageName:    stageName,
 			Operation:    processedOperation,
 			OldOperation: operation,
 		})
@@ -263,6 +306,21 @@ func (m *StagedManager) runStep(step Step, operation internal.Operation, logger
 	}
 }
 
+func endStepSpan(span trace.Span, operation internal.Operation, backoff time.Duration, err error) {
+	span.SetAttributes(attribute.String("kyma.operation.state", string(operation.State)))
+	if backoff > 0 {
+		span.SetAttributes(attribute.String("kyma.step.retry_after", backoff.String()))
+	}
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case operation.State == domain.Failed:
+		span.SetStatus(codes.Error, operation.LastError.Error())
+	}
+	span.End()
+}
+
 func (m *StagedManager) publishEventOnFail(operation *internal.Operation, err error) {
 	logOperation := m.log.With("operationID", operation.ID, "error_component", operation.LastError.GetComponent(), "error_reason", operation.LastError.GetReason())
 	logOperation.Error(fmt.Sprintf("Last error: %s", operation.LastError.Error()))
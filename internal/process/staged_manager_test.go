@@ -22,7 +22,14 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -52,6 +59,9 @@ func TestHappyPath(t *testing.T) {
 	op, _ := operationStorage.GetOperationByID(operation.ID)
 	assert.True(t, op.IsStageFinished("stage-1"))
 	assert.True(t, op.IsStageFinished("stage-2"))
+	assert.Len(t, op.StageTimes, 2)
+	assert.NotNil(t, op.StageTimes[0].FinishedAt)
+	assert.Len(t, op.StageTimes[0].Steps, 3)
 }
 
 func TestHappyPathWithStepCondition(t *testing.T) {
@@ -160,6 +170,69 @@ func TestSkipFinishedStage(t *testing.T) {
 	assert.True(t, op.IsStageFinished("stage-2"))
 }
 
+func TestStepSpans(t *testing.T) {
+	// given
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	requestCtx, requestSpan := otel.Tracer("test").Start(context.Background(), "PUT /oauth/v2/service_instances/{instance_id}")
+	operation := FixOperation("op-0001234")
+	operation.TraceContext = tracing.Inject(requestCtx)
+	requestSpan.End()
+
+	mgr, _, eventCollector := SetupStagedManager(t, operation)
+	err := mgr.AddStep("stage-1", &onceRetryingStep{name: "first", eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	err = mgr.AddStep("stage-2", &testingStep{name: "second", eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+
+	// when
+	_, err = mgr.Execute(operation.ID)
+
+	// then
+	assert.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 5)
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+		assert.Equal(t, requestSpan.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+	assert.Equal(t, []string{"PUT /oauth/v2/service_instances/{instance_id}", "step first", "step first", "step second", "process provision operation"}, names)
+	assert.Equal(t, requestSpan.SpanContext().SpanID(), spans[4].Parent().SpanID())
+	assert.Equal(t, spans[4].SpanContext().SpanID(), spans[1].Parent().SpanID())
+}
+
+func TestContextStep(t *testing.T) {
+	// given
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previousProvider)
+
+	operation := FixOperation("op-0001234")
+	mgr, _, _ := SetupStagedManager(t, operation)
+	step := &contextStep{name: "traced"}
+	err := mgr.AddStep("stage-1", step, nil)
+	assert.NoError(t, err)
+
+	// when
+	_, err = mgr.Execute(operation.ID)
+
+	// then
+	assert.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "step traced", spans[0].Name())
+	assert.Equal(t, spans[0].SpanContext(), step.spanContext)
+}
+
 func SetupStagedManager(t *testing.T, op internal.Operation) (*process.StagedManager, storage.Operations, *CollectingEventHandler) {
 	memoryStorage := storage.NewMemoryStorage()
 	err := memoryStorage.Operations().InsertOperation(op)
@@ -190,6 +263,24 @@ func (s *testingStep) Run(operation internal.Operation, logger *slog.Logger) (in
 	return operation, 0, nil
 }
 
+type contextStep struct {
+	name        string
+	spanContext trace.SpanContext
+}
+
+func (s *contextStep) Name() string {
+	return s.name
+}
+
+func (s *contextStep) Run(operation internal.Operation, logger *slog.Logger) (internal.Operation, time.Duration, error) {
+	return s.RunWithContext(context.Background(), operation, logger)
+}
+
+func (s *contextStep) RunWithContext(ctx context.Context, operation internal.Operation, logger *slog.Logger) (internal.Operation, time.Duration, error) {
+	s.spanContext = trace.SpanContextFromContext(ctx)
+	return operation, 0, nil
+}
+
 type onceRetryingStep struct {
 	name           string
 	processed      bool
@@ -0,0 +1,55 @@
+package process
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+// StepExecutionRecorder stores every run of an operation step in the step executions storage.
+type StepExecutionRecorder struct {
+	storage storage.StepExecutions
+}
+
+func NewStepExecutionRecorder(storage storage.StepExecutions) *StepExecutionRecorder {
+	return &StepExecutionRecorder{storage: storage}
+}
+
+func (r *StepExecutionRecorder) OnOperationStepProcessed(_ context.Context, ev interface{}) error {
+	stepProcessed, ok := ev.(OperationStepProcessed)
+	if !ok {
+		return fmt.Errorf("expected process.OperationStepProcessed in OnOperationStepProcessed but got %+v", ev)
+	}
+
+	execution := internal.StepExecution{
+		ID:          uuid.NewString(),
+		OperationID: stepProcessed.Operation.ID,
+		InstanceID:  stepProcessed.Operation.InstanceID,
+		Stage:       stepProcessed.StageName,
+		Step:        stepProcessed.StepName,
+		StartedAt:   stepProcessed.StartedAt,
+		FinishedAt:  stepProcessed.StartedAt.Add(stepProcessed.Duration),
+		Result:      internal.StepExecutionSucceeded,
+	}
+	switch {
+	case stepProcessed.Error != nil || stepProcessed.Operation.State == domain.Failed:
+		execution.Result = internal.StepExecutionFailed
+	case stepProcessed.When > 0:
+		execution.Result = internal.StepExecutionRetry
+		execution.RetryAfter = stepProcessed.When
+	}
+	if execution.Result != internal.StepExecutionSucceeded {
+		execution.ErrorReason = string(stepProcessed.Operation.LastError.GetReason())
+		execution.ErrorComponent = string(stepProcessed.Operation.LastError.GetComponent())
+	}
+
+	if err := r.storage.Insert(execution); err != nil {
+		return fmt.Errorf("while inserting execution of step %s for operation %s: %w", execution.Step, execution.OperationID, err)
+	}
+	return nil
+}
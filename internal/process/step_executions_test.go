@@ -0,0 +1,58 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepExecutionRecorder(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	recorder := NewStepExecutionRecorder(db.StepExecutions())
+	startedAt := time.Now()
+
+	operation := fixture.FixProvisioningOperation("op-1", "inst-1")
+	failedOperation := operation
+	failedOperation.State = domain.Failed
+	failedOperation.LastError = kebError.LastError{}.SetReason(kebError.KEBTimeOutCode).SetComponent(kebError.InfrastructureManagerDependency)
+
+	// when
+	for _, ev := range []OperationStepProcessed{
+		{StepProcessed: StepProcessed{StepName: "Create_Runtime", StartedAt: startedAt, Duration: time.Second, When: time.Minute}, StageName: "create_runtime", Operation: operation},
+		{StepProcessed: StepProcessed{StepName: "Create_Runtime", StartedAt: startedAt.Add(time.Minute), Duration: time.Second}, StageName: "create_runtime", Operation: operation},
+		{StepProcessed: StepProcessed{StepName: "Check_Runtime", StartedAt: startedAt.Add(2 * time.Minute), Duration: time.Second, Error: fmt.Errorf("failed")}, StageName: "create_runtime", Operation: failedOperation},
+	} {
+		require.NoError(t, recorder.OnOperationStepProcessed(context.Background(), ev))
+	}
+
+	// then
+	executions, err := db.StepExecutions().ListByOperationIDs([]string{"op-1"})
+	require.NoError(t, err)
+	require.Len(t, executions, 3)
+
+	assert.Equal(t, internal.StepExecutionRetry, executions[0].Result)
+	assert.Equal(t, time.Minute, executions[0].RetryAfter)
+	assert.Equal(t, 1, executions[0].Attempt)
+	assert.Equal(t, "create_runtime", executions[0].Stage)
+	assert.Equal(t, startedAt.Add(time.Second), executions[0].FinishedAt)
+
+	assert.Equal(t, internal.StepExecutionSucceeded, executions[1].Result)
+	assert.Equal(t, 2, executions[1].Attempt)
+	assert.Empty(t, executions[1].ErrorReason)
+
+	assert.Equal(t, internal.StepExecutionFailed, executions[2].Result)
+	assert.Equal(t, 1, executions[2].Attempt)
+	assert.Equal(t, string(kebError.KEBTimeOutCode), executions[2].ErrorReason)
+	assert.Equal(t, string(kebError.InfrastructureManagerDependency), executions[2].ErrorComponent)
+}
@@ -40,8 +40,8 @@ func (s *UpdateKymaStep) Name() string {
 }
 
 func (s *UpdateKymaStep) Run(operation internal.Operation, log *slog.Logger) (internal.Operation, time.Duration, error) {
-	if operation.UpdatedPlanID == "" {
-		log.Info("Plan did not change, skipping update Kyma resource step")
+	if operation.UpdatedPlanID == "" && operation.UpdatedMaintenanceInfo == nil {
+		log.Info("Plan and maintenance info did not change, skipping update Kyma resource step")
 		return operation, 0, nil
 	}
 
@@ -107,7 +107,16 @@ func (s *UpdateKymaStep) Run(operation internal.Operation, log *slog.Logger) (in
 
 	log.Info(fmt.Sprintf("Updating Kyma resource: %s in namespace:%s", kymaResourceName, operation.KymaResourceNamespace))
 
-	kymaUnstructured.SetLabels(steps.UpdatePlanLabels(kymaUnstructured.GetLabels(), operation.UpdatedPlanID))
+	if operation.UpdatedPlanID != "" {
+		kymaUnstructured.SetLabels(steps.UpdatePlanLabels(kymaUnstructured.GetLabels(), operation.UpdatedPlanID))
+	}
+	if operation.UpdatedMaintenanceInfo != nil {
+		if channel := operation.UpdatedMaintenanceInfo.Public[broker.MaintenanceInfoKymaChannelKey]; channel != "" {
+			if err := unstructured.SetNestedField(kymaUnstructured.Object, channel, "spec", "channel"); err != nil {
+				return s.operationManager.OperationFailed(operation, "unable to set channel in Kyma resource", err, log)
+			}
+		}
+	}
 	err = s.kcpClient.Update(context.Background(), kymaUnstructured)
 	if err != nil {
 		return s.operationManager.RetryOperationWithoutFail(operation, s.Name(), fmt.Sprintf("unable to update Kyma Resource %s", kymaResourceName), 10*time.Second, 1*time.Minute, log, err)
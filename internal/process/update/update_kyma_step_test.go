@@ -1,6 +1,7 @@
 package update
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -12,7 +13,10 @@ import (
 	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -65,6 +69,39 @@ func TestUpdateKymaStep_HappyPath(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUpdateKymaStep_MaintenanceInfoChanged(t *testing.T) {
+	// given
+	err := imv1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	kcpClient := fake.NewClientBuilder().Build()
+	err = fixture.FixKymaResourceWithGivenRuntimeID(kcpClient, "kyma-system", "runtime-inst-id")
+	require.NoError(t, err)
+	db := storage.NewMemoryStorage()
+	operations := db.Operations()
+
+	operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+	operation.UpdatedMaintenanceInfo = broker.NewMaintenanceInfo("1.31", "regular", 0)
+	operation.KymaTemplate = fixture.KymaTemplate
+	err = operations.InsertOperation(operation)
+	require.NoError(t, err)
+
+	step := NewUpdateKymaStep(db, kcpClient, nil)
+
+	// when
+	_, backoff, err := step.Run(operation, fixLogger())
+
+	// then
+	assert.Zero(t, backoff)
+	assert.NoError(t, err)
+
+	kyma := &unstructured.Unstructured{}
+	kyma.SetGroupVersionKind(schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "Kyma"})
+	err = kcpClient.Get(context.Background(), client.ObjectKey{Namespace: "kyma-system", Name: "runtime-inst-id"}, kyma)
+	require.NoError(t, err)
+	channel, _, _ := unstructured.NestedString(kyma.Object, "spec", "channel")
+	assert.Equal(t, "regular", channel)
+}
+
 func TestUpdateKymaStep_KymaTemplateFromProvider(t *testing.T) {
 	// given
 	err := imv1.AddToScheme(scheme.Scheme)
@@ -104,6 +104,7 @@ func (s *UpdateRuntimeStep) Run(operation internal.Operation, log *slog.Logger)
 	}
 
 	s.applyMaxPodsConfig(operation, &runtime)
+	s.updateKubernetesVersion(operation, &runtime, log)
 
 	err = s.k8sClient.Update(context.Background(), &runtime)
 	if err != nil {
@@ -405,3 +406,15 @@ func (s *UpdateRuntimeStep) getAdministrators(operation internal.Operation) []st
 		}
 	}
 }
+
+func (s *UpdateRuntimeStep) updateKubernetesVersion(operation internal.Operation, runtime *imv1.Runtime, log *slog.Logger) {
+	if operation.UpdatedMaintenanceInfo == nil {
+		return
+	}
+	version := operation.UpdatedMaintenanceInfo.Public[broker.MaintenanceInfoKubernetesVersionKey]
+	if version == "" {
+		return
+	}
+	log.Info(fmt.Sprintf("Kubernetes version updated from maintenance info: %s", version))
+	runtime.Spec.Shoot.Kubernetes.Version = &version
+}
@@ -4,7 +4,6 @@ import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
-	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
 )
 
 const (
@@ -37,11 +36,13 @@ type (
 	AWSTrialInputProvider struct {
 		PlatformRegionMapping  map[string]string
 		UseSmallerMachineTypes bool
+		EUAccess               bool
 		ProvisioningParameters internal.ProvisioningParameters
 		ZonesProvider          ZonesProvider
 	}
 	AWSFreemiumInputProvider struct {
 		UseSmallerMachineTypes bool
+		EUAccess               bool
 		ProvisioningParameters internal.ProvisioningParameters
 		ZonesProvider          ZonesProvider
 	}
@@ -99,7 +100,7 @@ func (p *AWSTrialInputProvider) Provide() internal.ProviderValues {
 }
 
 func (p *AWSTrialInputProvider) region() string {
-	if euaccess.IsEURestrictedAccess(p.ProvisioningParameters.PlatformRegion) {
+	if p.EUAccess {
 		return DefaultEuAccessAWSRegion
 	}
 	if p.ProvisioningParameters.PlatformRegion != "" {
@@ -142,7 +143,7 @@ func (p *AWSFreemiumInputProvider) Provide() internal.ProviderValues {
 }
 
 func (p *AWSFreemiumInputProvider) region() string {
-	if euaccess.IsEURestrictedAccess(p.ProvisioningParameters.PlatformRegion) {
+	if p.EUAccess {
 		return DefaultEuAccessAWSRegion
 	}
 	return DefaultAWSRegion
@@ -85,6 +85,7 @@ func TestAWSTrialDefaults(t *testing.T) {
 	// given
 	provider := AWSTrialInputProvider{
 		PlatformRegionMapping: TestTrialPlatformRegionMapping,
+		EUAccess:              true,
 		ProvisioningParameters: internal.ProvisioningParameters{
 			Parameters:     pkg.ProvisioningParametersDTO{Region: nil},
 			PlatformRegion: "cf-eu11",
@@ -4,7 +4,6 @@ import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
-	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
 )
 
 const (
@@ -36,6 +35,7 @@ type (
 	AzureTrialInputProvider struct {
 		PlatformRegionMapping  map[string]string
 		UseSmallerMachineTypes bool
+		EUAccess               bool
 		ProvisioningParameters internal.ProvisioningParameters
 		ZonesProvider          ZonesProvider
 	}
@@ -105,7 +105,7 @@ func (p *AzureTrialInputProvider) Provide() internal.ProviderValues {
 }
 
 func (p *AzureTrialInputProvider) region() string {
-	if euaccess.IsEURestrictedAccess(p.ProvisioningParameters.PlatformRegion) {
+	if p.EUAccess {
 		return DefaultEuAccessAzureRegion
 	}
 	if p.ProvisioningParameters.PlatformRegion != "" {
@@ -48,6 +48,7 @@ func TestAzureTrialDefaults(t *testing.T) {
 	// given
 	azure := AzureTrialInputProvider{
 		PlatformRegionMapping: AzureTrialPlatformRegionMapping,
+		EUAccess:              true,
 		ProvisioningParameters: internal.ProvisioningParameters{
 			Parameters:     pkg.ProvisioningParametersDTO{Region: ptr.String("eastus")},
 			PlatformRegion: "cf-eu11",
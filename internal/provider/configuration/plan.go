@@ -4,13 +4,49 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	PlanKindStandard = "standard"
+	PlanKindTrial    = "trial"
+	PlanKindFree     = "free"
+)
+
 type PlanSpecifications struct {
-	plans map[string]planSpecificationDTO
+	plans       map[string]planSpecificationDTO
+	definitions []PlanDefinition
+}
+
+// PlanDefinition describes a plan declared in the plans configuration file. A plan is declared by setting its ID,
+// the broker then registers it in the catalog and provisions it with the values of the plan's provider and kind.
+type PlanDefinition struct {
+	ID       string
+	Name     string
+	Provider string
+	Kind     string
+	Schema   PlanSchema
+	Defaults PlanDefaults
+}
+
+// PlanSchema holds the feature toggles of the provisioning and update schemas of the plan.
+type PlanSchema struct {
+	IngressFiltering       bool `yaml:"ingressFiltering"`
+	AdditionalVolumeSizeGi bool `yaml:"additionalVolumeSizeGi"`
+	DisableHAZones         bool `yaml:"disableHAZones"`
+	AutoScalerMinimum      int  `yaml:"autoScalerMinimum"`
+	AutoScalerMaximum      int  `yaml:"autoScalerMaximum"`
+}
+
+// PlanDefaults holds the values used when the provisioning parameters do not set them.
+type PlanDefaults struct {
+	AutoScalerMin int `yaml:"autoScalerMin"`
+	AutoScalerMax int `yaml:"autoScalerMax"`
 }
 
 func NewPlanSpecificationsFromFile(filePath string) (*PlanSpecifications, error) {
@@ -39,14 +75,63 @@ func NewPlanSpecifications(r io.Reader) (*PlanSpecifications, error) {
 		for _, planName := range planNames {
 			spec.plans[planName] = plan
 		}
+		if plan.ID == "" {
+			continue
+		}
+		definition, definitionErr := newPlanDefinition(planNames, plan)
+		if definitionErr != nil {
+			return nil, definitionErr
+		}
+		spec.definitions = append(spec.definitions, definition)
 	}
+	sort.Slice(spec.definitions, func(i, j int) bool { return spec.definitions[i].Name < spec.definitions[j].Name })
 
 	return spec, err
 }
 
+func newPlanDefinition(planNames []string, plan planSpecificationDTO) (PlanDefinition, error) {
+	if len(planNames) != 1 {
+		return PlanDefinition{}, fmt.Errorf("plan ID %s must be declared for a single plan, got %s", plan.ID, strings.Join(planNames, ","))
+	}
+	definition := PlanDefinition{
+		ID:       plan.ID,
+		Name:     planNames[0],
+		Provider: plan.Provider,
+		Kind:     plan.Kind,
+		Schema:   plan.Schema,
+		Defaults: plan.Defaults,
+	}
+	if definition.Kind == "" {
+		definition.Kind = PlanKindStandard
+	}
+	switch definition.Kind {
+	case PlanKindStandard:
+		if runtime.CloudProviderFromString(definition.Provider) == runtime.UnknownProvider {
+			return PlanDefinition{}, fmt.Errorf("plan %s: unknown provider %q", definition.Name, definition.Provider)
+		}
+	case PlanKindTrial, PlanKindFree:
+	default:
+		return PlanDefinition{}, fmt.Errorf("plan %s: unknown kind %q", definition.Name, definition.Kind)
+	}
+	if definition.Schema.AutoScalerMinimum > definition.Schema.AutoScalerMaximum && definition.Schema.AutoScalerMaximum > 0 {
+		return PlanDefinition{}, fmt.Errorf("plan %s: autoScalerMinimum %d is greater than autoScalerMaximum %d", definition.Name, definition.Schema.AutoScalerMinimum, definition.Schema.AutoScalerMaximum)
+	}
+	if definition.Defaults.AutoScalerMin > definition.Defaults.AutoScalerMax && definition.Defaults.AutoScalerMax > 0 {
+		return PlanDefinition{}, fmt.Errorf("plan %s: default autoScalerMin %d is greater than autoScalerMax %d", definition.Name, definition.Defaults.AutoScalerMin, definition.Defaults.AutoScalerMax)
+	}
+	return definition, nil
+}
+
 type PlanSpecificationsDTO map[string]planSpecificationDTO
 
 type planSpecificationDTO struct {
+	// set only for plans declared in the configuration
+	ID       string       `yaml:"id,omitempty"`
+	Provider string       `yaml:"provider,omitempty"`
+	Kind     string       `yaml:"kind,omitempty"`
+	Schema   PlanSchema   `yaml:"schema,omitempty"`
+	Defaults PlanDefaults `yaml:"defaults,omitempty"`
+
 	// platform region -> list of hyperscaler regions
 	Regions map[string][]string `yaml:"regions"`
 
@@ -57,6 +142,11 @@ type planSpecificationDTO struct {
 	UpgradableToPlans    []string `yaml:"upgradableToPlans,omitempty"`
 }
 
+// Definitions returns the plans declared in the configuration file, sorted by name.
+func (p *PlanSpecifications) Definitions() []PlanDefinition {
+	return p.definitions
+}
+
 func (p *PlanSpecifications) Regions(planName string, platformRegion string) []string {
 	plan, ok := p.plans[planName]
 	if !ok {
@@ -145,3 +145,74 @@ azure:
 	// plan with no internalOnlyMachines
 	assert.False(t, spec.IsInternalOnlyMachine("azure", "Standard_NC4as_T4_v3"))
 }
+
+func TestPlanDefinitions(t *testing.T) {
+	t.Run("should read declared plans", func(t *testing.T) {
+		// given
+		spec, err := NewPlanSpecifications(strings.NewReader(`
+aws,build-runtime-aws:
+  regularMachines: [m6i.large]
+sovereign-aws:
+  id: 5f9a8c1e-3b0d-4f43-9a63-1d2c7e8f0a11
+  provider: aws
+  schema:
+    ingressFiltering: true
+    autoScalerMinimum: 3
+  defaults:
+    autoScalerMin: 3
+  regularMachines: [m6i.large]
+free-eu:
+  id: 8e2b4c6d-0f1a-4b3c-9d5e-7f8a9b0c1d2e
+  kind: free
+`))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []PlanDefinition{
+			{
+				ID:   "8e2b4c6d-0f1a-4b3c-9d5e-7f8a9b0c1d2e",
+				Name: "free-eu",
+				Kind: PlanKindFree,
+			},
+			{
+				ID:       "5f9a8c1e-3b0d-4f43-9a63-1d2c7e8f0a11",
+				Name:     "sovereign-aws",
+				Provider: "aws",
+				Kind:     PlanKindStandard,
+				Schema:   PlanSchema{IngressFiltering: true, AutoScalerMinimum: 3},
+				Defaults: PlanDefaults{AutoScalerMin: 3},
+			},
+		}, spec.Definitions())
+		assert.Equal(t, "m6i.large", spec.DefaultMachineType("sovereign-aws"))
+	})
+
+	for name, tc := range map[string]struct {
+		input string
+		err   string
+	}{
+		"ID for several plans": {
+			input: "plan1,plan2:\n  id: id-1\n  provider: aws\n",
+			err:   "plan ID id-1 must be declared for a single plan, got plan1,plan2",
+		},
+		"unknown provider": {
+			input: "plan1:\n  id: id-1\n  provider: unknown\n",
+			err:   `plan plan1: unknown provider "unknown"`,
+		},
+		"unknown kind": {
+			input: "plan1:\n  id: id-1\n  kind: paid\n",
+			err:   `plan plan1: unknown kind "paid"`,
+		},
+		"invalid autoscaler bounds": {
+			input: "plan1:\n  id: id-1\n  provider: gcp\n  schema:\n    autoScalerMinimum: 5\n    autoScalerMaximum: 3\n",
+			err:   "plan plan1: autoScalerMinimum 5 is greater than autoScalerMaximum 3",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			_, err := NewPlanSpecifications(strings.NewReader(tc.input))
+
+			// then
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -3,7 +3,6 @@ package provider
 import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
-	"github.com/kyma-project/kyma-environment-broker/internal/assuredworkloads"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 )
 
@@ -31,12 +30,14 @@ type (
 		MultiZone              bool
 		ProvisioningParameters internal.ProvisioningParameters
 		FailureTolerance       string
+		AssuredWorkloads       bool
 		ZonesProvider          ZonesProvider
 	}
 
 	GCPTrialInputProvider struct {
 		Purpose                string
 		PlatformRegionMapping  map[string]string
+		AssuredWorkloads       bool
 		ProvisioningParameters internal.ProvisioningParameters
 		ZonesProvider          ZonesProvider
 	}
@@ -69,7 +70,7 @@ func (p *GCPInputProvider) zonesCount() int {
 }
 
 func (p *GCPInputProvider) region() string {
-	if assuredworkloads.IsKSA(p.ProvisioningParameters.PlatformRegion) {
+	if p.AssuredWorkloads {
 		return DefaultGCPAssuredWorkloadsRegion
 	}
 
@@ -99,7 +100,7 @@ func (p *GCPTrialInputProvider) Provide() internal.ProviderValues {
 }
 
 func (p *GCPTrialInputProvider) region() string {
-	if assuredworkloads.IsKSA(p.ProvisioningParameters.PlatformRegion) {
+	if p.AssuredWorkloads {
 		return DefaultGCPAssuredWorkloadsRegion
 	}
 	if p.ProvisioningParameters.PlatformRegion != "" {
@@ -299,6 +299,7 @@ func TestGCPTrial_KSA(t *testing.T) {
 	// given
 	provider := GCPTrialInputProvider{
 		PlatformRegionMapping: TestTrialPlatformRegionMapping,
+		AssuredWorkloads:      true,
 		ProvisioningParameters: internal.ProvisioningParameters{
 			Parameters: pkg.ProvisioningParametersDTO{
 				Region: ptr.String("eu-central-1"),
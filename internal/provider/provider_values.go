@@ -9,6 +9,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 )
 
 type Provider interface {
@@ -32,8 +33,9 @@ type PlanSpecificValuesProvider struct {
 	defaultPurpose             string
 	commercialFailureTolerance string
 
-	zonesProvider ZonesProvider
-	planSpec      PlanConfigProvider
+	zonesProvider     ZonesProvider
+	planSpec          PlanConfigProvider
+	residencyPolicies *residency.Policies
 }
 
 func NewPlanSpecificValuesProvider(cfg broker.InfrastructureManager,
@@ -48,9 +50,16 @@ func NewPlanSpecificValuesProvider(cfg broker.InfrastructureManager,
 		commercialFailureTolerance: cfg.ControlPlaneFailureTolerance,
 		zonesProvider:              zonesProvider,
 		planSpec:                   planSpec,
+		residencyPolicies:          residency.DefaultPolicies(),
 	}
 }
 
+// WithResidencyPolicies replaces the default residency policies used to mark EU Access and Assured Workloads platform regions.
+func (s *PlanSpecificValuesProvider) WithResidencyPolicies(policies *residency.Policies) *PlanSpecificValuesProvider {
+	s.residencyPolicies = policies
+	return s
+}
+
 func NewFakePlanSpecFromFile() (*configuration.PlanSpecifications, error) {
 	_, filename, _, _ := runtime.Caller(0)
 	dir := filepath.Dir(filename)
@@ -60,50 +69,22 @@ func NewFakePlanSpecFromFile() (*configuration.PlanSpecifications, error) {
 
 func (s *PlanSpecificValuesProvider) ValuesForPlanAndParameters(provisioningParameters internal.ProvisioningParameters) (internal.ProviderValues, error) {
 	var p Provider
-	switch provisioningParameters.PlanID {
-	case broker.AWSPlanID, broker.BuildRuntimeAWSPlanID:
-		p = &AWSInputProvider{
-			Purpose:                s.defaultPurpose,
-			MultiZone:              s.multiZoneCluster,
-			ProvisioningParameters: provisioningParameters,
-			FailureTolerance:       s.commercialFailureTolerance,
-			ZonesProvider:          s.zonesProvider,
-		}
-	case broker.PreviewPlanID:
-		p = &AWSInputProvider{
-			Purpose:                s.defaultPurpose,
-			MultiZone:              s.multiZoneCluster,
-			ProvisioningParameters: provisioningParameters,
-			FailureTolerance:       s.commercialFailureTolerance,
-			ZonesProvider:          s.zonesProvider,
-		}
-	case broker.AzurePlanID, broker.BuildRuntimeAzurePlanID:
-		p = &AzureInputProvider{
-			Purpose:                s.defaultPurpose,
-			MultiZone:              s.multiZoneCluster,
-			ProvisioningParameters: provisioningParameters,
-			FailureTolerance:       s.commercialFailureTolerance,
-			ZonesProvider:          s.zonesProvider,
-		}
-	case broker.AzureLitePlanID:
+	planID := provisioningParameters.PlanID
+	euAccess := s.residencyPolicies.IsEUAccess(provisioningParameters.PlatformRegion)
+	assuredWorkloads := s.residencyPolicies.IsAssuredWorkloads(provisioningParameters.PlatformRegion)
+	switch {
+	case planID == broker.AzureLitePlanID:
 		p = &AzureLiteInputProvider{
 			Purpose:                s.defaultPurpose,
 			ProvisioningParameters: provisioningParameters,
 			ZonesProvider:          s.zonesProvider,
 		}
-	case broker.GCPPlanID, broker.BuildRuntimeGCPPlanID:
-		p = &GCPInputProvider{
-			Purpose:                s.defaultPurpose,
-			MultiZone:              s.multiZoneCluster,
-			ProvisioningParameters: provisioningParameters,
-			FailureTolerance:       s.commercialFailureTolerance,
-			ZonesProvider:          s.zonesProvider,
-		}
-	case broker.FreemiumPlanID:
+	case broker.IsFreemiumPlan(planID):
 		switch provisioningParameters.PlatformProvider {
 		case pkg.AWS:
 			p = &AWSFreemiumInputProvider{
 				UseSmallerMachineTypes: s.useSmallerMachineTypes,
+				EUAccess:               euAccess,
 				ProvisioningParameters: provisioningParameters,
 				ZonesProvider:          s.zonesProvider,
 			}
@@ -116,23 +97,7 @@ func (s *PlanSpecificValuesProvider) ValuesForPlanAndParameters(provisioningPara
 		default:
 			return internal.ProviderValues{}, fmt.Errorf("freemium provider for '%s' is not supported", provisioningParameters.PlatformProvider)
 		}
-	case broker.SapConvergedCloudPlanID:
-		p = &SapConvergedCloudInputProvider{
-			Purpose:                s.defaultPurpose,
-			MultiZone:              s.multiZoneCluster,
-			ProvisioningParameters: provisioningParameters,
-			FailureTolerance:       s.commercialFailureTolerance,
-			ZonesProvider:          s.zonesProvider,
-		}
-	case broker.AlicloudPlanID, broker.BuildRuntimeAlicloudPlanID:
-		p = &AlicloudInputProvider{
-			Purpose:                s.defaultPurpose,
-			MultiZone:              s.multiZoneCluster,
-			ProvisioningParameters: provisioningParameters,
-			FailureTolerance:       s.commercialFailureTolerance,
-			ZonesProvider:          s.zonesProvider,
-		}
-	case broker.TrialPlanID:
+	case broker.IsTrialPlan(planID):
 		var trialProvider pkg.CloudProvider
 		if provisioningParameters.Parameters.Provider == nil {
 			trialProvider = s.defaultTrialProvider
@@ -144,12 +109,14 @@ func (s *PlanSpecificValuesProvider) ValuesForPlanAndParameters(provisioningPara
 			p = &AWSTrialInputProvider{
 				PlatformRegionMapping:  s.trialPlatformRegionMapping,
 				UseSmallerMachineTypes: s.useSmallerMachineTypes,
+				EUAccess:               euAccess,
 				ProvisioningParameters: provisioningParameters,
 				ZonesProvider:          s.zonesProvider,
 			}
 		case pkg.GCP:
 			p = &GCPTrialInputProvider{
 				PlatformRegionMapping:  s.trialPlatformRegionMapping,
+				AssuredWorkloads:       assuredWorkloads,
 				ProvisioningParameters: provisioningParameters,
 				ZonesProvider:          s.zonesProvider,
 			}
@@ -157,15 +124,23 @@ func (s *PlanSpecificValuesProvider) ValuesForPlanAndParameters(provisioningPara
 			p = &AzureTrialInputProvider{
 				PlatformRegionMapping:  s.trialPlatformRegionMapping,
 				UseSmallerMachineTypes: s.useSmallerMachineTypes,
+				EUAccess:               euAccess,
 				ProvisioningParameters: provisioningParameters,
 				ZonesProvider:          s.zonesProvider,
 			}
 		default:
 			return internal.ProviderValues{}, fmt.Errorf("trial provider for %s not yet implemented", trialProvider)
 		}
-
 	default:
-		return internal.ProviderValues{}, fmt.Errorf("plan %s not supported", provisioningParameters.PlanID)
+		// plans with a fixed provider, including the plans declared in the plans configuration file
+		cloudProvider, found := broker.PlanCloudProvider(broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(planID)))
+		if !found {
+			return internal.ProviderValues{}, fmt.Errorf("plan %s not supported", planID)
+		}
+		p = s.commercialProvider(cloudProvider, provisioningParameters)
+		if p == nil {
+			return internal.ProviderValues{}, fmt.Errorf("plan %s not supported", planID)
+		}
 	}
 
 	values := p.Provide()
@@ -184,9 +159,66 @@ func (s *PlanSpecificValuesProvider) ValuesForPlanAndParameters(provisioningPara
 		values.DefaultMachineType = defaultMachineType
 	}
 
+	if definition, found := broker.DeclaredPlan(planID); found {
+		if definition.Defaults.AutoScalerMin > 0 {
+			values.DefaultAutoScalerMin = definition.Defaults.AutoScalerMin
+		}
+		if definition.Defaults.AutoScalerMax > 0 {
+			values.DefaultAutoScalerMax = definition.Defaults.AutoScalerMax
+		}
+	}
+
 	return values, nil
 }
 
+func (s *PlanSpecificValuesProvider) commercialProvider(cloudProvider pkg.CloudProvider, provisioningParameters internal.ProvisioningParameters) Provider {
+	switch cloudProvider {
+	case pkg.AWS:
+		return &AWSInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.Azure:
+		return &AzureInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.GCP:
+		return &GCPInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			AssuredWorkloads:       s.residencyPolicies.IsAssuredWorkloads(provisioningParameters.PlatformRegion),
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.SapConvergedCloud:
+		return &SapConvergedCloudInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.Alicloud:
+		return &AlicloudInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	default:
+		return nil
+	}
+}
+
 func ProviderToCloudProvider(providerType string) pkg.CloudProvider {
 	switch providerType {
 	case "azure":
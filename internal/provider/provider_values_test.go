@@ -2,12 +2,14 @@ package provider_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -796,4 +798,31 @@ func TestPlanSpecificValuesProvider(t *testing.T) {
 			assert.Equal(t, defaultVolumeSizeGb, values.VolumeSizeGb)
 		})
 	})
+	t.Run("should use injected residency policies", func(t *testing.T) {
+		// given
+		planConfig, err := provider.NewFakePlanSpecFromFile()
+		require.NoError(t, err)
+		policies, err := residency.NewPolicies(strings.NewReader(`
+cf-us10:
+  euAccess: true
+`))
+		require.NoError(t, err)
+
+		planSpecValProvider := provider.NewPlanSpecificValuesProvider(
+			broker.InfrastructureManager{DefaultTrialProvider: runtime.AWS},
+			provider.TestTrialPlatformRegionMapping,
+			provider.FakeZonesProvider([]string{"a", "b", "c"}),
+			planConfig,
+		).WithResidencyPolicies(policies)
+
+		// when
+		euAccessValues, err := planSpecValProvider.ValuesForPlanAndParameters(internal.ProvisioningParameters{PlanID: broker.TrialPlanID, PlatformRegion: "cf-us10"})
+		require.NoError(t, err)
+		defaultValues, err := planSpecValProvider.ValuesForPlanAndParameters(internal.ProvisioningParameters{PlanID: broker.TrialPlanID, PlatformRegion: "cf-eu11"})
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, provider.DefaultEuAccessAWSRegion, euAccessValues.Region)
+		assert.Equal(t, provider.DefaultAWSTrialRegion, defaultValues.Region)
+	})
 }
@@ -0,0 +1,58 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Getter returns the quota assigned to the subaccount for the plan.
+type Getter interface {
+	GetQuota(ctx context.Context, subAccountID, planName string) (int, error)
+}
+
+type cacheEntry struct {
+	quota     int
+	expiresAt time.Time
+}
+
+// CachedClient caches the quotas returned by the entitlements service for the configured TTL.
+// Errors are not cached, so the next request calls the entitlements service again.
+type CachedClient struct {
+	client Getter
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func NewCachedClient(client Getter, ttl time.Duration) *CachedClient {
+	return &CachedClient{
+		client:  client,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *CachedClient) GetQuota(ctx context.Context, subAccountID, planName string) (int, error) {
+	key := subAccountID + "/" + planName
+
+	c.mu.Lock()
+	entry, found := c.entries[key]
+	c.mu.Unlock()
+	if found && c.now().Before(entry.expiresAt) {
+		return entry.quota, nil
+	}
+
+	quota, err := c.client.GetQuota(ctx, subAccountID, planName)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{quota: quota, expiresAt: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return quota, nil
+}
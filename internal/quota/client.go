@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/tracing"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -17,16 +18,18 @@ const (
 )
 
 type Config struct {
-	ClientID     string `envconfig:"optional"`
-	ClientSecret string `envconfig:"optional"`
-	AuthURL      string
-	ServiceURL   string
-	Retries      int           `envconfig:"default=5"`
-	Interval     time.Duration `envconfig:"default=1s"`
+	ClientID       string `envconfig:"optional"`
+	ClientSecret   string `envconfig:"optional"`
+	AuthURL        string
+	ServiceURL     string
+	Retries        int           `envconfig:"default=5"`
+	Interval       time.Duration `envconfig:"default=1s"`
+	MaxInterval    time.Duration `envconfig:"default=10s"`
+	CacheTTL       time.Duration `envconfig:"default=5m"`
+	LimitsFilePath string        `envconfig:"optional"`
 }
 
 type Client struct {
-	ctx        context.Context
 	httpClient *http.Client
 	config     Config
 	log        *slog.Logger
@@ -45,18 +48,18 @@ func NewClient(ctx context.Context, config Config, log *slog.Logger) *Client {
 	httpClientOAuth := cfg.Client(ctx)
 
 	return &Client{
-		ctx:        ctx,
-		httpClient: httpClientOAuth,
+		httpClient: tracing.WrapClient(httpClientOAuth),
 		config:     config,
 		log:        log,
 	}
 }
 
-func (c *Client) GetQuota(subAccountID, planName string) (int, error) {
+func (c *Client) GetQuota(ctx context.Context, subAccountID, planName string) (int, error) {
 	var lastErr error
 
+	interval := c.config.Interval
 	for i := 0; i < c.config.Retries; i++ {
-		quota, err, retry := c.do(subAccountID, planName)
+		quota, err, retry := c.do(ctx, subAccountID, planName)
 		if err == nil {
 			return quota, nil
 		}
@@ -66,15 +69,25 @@ func (c *Client) GetQuota(subAccountID, planName string) (int, error) {
 			return 0, lastErr
 		}
 
-		c.log.Warn(fmt.Sprintf("Error fetching quota, retrying in %s: %v", c.config.Interval, err))
-		time.Sleep(c.config.Interval)
+		c.log.Warn(fmt.Sprintf("Error fetching quota, retrying in %s: %v", interval, err))
+		time.Sleep(interval)
+		interval = nextInterval(interval, c.config.MaxInterval)
 	}
 
 	return 0, lastErr
 }
 
-func (c *Client) do(subAccountID, planName string) (int, error, bool) {
-	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, fmt.Sprintf(entitlementsServicePath, c.config.ServiceURL, planName, subAccountID), nil)
+// nextInterval doubles the interval between retries, so that the entitlements service is not flooded when it is unavailable.
+func nextInterval(interval, maxInterval time.Duration) time.Duration {
+	interval *= 2
+	if maxInterval > 0 && interval > maxInterval {
+		return maxInterval
+	}
+	return interval
+}
+
+func (c *Client) do(ctx context.Context, subAccountID, planName string) (int, error, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(entitlementsServicePath, c.config.ServiceURL, planName, subAccountID), nil)
 	if err != nil {
 		return 0, fmt.Errorf("while creating request: %w", err), false
 	}
@@ -47,7 +47,7 @@ func TestGetQuota_Success(t *testing.T) {
 			client, cleanup := fixClient(t, http.StatusOK, tc.response)
 			defer cleanup()
 
-			quota, err := client.GetQuota("test-subaccount", "aws")
+			quota, err := client.GetQuota(context.Background(), "test-subaccount", "aws")
 
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expected, quota)
@@ -64,7 +64,7 @@ func TestGetQuota_SubaccountNotExits(t *testing.T) {
 	defer cleanup()
 
 	// when
-	quota, err := client.GetQuota("test-subaccount", "aws")
+	quota, err := client.GetQuota(context.Background(), "test-subaccount", "aws")
 
 	// then
 	assert.EqualError(t, err, "Subaccount test-subaccount does not exist")
@@ -77,7 +77,7 @@ func TestGetQuota_ProvisioningServiceNotAvailable(t *testing.T) {
 	defer cleanup()
 
 	// when
-	quota, err := client.GetQuota("test-subaccount", "aws")
+	quota, err := client.GetQuota(context.Background(), "test-subaccount", "aws")
 
 	// then
 	assert.EqualError(t, err, "The entitlements service is currently unavailable. Please try again later")
@@ -119,7 +119,7 @@ func TestGetQuota_SuccessAfterProvisioningServiceRetry(t *testing.T) {
 	client := NewClient(context.Background(), fixConfig(authServer.URL, serviceServer.URL), slog.Default())
 
 	// when
-	quota, err := client.GetQuota("test-subaccount", "aws")
+	quota, err := client.GetQuota(context.Background(), "test-subaccount", "aws")
 
 	// then
 	assert.NoError(t, err)
@@ -143,7 +143,7 @@ func TestGetQuota_AuthenticationServiceNotAvailable(t *testing.T) {
 	client := NewClient(context.Background(), fixConfig(authServer.URL, serviceServer.URL), slog.Default())
 
 	// when
-	quota, err := client.GetQuota("test-subaccount", "aws")
+	quota, err := client.GetQuota(context.Background(), "test-subaccount", "aws")
 
 	// then
 	assert.EqualError(t, err, "The authentication service is currently unavailable. Please try again later")
@@ -185,7 +185,7 @@ func TestGetQuota_SuccessAfterAuthenticationServiceRetry(t *testing.T) {
 	client := NewClient(context.Background(), fixConfig(authServer.URL, serviceServer.URL), slog.Default())
 
 	// when
-	quota, err := client.GetQuota("test-subaccount", "aws")
+	quota, err := client.GetQuota(context.Background(), "test-subaccount", "aws")
 
 	// then
 	assert.NoError(t, err)
@@ -226,3 +226,9 @@ func fixConfig(authURL, serviceURL string) Config {
 		Interval:     10 * time.Millisecond,
 	}
 }
+
+func TestNextInterval(t *testing.T) {
+	assert.Equal(t, 2*time.Second, nextInterval(time.Second, 10*time.Second))
+	assert.Equal(t, 10*time.Second, nextInterval(8*time.Second, 10*time.Second))
+	assert.Equal(t, 16*time.Second, nextInterval(8*time.Second, 0))
+}
@@ -0,0 +1,215 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+// reservationTimeout is the time after which a reservation is not counted anymore, for example if the replica which recorded it
+// was stopped before the reservation was released. It is much longer than the provisioning and update requests.
+const reservationTimeout = 10 * time.Minute
+
+// Enforcer validates the quota of the subaccount assigned in the entitlements service and the global account and directory limits.
+// Instances which are being provisioned, but are not stored yet, are counted as reserved capacity, so that concurrent requests cannot exceed the quota.
+// The reservations are stored in the database, so the requests handled by different KEB replicas see each other.
+type Enforcer struct {
+	client       Getter
+	instances    storage.Instances
+	reservations storage.QuotaReservations
+	limits       *Limits
+}
+
+func NewEnforcer(client Getter, instances storage.Instances, reservations storage.QuotaReservations, limits *Limits) *Enforcer {
+	if limits == nil {
+		limits = NoLimits()
+	}
+	return &Enforcer{
+		client:       client,
+		instances:    instances,
+		reservations: reservations,
+		limits:       limits,
+	}
+}
+
+func (e *Enforcer) GetQuota(ctx context.Context, subAccountID, planName string) (int, error) {
+	return e.client.GetQuota(ctx, subAccountID, planName)
+}
+
+// Reserve implements broker.QuotaEnforcer. The subaccount quota is validated only if the subaccount already uses the plan or the instance is updated to the plan,
+// the global account and directory limits are always validated.
+// The reservation is stored before the quota is validated, so that concurrent requests, also on other replicas, see each other without holding
+// a lock while the entitlements service is called. Concurrent requests for the last free slot may both be rejected, but they cannot exceed the quota.
+func (e *Enforcer) Reserve(ctx context.Context, instanceID, globalAccountID, subAccountID, planID string, update bool) (func(), error) {
+	now := time.Now()
+	// the expired reservations are not counted, so the cleanup can fail without affecting the validation
+	_ = e.reservations.DeleteCreatedBefore(now.Add(-reservationTimeout))
+	err := e.reservations.Upsert(internal.QuotaReservation{
+		InstanceID:      instanceID,
+		GlobalAccountID: globalAccountID,
+		SubAccountID:    subAccountID,
+		PlanID:          planID,
+		CreatedAt:       now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("while reserving the quota for plan ID %s: %w", planID, err)
+	}
+	release := func() {
+		// a reservation which cannot be deleted expires after the reservation timeout
+		_ = e.reservations.Delete(instanceID)
+	}
+
+	if err := e.validate(ctx, instanceID, globalAccountID, subAccountID, planID, update); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+func (e *Enforcer) validate(ctx context.Context, instanceID, globalAccountID, subAccountID, planID string, update bool) error {
+	planName := broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(planID))
+
+	used, reserved, err := e.usage(dbmodel.InstanceFilter{SubAccountIDs: []string{subAccountID}, PlanIDs: []string{planID}}, instanceID, planID,
+		func(r internal.QuotaReservation) bool { return r.SubAccountID == subAccountID })
+	if err != nil {
+		return err
+	}
+	if used+reserved > 0 || update {
+		assignedQuota, err := e.client.GetQuota(ctx, subAccountID, planName)
+		if err != nil {
+			return fmt.Errorf("Failed to get assigned quota for plan %s: %w.", planName, err)
+		}
+		if used+reserved >= assignedQuota {
+			return fmt.Errorf("Kyma instances quota exceeded for plan %s. assignedQuota: %d, remainingQuota: 0. Contact your administrator.", planName, assignedQuota)
+		}
+	}
+
+	for _, scope := range e.limits.Scopes(globalAccountID, subAccountID, planName) {
+		used, reserved, err := e.scopeUsage(scope, instanceID, planID)
+		if err != nil {
+			return err
+		}
+		if used+reserved >= scope.Limit {
+			return fmt.Errorf("Kyma instances limit exceeded for plan %s in %s %s. limit: %d, remainingQuota: 0. Contact your administrator.", planName, scope.Kind, scope.ID, scope.Limit)
+		}
+	}
+	return nil
+}
+
+// CheckGlobalAccountLimit validates the limit of the plan in the global account before an existing instance is moved to it from another global account.
+func (e *Enforcer) CheckGlobalAccountLimit(globalAccountID, planID string) error {
+	planName := broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(planID))
+	for _, scope := range e.limits.Scopes(globalAccountID, "", planName) {
+		if scope.Kind != ScopeGlobalAccount {
+			continue
+		}
+		used, reserved, err := e.scopeUsage(scope, "", planID)
+		if err != nil {
+			return err
+		}
+		if used+reserved >= scope.Limit {
+			return fmt.Errorf("Kyma instances limit exceeded for plan %s in %s %s. limit: %d, remainingQuota: 0. Contact your administrator.", planName, scope.Kind, scope.ID, scope.Limit)
+		}
+	}
+	return nil
+}
+
+// Usage returns the quota usage of the subaccount for the given plans. The global account limits are reported only if the global account ID is set.
+func (e *Enforcer) Usage(ctx context.Context, subAccountID, globalAccountID string, planIDs []string) Usage {
+	usage := Usage{SubAccountID: subAccountID, GlobalAccountID: globalAccountID, Plans: make([]PlanUsage, 0, len(planIDs))}
+	for _, planID := range planIDs {
+		usage.Plans = append(usage.Plans, e.planUsage(ctx, subAccountID, globalAccountID, planID))
+	}
+	return usage
+}
+
+func (e *Enforcer) planUsage(ctx context.Context, subAccountID, globalAccountID, planID string) PlanUsage {
+	planName := broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(planID))
+	planUsage := PlanUsage{Plan: planName}
+
+	assignedQuota, err := e.client.GetQuota(ctx, subAccountID, planName)
+	if err != nil {
+		planUsage.Error = err.Error()
+		return planUsage
+	}
+
+	used, reserved, err := e.usage(dbmodel.InstanceFilter{SubAccountIDs: []string{subAccountID}, PlanIDs: []string{planID}}, "", planID,
+		func(r internal.QuotaReservation) bool { return r.SubAccountID == subAccountID })
+	if err != nil {
+		planUsage.Error = err.Error()
+		return planUsage
+	}
+	planUsage.Assigned = assignedQuota
+	planUsage.Used = used
+	planUsage.Reserved = reserved
+	planUsage.Available = max(assignedQuota-used-reserved, 0)
+
+	for _, scope := range e.limits.Scopes(globalAccountID, subAccountID, planName) {
+		used, reserved, err := e.scopeUsage(scope, "", planID)
+		if err != nil {
+			planUsage.Error = err.Error()
+			return planUsage
+		}
+		scopeUsage := ScopeUsage{
+			Scope:     scope.Kind,
+			ID:        scope.ID,
+			Limit:     scope.Limit,
+			Used:      used,
+			Reserved:  reserved,
+			Available: max(scope.Limit-used-reserved, 0),
+		}
+		planUsage.Limits = append(planUsage.Limits, scopeUsage)
+		planUsage.Available = min(planUsage.Available, scopeUsage.Available)
+	}
+	return planUsage
+}
+
+func (e *Enforcer) scopeUsage(scope Scope, instanceID, planID string) (int, int, error) {
+	filter := dbmodel.InstanceFilter{PlanIDs: []string{planID}}
+	switch scope.Kind {
+	case ScopeGlobalAccount:
+		filter.GlobalAccountIDs = []string{scope.ID}
+	default:
+		filter.SubAccountIDs = scope.Subaccounts
+	}
+	return e.usage(filter, instanceID, planID, func(r internal.QuotaReservation) bool { return scope.contains(r.GlobalAccountID, r.SubAccountID) })
+}
+
+// usage returns the number of stored instances matching the filter and the number of reservations for instances which are not stored yet.
+// The reservation of the given instance is skipped. The reservations are listed before the instances, so that an instance stored
+// and released in the meantime is counted at least once.
+func (e *Enforcer) usage(filter dbmodel.InstanceFilter, instanceID, planID string, matches func(internal.QuotaReservation) bool) (int, int, error) {
+	reservations, err := e.reservations.ListByPlanID(planID, time.Now().Add(-reservationTimeout))
+	if err != nil {
+		return 0, 0, fmt.Errorf("while listing quota reservations for plan ID %s: %w", planID, err)
+	}
+	pending := make([]string, 0, len(reservations))
+	for _, r := range reservations {
+		if r.InstanceID == instanceID || !matches(r) {
+			continue
+		}
+		pending = append(pending, r.InstanceID)
+	}
+
+	instances, _, _, err := e.instances.List(filter)
+	if err != nil {
+		return 0, 0, fmt.Errorf("while listing instances for plan ID %s: %w", planID, err)
+	}
+	stored := make(map[string]struct{}, len(instances))
+	for _, instance := range instances {
+		stored[instance.InstanceID] = struct{}{}
+	}
+
+	reserved := 0
+	for _, id := range pending {
+		if _, found := stored[id]; !found {
+			reserved++
+		}
+	}
+	return len(instances), reserved, nil
+}
@@ -0,0 +1,439 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixLimits = `
+globalAccounts:
+  ga-1:
+    aws: 2
+directories:
+  dir-1:
+    subaccounts:
+      - sa-1
+      - sa-2
+    plans:
+      aws: 3
+`
+
+type fakeGetter struct {
+	quota map[string]int
+	calls int
+	err   error
+}
+
+func (f *fakeGetter) GetQuota(_ context.Context, subAccountID, planName string) (int, error) {
+	f.calls++
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.quota[subAccountID+"/"+planName], nil
+}
+
+// blockingGetter blocks until unblock is closed, so that the test can check what happens while the entitlements service is called.
+type blockingGetter struct {
+	called  chan struct{}
+	unblock chan struct{}
+}
+
+func (b *blockingGetter) GetQuota(_ context.Context, _, _ string) (int, error) {
+	close(b.called)
+	<-b.unblock
+	return 10, nil
+}
+
+func TestCachedClient(t *testing.T) {
+	// given
+	getter := &fakeGetter{quota: map[string]int{"sa-1/aws": 2}}
+	client := NewCachedClient(getter, time.Minute)
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	// when
+	for range 3 {
+		quota, err := client.GetQuota(context.Background(), "sa-1", "aws")
+		require.NoError(t, err)
+		assert.Equal(t, 2, quota)
+	}
+
+	// then
+	assert.Equal(t, 1, getter.calls)
+
+	// when
+	now = now.Add(2 * time.Minute)
+	_, err := client.GetQuota(context.Background(), "sa-1", "aws")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, getter.calls)
+
+	// when
+	getter.err = fmt.Errorf("unavailable")
+	now = now.Add(2 * time.Minute)
+	_, err = client.GetQuota(context.Background(), "sa-1", "aws")
+	require.Error(t, err)
+	getter.err = nil
+	_, err = client.GetQuota(context.Background(), "sa-1", "aws")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 4, getter.calls)
+}
+
+func TestNewLimits(t *testing.T) {
+	t.Run("should read limits", func(t *testing.T) {
+		// when
+		limits, err := NewLimits(strings.NewReader(fixLimits))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Scope{
+			{Kind: ScopeGlobalAccount, ID: "ga-1", Limit: 2},
+			{Kind: ScopeDirectory, ID: "dir-1", Subaccounts: []string{"sa-1", "sa-2"}, Limit: 3},
+		}, limits.Scopes("ga-1", "sa-1", "aws"))
+		assert.Empty(t, limits.Scopes("ga-2", "sa-3", "aws"))
+		assert.Empty(t, limits.Scopes("ga-1", "sa-1", "azure"))
+	})
+
+	for name, tc := range map[string]struct {
+		input string
+		err   string
+	}{
+		"unknown plan": {
+			input: "globalAccounts:\n  ga-1:\n    unknown: 1\n",
+			err:   "global account ga-1: unknown plan unknown",
+		},
+		"negative limit": {
+			input: "globalAccounts:\n  ga-1:\n    aws: -1\n",
+			err:   "global account ga-1: negative limit -1 for plan aws",
+		},
+		"empty directory": {
+			input: "directories:\n  dir-1:\n    plans:\n      aws: 1\n",
+			err:   "directory dir-1: empty list of subaccounts",
+		},
+		"subaccount in two directories": {
+			input: "directories:\n  dir-1:\n    subaccounts: [sa-1]\n  dir-2:\n    subaccounts: [sa-1]\n",
+			err:   "subaccount sa-1 belongs to directories",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			_, err := NewLimits(strings.NewReader(tc.input))
+
+			// then
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestEnforcer_Reserve(t *testing.T) {
+	t.Run("should not check the assigned quota for the first instance in the subaccount", func(t *testing.T) {
+		// given
+		getter := &fakeGetter{}
+		db := storage.NewMemoryStorage()
+		enforcer := NewEnforcer(getter, db.Instances(), db.QuotaReservations(), nil)
+
+		// when
+		release, err := enforcer.Reserve(context.Background(), "inst-1", "ga-1", "sa-1", broker.AWSPlanID, false)
+
+		// then
+		require.NoError(t, err)
+		release()
+		assert.Zero(t, getter.calls)
+	})
+
+	t.Run("should count reserved instances until they are stored", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		enforcer := NewEnforcer(&fakeGetter{quota: map[string]int{"sa-1/aws": 2}}, db.Instances(), db.QuotaReservations(), nil)
+
+		// when
+		releaseFirst, err := enforcer.Reserve(context.Background(), "inst-1", "ga-1", "sa-1", broker.AWSPlanID, false)
+		require.NoError(t, err)
+		releaseSecond, err := enforcer.Reserve(context.Background(), "inst-2", "ga-1", "sa-1", broker.AWSPlanID, false)
+		require.NoError(t, err)
+		_, err = enforcer.Reserve(context.Background(), "inst-3", "ga-1", "sa-1", broker.AWSPlanID, false)
+
+		// then
+		assert.EqualError(t, err, "Kyma instances quota exceeded for plan aws. assignedQuota: 2, remainingQuota: 0. Contact your administrator.")
+
+		// when
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-1", "ga-1", "sa-1", broker.AWSPlanID)))
+		releaseFirst()
+		releaseSecond()
+		release, err := enforcer.Reserve(context.Background(), "inst-3", "ga-1", "sa-1", broker.AWSPlanID, false)
+
+		// then
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("should not exceed the quota with concurrent requests", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		enforcer := NewEnforcer(&fakeGetter{quota: map[string]int{"sa-1/aws": 3}}, db.Instances(), db.QuotaReservations(), nil)
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-0", "ga-1", "sa-1", broker.AWSPlanID)))
+
+		// when
+		var wg sync.WaitGroup
+		var reserved atomic.Int32
+		for i := 1; i <= 10; i++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				if _, err := enforcer.Reserve(context.Background(), id, "ga-1", "sa-1", broker.AWSPlanID, false); err == nil {
+					reserved.Add(1)
+				}
+			}(fmt.Sprintf("inst-%d", i))
+		}
+		wg.Wait()
+
+		// then
+		assert.LessOrEqual(t, reserved.Load(), int32(2))
+	})
+
+	t.Run("should not exceed the quota with concurrent requests handled by different replicas", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		getter := &fakeGetter{quota: map[string]int{"sa-1/aws": 3}}
+		replicas := []*Enforcer{
+			NewEnforcer(getter, db.Instances(), db.QuotaReservations(), nil),
+			NewEnforcer(getter, db.Instances(), db.QuotaReservations(), nil),
+		}
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-0", "ga-1", "sa-1", broker.AWSPlanID)))
+
+		// when
+		var wg sync.WaitGroup
+		var reserved atomic.Int32
+		for i := 1; i <= 10; i++ {
+			wg.Add(1)
+			go func(enforcer *Enforcer, id string) {
+				defer wg.Done()
+				if _, err := enforcer.Reserve(context.Background(), id, "ga-1", "sa-1", broker.AWSPlanID, false); err == nil {
+					reserved.Add(1)
+				}
+			}(replicas[i%2], fmt.Sprintf("inst-%d", i))
+		}
+		wg.Wait()
+
+		// then
+		assert.LessOrEqual(t, reserved.Load(), int32(2))
+	})
+
+	t.Run("should not count expired reservations", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		enforcer := NewEnforcer(&fakeGetter{quota: map[string]int{"sa-1/aws": 2}}, db.Instances(), db.QuotaReservations(), nil)
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-0", "ga-1", "sa-1", broker.AWSPlanID)))
+		require.NoError(t, db.QuotaReservations().Upsert(internal.QuotaReservation{
+			InstanceID:      "inst-1",
+			GlobalAccountID: "ga-1",
+			SubAccountID:    "sa-1",
+			PlanID:          broker.AWSPlanID,
+			CreatedAt:       time.Now().Add(-2 * reservationTimeout),
+		}))
+
+		// when
+		release, err := enforcer.Reserve(context.Background(), "inst-2", "ga-1", "sa-1", broker.AWSPlanID, false)
+
+		// then
+		require.NoError(t, err)
+		release()
+		reservations, err := db.QuotaReservations().ListByPlanID(broker.AWSPlanID, time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, reservations)
+	})
+
+	t.Run("should not block other subaccounts while the assigned quota is fetched", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		getter := &blockingGetter{called: make(chan struct{}), unblock: make(chan struct{})}
+		enforcer := NewEnforcer(getter, db.Instances(), db.QuotaReservations(), nil)
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-1", "ga-1", "sa-1", broker.AWSPlanID)))
+		go func() {
+			_, _ = enforcer.Reserve(context.Background(), "inst-2", "ga-1", "sa-1", broker.AWSPlanID, false)
+		}()
+		<-getter.called
+		defer close(getter.unblock)
+
+		// when
+		release, err := enforcer.Reserve(context.Background(), "inst-3", "ga-1", "sa-2", broker.AWSPlanID, false)
+
+		// then
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("should enforce global account and directory limits", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		limits, err := NewLimits(strings.NewReader(fixLimits))
+		require.NoError(t, err)
+		quota := map[string]int{"sa-1/aws": 10, "sa-2/aws": 10, "sa-3/aws": 10}
+		enforcer := NewEnforcer(&fakeGetter{quota: quota}, db.Instances(), db.QuotaReservations(), limits)
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-1", "ga-1", "sa-1", broker.AWSPlanID)))
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-2", "ga-2", "sa-2", broker.AWSPlanID)))
+
+		// when
+		_, err = enforcer.Reserve(context.Background(), "inst-3", "ga-1", "sa-3", broker.AWSPlanID, false)
+
+		// then
+		require.NoError(t, err)
+
+		// when
+		_, err = enforcer.Reserve(context.Background(), "inst-4", "ga-1", "sa-4", broker.AWSPlanID, false)
+
+		// then
+		assert.EqualError(t, err, "Kyma instances limit exceeded for plan aws in globalAccount ga-1. limit: 2, remainingQuota: 0. Contact your administrator.")
+
+		// when
+		release, err := enforcer.Reserve(context.Background(), "inst-5", "ga-2", "sa-2", broker.AWSPlanID, false)
+		require.NoError(t, err)
+		_, err = enforcer.Reserve(context.Background(), "inst-6", "ga-2", "sa-1", broker.AWSPlanID, false)
+
+		// then
+		assert.EqualError(t, err, "Kyma instances limit exceeded for plan aws in directory dir-1. limit: 3, remainingQuota: 0. Contact your administrator.")
+
+		// when
+		release()
+		_, err = enforcer.Reserve(context.Background(), "inst-6", "ga-2", "sa-1", broker.AWSPlanID, false)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("should check the assigned quota for plan updates", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		enforcer := NewEnforcer(&fakeGetter{quota: map[string]int{"sa-1/azure": 0}}, db.Instances(), db.QuotaReservations(), nil)
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-1", "ga-1", "sa-1", broker.AWSPlanID)))
+
+		// when
+		_, err := enforcer.Reserve(context.Background(), "inst-1", "ga-1", "sa-1", broker.AzurePlanID, true)
+
+		// then
+		assert.EqualError(t, err, "Kyma instances quota exceeded for plan azure. assignedQuota: 0, remainingQuota: 0. Contact your administrator.")
+	})
+}
+
+func TestEnforcer_CheckGlobalAccountLimit(t *testing.T) {
+	t.Run("should enforce the global account limit", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		limits, err := NewLimits(strings.NewReader(fixLimits))
+		require.NoError(t, err)
+		enforcer := NewEnforcer(&fakeGetter{}, db.Instances(), db.QuotaReservations(), limits)
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-1", "ga-1", "sa-1", broker.AWSPlanID)))
+
+		// when
+		err = enforcer.CheckGlobalAccountLimit("ga-1", broker.AWSPlanID)
+
+		// then
+		require.NoError(t, err)
+
+		// when
+		require.NoError(t, db.Instances().Insert(fixInstance("inst-2", "ga-1", "sa-3", broker.AWSPlanID)))
+		err = enforcer.CheckGlobalAccountLimit("ga-1", broker.AWSPlanID)
+
+		// then
+		assert.EqualError(t, err, "Kyma instances limit exceeded for plan aws in globalAccount ga-1. limit: 2, remainingQuota: 0. Contact your administrator.")
+	})
+
+	t.Run("should ignore directory limits and global accounts without limits", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		limits, err := NewLimits(strings.NewReader(fixLimits))
+		require.NoError(t, err)
+		enforcer := NewEnforcer(&fakeGetter{}, db.Instances(), db.QuotaReservations(), limits)
+		for _, id := range []string{"inst-1", "inst-2", "inst-3"} {
+			require.NoError(t, db.Instances().Insert(fixInstance(id, "ga-2", "sa-1", broker.AWSPlanID)))
+		}
+
+		// when
+		err = enforcer.CheckGlobalAccountLimit("ga-2", broker.AWSPlanID)
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	limits, err := NewLimits(strings.NewReader(fixLimits))
+	require.NoError(t, err)
+	enforcer := NewEnforcer(&fakeGetter{quota: map[string]int{"sa-1/aws": 3, "sa-1/azure": 1}}, db.Instances(), db.QuotaReservations(), limits)
+	require.NoError(t, db.Instances().Insert(fixInstance("inst-1", "ga-1", "sa-1", broker.AWSPlanID)))
+	release, err := enforcer.Reserve(context.Background(), "inst-2", "ga-1", "sa-1", broker.AWSPlanID, false)
+	require.NoError(t, err)
+	defer release()
+
+	router := httputil.NewRouter()
+	NewHandler(enforcer, db.Instances(), []string{"aws", "azure"}, whitelist.Set{}, slog.Default()).AttachRoutes(router)
+
+	t.Run("should return the usage per plan", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quota/sa-1", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, w.Code)
+		var usage Usage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&usage))
+		assert.Equal(t, Usage{
+			SubAccountID:    "sa-1",
+			GlobalAccountID: "ga-1",
+			Plans: []PlanUsage{
+				{
+					Plan:      "aws",
+					Assigned:  3,
+					Used:      1,
+					Reserved:  1,
+					Available: 0,
+					Limits: []ScopeUsage{
+						{Scope: ScopeGlobalAccount, ID: "ga-1", Limit: 2, Used: 1, Reserved: 1, Available: 0},
+						{Scope: ScopeDirectory, ID: "dir-1", Limit: 3, Used: 1, Reserved: 1, Available: 1},
+					},
+				},
+				{Plan: "azure", Assigned: 1, Available: 1},
+			},
+		}, usage)
+	})
+
+	t.Run("should reject unknown plan", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quota/sa-1?plan=unknown", nil))
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func fixInstance(instanceID, globalAccountID, subAccountID, planID string) internal.Instance {
+	return internal.Instance{
+		InstanceID:      instanceID,
+		GlobalAccountID: globalAccountID,
+		SubAccountID:    subAccountID,
+		ServicePlanID:   planID,
+	}
+}
@@ -0,0 +1,106 @@
+package quota
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
+)
+
+type Usage struct {
+	SubAccountID    string      `json:"subaccountID"`
+	GlobalAccountID string      `json:"globalAccountID,omitempty"`
+	Unlimited       bool        `json:"unlimited"`
+	Plans           []PlanUsage `json:"plans"`
+}
+
+type PlanUsage struct {
+	Plan      string       `json:"plan"`
+	Assigned  int          `json:"assigned"`
+	Used      int          `json:"used"`
+	Reserved  int          `json:"reserved"`
+	Available int          `json:"available"`
+	Limits    []ScopeUsage `json:"limits,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+type ScopeUsage struct {
+	Scope     string `json:"scope"`
+	ID        string `json:"id"`
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Reserved  int    `json:"reserved"`
+	Available int    `json:"available"`
+}
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler interface {
+	AttachRoutes(r router)
+}
+
+type handler struct {
+	enforcer       *Enforcer
+	instances      storage.Instances
+	plans          []string
+	quotaWhitelist whitelist.Set
+	log            *slog.Logger
+}
+
+// NewHandler creates the handler of the endpoint which returns the quota usage of the subaccount for the given plan names.
+func NewHandler(enforcer *Enforcer, instances storage.Instances, plans []string, quotaWhitelist whitelist.Set, log *slog.Logger) Handler {
+	return &handler{
+		enforcer:       enforcer,
+		instances:      instances,
+		plans:          plans,
+		quotaWhitelist: quotaWhitelist,
+		log:            log.With("service", "QuotaEndpoint"),
+	}
+}
+
+func (h *handler) AttachRoutes(r router) {
+	r.HandleFunc("GET /quota/{subaccount_id}", h.getQuota)
+}
+
+func (h *handler) getQuota(w http.ResponseWriter, req *http.Request) {
+	subAccountID := req.PathValue("subaccount_id")
+
+	plans := h.plans
+	if param := req.URL.Query().Get("plan"); param != "" {
+		plans = strings.Split(param, ",")
+	}
+	planIDs := make([]string, 0, len(plans))
+	for _, planName := range plans {
+		planID, found := broker.AvailablePlans.GetPlanIDByName(broker.PlanNameType(planName))
+		if !found {
+			httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("unknown plan %s", planName))
+			return
+		}
+		planIDs = append(planIDs, string(planID))
+	}
+
+	globalAccountID := req.URL.Query().Get("globalaccount_id")
+	if globalAccountID == "" {
+		instances, _, _, err := h.instances.List(dbmodel.InstanceFilter{SubAccountIDs: []string{subAccountID}, PageSize: 1, Page: 1})
+		if err != nil {
+			h.log.Error(fmt.Sprintf("while listing instances of subaccount %s: %s", subAccountID, err))
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		if len(instances) > 0 {
+			globalAccountID = instances[0].GlobalAccountID
+		}
+	}
+
+	usage := h.enforcer.Usage(req.Context(), subAccountID, globalAccountID, planIDs)
+	usage.Unlimited = whitelist.IsWhitelisted(subAccountID, h.quotaWhitelist)
+	httputil.WriteResponse(w, http.StatusOK, usage)
+}
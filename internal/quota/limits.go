@@ -0,0 +1,130 @@
+package quota
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Directory groups subaccounts which share the limits of the plans.
+type Directory struct {
+	Subaccounts []string       `yaml:"subaccounts"`
+	Plans       map[string]int `yaml:"plans"`
+}
+
+type limitsDTO struct {
+	GlobalAccounts map[string]map[string]int `yaml:"globalAccounts"`
+	Directories    map[string]Directory      `yaml:"directories"`
+}
+
+// Limits holds the maximum number of instances per plan in global accounts and directories.
+// They are enforced in addition to the quota assigned to the subaccount in the entitlements service.
+type Limits struct {
+	globalAccounts map[string]map[string]int
+	directories    map[string]Directory
+	subaccounts    map[string]string
+}
+
+// Scope is a global account or a directory limit which applies to a subaccount.
+type Scope struct {
+	Kind        string
+	ID          string
+	Subaccounts []string
+	Limit       int
+}
+
+const (
+	ScopeGlobalAccount = "globalAccount"
+	ScopeDirectory     = "directory"
+)
+
+func NewLimitsFromFile(filePath string) (*Limits, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("while opening quota limits file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return NewLimits(file)
+}
+
+func NewLimits(r io.Reader) (*Limits, error) {
+	dto := limitsDTO{}
+	if err := yaml.NewDecoder(r).Decode(&dto); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("while decoding quota limits: %w", err)
+	}
+
+	limits := &Limits{
+		globalAccounts: make(map[string]map[string]int, len(dto.GlobalAccounts)),
+		directories:    make(map[string]Directory, len(dto.Directories)),
+		subaccounts:    make(map[string]string),
+	}
+	for globalAccountID, plans := range dto.GlobalAccounts {
+		if err := validatePlanLimits(plans); err != nil {
+			return nil, fmt.Errorf("global account %s: %w", globalAccountID, err)
+		}
+		limits.globalAccounts[globalAccountID] = plans
+	}
+	for directoryID, directory := range dto.Directories {
+		if err := validatePlanLimits(directory.Plans); err != nil {
+			return nil, fmt.Errorf("directory %s: %w", directoryID, err)
+		}
+		if len(directory.Subaccounts) == 0 {
+			return nil, fmt.Errorf("directory %s: empty list of subaccounts", directoryID)
+		}
+		for _, subAccountID := range directory.Subaccounts {
+			if other, found := limits.subaccounts[subAccountID]; found {
+				return nil, fmt.Errorf("subaccount %s belongs to directories %s and %s", subAccountID, other, directoryID)
+			}
+			limits.subaccounts[subAccountID] = directoryID
+		}
+		limits.directories[directoryID] = directory
+	}
+	return limits, nil
+}
+
+// NoLimits returns limits used when no quota limits file is configured.
+func NoLimits() *Limits {
+	return &Limits{}
+}
+
+// Scopes returns the global account and directory limits of the plan which apply to the subaccount.
+func (l *Limits) Scopes(globalAccountID, subAccountID, planName string) []Scope {
+	var scopes []Scope
+	if limit, found := l.globalAccounts[globalAccountID][planName]; found && globalAccountID != "" {
+		scopes = append(scopes, Scope{Kind: ScopeGlobalAccount, ID: globalAccountID, Limit: limit})
+	}
+	if directoryID, found := l.subaccounts[subAccountID]; found {
+		directory := l.directories[directoryID]
+		if limit, found := directory.Plans[planName]; found {
+			scopes = append(scopes, Scope{Kind: ScopeDirectory, ID: directoryID, Subaccounts: directory.Subaccounts, Limit: limit})
+		}
+	}
+	return scopes
+}
+
+func validatePlanLimits(plans map[string]int) error {
+	for planName, limit := range plans {
+		if _, found := broker.AvailablePlans.GetPlanIDByName(broker.PlanNameType(planName)); !found {
+			return fmt.Errorf("unknown plan %s", planName)
+		}
+		if limit < 0 {
+			return fmt.Errorf("negative limit %d for plan %s", limit, planName)
+		}
+	}
+	return nil
+}
+
+func (s Scope) contains(instanceGlobalAccountID, instanceSubAccountID string) bool {
+	switch s.Kind {
+	case ScopeGlobalAccount:
+		return instanceGlobalAccountID == s.ID
+	default:
+		return slices.Contains(s.Subaccounts, instanceSubAccountID)
+	}
+}
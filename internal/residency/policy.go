@@ -0,0 +1,275 @@
+package residency
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Features which can be forbidden for a platform region by a residency policy.
+const (
+	FeatureAdditionalWorkerNodePools = "additionalWorkerNodePools"
+	FeatureColocateControlPlane      = "colocateControlPlane"
+	FeatureIngressFiltering          = "ingressFiltering"
+	FeatureGvisor                    = "gvisor"
+	FeatureDualStack                 = "dualStack"
+	FeatureAuditLogAccess            = "auditLogAccess"
+	FeatureAccessControlList         = "accessControlList"
+)
+
+var knownFeatures = []string{
+	FeatureAdditionalWorkerNodePools,
+	FeatureColocateControlPlane,
+	FeatureIngressFiltering,
+	FeatureGvisor,
+	FeatureDualStack,
+	FeatureAuditLogAccess,
+	FeatureAccessControlList,
+}
+
+// Policy describes data residency constraints for a single BTP platform region.
+type Policy struct {
+	// EUAccess marks the platform region as EU restricted access, which is passed to KIM as the euAccess flag.
+	EUAccess bool `yaml:"euAccess"`
+	// AssuredWorkloads marks the platform region as requiring Assured Workloads (for example, KSA).
+	AssuredWorkloads bool `yaml:"assuredWorkloads"`
+	// HAPEUAccessPlans lists plans for which the HAP rule matched in the platform region must have the EU attribute.
+	HAPEUAccessPlans []string `yaml:"hapEuAccessPlans,omitempty"`
+	// HyperscalerRegions maps a provider to the hyperscaler regions allowed for the platform region.
+	// A provider which is not listed is not restricted.
+	HyperscalerRegions map[string][]string `yaml:"hyperscalerRegions,omitempty"`
+	// MachineFamilies lists allowed machine type prefixes. Empty means all machine types are allowed.
+	MachineFamilies []string `yaml:"machineFamilies,omitempty"`
+	// ForbiddenFeatures lists provisioning and update features which must not be used in the platform region.
+	ForbiddenFeatures []string `yaml:"forbiddenFeatures,omitempty"`
+}
+
+// Request holds the residency relevant attributes of a provisioning or update request.
+type Request struct {
+	Provider     runtime.CloudProvider
+	Region       string
+	MachineTypes []string
+	Features     []string
+}
+
+// Policies holds residency policies keyed by platform region.
+type Policies struct {
+	policies map[string]Policy
+}
+
+func NewPoliciesFromFile(filePath string) (*Policies, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("while opening residency policy file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return NewPolicies(file)
+}
+
+func NewPolicies(r io.Reader) (*Policies, error) {
+	dto := map[string]Policy{}
+	if err := yaml.NewDecoder(r).Decode(&dto); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("while decoding residency policies: %w", err)
+	}
+
+	policies := &Policies{policies: make(map[string]Policy, len(dto))}
+	for platformRegion, policy := range dto {
+		for _, feature := range policy.ForbiddenFeatures {
+			if !slices.Contains(knownFeatures, feature) {
+				return nil, fmt.Errorf("platform region %s: unknown forbidden feature %q, allowed: %s", platformRegion, feature, strings.Join(knownFeatures, ", "))
+			}
+		}
+		regions := make(map[string][]string, len(policy.HyperscalerRegions))
+		for provider, hyperscalerRegions := range policy.HyperscalerRegions {
+			if len(hyperscalerRegions) == 0 {
+				return nil, fmt.Errorf("platform region %s: empty list of hyperscaler regions for provider %s", platformRegion, provider)
+			}
+			if runtime.CloudProviderFromString(provider) == runtime.UnknownProvider {
+				return nil, fmt.Errorf("platform region %s: unknown provider %s", platformRegion, provider)
+			}
+			regions[providerKey(runtime.CloudProvider(provider))] = hyperscalerRegions
+		}
+		policy.HyperscalerRegions = regions
+		policies.policies[platformRegion] = policy
+	}
+	return policies, nil
+}
+
+// DefaultPolicies returns the policies used when no residency policy file is configured.
+// They only mark the EU Access and Assured Workloads platform regions supported so far and do not restrict regions, machines, or features.
+func DefaultPolicies() *Policies {
+	return &Policies{policies: map[string]Policy{
+		"cf-ch20": {EUAccess: true},
+		"cf-eu11": {EUAccess: true},
+		"cf-eu01": {EUAccess: true},
+		"cf-eu02": {EUAccess: true},
+		"cf-sa30": {AssuredWorkloads: true},
+	}}
+}
+
+func (p *Policies) For(platformRegion string) (Policy, bool) {
+	policy, found := p.policies[platformRegion]
+	return policy, found
+}
+
+func (p *Policies) PlatformRegions() []string {
+	regions := make([]string, 0, len(p.policies))
+	for region := range p.policies {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+func (p *Policies) IsEUAccess(platformRegion string) bool {
+	return p.policies[platformRegion].EUAccess
+}
+
+func (p *Policies) IsAssuredWorkloads(platformRegion string) bool {
+	return p.policies[platformRegion].AssuredWorkloads
+}
+
+func (p *Policies) RequiresHAPEUAccess(platformRegion, planName string) bool {
+	return slices.Contains(p.policies[platformRegion].HAPEUAccessPlans, planName)
+}
+
+// AllowedRegions returns the hyperscaler regions allowed for the provider in the platform region.
+// The second value is false when the policy does not restrict regions.
+func (p *Policies) AllowedRegions(platformRegion string, provider runtime.CloudProvider) ([]string, bool) {
+	policy, found := p.policies[platformRegion]
+	if !found {
+		return nil, false
+	}
+	regions, found := policy.HyperscalerRegions[providerKey(provider)]
+	return regions, found
+}
+
+// FilterRegions removes regions not allowed by the policy, keeping the original order.
+func (p *Policies) FilterRegions(platformRegion string, provider runtime.CloudProvider, regions []string) []string {
+	allowed, restricted := p.AllowedRegions(platformRegion, provider)
+	if !restricted {
+		return regions
+	}
+	filtered := make([]string, 0, len(regions))
+	for _, region := range regions {
+		if slices.Contains(allowed, region) {
+			filtered = append(filtered, region)
+		}
+	}
+	return filtered
+}
+
+// FilterMachineTypes removes machine types not allowed by the policy, keeping the original order.
+func (p *Policies) FilterMachineTypes(platformRegion string, machineTypes []string) []string {
+	policy, found := p.policies[platformRegion]
+	if !found || len(policy.MachineFamilies) == 0 {
+		return machineTypes
+	}
+	filtered := make([]string, 0, len(machineTypes))
+	for _, machineType := range machineTypes {
+		if policy.isMachineTypeAllowed(machineType) {
+			filtered = append(filtered, machineType)
+		}
+	}
+	return filtered
+}
+
+// Check verifies the request against the policy defined for the platform region.
+func (p *Policies) Check(platformRegion string, request Request) error {
+	policy, found := p.policies[platformRegion]
+	if !found {
+		return nil
+	}
+
+	if request.Region != "" {
+		if allowed, restricted := policy.HyperscalerRegions[providerKey(request.Provider)]; restricted && !slices.Contains(allowed, request.Region) {
+			return fmt.Errorf("region %s is not allowed in the %s platform region, allowed regions: %s", request.Region, platformRegion, strings.Join(allowed, ", "))
+		}
+	}
+
+	for _, machineType := range request.MachineTypes {
+		if machineType != "" && !policy.isMachineTypeAllowed(machineType) {
+			return fmt.Errorf("machine type %s is not allowed in the %s platform region, allowed machine families: %s", machineType, platformRegion, strings.Join(policy.MachineFamilies, ", "))
+		}
+	}
+
+	for _, feature := range request.Features {
+		if slices.Contains(policy.ForbiddenFeatures, feature) {
+			return fmt.Errorf("%s is not allowed in the %s platform region", feature, platformRegion)
+		}
+	}
+
+	return nil
+}
+
+// CheckHAPEUAccess verifies that the EU attribute of a HAP rule matched for the plan satisfies the policy.
+func (p *Policies) CheckHAPEUAccess(platformRegion, planName string, euAccess bool, rule string) error {
+	if p.RequiresHAPEUAccess(platformRegion, planName) && !euAccess {
+		return fmt.Errorf("HAP rule %q matched for the %s plan in the %s platform region does not have the EU attribute required by the residency policy", rule, planName, platformRegion)
+	}
+	return nil
+}
+
+// FeaturesFromProvisioningParameters returns the residency relevant features used in provisioning parameters.
+func FeaturesFromProvisioningParameters(parameters runtime.ProvisioningParametersDTO) []string {
+	var features []string
+	if len(parameters.AdditionalWorkerNodePools) > 0 {
+		features = append(features, FeatureAdditionalWorkerNodePools)
+	}
+	if parameters.ColocateControlPlane != nil && *parameters.ColocateControlPlane {
+		features = append(features, FeatureColocateControlPlane)
+	}
+	if parameters.IngressFiltering != nil && *parameters.IngressFiltering {
+		features = append(features, FeatureIngressFiltering)
+	}
+	if isGvisorEnabled(parameters.Gvisor, parameters.AdditionalWorkerNodePools) {
+		features = append(features, FeatureGvisor)
+	}
+	if parameters.Networking != nil && parameters.Networking.DualStack != nil && *parameters.Networking.DualStack {
+		features = append(features, FeatureDualStack)
+	}
+	if parameters.AuditLogAccess != nil && *parameters.AuditLogAccess {
+		features = append(features, FeatureAuditLogAccess)
+	}
+	if parameters.AccessControlList != nil {
+		features = append(features, FeatureAccessControlList)
+	}
+	return features
+}
+
+func isGvisorEnabled(gvisor *runtime.GvisorDTO, pools []runtime.AdditionalWorkerNodePool) bool {
+	if gvisor != nil && gvisor.Enabled {
+		return true
+	}
+	for _, pool := range pools {
+		if pool.Gvisor != nil && pool.Gvisor.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Policy) isMachineTypeAllowed(machineType string) bool {
+	if len(p.MachineFamilies) == 0 {
+		return true
+	}
+	for _, family := range p.MachineFamilies {
+		if strings.HasPrefix(machineType, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// providerKey normalizes provider names, so "sap-converged-cloud" and "SapConvergedCloud" point to the same policy entry
+func providerKey(provider runtime.CloudProvider) string {
+	return string(runtime.CloudProviderFromString(string(provider)))
+}
@@ -0,0 +1,215 @@
+package residency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const testPolicies = `
+cf-eu11:
+  euAccess: true
+  hapEuAccessPlans: [ "aws" ]
+  hyperscalerRegions:
+    aws: [ "eu-central-1" ]
+  machineFamilies: [ "m6i", "m5" ]
+  forbiddenFeatures: [ "colocateControlPlane" ]
+cf-sa30:
+  assuredWorkloads: true
+  hyperscalerRegions:
+    gcp: [ "me-central2" ]
+cf-eu01:
+  euAccess: true
+  hyperscalerRegions:
+    sap-converged-cloud: [ "eu-de-2" ]
+`
+
+func TestPolicies(t *testing.T) {
+	// given
+	policies, err := NewPolicies(strings.NewReader(testPolicies))
+	require.NoError(t, err)
+
+	t.Run("should return platform regions", func(t *testing.T) {
+		assert.Equal(t, []string{"cf-eu01", "cf-eu11", "cf-sa30"}, policies.PlatformRegions())
+	})
+
+	t.Run("should return flags", func(t *testing.T) {
+		assert.True(t, policies.IsEUAccess("cf-eu11"))
+		assert.True(t, policies.IsEUAccess("cf-eu01"))
+		assert.False(t, policies.IsEUAccess("cf-sa30"))
+		assert.False(t, policies.IsEUAccess("cf-eu10"))
+
+		assert.True(t, policies.IsAssuredWorkloads("cf-sa30"))
+		assert.False(t, policies.IsAssuredWorkloads("cf-eu11"))
+
+		assert.True(t, policies.RequiresHAPEUAccess("cf-eu11", "aws"))
+		assert.False(t, policies.RequiresHAPEUAccess("cf-eu11", "trial"))
+		assert.False(t, policies.RequiresHAPEUAccess("cf-eu01", "sap-converged-cloud"))
+	})
+
+	t.Run("should normalize provider names", func(t *testing.T) {
+		regions, restricted := policies.AllowedRegions("cf-eu01", runtime.SapConvergedCloud)
+		assert.True(t, restricted)
+		assert.Equal(t, []string{"eu-de-2"}, regions)
+
+		_, restricted = policies.AllowedRegions("cf-eu01", runtime.AWS)
+		assert.False(t, restricted)
+	})
+
+	t.Run("should filter regions", func(t *testing.T) {
+		assert.Equal(t, []string{"eu-central-1"}, policies.FilterRegions("cf-eu11", runtime.AWS, []string{"eu-west-2", "eu-central-1"}))
+		assert.Equal(t, []string{"westeurope"}, policies.FilterRegions("cf-eu11", runtime.Azure, []string{"westeurope"}))
+		assert.Equal(t, []string{"us-east-1"}, policies.FilterRegions("cf-us10", runtime.AWS, []string{"us-east-1"}))
+	})
+
+	t.Run("should filter machine types", func(t *testing.T) {
+		assert.Equal(t, []string{"m6i.large", "m5.xlarge"}, policies.FilterMachineTypes("cf-eu11", []string{"m6i.large", "g6.xlarge", "m5.xlarge"}))
+		assert.Equal(t, []string{"g6.xlarge"}, policies.FilterMachineTypes("cf-sa30", []string{"g6.xlarge"}))
+	})
+
+	t.Run("should check requests", func(t *testing.T) {
+		assert.NoError(t, policies.Check("cf-eu11", Request{Provider: runtime.AWS, Region: "eu-central-1", MachineTypes: []string{"m6i.large"}}))
+		assert.NoError(t, policies.Check("cf-us10", Request{Provider: runtime.AWS, Region: "us-east-1", Features: []string{FeatureColocateControlPlane}}))
+
+		err := policies.Check("cf-eu11", Request{Provider: runtime.AWS, Region: "eu-west-2"})
+		assert.EqualError(t, err, "region eu-west-2 is not allowed in the cf-eu11 platform region, allowed regions: eu-central-1")
+
+		err = policies.Check("cf-eu11", Request{Provider: runtime.AWS, MachineTypes: []string{"m6i.large", "g6.xlarge"}})
+		assert.EqualError(t, err, "machine type g6.xlarge is not allowed in the cf-eu11 platform region, allowed machine families: m6i, m5")
+
+		err = policies.Check("cf-eu11", Request{Provider: runtime.AWS, Features: []string{FeatureIngressFiltering, FeatureColocateControlPlane}})
+		assert.EqualError(t, err, "colocateControlPlane is not allowed in the cf-eu11 platform region")
+	})
+
+	t.Run("should check HAP EU access", func(t *testing.T) {
+		assert.NoError(t, policies.CheckHAPEUAccess("cf-eu11", "aws", true, "aws(PR=cf-eu11) -> EU"))
+		assert.NoError(t, policies.CheckHAPEUAccess("cf-eu11", "trial", false, "trial -> S"))
+		assert.Error(t, policies.CheckHAPEUAccess("cf-eu11", "aws", false, "aws"))
+	})
+}
+
+func TestNewPolicies_Invalid(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		input         string
+		expectedError string
+	}{
+		"unknown feature": {
+			input:         "cf-eu11: { forbiddenFeatures: [ foo ] }",
+			expectedError: `platform region cf-eu11: unknown forbidden feature "foo"`,
+		},
+		"unknown provider": {
+			input:         "cf-eu11: { hyperscalerRegions: { foo: [ bar ] } }",
+			expectedError: "platform region cf-eu11: unknown provider foo",
+		},
+		"empty regions": {
+			input:         "cf-eu11: { hyperscalerRegions: { aws: [] } }",
+			expectedError: "platform region cf-eu11: empty list of hyperscaler regions for provider aws",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			_, err := NewPolicies(strings.NewReader(tc.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+		})
+	}
+}
+
+func TestDefaultPolicies(t *testing.T) {
+	policies := DefaultPolicies()
+
+	for _, platformRegion := range []string{"cf-ch20", "cf-eu11", "cf-eu01", "cf-eu02"} {
+		assert.True(t, policies.IsEUAccess(platformRegion))
+	}
+	assert.True(t, policies.IsAssuredWorkloads("cf-sa30"))
+	assert.False(t, policies.IsEUAccess("cf-eu10"))
+
+	_, restricted := policies.AllowedRegions("cf-eu11", runtime.AWS)
+	assert.False(t, restricted)
+	assert.False(t, policies.RequiresHAPEUAccess("cf-eu11", "aws"))
+}
+
+func TestFeaturesFromProvisioningParameters(t *testing.T) {
+	enabled := true
+
+	assert.Empty(t, FeaturesFromProvisioningParameters(runtime.ProvisioningParametersDTO{}))
+	assert.Equal(t, []string{FeatureAdditionalWorkerNodePools, FeatureColocateControlPlane, FeatureGvisor}, FeaturesFromProvisioningParameters(runtime.ProvisioningParametersDTO{
+		ColocateControlPlane: &enabled,
+		AdditionalWorkerNodePools: []runtime.AdditionalWorkerNodePool{
+			{Name: "pool", Gvisor: &runtime.GvisorDTO{Enabled: true}},
+		},
+	}))
+}
+
+func TestValidate(t *testing.T) {
+	// given
+	policies, err := NewPolicies(strings.NewReader(testPolicies))
+	require.NoError(t, err)
+
+	providerSpec, err := configuration.NewProviderSpec(strings.NewReader(`
+aws:
+  regions:
+    eu-central-1:
+      displayName: "eu-central-1 (Europe, Frankfurt)"
+      zones: [ "a", "b", "c" ]
+  machines:
+    "m6i.large": "m6i.large (2vCPU, 8GB RAM)"
+gcp:
+  regions:
+    me-central2:
+      displayName: "me-central2 (KSA, Dammam)"
+      zones: [ "a", "b", "c" ]
+sap-converged-cloud:
+  regions:
+    eu-de-1:
+      displayName: "eu-de-1"
+      zones: [ "a", "b", "d" ]
+`))
+	require.NoError(t, err)
+
+	planSpec, err := configuration.NewPlanSpecifications(strings.NewReader(`
+aws:
+  regions:
+    cf-eu11: [ "eu-central-1", "eu-west-2" ]
+    default: [ "eu-central-1" ]
+gcp:
+  regions:
+    default: [ "me-central2" ]
+`))
+	require.NoError(t, err)
+
+	planProviders := map[string]runtime.CloudProvider{"aws": runtime.AWS, "gcp": runtime.GCP}
+
+	t.Run("should validate providers", func(t *testing.T) {
+		errs := policies.ValidateProviders(providerSpec)
+
+		require.Len(t, errs, 2)
+		assert.Contains(t, errs[0].Error(), "platform region cf-eu01")
+		assert.Contains(t, errs[0].Error(), "eu-de-2")
+		assert.EqualError(t, errs[1], "platform region cf-eu11: machine family m5 does not match any AWS machine type")
+	})
+
+	t.Run("should validate plans", func(t *testing.T) {
+		errs := policies.ValidatePlans(planSpec, planProviders)
+
+		require.Len(t, errs, 1)
+		assert.EqualError(t, errs[0], "platform region cf-eu11: plan aws offers region eu-west-2 which is not allowed by the residency policy")
+	})
+
+	t.Run("should validate HAP rules", func(t *testing.T) {
+		rulesService, err := rules.NewRulesServiceFromSlice([]string{"aws", "aws(PR=cf-eu11) -> EU", "gcp"}, sets.New("aws", "gcp"), sets.New[string]())
+		require.NoError(t, err)
+		assert.Empty(t, policies.ValidateHAPRules(rulesService, planProviders))
+
+		rulesService, err = rules.NewRulesServiceFromSlice([]string{"aws", "gcp"}, sets.New("aws", "gcp"), sets.New[string]())
+		require.NoError(t, err)
+		errs := policies.ValidateHAPRules(rulesService, planProviders)
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "does not have the EU attribute required by the residency policy")
+	})
+}
@@ -0,0 +1,106 @@
+package residency
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+)
+
+// ValidateProviders checks that every hyperscaler region and machine family used in the policies is defined in the providers configuration.
+func (p *Policies) ValidateProviders(providerSpec *configuration.ProviderSpec) []error {
+	var errs []error
+	for _, platformRegion := range p.PlatformRegions() {
+		policy := p.policies[platformRegion]
+		for _, provider := range sortedKeys(policy.HyperscalerRegions) {
+			cp := runtime.CloudProviderFromString(provider)
+			for _, region := range policy.HyperscalerRegions[provider] {
+				if err := providerSpec.Validate(cp, region); err != nil {
+					errs = append(errs, fmt.Errorf("platform region %s: %w", platformRegion, err))
+				}
+			}
+			for _, family := range policy.MachineFamilies {
+				if !matchesAnyMachine(family, providerSpec.MachineTypes(cp)) {
+					errs = append(errs, fmt.Errorf("platform region %s: machine family %s does not match any %s machine type", platformRegion, family, cp))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// ValidatePlans checks that plans do not offer hyperscaler regions which are forbidden by the policies.
+// planProviders maps a plan name to its cloud provider.
+func (p *Policies) ValidatePlans(planSpec *configuration.PlanSpecifications, planProviders map[string]runtime.CloudProvider) []error {
+	var errs []error
+	for _, platformRegion := range p.PlatformRegions() {
+		for _, planName := range sortedKeys(planProviders) {
+			allowed, restricted := p.AllowedRegions(platformRegion, planProviders[planName])
+			if !restricted {
+				continue
+			}
+			for _, region := range planSpec.Regions(planName, platformRegion) {
+				if !slices.Contains(allowed, region) {
+					errs = append(errs, fmt.Errorf("platform region %s: plan %s offers region %s which is not allowed by the residency policy", platformRegion, planName, region))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// EUAccessMatcher matches the HAP rule for the given attributes, it is implemented by the rules.RulesService.
+// The rules package is not imported directly, because its tests depend on the internal package, which depends on this one.
+type EUAccessMatcher interface {
+	MatchEUAccess(plan, platformRegion, hyperscalerRegion, hyperscaler string) (euAccess bool, rule string, found bool)
+}
+
+// ValidateHAPRules checks that HAP rules matched for plans requiring EU access have the EU attribute.
+// planProviders maps a plan name to its cloud provider.
+func (p *Policies) ValidateHAPRules(rulesService EUAccessMatcher, planProviders map[string]runtime.CloudProvider) []error {
+	var errs []error
+	for _, platformRegion := range p.PlatformRegions() {
+		for _, planName := range p.policies[platformRegion].HAPEUAccessPlans {
+			provider, found := planProviders[planName]
+			if !found {
+				errs = append(errs, fmt.Errorf("platform region %s: unknown plan %s in hapEuAccessPlans", platformRegion, planName))
+				continue
+			}
+			regions, restricted := p.AllowedRegions(platformRegion, provider)
+			if !restricted {
+				regions = []string{""}
+			}
+			for _, region := range regions {
+				euAccess, rule, found := rulesService.MatchEUAccess(planName, platformRegion, region, strings.ToLower(string(provider)))
+				if !found {
+					continue
+				}
+				if err := p.CheckHAPEUAccess(platformRegion, planName, euAccess, rule); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func matchesAnyMachine(family string, machineTypes []string) bool {
+	for _, machineType := range machineTypes {
+		if strings.HasPrefix(machineType, family) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -22,7 +22,9 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/common/pagination"
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/expiration"
 	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/instancetags"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
@@ -40,10 +42,15 @@ type Handler struct {
 	bindingsDb          storage.Bindings
 	instancesArchivedDb storage.InstancesArchived
 	actionsDb           storage.Actions
+	stepExecutionsDb    storage.StepExecutions
+	instanceTagsDb      storage.InstanceTags
 	converter           Converter
 	defaultMaxPage      int
-	k8sClient           client.Client
-	logger              *slog.Logger
+	// expirationPeriods are used to calculate the expiration time of the trial and free instances
+	expirationPeriods expiration.Periods
+	kymaStatus        broker.KymaStatusProvider
+	k8sClient         client.Client
+	logger            *slog.Logger
 }
 
 func NewHandler(storage storage.BrokerStorage, defaultMaxPage int, defaultRequestRegion string,
@@ -54,6 +61,8 @@ func NewHandler(storage storage.BrokerStorage, defaultMaxPage int, defaultReques
 		bindingsDb:          storage.Bindings(),
 		instancesArchivedDb: storage.InstancesArchived(),
 		actionsDb:           storage.Actions(),
+		stepExecutionsDb:    storage.StepExecutions(),
+		instanceTagsDb:      storage.InstanceTags(),
 		converter:           NewConverter(defaultRequestRegion),
 		defaultMaxPage:      defaultMaxPage,
 		k8sClient:           k8sClient,
@@ -61,6 +70,18 @@ func NewHandler(storage storage.BrokerStorage, defaultMaxPage int, defaultReques
 	}
 }
 
+// WithExpirationPeriods sets the periods after which the trial and free instances expire, which are shown in the expiresAt field
+func (h *Handler) WithExpirationPeriods(periods expiration.Periods) *Handler {
+	h.expirationPeriods = periods
+	return h
+}
+
+// WithKymaStatus makes the handler return the status of the Kyma custom resource with the runtime status
+func (h *Handler) WithKymaStatus(provider broker.KymaStatusProvider) *Handler {
+	h.kymaStatus = provider
+	return h
+}
+
 func (h *Handler) AttachRoutes(router *httputil.Router) {
 	router.HandleFunc("/runtimes", h.GetRuntimes)
 }
@@ -88,9 +109,15 @@ func (h *Handler) listInstances(filter dbmodel.InstanceFilter) ([]pkg.RuntimeDTO
 		filter.DeletionAttempted = &deletionAttempted
 		instances, instancesCount, instancesTotalCount, _ := h.instancesDb.List(filter)
 
-		instancesArchived, instancesArchivedCount, instancesArchivedTotalCount, err := h.instancesArchivedDb.List(filter)
-		if err != nil {
-			return []pkg.RuntimeDTO{}, instancesArchivedCount, instancesArchivedTotalCount, err
+		var instancesArchived []internal.InstanceArchived
+		var instancesArchivedCount, instancesArchivedTotalCount int
+		// the tags are removed when the instance is archived, so archived instances are not selected by tags
+		if filter.TagSelector == nil {
+			var err error
+			instancesArchived, instancesArchivedCount, instancesArchivedTotalCount, err = h.instancesArchivedDb.List(filter)
+			if err != nil {
+				return []pkg.RuntimeDTO{}, instancesArchivedCount, instancesArchivedTotalCount, err
+			}
 		}
 
 		// return union of all sets of instances
@@ -136,6 +163,7 @@ func (h *Handler) listInstances(filter dbmodel.InstanceFilter) ([]pkg.RuntimeDTO
 		dto, err := h.converter.NewDTO(instance.Instance)
 		dto.BetaEnabled = instance.BetaEnabled
 		dto.UsedForProduction = instance.UsedForProduction
+		h.setExpiresAt(&dto, instance.Instance)
 		if err != nil {
 			return []pkg.RuntimeDTO{}, 0, 0, err
 		}
@@ -144,6 +172,19 @@ func (h *Handler) listInstances(filter dbmodel.InstanceFilter) ([]pkg.RuntimeDTO
 	return result, count, total, nil
 }
 
+// setExpiresAt sets the expiration time of the trial and free instances which are not expired yet
+func (h *Handler) setExpiresAt(dto *pkg.RuntimeDTO, instance internal.Instance) {
+	if instance.IsExpired() {
+		return
+	}
+	period, expiring := h.expirationPeriods.ForPlan(instance.ServicePlanID)
+	if !expiring || (period == 0 && instance.ExpiresAt == nil) {
+		return
+	}
+	expiresAt := instance.ExpirationTime(period)
+	dto.ExpiresAt = &expiresAt
+}
+
 func (h *Handler) InstanceFromInstanceArchived(archived internal.InstanceArchived) internal.Instance {
 	return internal.Instance{
 		InstanceID:                  archived.InstanceID,
@@ -186,10 +227,28 @@ func (h *Handler) GetRuntimes(w http.ResponseWriter, req *http.Request) {
 	filter := h.getFilters(req)
 	filter.PageSize = pageSize
 	filter.Page = page
+	if selector := req.URL.Query().Get(pkg.TagsParam); selector != "" {
+		filter.TagSelector, err = instancetags.ParseSelector(selector)
+		if err != nil {
+			httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+			return
+		}
+	}
+	filter.After, err = getCursor(req, filter)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
+	fields, err := getProjection(req)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
 	opDetail := getOpDetail(req)
 	runtimeResourceConfig := getBoolParam(pkg.RuntimeConfigParam, req)
 	bindings := getBoolParam(pkg.BindingsParam, req)
 	actions := getBoolParam(pkg.ActionsParam, req)
+	stepExecutions := getBoolParam(pkg.StepExecutionsParam, req)
 
 	instances, count, totalCount, err := h.listInstances(filter)
 	if err != nil {
@@ -197,30 +256,49 @@ func (h *Handler) GetRuntimes(w http.ResponseWriter, req *http.Request) {
 		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while fetching instances: %s", err.Error()))
 		return
 	}
-
-	for _, dto := range instances {
-
-		switch opDetail {
-		case pkg.AllOperation:
-			err = h.addAllOperationsToRuntime(&dto)
-		case
-			pkg.LastOperation:
-			err = h.addLastOperationToRuntime(&dto)
-		}
+	var tags map[string]map[string]string
+	if fields.includes(tagsField) {
+		tags, err = h.getTags(instances)
 		if err != nil {
-			h.logger.Warn(fmt.Sprintf("unable to set operations: %s", err.Error()))
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+			h.logger.Warn(fmt.Sprintf("unable to fetch tags: %s", err.Error()))
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while fetching tags: %s", err.Error()))
 			return
 		}
+	}
 
-		err = h.determineStatusModifiedAt(&dto)
-		if err != nil {
-			h.logger.Warn(fmt.Sprintf("unable to determine status: %s", err.Error()))
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
-			return
+	for _, dto := range instances {
+		dto.Tags = tags[dto.InstanceID]
+
+		// the operations are fetched only if the status is returned
+		if fields.includes(statusField) {
+			switch opDetail {
+			case pkg.AllOperation:
+				err = h.addAllOperationsToRuntime(&dto)
+			case
+				pkg.LastOperation:
+				err = h.addLastOperationToRuntime(&dto)
+			}
+			if err != nil {
+				h.logger.Warn(fmt.Sprintf("unable to set operations: %s", err.Error()))
+				httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			err = h.determineStatusModifiedAt(&dto)
+			if err != nil {
+				h.logger.Warn(fmt.Sprintf("unable to determine status: %s", err.Error()))
+				httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			if h.kymaStatus != nil {
+				if kymaStatus, found := h.kymaStatus.Status(dto.InstanceID); found {
+					dto.Status.Kyma = kymaStatus
+				}
+			}
 		}
 
-		if runtimeResourceConfig && dto.RuntimeID != "" {
+		if runtimeResourceConfig && fields.includes(runtimeConfigField) && dto.RuntimeID != "" {
 			runtimeResourceName, runtimeNamespaceName := h.getRuntimeNamesFromLastOperation(dto)
 
 			runtimeResourceObject := &unstructured.Unstructured{}
@@ -250,7 +328,7 @@ func (h *Handler) GetRuntimes(w http.ResponseWriter, req *http.Request) {
 			}
 
 		}
-		if bindings {
+		if bindings && fields.includes(bindingsField) {
 			err := h.addBindings(&dto)
 			if err != nil {
 				h.logger.Warn(fmt.Sprintf("unable to apply bindings: %s", err.Error()))
@@ -258,7 +336,7 @@ func (h *Handler) GetRuntimes(w http.ResponseWriter, req *http.Request) {
 				return
 			}
 		}
-		if actions {
+		if actions && fields.includes(actionsField) {
 			actions, err := h.actionsDb.ListActionsByInstanceID(dto.InstanceID)
 			if err != nil {
 				h.logger.Warn(fmt.Sprintf("unable to list actions: %s", err.Error()))
@@ -267,6 +345,14 @@ func (h *Handler) GetRuntimes(w http.ResponseWriter, req *http.Request) {
 			}
 			dto.Actions = actions
 		}
+		if stepExecutions && fields.includes(statusField) {
+			err := h.addStepExecutions(&dto)
+			if err != nil {
+				h.logger.Warn(fmt.Sprintf("unable to apply step executions: %s", err.Error()))
+				httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
 
 		toReturn = append(toReturn, dto)
 	}
@@ -275,8 +361,24 @@ func (h *Handler) GetRuntimes(w http.ResponseWriter, req *http.Request) {
 		Data:       toReturn,
 		Count:      count,
 		TotalCount: totalCount,
+		NextCursor: nextCursor(instances, filter),
+	}
+	if fields == nil {
+		httputil.WriteResponseWithETag(w, req, http.StatusOK, runtimePage)
+		return
+	}
+	projected, err := fields.project(runtimePage.Data)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("unable to project runtimes: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
 	}
-	httputil.WriteResponse(w, http.StatusOK, runtimePage)
+	httputil.WriteResponseWithETag(w, req, http.StatusOK, projectedRuntimesPage{
+		Data:       projected,
+		Count:      runtimePage.Count,
+		TotalCount: runtimePage.TotalCount,
+		NextCursor: runtimePage.NextCursor,
+	})
 }
 
 func (h *Handler) getRuntimeNamesFromLastOperation(dto pkg.RuntimeDTO) (string, string) {
@@ -473,6 +575,14 @@ func (h *Handler) getFilters(req *http.Request) dbmodel.InstanceFilter {
 	return filter
 }
 
+func (h *Handler) getTags(instances []pkg.RuntimeDTO) (map[string]map[string]string, error) {
+	instanceIDs := make([]string, 0, len(instances))
+	for _, dto := range instances {
+		instanceIDs = append(instanceIDs, dto.InstanceID)
+	}
+	return h.instanceTagsDb.GetByInstanceIDs(instanceIDs)
+}
+
 func (h *Handler) addBindings(p *pkg.RuntimeDTO) error {
 	bindings, err := h.bindingsDb.ListByInstanceID(p.InstanceID)
 	if err != nil {
@@ -493,6 +603,92 @@ func (h *Handler) addBindings(p *pkg.RuntimeDTO) error {
 	return nil
 }
 
+// addStepExecutions attaches the recorded step executions to the operations already set in the runtime
+func (h *Handler) addStepExecutions(p *pkg.RuntimeDTO) error {
+	var operations []*pkg.Operation
+	operations = append(operations, p.Status.Provisioning, p.Status.Deprovisioning)
+	for _, data := range []*pkg.OperationsData{p.Status.UpgradingCluster, p.Status.Suspension, p.Status.Unsuspension} {
+		if data == nil {
+			continue
+		}
+		for i := range data.Data {
+			operations = append(operations, &data.Data[i])
+		}
+	}
+	if p.Status.Update != nil {
+		for i := range p.Status.Update.Data {
+			operations = append(operations, &p.Status.Update.Data[i])
+		}
+	}
+
+	byID := make(map[string]*pkg.Operation)
+	ids := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		if operation == nil || operation.OperationID == "" {
+			continue
+		}
+		byID[operation.OperationID] = operation
+		ids = append(ids, operation.OperationID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	executions, err := h.stepExecutionsDb.ListByOperationIDs(ids)
+	if err != nil {
+		return err
+	}
+	for _, execution := range executions {
+		operation, found := byID[execution.OperationID]
+		if !found {
+			continue
+		}
+		operation.StepExecutions = append(operation.StepExecutions, pkg.StepExecution{
+			Stage:             execution.Stage,
+			Step:              execution.Step,
+			Attempt:           execution.Attempt,
+			StartedAt:         execution.StartedAt,
+			FinishedAt:        execution.FinishedAt,
+			Result:            string(execution.Result),
+			RetryAfterSeconds: int(execution.RetryAfter.Seconds()),
+			ErrorReason:       execution.ErrorReason,
+			ErrorComponent:    execution.ErrorComponent,
+		})
+	}
+
+	return nil
+}
+
+// getCursor returns the cursor of the cursor-based pagination. It cannot be combined with the page parameter,
+// and it is not supported for deprovisioned runtimes, because they are listed from two tables.
+func getCursor(req *http.Request, filter dbmodel.InstanceFilter) (*pagination.Cursor, error) {
+	query := req.URL.Query()
+	encoded := query.Get(pagination.CursorParam)
+	if encoded == "" {
+		return nil, nil
+	}
+	if query.Has(pagination.PageParam) {
+		return nil, fmt.Errorf("%s cannot be used together with %s", pagination.CursorParam, pagination.PageParam)
+	}
+	if slices.Contains(filter.States, dbmodel.InstanceDeprovisioned) {
+		return nil, fmt.Errorf("%s is not supported for %s runtimes", pagination.CursorParam, pkg.StateDeprovisioned)
+	}
+	cursor, err := pagination.DecodeCursor(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// nextCursor returns the cursor of the next page if the page is full
+func nextCursor(instances []pkg.RuntimeDTO, filter dbmodel.InstanceFilter) string {
+	if slices.Contains(filter.States, dbmodel.InstanceDeprovisioned) || len(instances) == 0 || len(instances) < filter.PageSize {
+		return ""
+	}
+	last := instances[len(instances)-1]
+	return pagination.EncodeCursor(pagination.Cursor{CreatedAt: last.Status.CreatedAt, ID: last.InstanceID})
+}
+
 func getOpDetail(req *http.Request) pkg.OperationDetail {
 	opDetail := pkg.AllOperation
 	opDetailParams := req.URL.Query()[pkg.OperationDetailParam]
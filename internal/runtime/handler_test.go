@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/expiration"
 	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -20,6 +22,7 @@ import (
 
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
@@ -194,6 +197,300 @@ func TestRuntimeHandler(t *testing.T) {
 		assert.Equal(t, testID1, out.Data[0].InstanceID)
 	})
 
+	t.Run("test tags filtering should work", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		for i, id := range []string{testID1, testID2} {
+			instance := fixInstance(id, time.Now().Add(time.Duration(i)*time.Minute))
+			require.NoError(t, db.Instances().Insert(instance))
+			require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation(fixRandomID(), id)))
+		}
+		require.NoError(t, db.InstanceTags().Patch(testID1, map[string]*string{"customer-tier": ptr.String("gold"), "incident": ptr.String("1234")}))
+		require.NoError(t, db.InstanceTags().Patch(testID2, map[string]*string{"customer-tier": ptr.String("silver")}))
+
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		for selector, expectedIDs := range map[string][]string{
+			"customer-tier=gold":               {testID1},
+			"customer-tier in (gold,silver)":   {testID1, testID2},
+			"customer-tier,!incident":          {testID2},
+			"customer-tier!=gold":              {testID2},
+			"customer-tier=bronze":             {},
+			"incident,customer-tier notin (a)": {testID1},
+		} {
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/runtimes?tags="+url.QueryEscape(selector), nil)
+			require.NoError(t, err)
+
+			// when
+			router.ServeHTTP(rr, req)
+
+			// then
+			require.Equal(t, http.StatusOK, rr.Code, selector)
+			var out pkg.RuntimesPage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+			ids := make([]string, 0, len(out.Data))
+			for _, dto := range out.Data {
+				ids = append(ids, dto.InstanceID)
+			}
+			assert.ElementsMatch(t, expectedIDs, ids, selector)
+		}
+
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/runtimes?tags="+url.QueryEscape("customer-tier=gold"), nil)
+		require.NoError(t, err)
+		router.ServeHTTP(rr, req)
+		var out pkg.RuntimesPage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, map[string]string{"customer-tier": "gold", "incident": "1234"}, out.Data[0].Tags)
+	})
+
+	t.Run("test invalid tags filter", func(t *testing.T) {
+		// given
+		runtimeHandler := runtime.NewHandler(storage.NewMemoryStorage(), 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/runtimes?tags="+url.QueryEscape("customer-tier in (gold"), nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("test cursor pagination should work", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		createdAt := time.Now()
+		// two instances created at the same time are ordered by the instance ID
+		for i, id := range []string{testID1, testID2, testID3, testID4} {
+			require.NoError(t, db.Instances().Insert(fixInstance(id, createdAt.Add(time.Duration(i/2)*time.Minute))))
+		}
+
+		runtimeHandler := runtime.NewHandler(db, 3, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		var ids []string
+		cursor := ""
+		for pages := 0; pages < 3; pages++ {
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/runtimes?page_size=3&cursor="+cursor, nil)
+			require.NoError(t, err)
+
+			// when
+			router.ServeHTTP(rr, req)
+
+			// then
+			require.Equal(t, http.StatusOK, rr.Code)
+			var out pkg.RuntimesPage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+			assert.Equal(t, 4, out.TotalCount)
+			for _, dto := range out.Data {
+				ids = append(ids, dto.InstanceID)
+			}
+			if out.NextCursor == "" {
+				break
+			}
+			cursor = out.NextCursor
+		}
+		assert.Equal(t, []string{testID1, testID2, testID3, testID4}, ids)
+	})
+
+	t.Run("test invalid cursor", func(t *testing.T) {
+		// given
+		runtimeHandler := runtime.NewHandler(storage.NewMemoryStorage(), 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+		cursor := pagination.EncodeCursor(pagination.Cursor{CreatedAt: time.Now(), ID: testID1})
+
+		for _, query := range []string{
+			"cursor=invalid",
+			"cursor=" + cursor + "&page=2",
+			"cursor=" + cursor + "&state=deprovisioned",
+		} {
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/runtimes?"+query, nil)
+			require.NoError(t, err)
+
+			// when
+			router.ServeHTTP(rr, req)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+		}
+	})
+
+	t.Run("test fields projection", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance(testID1, time.Now())))
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation(fixRandomID(), testID1)))
+		require.NoError(t, db.InstanceTags().Patch(testID1, map[string]*string{"customer-tier": ptr.String("gold")}))
+
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/runtimes?fields=globalAccountID,tags&actions=true", nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, map[string]interface{}{
+			"instanceID":      testID1,
+			"globalAccountID": testID1,
+			"tags":            map[string]interface{}{"customer-tier": "gold"},
+		}, out.Data[0])
+	})
+
+	t.Run("test unknown field in projection", func(t *testing.T) {
+		// given
+		runtimeHandler := runtime.NewHandler(storage.NewMemoryStorage(), 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/runtimes?fields=instanceID,kubeconfig", nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("test ETag", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		require.NoError(t, db.Instances().Insert(fixInstance(testID1, time.Now())))
+
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/runtimes", nil)
+		require.NoError(t, err)
+		router.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		etag := rr.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		// when
+		rr = httptest.NewRecorder()
+		req.Header.Set("If-None-Match", etag)
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+
+		// when
+		require.NoError(t, db.Instances().Insert(fixInstance(testID2, time.Now())))
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+	})
+
+	t.Run("test expiresAt", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		createdAt := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+		extendedExpiration := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+		trial := fixInstance("trial", createdAt)
+		trial.ServicePlanID = broker.TrialPlanID
+		free := fixInstance("free", createdAt.Add(time.Minute))
+		free.ServicePlanID = broker.FreemiumPlanID
+		free.ExpiresAt = &extendedExpiration
+		expired := fixInstance("expired", createdAt.Add(2*time.Minute))
+		expired.ServicePlanID = broker.TrialPlanID
+		expired.ExpiredAt = ptr.Time(createdAt)
+		paid := fixInstance("paid", createdAt.Add(3*time.Minute))
+		paid.ServicePlanID = broker.AWSPlanID
+		for _, instance := range []internal.Instance{trial, free, expired, paid} {
+			require.NoError(t, db.Instances().Insert(instance))
+		}
+
+		runtimeHandler := runtime.NewHandler(db, 10, "", k8sClient, log).WithExpirationPeriods(expiration.Periods{Trial: 14 * 24 * time.Hour, Free: 90 * 24 * time.Hour})
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/runtimes", nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out pkg.RuntimesPage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		require.Len(t, out.Data, 4)
+		expiresAt := map[string]*time.Time{}
+		for _, dto := range out.Data {
+			expiresAt[dto.InstanceID] = dto.ExpiresAt
+		}
+		require.NotNil(t, expiresAt["trial"])
+		assert.True(t, createdAt.Add(14*24*time.Hour).Equal(*expiresAt["trial"]))
+		require.NotNil(t, expiresAt["free"])
+		assert.True(t, extendedExpiration.Equal(*expiresAt["free"]))
+		assert.Nil(t, expiresAt["expired"])
+		assert.Nil(t, expiresAt["paid"])
+	})
+
+	t.Run("test kyma status", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		for _, id := range []string{testID1, testID2} {
+			require.NoError(t, db.Instances().Insert(fixInstance(id, time.Now())))
+			require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation(fixRandomID(), id)))
+		}
+		kymaStatus := &pkg.KymaStatus{
+			State:   "Warning",
+			Modules: []pkg.KymaModuleStatus{{Name: "istio", State: "Warning", Version: "1.10.0", Channel: "regular"}},
+		}
+
+		runtimeHandler := runtime.NewHandler(db, 10, "", k8sClient, log).WithKymaStatus(fixKymaStatusProvider{testID1: kymaStatus})
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/runtimes", nil)
+		require.NoError(t, err)
+
+		// when
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out pkg.RuntimesPage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		require.Len(t, out.Data, 2)
+		for _, dto := range out.Data {
+			if dto.InstanceID == testID1 {
+				assert.Equal(t, kymaStatus, dto.Status.Kyma)
+			} else {
+				assert.Nil(t, dto.Status.Kyma)
+			}
+		}
+	})
+
 	t.Run("test state filtering should work", func(t *testing.T) {
 		// given
 		db := storage.NewMemoryStorage()
@@ -1109,6 +1406,58 @@ func TestRuntimeHandler_WithKimOnlyDrivenInstances(t *testing.T) {
 		assert.Equal(t, out.Data[0].Actions[0].Type, pkg.SubaccountMovementActionType)
 		assert.Equal(t, out.Data[0].Actions[1].Type, pkg.PlanUpdateActionType)
 	})
+
+	t.Run("test step executions", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		testTime := time.Now()
+		testInstance := fixInstanceForPreview(testID1, testTime)
+
+		err := db.Instances().Insert(testInstance)
+		require.NoError(t, err)
+
+		provOp := fixture.FixProvisioningOperation(fixRandomID(), testID1)
+		err = db.Operations().InsertOperation(provOp)
+		require.NoError(t, err)
+
+		for _, result := range []internal.StepExecutionResult{internal.StepExecutionRetry, internal.StepExecutionSucceeded} {
+			err = db.StepExecutions().Insert(internal.StepExecution{
+				OperationID: provOp.ID,
+				InstanceID:  testID1,
+				Stage:       "create_runtime",
+				Step:        "Create_Runtime_Resource",
+				StartedAt:   testTime,
+				FinishedAt:  testTime.Add(time.Second),
+				Result:      result,
+			})
+			require.NoError(t, err)
+		}
+
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+
+		rr := httptest.NewRecorder()
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		req, err := http.NewRequest("GET", "/runtimes?step_executions=true", nil)
+		require.NoError(t, err)
+		router.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var out pkg.RuntimesPage
+
+		err = json.Unmarshal(rr.Body.Bytes(), &out)
+		require.NoError(t, err)
+		executions := out.Data[0].Status.Provisioning.StepExecutions
+		require.Len(t, executions, 2)
+		assert.Equal(t, 1, executions[0].Attempt)
+		assert.Equal(t, string(internal.StepExecutionRetry), executions[0].Result)
+		assert.Equal(t, 2, executions[1].Attempt)
+		assert.Equal(t, string(internal.StepExecutionSucceeded), executions[1].Result)
+	})
 }
 
 func fixInstance(id string, t time.Time) internal.Instance {
@@ -1139,6 +1488,13 @@ func fixInstanceForPreview(id string, t time.Time) internal.Instance {
 	return instance
 }
 
+type fixKymaStatusProvider map[string]*pkg.KymaStatus
+
+func (p fixKymaStatusProvider) Status(instanceID string) (*pkg.KymaStatus, bool) {
+	status, found := p[instanceID]
+	return status, found
+}
+
 func fixRandomID() string {
 	return rand.String(16)
 }
@@ -0,0 +1,104 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+)
+
+const (
+	instanceIDField    = "instanceID"
+	statusField        = "status"
+	runtimeConfigField = "runtimeConfig"
+	bindingsField      = "bindings"
+	actionsField       = "actions"
+	tagsField          = "tags"
+)
+
+// runtimeFields contains the top-level JSON names of the RuntimeDTO fields which can be projected
+var runtimeFields = jsonFieldNames(reflect.TypeOf(pkg.RuntimeDTO{}))
+
+// projectedRuntimesPage is the RuntimesPage with the runtimes limited to the requested fields
+type projectedRuntimesPage struct {
+	Data       []map[string]json.RawMessage `json:"data"`
+	Count      int                          `json:"count"`
+	TotalCount int                          `json:"totalCount"`
+	NextCursor string                       `json:"nextCursor,omitempty"`
+}
+
+// projection is the set of the requested RuntimeDTO fields, nil means all fields
+type projection map[string]bool
+
+func (p projection) includes(field string) bool {
+	return p == nil || p[field]
+}
+
+// getProjection parses the comma-separated list of the requested fields, the instance ID is always returned
+func getProjection(req *http.Request) (projection, error) {
+	params := req.URL.Query()[pkg.FieldsParam]
+	if len(params) == 0 {
+		return nil, nil
+	}
+	fields := projection{instanceIDField: true}
+	for _, param := range params {
+		for _, field := range strings.Split(param, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if !runtimeFields[field] {
+				return nil, fmt.Errorf("unknown field %q, allowed fields: %s", field, strings.Join(sortedKeys(runtimeFields), ", "))
+			}
+			fields[field] = true
+		}
+	}
+	return fields, nil
+}
+
+// project returns only the requested fields of the runtimes
+func (p projection) project(dtos []pkg.RuntimeDTO) ([]map[string]json.RawMessage, error) {
+	result := make([]map[string]json.RawMessage, 0, len(dtos))
+	for _, dto := range dtos {
+		content, err := json.Marshal(dto)
+		if err != nil {
+			return nil, fmt.Errorf("while marshalling runtime %s: %w", dto.InstanceID, err)
+		}
+		all := map[string]json.RawMessage{}
+		if err := json.Unmarshal(content, &all); err != nil {
+			return nil, fmt.Errorf("while unmarshalling runtime %s: %w", dto.InstanceID, err)
+		}
+		projected := make(map[string]json.RawMessage, len(p))
+		for field := range p {
+			if value, found := all[field]; found {
+				projected[field] = value
+			}
+		}
+		result = append(result, projected)
+	}
+	return result, nil
+}
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+package runtimereconciler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	btpmgrcreds "github.com/kyma-project/kyma-environment-broker/internal/btpmanager/credentials"
+	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const BTPManagerSecretReconcilerName = "btp-manager-secret"
+
+// BTPManagerSecretReconciler reconciles the sap-btp-manager Secret on the runtime with the Service Manager credentials stored in KEB.
+type BTPManagerSecretReconciler struct {
+	k8sClientProvider btpmgrcreds.K8sClientProvider
+	log               *slog.Logger
+}
+
+func NewBTPManagerSecretReconciler(kcpClient client.Client, log *slog.Logger) *BTPManagerSecretReconciler {
+	return &BTPManagerSecretReconciler{
+		k8sClientProvider: kubeconfig.NewK8sClientFromSecretProvider(kcpClient),
+		log:               log,
+	}
+}
+
+func (r *BTPManagerSecretReconciler) Name() string {
+	return BTPManagerSecretReconcilerName
+}
+
+func (r *BTPManagerSecretReconciler) IsCandidate(instance internal.Instance) bool {
+	return instance.Parameters.ErsContext.SMOperatorCredentials != nil && instance.InstanceDetails.ServiceManagerClusterID != ""
+}
+
+// Compare does not expose the Secret values, only the names of the keys which differ.
+func (r *BTPManagerSecretReconciler) Compare(ctx context.Context, instance internal.Instance) ([]Difference, error) {
+	expected, err := btpmgrcreds.PrepareSecret(instance.Parameters.ErsContext.SMOperatorCredentials, instance.InstanceDetails.ServiceManagerClusterID)
+	if err != nil {
+		return nil, err
+	}
+	k8sClient, err := r.k8sClientProvider.K8sClientForRuntimeID(instance.RuntimeID)
+	if err != nil {
+		return nil, fmt.Errorf("while getting k8sClient: %w", err)
+	}
+
+	current := &v1.Secret{}
+	err = k8sClient.Get(ctx, client.ObjectKey{Name: btpmgrcreds.BtpManagerSecretName, Namespace: btpmgrcreds.BtpManagerSecretNamespace}, current)
+	switch {
+	case errors.IsNotFound(err):
+		return []Difference{{Field: "secret", Expected: "present", Actual: "not found"}}, nil
+	case err != nil:
+		return nil, fmt.Errorf("while getting secret from cluster: %w", err)
+	}
+
+	if current.Labels[btpmgrcreds.SkipReconciliationLabel] == "true" {
+		return nil, ErrSkipped
+	}
+
+	notMatchingKeys, err := btpmgrcreds.CompareSecrets(current, expected)
+	if err != nil {
+		return nil, fmt.Errorf("while comparing secrets: %w", err)
+	}
+	differences := make([]Difference, 0, len(notMatchingKeys))
+	for _, key := range notMatchingKeys {
+		differences = append(differences, Difference{Field: "data." + key})
+	}
+	return differences, nil
+}
+
+func (r *BTPManagerSecretReconciler) Apply(_ context.Context, instance internal.Instance, _ []Difference) error {
+	expected, err := btpmgrcreds.PrepareSecret(instance.Parameters.ErsContext.SMOperatorCredentials, instance.InstanceDetails.ServiceManagerClusterID)
+	if err != nil {
+		return err
+	}
+	k8sClient, err := r.k8sClientProvider.K8sClientForRuntimeID(instance.RuntimeID)
+	if err != nil {
+		return fmt.Errorf("while getting k8sClient: %w", err)
+	}
+	return btpmgrcreds.CreateOrUpdateSecret(k8sClient, expected, r.log.With("instanceID", instance.InstanceID))
+}
@@ -0,0 +1,144 @@
+package runtimereconciler
+
+import (
+	"context"
+	"fmt"
+
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	btpmgrcreds "github.com/kyma-project/kyma-environment-broker/internal/btpmanager/credentials"
+	"github.com/kyma-project/kyma-environment-broker/internal/drift"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	KymaLabelsReconcilerName    = "kyma-labels"
+	RuntimeLabelsReconcilerName = "runtime-labels"
+)
+
+// KymaLabelsReconciler reconciles the labels of the Kyma CR in KCP, see customresources/labels.go.
+type KymaLabelsReconciler struct {
+	kcpClient client.Client
+}
+
+func NewKymaLabelsReconciler(kcpClient client.Client) *KymaLabelsReconciler {
+	return &KymaLabelsReconciler{kcpClient: kcpClient}
+}
+
+func (r *KymaLabelsReconciler) Name() string {
+	return KymaLabelsReconcilerName
+}
+
+func (r *KymaLabelsReconciler) IsCandidate(instance internal.Instance) bool {
+	return true
+}
+
+// Compare does not report differences when the Kyma CR does not exist, because KEB does not re-create it.
+func (r *KymaLabelsReconciler) Compare(ctx context.Context, instance internal.Instance) ([]Difference, error) {
+	kyma, err := r.getKyma(ctx, instance)
+	switch {
+	case errors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return fromDrifts(drift.CompareKymaLabels(instance, kyma.GetLabels())), nil
+}
+
+func (r *KymaLabelsReconciler) Apply(ctx context.Context, instance internal.Instance, differences []Difference) error {
+	kyma, err := r.getKyma(ctx, instance)
+	if err != nil {
+		return err
+	}
+	kyma.SetLabels(drift.ApplyKymaLabels(kyma.GetLabels(), toDrifts(drift.KymaResource, differences)))
+	if err := r.kcpClient.Update(ctx, kyma); err != nil {
+		return fmt.Errorf("while updating Kyma CR: %w", err)
+	}
+	return nil
+}
+
+func (r *KymaLabelsReconciler) getKyma(ctx context.Context, instance internal.Instance) (*unstructured.Unstructured, error) {
+	name := instance.InstanceDetails.KymaResourceName
+	if name == "" {
+		name = instance.InstanceDetails.GetRuntimeResourceName()
+	}
+	kyma := &unstructured.Unstructured{}
+	kyma.SetGroupVersionKind(btpmgrcreds.KymaGvk)
+	err := r.kcpClient.Get(ctx, client.ObjectKey{Name: name, Namespace: instance.InstanceDetails.GetRuntimeResourceNamespace()}, kyma)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("while getting Kyma CR: %w", err)
+	}
+	return kyma, err
+}
+
+// RuntimeLabelsReconciler reconciles the labels of the Runtime CR in KCP, see customresources/labels.go.
+type RuntimeLabelsReconciler struct {
+	kcpClient client.Client
+}
+
+func NewRuntimeLabelsReconciler(kcpClient client.Client) *RuntimeLabelsReconciler {
+	return &RuntimeLabelsReconciler{kcpClient: kcpClient}
+}
+
+func (r *RuntimeLabelsReconciler) Name() string {
+	return RuntimeLabelsReconcilerName
+}
+
+func (r *RuntimeLabelsReconciler) IsCandidate(instance internal.Instance) bool {
+	return true
+}
+
+// Compare does not report differences when the Runtime CR does not exist, because KEB does not re-create it.
+func (r *RuntimeLabelsReconciler) Compare(ctx context.Context, instance internal.Instance) ([]Difference, error) {
+	runtime, err := r.getRuntime(ctx, instance)
+	switch {
+	case errors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return fromDrifts(drift.CompareRuntimeLabels(instance, runtime.GetLabels())), nil
+}
+
+func (r *RuntimeLabelsReconciler) Apply(ctx context.Context, instance internal.Instance, differences []Difference) error {
+	runtime, err := r.getRuntime(ctx, instance)
+	if err != nil {
+		return err
+	}
+	drift.ApplyRuntime(runtime, toDrifts(drift.RuntimeResource, differences))
+	if err := r.kcpClient.Update(ctx, runtime); err != nil {
+		return fmt.Errorf("while updating Runtime CR: %w", err)
+	}
+	return nil
+}
+
+func (r *RuntimeLabelsReconciler) getRuntime(ctx context.Context, instance internal.Instance) (*imv1.Runtime, error) {
+	runtime := &imv1.Runtime{}
+	err := r.kcpClient.Get(ctx, client.ObjectKey{
+		Name:      instance.InstanceDetails.GetRuntimeResourceName(),
+		Namespace: instance.InstanceDetails.GetRuntimeResourceNamespace(),
+	}, runtime)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("while getting Runtime CR: %w", err)
+	}
+	return runtime, err
+}
+
+func fromDrifts(drifts []drift.Drift) []Difference {
+	differences := make([]Difference, 0, len(drifts))
+	for _, d := range drifts {
+		differences = append(differences, Difference{Field: d.Field, Expected: d.Expected, Actual: d.Actual})
+	}
+	return differences
+}
+
+func toDrifts(resource string, differences []Difference) []drift.Drift {
+	drifts := make([]drift.Drift, 0, len(differences))
+	for _, d := range differences {
+		drifts = append(drifts, drift.Drift{Resource: resource, Field: d.Field, Expected: d.Expected, Actual: d.Actual})
+	}
+	return drifts
+}
@@ -0,0 +1,70 @@
+package runtimereconciler
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type Metrics struct {
+	runtimes *prometheus.GaugeVec
+	applied  *prometheus.CounterVec
+	lastRun  *prometheus.GaugeVec
+
+	// Deprecated: reconciledSecrets is kept for dashboards built on the former BTP Manager secret reconciliation job.
+	// Use reconciled_runtimes with the reconciler="btp-manager-secret" label instead.
+	reconciledSecrets *prometheus.GaugeVec
+}
+
+func NewMetrics(reg prometheus.Registerer, namespace string) *Metrics {
+	m := &Metrics{
+		runtimes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "reconciled_runtimes",
+			Help:      "Number of runtimes by the result of the last reconciler run.",
+		}, []string{"reconciler", "result"}),
+		applied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "applied_differences_total",
+			Help:      "Total number of differences reconciled back to the state stored in KEB.",
+		}, []string{"reconciler", "field"}),
+		lastRun: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_run_timestamp_seconds",
+			Help:      "Time of the last reconciler run.",
+		}, []string{"reconciler"}),
+		reconciledSecrets: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "reconciled_secrets",
+			Help:      "Reconciled secrets. Deprecated: use reconciled_runtimes with the reconciler=\"btp-manager-secret\" label.",
+		}, []string{"shoot", "state"}),
+	}
+	reg.MustRegister(m.runtimes, m.applied, m.lastRun, m.reconciledSecrets)
+	return m
+}
+
+func (m *Metrics) update(reconciler string, reports []Report) map[Result]int {
+	counts := map[Result]int{}
+	for _, report := range reports {
+		counts[report.Result]++
+		if reconciler == BTPManagerSecretReconcilerName {
+			m.updateReconciledSecrets(report)
+		}
+		if report.Result != ResultApplied {
+			continue
+		}
+		for _, difference := range report.Differences {
+			m.applied.WithLabelValues(reconciler, difference.Field).Inc()
+		}
+	}
+	for _, result := range results {
+		m.runtimes.WithLabelValues(reconciler, string(result)).Set(float64(counts[result]))
+	}
+	m.lastRun.WithLabelValues(reconciler).SetToCurrentTime()
+	return counts
+}
+
+func (m *Metrics) updateReconciledSecrets(report Report) {
+	skipped, reconciled := float64(0), float64(1)
+	if report.Result == ResultSkipped {
+		skipped, reconciled = 1, 0
+	}
+	m.reconciledSecrets.With(prometheus.Labels{"shoot": report.ShootName, "state": "skipped"}).Set(skipped)
+	m.reconciledSecrets.With(prometheus.Labels{"shoot": report.ShootName, "state": "reconciled"}).Set(reconciled)
+}
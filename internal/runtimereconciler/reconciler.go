@@ -0,0 +1,57 @@
+package runtimereconciler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+)
+
+// ErrSkipped is returned by Reconciler.Compare when the runtime opted out of the reconciliation.
+var ErrSkipped = errors.New("reconciliation skipped")
+
+// Reconciler compares a single aspect of a runtime with the state stored in KEB and reconciles it back.
+type Reconciler interface {
+	// Name identifies the reconciler in logs, metrics and reports.
+	Name() string
+	// IsCandidate returns false for instances which the reconciler does not handle.
+	IsCandidate(instance internal.Instance) bool
+	// Compare returns the differences between the runtime and the state stored in KEB.
+	Compare(ctx context.Context, instance internal.Instance) ([]Difference, error)
+	// Apply reconciles the differences returned by Compare.
+	Apply(ctx context.Context, instance internal.Instance, differences []Difference) error
+}
+
+// Difference describes a single value which differs from the state stored in KEB.
+// Expected and Actual are empty for sensitive values.
+type Difference struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+type Result string
+
+const (
+	ResultInSync  Result = "in_sync"
+	ResultDrifted Result = "drifted"
+	ResultApplied Result = "applied"
+	ResultSkipped Result = "skipped"
+	ResultFailed  Result = "failed"
+)
+
+var results = []Result{ResultInSync, ResultDrifted, ResultApplied, ResultSkipped, ResultFailed}
+
+// Report is the result of a single reconciler run for a single runtime.
+// Differences found in the dry-run mode are reported with ResultDrifted.
+type Report struct {
+	Reconciler  string       `json:"reconciler"`
+	InstanceID  string       `json:"instanceID"`
+	RuntimeID   string       `json:"runtimeID"`
+	ShootName   string       `json:"shootName"`
+	Result      Result       `json:"result"`
+	Differences []Difference `json:"differences,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	Timestamp   time.Time    `json:"timestamp"`
+}
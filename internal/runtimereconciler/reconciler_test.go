@@ -0,0 +1,458 @@
+package runtimereconciler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/kyma-project/kyma-environment-broker/common/gardener"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	btpmgrcreds "github.com/kyma-project/kyma-environment-broker/internal/btpmanager/credentials"
+	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const gardenerNamespace = "garden-kyma"
+
+func TestRunner(t *testing.T) {
+	t.Run("should report differences without applying them in dry-run mode", func(t *testing.T) {
+		// given
+		instances := fixInstances(t, "in-sync", "drifted", "skipped", "failed")
+		reconciler := &fakeReconciler{differences: map[string][]Difference{"drifted": {{Field: "field", Expected: "a", Actual: "b"}}}}
+		metrics := NewMetrics(prometheus.NewRegistry(), "test")
+		runner := NewRunner(instances, []Reconciler{reconciler}, true, 0, metrics, fixLogger())
+
+		// when
+		err := runner.RunAll(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, reconciler.applied)
+		assertResults(t, runner.Reports(), map[string]Result{
+			"in-sync": ResultInSync,
+			"drifted": ResultDrifted,
+			"skipped": ResultSkipped,
+			"failed":  ResultFailed,
+		})
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.runtimes.WithLabelValues("fake", string(ResultDrifted))))
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.runtimes.WithLabelValues("fake", string(ResultApplied))))
+	})
+
+	t.Run("should apply differences", func(t *testing.T) {
+		// given
+		instances := fixInstances(t, "in-sync", "drifted")
+		reconciler := &fakeReconciler{differences: map[string][]Difference{"drifted": {{Field: "field", Expected: "a", Actual: "b"}}}}
+		metrics := NewMetrics(prometheus.NewRegistry(), "test")
+		runner := NewRunner(instances, []Reconciler{reconciler}, false, 0, metrics, fixLogger())
+
+		// when
+		err := runner.RunAll(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"drifted"}, reconciler.applied)
+		assertResults(t, runner.Reports(), map[string]Result{
+			"in-sync": ResultInSync,
+			"drifted": ResultApplied,
+		})
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.applied.WithLabelValues("fake", "field")))
+	})
+
+	t.Run("should not reconcile instances which are not reconcilable or not candidates", func(t *testing.T) {
+		// given
+		instances := fixInstances(t, "in-sync", "not-candidate")
+		notReconcilable := fixture.FixInstance("not-reconcilable")
+		require.NoError(t, instances.Insert(notReconcilable))
+		reconciler := &fakeReconciler{}
+		runner := NewRunner(instances, []Reconciler{reconciler}, false, 0, NewMetrics(prometheus.NewRegistry(), "test"), fixLogger())
+
+		// when
+		err := runner.RunAll(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assertResults(t, runner.Reports(), map[string]Result{"in-sync": ResultInSync})
+	})
+
+	t.Run("should keep the deprecated reconciled secrets metric for the BTP Manager secret reconciler", func(t *testing.T) {
+		// given
+		metrics := NewMetrics(prometheus.NewRegistry(), "test")
+		reports := []Report{
+			{ShootName: "shoot-applied", Result: ResultApplied},
+			{ShootName: "shoot-skipped", Result: ResultSkipped},
+		}
+
+		// when
+		metrics.update(BTPManagerSecretReconcilerName, reports)
+
+		// then
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.reconciledSecrets.WithLabelValues("shoot-applied", "reconciled")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.reconciledSecrets.WithLabelValues("shoot-applied", "skipped")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.reconciledSecrets.WithLabelValues("shoot-skipped", "skipped")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.reconciledSecrets.WithLabelValues("shoot-skipped", "reconciled")))
+	})
+
+	t.Run("should serve filtered reports", func(t *testing.T) {
+		// given
+		instances := fixInstances(t, "in-sync", "drifted")
+		reconciler := &fakeReconciler{differences: map[string][]Difference{"drifted": {{Field: "field"}}}}
+		runner := NewRunner(instances, []Reconciler{reconciler}, true, 0, NewMetrics(prometheus.NewRegistry(), "test"), fixLogger())
+		require.NoError(t, runner.RunAll(context.Background()))
+
+		// when
+		recorder := httptest.NewRecorder()
+		runner.Reports().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/reports?result=drifted", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var reports []Report
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &reports))
+		require.Len(t, reports, 1)
+		assert.Equal(t, "drifted", reports[0].InstanceID)
+		assert.Equal(t, []Difference{{Field: "field"}}, reports[0].Differences)
+	})
+}
+
+func TestLabelsReconcilers(t *testing.T) {
+	require.NoError(t, imv1.AddToScheme(scheme.Scheme))
+	instance := fixture.FixInstance("instance-id")
+	ctx := context.Background()
+
+	t.Run("should reconcile Runtime CR labels", func(t *testing.T) {
+		// given
+		runtime := &imv1.Runtime{ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.InstanceDetails.GetRuntimeResourceName(),
+			Namespace: instance.InstanceDetails.GetRuntimeResourceNamespace(),
+			Labels:    map[string]string{customresources.GlobalAccountIdLabel: "other-ga"},
+		}}
+		kcpClient := fake.NewClientBuilder().WithObjects(runtime).Build()
+		reconciler := NewRuntimeLabelsReconciler(kcpClient)
+
+		// when
+		differences, err := reconciler.Compare(ctx, instance)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, differences, Difference{Field: "metadata.labels." + customresources.GlobalAccountIdLabel, Expected: fixture.GlobalAccountId, Actual: "other-ga"})
+
+		// when
+		require.NoError(t, reconciler.Apply(ctx, instance, differences))
+
+		// then
+		differences, err = reconciler.Compare(ctx, instance)
+		require.NoError(t, err)
+		assert.Empty(t, differences)
+	})
+
+	t.Run("should reconcile Kyma CR labels", func(t *testing.T) {
+		// given
+		kyma := &unstructured.Unstructured{}
+		kyma.SetGroupVersionKind(btpmgrcreds.KymaGvk)
+		kyma.SetName(instance.InstanceDetails.KymaResourceName)
+		kyma.SetNamespace(instance.InstanceDetails.GetRuntimeResourceNamespace())
+		kcpClient := fake.NewClientBuilder().WithObjects(kyma).Build()
+		reconciler := NewKymaLabelsReconciler(kcpClient)
+
+		// when
+		differences, err := reconciler.Compare(ctx, instance)
+
+		// then
+		require.NoError(t, err)
+		assert.NotEmpty(t, differences)
+
+		// when
+		require.NoError(t, reconciler.Apply(ctx, instance, differences))
+
+		// then
+		differences, err = reconciler.Compare(ctx, instance)
+		require.NoError(t, err)
+		assert.Empty(t, differences)
+	})
+
+	t.Run("should not report differences for missing CRs", func(t *testing.T) {
+		// given
+		kcpClient := fake.NewClientBuilder().Build()
+
+		// when
+		runtimeDifferences, runtimeErr := NewRuntimeLabelsReconciler(kcpClient).Compare(ctx, instance)
+		kymaDifferences, kymaErr := NewKymaLabelsReconciler(kcpClient).Compare(ctx, instance)
+
+		// then
+		assert.NoError(t, runtimeErr)
+		assert.Empty(t, runtimeDifferences)
+		assert.NoError(t, kymaErr)
+		assert.Empty(t, kymaDifferences)
+	})
+}
+
+func TestLabelsReconcilersWithDeclaredPlan(t *testing.T) {
+	require.NoError(t, imv1.AddToScheme(scheme.Scheme))
+	registerDeclaredPlan(t)
+	ctx := context.Background()
+
+	t.Run("should reconcile the plan name label of a declared plan", func(t *testing.T) {
+		// given
+		instance := fixture.FixInstance("instance-id")
+		instance.ServicePlanID = declaredPlanID
+		runtime := &imv1.Runtime{ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.InstanceDetails.GetRuntimeResourceName(),
+			Namespace: instance.InstanceDetails.GetRuntimeResourceNamespace(),
+			Labels:    map[string]string{customresources.PlanNameLabel: "other"},
+		}}
+		kcpClient := fake.NewClientBuilder().WithObjects(runtime).Build()
+		reconciler := NewRuntimeLabelsReconciler(kcpClient)
+
+		// when
+		differences, err := reconciler.Compare(ctx, instance)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, differences, Difference{Field: "metadata.labels." + customresources.PlanNameLabel, Expected: declaredPlanName, Actual: "other"})
+
+		// when
+		require.NoError(t, reconciler.Apply(ctx, instance, differences))
+
+		// then
+		updated := &imv1.Runtime{}
+		require.NoError(t, kcpClient.Get(ctx, client.ObjectKeyFromObject(runtime), updated))
+		assert.Equal(t, declaredPlanName, updated.GetLabels()[customresources.PlanNameLabel])
+	})
+
+	t.Run("should not overwrite the plan name label of an unknown plan", func(t *testing.T) {
+		// given
+		instance := fixture.FixInstance("instance-id")
+		instance.ServicePlanID = "unknown-plan-id"
+		kyma := &unstructured.Unstructured{}
+		kyma.SetGroupVersionKind(btpmgrcreds.KymaGvk)
+		kyma.SetName(instance.InstanceDetails.KymaResourceName)
+		kyma.SetNamespace(instance.InstanceDetails.GetRuntimeResourceNamespace())
+		kyma.SetLabels(map[string]string{customresources.PlanNameLabel: declaredPlanName})
+		kcpClient := fake.NewClientBuilder().WithObjects(kyma).Build()
+		reconciler := NewKymaLabelsReconciler(kcpClient)
+
+		// when
+		differences, err := reconciler.Compare(ctx, instance)
+
+		// then
+		require.NoError(t, err)
+		for _, difference := range differences {
+			assert.NotEqual(t, "metadata.labels."+customresources.PlanNameLabel, difference.Field)
+		}
+	})
+}
+
+func TestSubscriptionLabelsReconciler(t *testing.T) {
+	instance := fixture.FixInstance("instance-id")
+	ctx := context.Background()
+
+	t.Run("should reconcile tenantName label", func(t *testing.T) {
+		// given
+		binding := fixCredentialsBinding(instance.SubscriptionSecretName, map[string]string{gardener.TenantNameLabelKey: "other-ga"})
+		reconciler := NewSubscriptionLabelsReconciler(gardener.NewClient(gardener.NewDynamicFakeClient(binding), gardenerNamespace))
+
+		// when
+		differences, err := reconciler.Compare(ctx, instance)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Difference{{Field: "metadata.labels.tenantName", Expected: fixture.GlobalAccountId, Actual: "other-ga"}}, differences)
+
+		// when
+		require.NoError(t, reconciler.Apply(ctx, instance, differences))
+
+		// then
+		differences, err = reconciler.Compare(ctx, instance)
+		require.NoError(t, err)
+		assert.Empty(t, differences)
+	})
+
+	t.Run("should skip shared credentials binding", func(t *testing.T) {
+		// given
+		binding := fixCredentialsBinding(instance.SubscriptionSecretName, map[string]string{gardener.SharedLabelKey: "true"})
+		reconciler := NewSubscriptionLabelsReconciler(gardener.NewClient(gardener.NewDynamicFakeClient(binding), gardenerNamespace))
+
+		// when
+		_, err := reconciler.Compare(ctx, instance)
+
+		// then
+		assert.ErrorIs(t, err, ErrSkipped)
+	})
+}
+
+func TestBTPManagerSecretReconciler(t *testing.T) {
+	instance := fixture.FixInstance("instance-id")
+	instance.Parameters.ErsContext.SMOperatorCredentials = &internal.ServiceManagerOperatorCredentials{
+		ClientID:          "client-id",
+		ClientSecret:      "client-secret",
+		ServiceManagerURL: "https://sm.example.com",
+		URL:               "https://token.example.com",
+	}
+	instance.InstanceDetails.ServiceManagerClusterID = "cluster-id"
+	ctx := context.Background()
+
+	t.Run("should reconcile changed secret without exposing values", func(t *testing.T) {
+		// given
+		secret, err := btpmgrcreds.PrepareSecret(instance.Parameters.ErsContext.SMOperatorCredentials, "other-cluster-id")
+		require.NoError(t, err)
+		skrClient := fake.NewClientBuilder().WithObjects(secret).Build()
+		reconciler := &BTPManagerSecretReconciler{k8sClientProvider: fakeK8sClientProvider{client: skrClient}, log: fixLogger()}
+
+		// when
+		differences, err := reconciler.Compare(ctx, instance)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Difference{{Field: "data.cluster_id"}}, differences)
+
+		// when
+		require.NoError(t, reconciler.Apply(ctx, instance, differences))
+
+		// then
+		differences, err = reconciler.Compare(ctx, instance)
+		require.NoError(t, err)
+		assert.Empty(t, differences)
+	})
+
+	t.Run("should skip secret with skip reconciliation label", func(t *testing.T) {
+		// given
+		secret, err := btpmgrcreds.PrepareSecret(instance.Parameters.ErsContext.SMOperatorCredentials, "other-cluster-id")
+		require.NoError(t, err)
+		secret.Labels = map[string]string{btpmgrcreds.SkipReconciliationLabel: "true"}
+		skrClient := fake.NewClientBuilder().WithObjects(secret).Build()
+		reconciler := &BTPManagerSecretReconciler{k8sClientProvider: fakeK8sClientProvider{client: skrClient}, log: fixLogger()}
+
+		// when
+		_, err = reconciler.Compare(ctx, instance)
+
+		// then
+		assert.ErrorIs(t, err, ErrSkipped)
+	})
+
+	t.Run("should report missing secret", func(t *testing.T) {
+		// given
+		skrClient := fake.NewClientBuilder().Build()
+		reconciler := &BTPManagerSecretReconciler{k8sClientProvider: fakeK8sClientProvider{client: skrClient}, log: fixLogger()}
+
+		// when
+		differences, err := reconciler.Compare(ctx, instance)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, differences, 1)
+
+		// when
+		require.NoError(t, reconciler.Apply(ctx, instance, differences))
+
+		// then
+		actual := &v1.Secret{}
+		assert.NoError(t, skrClient.Get(ctx, client.ObjectKey{Name: btpmgrcreds.BtpManagerSecretName, Namespace: btpmgrcreds.BtpManagerSecretNamespace}, actual))
+	})
+}
+
+// fakeReconciler reports differences configured per instance ID, skips the "skipped" instance, fails for the "failed" instance,
+// and does not handle the "not-candidate" instance.
+type fakeReconciler struct {
+	differences map[string][]Difference
+	applied     []string
+}
+
+func (r *fakeReconciler) Name() string {
+	return "fake"
+}
+
+func (r *fakeReconciler) IsCandidate(instance internal.Instance) bool {
+	return instance.InstanceID != "not-candidate"
+}
+
+func (r *fakeReconciler) Compare(_ context.Context, instance internal.Instance) ([]Difference, error) {
+	switch instance.InstanceID {
+	case "skipped":
+		return nil, ErrSkipped
+	case "failed":
+		return nil, errors.New("compare failed")
+	}
+	return r.differences[instance.InstanceID], nil
+}
+
+func (r *fakeReconciler) Apply(_ context.Context, instance internal.Instance, _ []Difference) error {
+	r.applied = append(r.applied, instance.InstanceID)
+	return nil
+}
+
+type fakeK8sClientProvider struct {
+	client client.Client
+}
+
+func (p fakeK8sClientProvider) K8sClientForRuntimeID(string) (client.Client, error) {
+	return p.client, nil
+}
+
+const (
+	declaredPlanID   = "5f9a8c1e-3b0d-4f43-9a63-1d2c7e8f0a11"
+	declaredPlanName = "sovereign-aws"
+)
+
+// the plans can be registered only once in the process
+var registerPlansOnce sync.Once
+
+func registerDeclaredPlan(t *testing.T) {
+	registerPlansOnce.Do(func() {
+		plans, err := configuration.NewPlanSpecifications(strings.NewReader(fmt.Sprintf("%s:\n  id: %s\n  provider: aws\n", declaredPlanName, declaredPlanID)))
+		require.NoError(t, err)
+		require.NoError(t, broker.RegisterPlans(plans.Definitions()))
+	})
+}
+
+func fixInstances(t *testing.T, ids ...string) storage.Instances {
+	instances := storage.NewMemoryStorage().Instances()
+	for _, id := range ids {
+		instance := fixture.FixInstance(id)
+		instance.Reconcilable = true
+		require.NoError(t, instances.Insert(instance))
+	}
+	return instances
+}
+
+func fixCredentialsBinding(name string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gardener.CredentialsBindingGVK)
+	u.SetName(name)
+	u.SetNamespace(gardenerNamespace)
+	u.SetLabels(labels)
+	return u
+}
+
+func assertResults(t *testing.T, reports *Reports, expected map[string]Result) {
+	actual := map[string]Result{}
+	for _, report := range reports.List("", "", "") {
+		actual[report.InstanceID] = report.Result
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+}
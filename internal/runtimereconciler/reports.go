@@ -0,0 +1,64 @@
+package runtimereconciler
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// Reports keeps the per-runtime reports of the last run of each reconciler and serves them as JSON.
+// The reports can be filtered with the reconciler, instance_id, and result query parameters.
+type Reports struct {
+	mu      sync.RWMutex
+	reports map[string][]Report
+}
+
+func NewReports() *Reports {
+	return &Reports{reports: map[string][]Report{}}
+}
+
+func (r *Reports) set(reconciler string, reports []Report) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports[reconciler] = reports
+}
+
+// List returns the reports matching the given reconciler name, instance ID, and result. Empty values match all reports.
+func (r *Reports) List(reconciler, instanceID string, result Result) []Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.reports))
+	for name := range r.reports {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	reports := []Report{}
+	for _, name := range names {
+		if reconciler != "" && reconciler != name {
+			continue
+		}
+		for _, report := range r.reports[name] {
+			if instanceID != "" && instanceID != report.InstanceID {
+				continue
+			}
+			if result != "" && result != report.Result {
+				continue
+			}
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+func (r *Reports) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	reports := r.List(query.Get("reconciler"), query.Get("instance_id"), Result(query.Get("result")))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reports); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
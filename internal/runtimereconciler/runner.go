@@ -0,0 +1,153 @@
+package runtimereconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+// Runner runs all registered reconcilers for all reconcilable instances and collects per-runtime reports.
+type Runner struct {
+	instances   storage.Instances
+	reconcilers []Reconciler
+	dryRun      bool
+	delay       time.Duration
+	metrics     *Metrics
+	reports     *Reports
+	log         *slog.Logger
+}
+
+func NewRunner(instances storage.Instances, reconcilers []Reconciler, dryRun bool, delay time.Duration, metrics *Metrics, log *slog.Logger) *Runner {
+	return &Runner{
+		instances:   instances,
+		reconcilers: reconcilers,
+		dryRun:      dryRun,
+		delay:       delay,
+		metrics:     metrics,
+		reports:     NewReports(),
+		log:         log,
+	}
+}
+
+func (r *Runner) Reports() *Reports {
+	return r.reports
+}
+
+// Start schedules RunAll every interval minutes.
+func (r *Runner) Start(ctx context.Context, interval int) error {
+	scheduler := gocron.NewScheduler(time.UTC)
+	_, err := scheduler.Every(interval).Minutes().Do(func() {
+		r.log.Info(fmt.Sprintf("runtime-reconciler: scheduled call started at %s", time.Now()))
+		if err := r.RunAll(ctx); err != nil {
+			r.log.Error(fmt.Sprintf("runtime-reconciler: scheduled call finished with error: %s", err))
+			return
+		}
+		r.log.Info(fmt.Sprintf("runtime-reconciler: scheduled call finished with success at %s", time.Now()))
+	})
+	if err != nil {
+		return fmt.Errorf("while scheduling runtime reconciliation: %w", err)
+	}
+	r.log.Info("runtime-reconciler: start scheduler")
+	scheduler.StartAsync()
+	return nil
+}
+
+// RunAll runs every reconciler for its candidates among the reconcilable instances.
+func (r *Runner) RunAll(ctx context.Context) error {
+	allInstances, _, _, err := r.instances.List(dbmodel.InstanceFilter{})
+	if err != nil {
+		return fmt.Errorf("while getting all instances: %w", err)
+	}
+
+	var instances []internal.Instance
+	for _, instance := range allInstances {
+		// not reconcilable means no runtime ID, the last operation was deprovisioning, or an operation is in progress
+		if instance.Reconcilable {
+			instances = append(instances, instance)
+		}
+	}
+	r.log.Info(fmt.Sprintf("from total number of instances (%d) took %d as reconcilable", len(allInstances), len(instances)))
+
+	for _, reconciler := range r.reconcilers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		r.run(ctx, reconciler, instances)
+	}
+	return nil
+}
+
+func (r *Runner) run(ctx context.Context, reconciler Reconciler, instances []internal.Instance) {
+	log := r.log.With("reconciler", reconciler.Name())
+
+	var reports []Report
+	for _, instance := range instances {
+		if !reconciler.IsCandidate(instance) {
+			continue
+		}
+		time.Sleep(r.delay)
+		report := r.reconcile(ctx, reconciler, instance, log.With("instanceID", instance.InstanceID))
+		reports = append(reports, report)
+	}
+
+	r.reports.set(reconciler.Name(), reports)
+	counts := r.metrics.update(reconciler.Name(), reports)
+	log.Info(fmt.Sprintf("runtime-reconciler summary: total %d instances: %d in sync, %d drifted, %d applied, %d skipped, %d failed",
+		len(reports), counts[ResultInSync], counts[ResultDrifted], counts[ResultApplied], counts[ResultSkipped], counts[ResultFailed]))
+}
+
+func (r *Runner) reconcile(ctx context.Context, reconciler Reconciler, instance internal.Instance, log *slog.Logger) Report {
+	report := Report{
+		Reconciler: reconciler.Name(),
+		InstanceID: instance.InstanceID,
+		RuntimeID:  instance.RuntimeID,
+		ShootName:  instance.InstanceDetails.ShootName,
+		Timestamp:  time.Now(),
+	}
+
+	differences, err := reconciler.Compare(ctx, instance)
+	switch {
+	case errors.Is(err, ErrSkipped):
+		log.Info("skipping reconciliation")
+		report.Result = ResultSkipped
+		return report
+	case err != nil:
+		log.Error(fmt.Sprintf("while comparing: %s", err))
+		report.Result = ResultFailed
+		report.Error = err.Error()
+		return report
+	case len(differences) == 0:
+		report.Result = ResultInSync
+		return report
+	}
+
+	report.Differences = differences
+	fields := make([]string, 0, len(differences))
+	for _, difference := range differences {
+		fields = append(fields, difference.Field)
+	}
+	log.Info(fmt.Sprintf("runtime does not match the state stored in KEB, differences in: %s", strings.Join(fields, ", ")))
+
+	if r.dryRun {
+		log.Info("[dry-run] differences would be reconciled")
+		report.Result = ResultDrifted
+		return report
+	}
+	if err := reconciler.Apply(ctx, instance, differences); err != nil {
+		log.Error(fmt.Sprintf("while applying: %s", err))
+		report.Result = ResultFailed
+		report.Error = err.Error()
+		return report
+	}
+	log.Info("differences reconciled")
+	report.Result = ResultApplied
+	return report
+}
@@ -0,0 +1,66 @@
+package runtimereconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/common/gardener"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+)
+
+const SubscriptionLabelsReconcilerName = "subscription-labels"
+
+const tenantNameField = "metadata.labels." + gardener.TenantNameLabelKey
+
+// SubscriptionLabelsReconciler reconciles the tenantName label of the Gardener CredentialsBinding used by the instance (subscription).
+// The label must point to the global account which claimed the binding, see the ResolveCredentialsBindingStep.
+type SubscriptionLabelsReconciler struct {
+	gardenerClient *gardener.Client
+}
+
+func NewSubscriptionLabelsReconciler(gardenerClient *gardener.Client) *SubscriptionLabelsReconciler {
+	return &SubscriptionLabelsReconciler{gardenerClient: gardenerClient}
+}
+
+func (r *SubscriptionLabelsReconciler) Name() string {
+	return SubscriptionLabelsReconcilerName
+}
+
+func (r *SubscriptionLabelsReconciler) IsCandidate(instance internal.Instance) bool {
+	return instance.SubscriptionSecretName != ""
+}
+
+// Compare skips shared bindings, which are not claimed by any global account, and dirty bindings, which are being cleaned up.
+func (r *SubscriptionLabelsReconciler) Compare(_ context.Context, instance internal.Instance) ([]Difference, error) {
+	binding, err := r.gardenerClient.GetCredentialsBinding(instance.SubscriptionSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("while getting credentials binding %s: %w", instance.SubscriptionSecretName, err)
+	}
+	labels := binding.GetLabels()
+	if labels[gardener.SharedLabelKey] == "true" || labels[gardener.DirtyLabelKey] == "true" {
+		return nil, ErrSkipped
+	}
+
+	expected := instance.GetSubscriptionGlobalAccoundID()
+	if actual := labels[gardener.TenantNameLabelKey]; actual != expected {
+		return []Difference{{Field: tenantNameField, Expected: expected, Actual: actual}}, nil
+	}
+	return nil, nil
+}
+
+func (r *SubscriptionLabelsReconciler) Apply(_ context.Context, instance internal.Instance, _ []Difference) error {
+	binding, err := r.gardenerClient.GetCredentialsBinding(instance.SubscriptionSecretName)
+	if err != nil {
+		return fmt.Errorf("while getting credentials binding %s: %w", instance.SubscriptionSecretName, err)
+	}
+	labels := binding.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[gardener.TenantNameLabelKey] = instance.GetSubscriptionGlobalAccoundID()
+	binding.SetLabels(labels)
+	if _, err := r.gardenerClient.UpdateCredentialsBinding(binding); err != nil {
+		return fmt.Errorf("while updating credentials binding %s: %w", instance.SubscriptionSecretName, err)
+	}
+	return nil
+}
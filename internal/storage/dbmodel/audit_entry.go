@@ -0,0 +1,38 @@
+package dbmodel
+
+import (
+	"time"
+)
+
+type AuditEntryDTO struct {
+	Sequence          int64
+	ID                string
+	CreatedAt         time.Time
+	Action            string
+	Method            string
+	Path              string
+	RequestID         string
+	PrincipalPlatform string
+	PrincipalIdentity string
+	PrincipalSubject  string
+	PrincipalClientID string
+	PrincipalVerified bool
+	UserAgent         string
+	InstanceID        string
+	Parameters        string
+	StatusCode        int
+	Outcome           string
+	PreviousHash      string
+	Hash              string
+}
+
+type AuditFilter struct {
+	InstanceID string
+	Action     string
+	// Principal matches the subject, the client ID, or the originating identity of the call
+	Principal string
+	From      *time.Time
+	To        *time.Time
+	Page      int
+	PageSize  int
+}
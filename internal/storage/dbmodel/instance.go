@@ -2,6 +2,9 @@ package dbmodel
 
 import (
 	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type InstanceState string
@@ -36,6 +39,11 @@ type InstanceFilter struct {
 	DeletionAttempted            *bool
 	BindingExists                *bool
 	Suspended                    *bool
+	// TagSelector filters the instances by their tags, nil means no filtering
+	TagSelector labels.Selector
+	// After switches to the cursor-based pagination: the instances following the cursor are returned,
+	// up to PageSize instances, and Page is ignored
+	After *pagination.Cursor
 }
 
 type InstanceDTO struct {
@@ -59,6 +67,7 @@ type InstanceDTO struct {
 	UpdatedAt time.Time
 	DeletedAt time.Time
 	ExpiredAt *time.Time
+	ExpiresAt *time.Time
 
 	Version int
 
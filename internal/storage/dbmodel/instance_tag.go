@@ -0,0 +1,13 @@
+package dbmodel
+
+import (
+	"time"
+)
+
+type InstanceTagDTO struct {
+	InstanceID string
+	Key        string
+	Value      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,13 @@
+package dbmodel
+
+import (
+	"time"
+)
+
+type QuotaReservationDTO struct {
+	InstanceID      string
+	GlobalAccountID string
+	SubAccountID    string
+	PlanID          string
+	CreatedAt       time.Time
+}
@@ -0,0 +1,20 @@
+package dbmodel
+
+import (
+	"time"
+)
+
+type StepExecutionDTO struct {
+	ID                string
+	OperationID       string
+	InstanceID        string
+	Stage             string
+	Step              string
+	Attempt           int
+	StartedAt         time.Time
+	FinishedAt        time.Time
+	Result            string
+	RetryAfterSeconds int
+	ErrorReason       string
+	ErrorComponent    string
+}
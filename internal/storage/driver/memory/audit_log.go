@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []internal.AuditEntry
+}
+
+func NewAuditLog() *AuditLog {
+	return &AuditLog{
+		entries: make([]internal.AuditEntry, 0),
+	}
+}
+
+func (s *AuditLog) Append(entry internal.AuditEntry) (internal.AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.Sequence = 1
+	entry.PreviousHash = ""
+	if len(s.entries) > 0 {
+		last := s.entries[len(s.entries)-1]
+		entry.Sequence = last.Sequence + 1
+		entry.PreviousHash = last.Hash
+	}
+	entry.Timestamp = entry.Timestamp.UTC()
+	entry.Hash = entry.ComputeHash()
+	s.entries = append(s.entries, entry)
+	return entry, nil
+}
+
+func (s *AuditLog) List(filter dbmodel.AuditFilter) ([]internal.AuditEntry, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filtered := make([]internal.AuditEntry, 0)
+	// the newest entries first
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if matchesAuditFilter(s.entries[i], filter) {
+			filtered = append(filtered, s.entries[i])
+		}
+	}
+	totalCount := len(filtered)
+	if filter.Page > 0 && filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		if offset >= len(filtered) {
+			return []internal.AuditEntry{}, totalCount, nil
+		}
+		filtered = filtered[offset:min(offset+filter.PageSize, len(filtered))]
+	}
+	return filtered, totalCount, nil
+}
+
+func (s *AuditLog) ListAfter(sequence int64, limit int) ([]internal.AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]internal.AuditEntry, 0)
+	for _, e := range s.entries {
+		if len(result) >= limit {
+			break
+		}
+		if e.Sequence > sequence {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func matchesAuditFilter(entry internal.AuditEntry, filter dbmodel.AuditFilter) bool {
+	if filter.InstanceID != "" && entry.InstanceID != filter.InstanceID {
+		return false
+	}
+	if filter.Action != "" && entry.Action != filter.Action {
+		return false
+	}
+	if filter.Principal != "" && entry.Principal.Subject != filter.Principal &&
+		entry.Principal.ClientID != filter.Principal && entry.Principal.Identity != filter.Principal {
+		return false
+	}
+	if filter.From != nil && entry.Timestamp.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !entry.Timestamp.Before(*filter.To) {
+		return false
+	}
+	return true
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type instances struct {
@@ -18,13 +19,15 @@ type instances struct {
 	instances               map[string]internal.Instance
 	operationsStorage       *operations
 	subaccountStatesStorage *SubaccountStates
+	tagsStorage             *InstanceTags
 }
 
-func NewInstance(operations *operations, subaccountStates *SubaccountStates) *instances {
+func NewInstance(operations *operations, subaccountStates *SubaccountStates, tags *InstanceTags) *instances {
 	return &instances{
 		instances:               make(map[string]internal.Instance, 0),
 		operationsStorage:       operations,
 		subaccountStatesStorage: subaccountStates,
+		tagsStorage:             tags,
 	}
 }
 
@@ -223,13 +226,11 @@ func (s *instances) List(filter dbmodel.InstanceFilter) ([]internal.Instance, in
 	defer s.mu.Unlock()
 	var toReturn []internal.Instance
 
-	offset := pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
-
 	instances := s.filterInstances(filter)
 	sortInstancesByCreatedAt(instances)
 
-	for i := offset; (filter.PageSize < 1 || i < offset+filter.PageSize) && i < len(instances); i++ {
-		toReturn = append(toReturn, s.instances[instances[i].InstanceID])
+	for _, instance := range pageOfInstances(instances, filter) {
+		toReturn = append(toReturn, s.instances[instance.InstanceID])
 	}
 
 	return toReturn,
@@ -243,15 +244,11 @@ func (s *instances) ListWithSubaccountState(filter dbmodel.InstanceFilter) ([]in
 	defer s.mu.Unlock()
 	var toReturn []internal.InstanceWithSubaccountState
 
-	offset := pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
-
 	instances := s.filterInstances(filter)
-	sort.Slice(instances, func(i, j int) bool {
-		return instances[i].CreatedAt.Before(instances[j].CreatedAt)
-	})
+	sortInstancesByCreatedAt(instances)
 
-	for i := offset; (filter.PageSize < 1 || i < offset+filter.PageSize) && i < len(instances); i++ {
-		instanceToReturn := s.instances[instances[i].InstanceID]
+	for _, instance := range pageOfInstances(instances, filter) {
+		instanceToReturn := s.instances[instance.InstanceID]
 		instanceWithSubaccountState := internal.InstanceWithSubaccountState{
 			Instance: instanceToReturn,
 		}
@@ -270,10 +267,30 @@ func (s *instances) ListWithSubaccountState(filter dbmodel.InstanceFilter) ([]in
 
 func sortInstancesByCreatedAt(instances []internal.Instance) {
 	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].CreatedAt.Equal(instances[j].CreatedAt) {
+			return instances[i].InstanceID < instances[j].InstanceID
+		}
 		return instances[i].CreatedAt.Before(instances[j].CreatedAt)
 	})
 }
 
+// pageOfInstances returns the requested page of the sorted instances, using the cursor if it is set
+func pageOfInstances(instances []internal.Instance, filter dbmodel.InstanceFilter) []internal.Instance {
+	offset := pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
+	if filter.After != nil {
+		offset = sort.Search(len(instances), func(i int) bool {
+			return filter.After.After(instances[i].CreatedAt, instances[i].InstanceID)
+		})
+	}
+	if offset >= len(instances) {
+		return nil
+	}
+	if filter.PageSize > 0 && offset+filter.PageSize < len(instances) {
+		return instances[offset : offset+filter.PageSize]
+	}
+	return instances[offset:]
+}
+
 func (s *instances) filterInstances(filter dbmodel.InstanceFilter) []internal.Instance {
 	inst := make([]internal.Instance, 0, len(s.instances))
 	var ok bool
@@ -317,6 +334,15 @@ func (s *instances) filterInstances(filter dbmodel.InstanceFilter) []internal.In
 		if ok = s.matchInstanceState(v.InstanceID, filter.States); !ok {
 			continue
 		}
+		if filter.DeletionAttempted != nil && *filter.DeletionAttempted == v.DeletedAt.IsZero() {
+			continue
+		}
+		if filter.TagSelector != nil {
+			tags, _ := s.tagsStorage.GetByInstanceID(v.InstanceID)
+			if ok = filter.TagSelector.Matches(labels.Set(tags)); !ok {
+				continue
+			}
+		}
 
 		inst = append(inst, v)
 	}
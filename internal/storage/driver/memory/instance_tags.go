@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"sync"
+)
+
+type InstanceTags struct {
+	mu   sync.Mutex
+	tags map[string]map[string]string
+}
+
+func NewInstanceTags() *InstanceTags {
+	return &InstanceTags{
+		tags: make(map[string]map[string]string),
+	}
+}
+
+func (s *InstanceTags) GetByInstanceID(instanceID string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tags := make(map[string]string, len(s.tags[instanceID]))
+	for key, value := range s.tags[instanceID] {
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+func (s *InstanceTags) GetByInstanceIDs(instanceIDs []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	for _, instanceID := range instanceIDs {
+		tags, _ := s.GetByInstanceID(instanceID)
+		if len(tags) > 0 {
+			result[instanceID] = tags
+		}
+	}
+	return result, nil
+}
+
+func (s *InstanceTags) Patch(instanceID string, patch map[string]*string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tags[instanceID] == nil {
+		s.tags[instanceID] = make(map[string]string)
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(s.tags[instanceID], key)
+			continue
+		}
+		s.tags[instanceID][key] = *value
+	}
+	if len(s.tags[instanceID]) == 0 {
+		delete(s.tags, instanceID)
+	}
+	return nil
+}
+
+func (s *InstanceTags) DeleteByInstanceID(instanceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tags, instanceID)
+	return nil
+}
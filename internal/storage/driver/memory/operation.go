@@ -206,6 +206,8 @@ func (s *operations) ListOperationsByInstanceIDGroupByType(instanceID string) (*
 
 		case internal.OperationTypeUpdate:
 			grouped.UpdateOperations = append(grouped.UpdateOperations, internal.UpdatingOperation{Operation: op})
+		case internal.OperationTypeTransfer:
+			continue
 		default:
 			panic("Invalid type of operation")
 		}
@@ -404,7 +406,7 @@ func (s *operations) GetLastOperation(instanceID string) (*internal.Operation, e
 	var rows []internal.Operation
 
 	for _, op := range s.operations {
-		if op.InstanceID == instanceID && op.State != internal.OperationStatePending {
+		if op.InstanceID == instanceID && op.State != internal.OperationStatePending && op.Type != internal.OperationTypeTransfer {
 			rows = append(rows, op)
 		}
 	}
@@ -437,7 +439,7 @@ func (s *operations) GetLastOperationWithAllStates(instanceID string) (*internal
 	var rows []internal.Operation
 
 	for _, op := range s.operations {
-		if op.InstanceID == instanceID {
+		if op.InstanceID == instanceID && op.Type != internal.OperationTypeTransfer {
 			rows = append(rows, op)
 		}
 	}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+)
+
+type QuotaReservation struct {
+	mu           sync.Mutex
+	reservations map[string]internal.QuotaReservation
+}
+
+func NewQuotaReservation() *QuotaReservation {
+	return &QuotaReservation{
+		reservations: make(map[string]internal.QuotaReservation),
+	}
+}
+
+func (s *QuotaReservation) Upsert(reservation internal.QuotaReservation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reservations[reservation.InstanceID] = reservation
+	return nil
+}
+
+func (s *QuotaReservation) ListByPlanID(planID string, createdAfter time.Time) ([]internal.QuotaReservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservations := make([]internal.QuotaReservation, 0)
+	for _, reservation := range s.reservations {
+		if reservation.PlanID == planID && reservation.CreatedAt.After(createdAfter) {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
+
+func (s *QuotaReservation) Delete(instanceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.reservations, instanceID)
+	return nil
+}
+
+func (s *QuotaReservation) DeleteCreatedBefore(createdBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for instanceID, reservation := range s.reservations {
+		if reservation.CreatedAt.Before(createdBefore) {
+			delete(s.reservations, instanceID)
+		}
+	}
+	return nil
+}
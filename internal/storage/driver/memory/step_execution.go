@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+)
+
+type StepExecution struct {
+	mu         sync.Mutex
+	executions []internal.StepExecution
+}
+
+func NewStepExecution() *StepExecution {
+	return &StepExecution{
+		executions: make([]internal.StepExecution, 0),
+	}
+}
+
+func (s *StepExecution) Insert(execution internal.StepExecution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	execution.Attempt = 1
+	for _, e := range s.executions {
+		if e.OperationID == execution.OperationID && e.Step == execution.Step && e.Attempt >= execution.Attempt {
+			execution.Attempt = e.Attempt + 1
+		}
+	}
+	s.executions = append(s.executions, execution)
+	return nil
+}
+
+func (s *StepExecution) ListByOperationIDs(operationIDs []string) ([]internal.StepExecution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filtered := make([]internal.StepExecution, 0)
+	for _, e := range s.executions {
+		if slices.Contains(operationIDs, e.OperationID) {
+			filtered = append(filtered, e)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].StartedAt.Before(filtered[j].StartedAt)
+	})
+	return filtered, nil
+}
+
+func (s *StepExecution) DeleteByOperationID(operationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.executions = slices.DeleteFunc(s.executions, func(e internal.StepExecution) bool {
+		return e.OperationID == operationID
+	})
+	return nil
+}
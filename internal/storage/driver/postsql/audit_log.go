@@ -0,0 +1,131 @@
+package postsql
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type AuditLog struct {
+	postsql.Factory
+}
+
+func NewAuditLog(sess postsql.Factory) *AuditLog {
+	return &AuditLog{
+		Factory: sess,
+	}
+}
+
+// Append links the entry to the last entry of the audit log and inserts it.
+// The table is locked for the time of the transaction, so concurrent calls cannot fork the hash chain.
+func (s *AuditLog) Append(entry internal.AuditEntry) (internal.AuditEntry, error) {
+	sess, err := s.Factory.NewSessionWithinTransaction()
+	if err != nil {
+		return internal.AuditEntry{}, err
+	}
+	defer sess.RollbackUnlessCommitted()
+
+	if err := sess.LockAuditLog(); err != nil {
+		return internal.AuditEntry{}, err
+	}
+	last, err := sess.GetLastAuditEntry()
+	switch {
+	case err == nil:
+	case dberr.IsNotFound(err):
+		last = dbmodel.AuditEntryDTO{}
+	default:
+		return internal.AuditEntry{}, err
+	}
+
+	entry.Sequence = last.Sequence + 1
+	entry.PreviousHash = last.Hash
+	// postgres stores timestamps with microsecond precision, the hash must be computed from the stored value
+	entry.Timestamp = entry.Timestamp.UTC().Truncate(time.Microsecond)
+	entry.Hash = entry.ComputeHash()
+
+	if err := sess.InsertAuditEntry(toAuditEntryDTO(entry)); err != nil {
+		return internal.AuditEntry{}, err
+	}
+	if err := sess.Commit(); err != nil {
+		return internal.AuditEntry{}, err
+	}
+	return entry, nil
+}
+
+func (s *AuditLog) List(filter dbmodel.AuditFilter) ([]internal.AuditEntry, int, error) {
+	dtos, totalCount, err := s.Factory.NewReadSession().ListAuditEntries(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return toAuditEntries(dtos), totalCount, nil
+}
+
+func (s *AuditLog) ListAfter(sequence int64, limit int) ([]internal.AuditEntry, error) {
+	dtos, err := s.Factory.NewReadSession().ListAuditEntriesAfter(sequence, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toAuditEntries(dtos), nil
+}
+
+func toAuditEntryDTO(entry internal.AuditEntry) dbmodel.AuditEntryDTO {
+	return dbmodel.AuditEntryDTO{
+		Sequence:          entry.Sequence,
+		ID:                entry.ID,
+		CreatedAt:         entry.Timestamp,
+		Action:            entry.Action,
+		Method:            entry.Method,
+		Path:              entry.Path,
+		RequestID:         entry.RequestID,
+		PrincipalPlatform: entry.Principal.Platform,
+		PrincipalIdentity: entry.Principal.Identity,
+		PrincipalSubject:  entry.Principal.Subject,
+		PrincipalClientID: entry.Principal.ClientID,
+		PrincipalVerified: entry.Principal.Verified,
+		UserAgent:         entry.Principal.UserAgent,
+		InstanceID:        entry.InstanceID,
+		Parameters:        string(entry.Parameters),
+		StatusCode:        entry.StatusCode,
+		Outcome:           string(entry.Outcome),
+		PreviousHash:      entry.PreviousHash,
+		Hash:              entry.Hash,
+	}
+}
+
+func toAuditEntries(dtos []dbmodel.AuditEntryDTO) []internal.AuditEntry {
+	entries := make([]internal.AuditEntry, 0, len(dtos))
+	for _, dto := range dtos {
+		var parameters json.RawMessage
+		if dto.Parameters != "" {
+			parameters = json.RawMessage(dto.Parameters)
+		}
+		entries = append(entries, internal.AuditEntry{
+			Sequence:  dto.Sequence,
+			ID:        dto.ID,
+			Timestamp: dto.CreatedAt.UTC(),
+			Action:    dto.Action,
+			Method:    dto.Method,
+			Path:      dto.Path,
+			RequestID: dto.RequestID,
+			Principal: internal.AuditPrincipal{
+				Platform:  dto.PrincipalPlatform,
+				Identity:  dto.PrincipalIdentity,
+				Subject:   dto.PrincipalSubject,
+				ClientID:  dto.PrincipalClientID,
+				UserAgent: dto.UserAgent,
+				Verified:  dto.PrincipalVerified,
+			},
+			InstanceID:   dto.InstanceID,
+			Parameters:   parameters,
+			StatusCode:   dto.StatusCode,
+			Outcome:      internal.AuditOutcome(dto.Outcome),
+			PreviousHash: dto.PreviousHash,
+			Hash:         dto.Hash,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,85 @@
+package postsql_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+
+	timestamp := time.Now().UTC()
+	fixEntry := func(id, action, instanceID, subject string, offset time.Duration) internal.AuditEntry {
+		return internal.AuditEntry{
+			ID:         id,
+			Timestamp:  timestamp.Add(offset),
+			Action:     action,
+			Method:     "PUT",
+			Path:       "/oauth/v2/service_instances/" + instanceID,
+			RequestID:  "request-" + id,
+			Principal:  internal.AuditPrincipal{Platform: "cloudfoundry", Subject: subject, UserAgent: "cf-cli"},
+			InstanceID: instanceID,
+			StatusCode: 202,
+			Outcome:    internal.AuditOutcomeSuccess,
+		}
+	}
+
+	first := fixEntry("audit-1", "provision", "instance-1", "john", 0)
+	first.Parameters = json.RawMessage(`{"name":"my-cluster"}`)
+	first, err = brokerStorage.AuditLog().Append(first)
+	require.NoError(t, err)
+	second, err := brokerStorage.AuditLog().Append(fixEntry("audit-2", "deprovision", "instance-1", "anna", time.Minute))
+	require.NoError(t, err)
+	_, err = brokerStorage.AuditLog().Append(fixEntry("audit-3", "provision", "instance-2", "john", 2*time.Minute))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), first.Sequence)
+	assert.Empty(t, first.PreviousHash)
+	assert.Equal(t, int64(2), second.Sequence)
+	assert.Equal(t, first.Hash, second.PreviousHash)
+
+	entries, err := brokerStorage.AuditLog().ListAfter(0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	for _, entry := range entries {
+		assert.Equal(t, entry.Hash, entry.ComputeHash())
+	}
+	assert.Equal(t, first, entries[0])
+
+	entries, err = brokerStorage.AuditLog().ListAfter(1, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "audit-2", entries[0].ID)
+
+	entries, count, err := brokerStorage.AuditLog().List(dbmodel.AuditFilter{InstanceID: "instance-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "audit-2", entries[0].ID)
+
+	from := timestamp.Add(30 * time.Second)
+	entries, count, err = brokerStorage.AuditLog().List(dbmodel.AuditFilter{Principal: "john", From: &from})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "audit-3", entries[0].ID)
+
+	entries, count, err = brokerStorage.AuditLog().List(dbmodel.AuditFilter{Action: "provision", Page: 2, PageSize: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "audit-1", entries[0].ID)
+}
@@ -202,6 +202,7 @@ func (s *Instance) toInstance(dto dbmodel.InstanceDTO) (internal.Instance, error
 		UpdatedAt:                   dto.UpdatedAt,
 		DeletedAt:                   dto.DeletedAt,
 		ExpiredAt:                   dto.ExpiredAt,
+		ExpiresAt:                   dto.ExpiresAt,
 		Version:                     dto.Version,
 		Provider:                    pkg.CloudProvider(dto.Provider),
 		EmptyUpdates:                dto.EmptyUpdates,
@@ -254,6 +255,7 @@ func (s *Instance) toInstanceWithSubaccountState(dto dbmodel.InstanceWithSubacco
 			UpdatedAt:                   dto.InstanceDTO.UpdatedAt,
 			DeletedAt:                   dto.DeletedAt,
 			ExpiredAt:                   dto.ExpiredAt,
+			ExpiresAt:                   dto.ExpiresAt,
 			Version:                     dto.InstanceDTO.Version,
 			EmptyUpdates:                dto.EmptyUpdates,
 			Provider:                    pkg.CloudProvider(dto.Provider)},
@@ -350,6 +352,7 @@ func (s *Instance) toInstanceDTO(instance internal.Instance) (dbmodel.InstanceDT
 		UpdatedAt:                   instance.UpdatedAt,
 		DeletedAt:                   instance.DeletedAt,
 		ExpiredAt:                   instance.ExpiredAt,
+		ExpiresAt:                   instance.ExpiresAt,
 		Version:                     instance.Version,
 		Provider:                    string(instance.Provider),
 		EmptyUpdates:                instance.EmptyUpdates,
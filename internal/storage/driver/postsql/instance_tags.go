@@ -0,0 +1,81 @@
+package postsql
+
+import (
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type InstanceTags struct {
+	postsql.Factory
+}
+
+func NewInstanceTags(sess postsql.Factory) *InstanceTags {
+	return &InstanceTags{
+		Factory: sess,
+	}
+}
+
+func (s *InstanceTags) GetByInstanceID(instanceID string) (map[string]string, error) {
+	tags, err := s.GetByInstanceIDs([]string{instanceID})
+	if err != nil {
+		return nil, err
+	}
+	if tags[instanceID] == nil {
+		return map[string]string{}, nil
+	}
+	return tags[instanceID], nil
+}
+
+func (s *InstanceTags) GetByInstanceIDs(instanceIDs []string) (map[string]map[string]string, error) {
+	dtos, err := s.Factory.NewReadSession().GetInstanceTags(instanceIDs)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]map[string]string)
+	for _, dto := range dtos {
+		if tags[dto.InstanceID] == nil {
+			tags[dto.InstanceID] = make(map[string]string)
+		}
+		tags[dto.InstanceID][dto.Key] = dto.Value
+	}
+	return tags, nil
+}
+
+func (s *InstanceTags) Patch(instanceID string, patch map[string]*string) error {
+	sess, err := s.Factory.NewSessionWithinTransaction()
+	if err != nil {
+		return err
+	}
+	defer sess.RollbackUnlessCommitted()
+
+	now := time.Now()
+	var deleted []string
+	for key, value := range patch {
+		if value == nil {
+			deleted = append(deleted, key)
+			continue
+		}
+		err := sess.UpsertInstanceTag(dbmodel.InstanceTagDTO{
+			InstanceID: instanceID,
+			Key:        key,
+			Value:      *value,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(deleted) > 0 {
+		if err := sess.DeleteInstanceTags(instanceID, deleted); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+func (s *InstanceTags) DeleteByInstanceID(instanceID string) error {
+	return s.Factory.NewWriteSession().DeleteInstanceTags(instanceID, nil)
+}
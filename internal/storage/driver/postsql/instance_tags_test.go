@@ -0,0 +1,79 @@
+package postsql_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestInstanceTags(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+
+	for _, id := range []string{"inst-1", "inst-2", "inst-3"} {
+		require.NoError(t, brokerStorage.Instances().Insert(fixture.FixInstance(id)))
+		operation := fixture.FixProvisioningOperation("op-"+id, id)
+		require.NoError(t, brokerStorage.Operations().InsertOperation(operation))
+		require.NoError(t, brokerStorage.Instances().UpdateInstanceLastOperation(id, operation.ID))
+	}
+
+	tags := brokerStorage.InstanceTags()
+	require.NoError(t, tags.Patch("inst-1", map[string]*string{"customer-tier": ptr.String("gold"), "incident": ptr.String("1234"), "priority": ptr.String("10")}))
+	require.NoError(t, tags.Patch("inst-2", map[string]*string{"customer-tier": ptr.String("silver"), "priority": ptr.String("high")}))
+	require.NoError(t, tags.Patch("inst-1", map[string]*string{"customer-tier": ptr.String("platinum"), "incident": nil}))
+
+	got, err := tags.GetByInstanceID("inst-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"customer-tier": "platinum", "priority": "10"}, got)
+
+	got, err = tags.GetByInstanceID("inst-3")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	all, err := tags.GetByInstanceIDs([]string{"inst-1", "inst-2", "inst-3"})
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Equal(t, "silver", all["inst-2"]["customer-tier"])
+
+	for selector, expectedIDs := range map[string][]string{
+		"customer-tier=platinum":             {"inst-1"},
+		"customer-tier in (platinum,silver)": {"inst-1", "inst-2"},
+		"customer-tier!=platinum":            {"inst-2", "inst-3"},
+		"customer-tier notin (silver)":       {"inst-1", "inst-3"},
+		"customer-tier":                      {"inst-1", "inst-2"},
+		"!customer-tier":                     {"inst-3"},
+		"priority>5":                         {"inst-1"},
+		"priority<5":                         {},
+		"customer-tier,!incident":            {"inst-1", "inst-2"},
+	} {
+		parsed, err := labels.Parse(selector)
+		require.NoError(t, err)
+
+		instances, count, totalCount, err := brokerStorage.Instances().List(dbmodel.InstanceFilter{TagSelector: parsed})
+		require.NoError(t, err, selector)
+
+		ids := make([]string, 0, len(instances))
+		for _, instance := range instances {
+			ids = append(ids, instance.InstanceID)
+		}
+		assert.ElementsMatch(t, expectedIDs, ids, selector)
+		assert.Equal(t, len(expectedIDs), count, selector)
+		assert.Equal(t, len(expectedIDs), totalCount, selector)
+	}
+
+	require.NoError(t, tags.DeleteByInstanceID("inst-1"))
+	got, err = tags.GetByInstanceID("inst-1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
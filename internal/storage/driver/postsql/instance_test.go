@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
@@ -374,6 +375,42 @@ func TestInstance_UsingLastOperationID(t *testing.T) {
 		assert.Equal(t, fixInstances[2].InstanceID, out[0].InstanceID)
 	})
 
+	t.Run("Should list instances following the cursor", func(t *testing.T) {
+		storageCleanup, brokerStorage, err := storage.GetStorageForTests(cfg)
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+		defer func() {
+			err := storageCleanup()
+			assert.NoError(t, err)
+		}()
+
+		// populate database with samples
+		for _, id := range []string{"1", "2", "3"} {
+			err = brokerStorage.Instances().Insert(*fixInstance(instanceData{val: id}))
+			require.NoError(t, err)
+			op := fixture.FixProvisioningOperation("op"+id, id)
+			err = brokerStorage.Operations().InsertOperation(op)
+			require.NoError(t, err)
+			err = brokerStorage.Instances().UpdateInstanceLastOperation(id, op.ID)
+			require.NoError(t, err)
+		}
+		first, _, _, err := brokerStorage.Instances().List(dbmodel.InstanceFilter{PageSize: 1, Page: 1})
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+
+		// when
+		out, count, totalCount, err := brokerStorage.Instances().ListWithSubaccountState(dbmodel.InstanceFilter{
+			PageSize: 1,
+			After:    &pagination.Cursor{CreatedAt: first[0].CreatedAt, ID: first[0].InstanceID},
+		})
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Equal(t, 3, totalCount)
+		assert.Equal(t, "2", out[0].InstanceID)
+	})
+
 	t.Run("Should list instances based on filters", func(t *testing.T) {
 		storageCleanup, brokerStorage, err := storage.GetStorageForTests(cfg)
 		require.NoError(t, err)
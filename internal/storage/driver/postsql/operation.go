@@ -181,7 +181,7 @@ func (s *operations) ListOperationsByInstanceIDGroupByType(instanceID string) (*
 				return nil, fmt.Errorf("while converting DTO to Operation: %w", err)
 			}
 			grouped.UpdateOperations = append(grouped.UpdateOperations, *ret)
-		case internal.OperationTypeUpgradeKyma:
+		case internal.OperationTypeUpgradeKyma, internal.OperationTypeTransfer:
 			continue
 		default:
 			return nil, fmt.Errorf("while converting DTO to Operation: unrecognized type of operation")
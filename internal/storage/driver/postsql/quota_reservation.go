@@ -0,0 +1,55 @@
+package postsql
+
+import (
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type QuotaReservation struct {
+	postsql.Factory
+}
+
+func NewQuotaReservation(sess postsql.Factory) *QuotaReservation {
+	return &QuotaReservation{
+		Factory: sess,
+	}
+}
+
+func (s *QuotaReservation) Upsert(reservation internal.QuotaReservation) error {
+	return s.Factory.NewWriteSession().UpsertQuotaReservation(dbmodel.QuotaReservationDTO{
+		InstanceID:      reservation.InstanceID,
+		GlobalAccountID: reservation.GlobalAccountID,
+		SubAccountID:    reservation.SubAccountID,
+		PlanID:          reservation.PlanID,
+		CreatedAt:       reservation.CreatedAt,
+	})
+}
+
+func (s *QuotaReservation) ListByPlanID(planID string, createdAfter time.Time) ([]internal.QuotaReservation, error) {
+	dtos, err := s.Factory.NewReadSession().ListQuotaReservations(planID, createdAfter)
+	if err != nil {
+		return nil, err
+	}
+	reservations := make([]internal.QuotaReservation, 0, len(dtos))
+	for _, dto := range dtos {
+		reservations = append(reservations, internal.QuotaReservation{
+			InstanceID:      dto.InstanceID,
+			GlobalAccountID: dto.GlobalAccountID,
+			SubAccountID:    dto.SubAccountID,
+			PlanID:          dto.PlanID,
+			CreatedAt:       dto.CreatedAt,
+		})
+	}
+	return reservations, nil
+}
+
+func (s *QuotaReservation) Delete(instanceID string) error {
+	return s.Factory.NewWriteSession().DeleteQuotaReservations(instanceID, time.Time{})
+}
+
+func (s *QuotaReservation) DeleteCreatedBefore(createdBefore time.Time) error {
+	return s.Factory.NewWriteSession().DeleteQuotaReservations("", createdBefore)
+}
@@ -0,0 +1,65 @@
+package postsql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaReservation(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	fixReservation := func(instanceID, planID string, createdAt time.Time) internal.QuotaReservation {
+		return internal.QuotaReservation{
+			InstanceID:      instanceID,
+			GlobalAccountID: "ga-1",
+			SubAccountID:    "sa-1",
+			PlanID:          planID,
+			CreatedAt:       createdAt,
+		}
+	}
+
+	reservations := brokerStorage.QuotaReservations()
+	require.NoError(t, reservations.Upsert(fixReservation("inst-1", "plan-1", now.Add(-time.Hour))))
+	require.NoError(t, reservations.Upsert(fixReservation("inst-2", "plan-1", now)))
+	require.NoError(t, reservations.Upsert(fixReservation("inst-3", "plan-2", now)))
+
+	// the reservation is renewed
+	require.NoError(t, reservations.Upsert(fixReservation("inst-1", "plan-1", now.Add(-time.Minute))))
+
+	got, err := reservations.ListByPlanID("plan-1", now.Add(-10*time.Minute))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []internal.QuotaReservation{
+		fixReservation("inst-1", "plan-1", now.Add(-time.Minute)),
+		fixReservation("inst-2", "plan-1", now),
+	}, normalizeReservations(got))
+
+	require.NoError(t, reservations.Delete("inst-2"))
+	require.NoError(t, reservations.DeleteCreatedBefore(now.Add(-30*time.Second)))
+
+	got, err = reservations.ListByPlanID("plan-1", time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = reservations.ListByPlanID("plan-2", time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func normalizeReservations(reservations []internal.QuotaReservation) []internal.QuotaReservation {
+	for i := range reservations {
+		reservations[i].CreatedAt = reservations[i].CreatedAt.UTC()
+	}
+	return reservations
+}
@@ -0,0 +1,64 @@
+package postsql
+
+import (
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type StepExecution struct {
+	postsql.Factory
+}
+
+func NewStepExecution(sess postsql.Factory) *StepExecution {
+	return &StepExecution{
+		Factory: sess,
+	}
+}
+
+func (s *StepExecution) Insert(execution internal.StepExecution) error {
+	return s.Factory.NewWriteSession().InsertStepExecution(dbmodel.StepExecutionDTO{
+		ID:                execution.ID,
+		OperationID:       execution.OperationID,
+		InstanceID:        execution.InstanceID,
+		Stage:             execution.Stage,
+		Step:              execution.Step,
+		StartedAt:         execution.StartedAt,
+		FinishedAt:        execution.FinishedAt,
+		Result:            string(execution.Result),
+		RetryAfterSeconds: int(execution.RetryAfter.Seconds()),
+		ErrorReason:       execution.ErrorReason,
+		ErrorComponent:    execution.ErrorComponent,
+	})
+}
+
+func (s *StepExecution) ListByOperationIDs(operationIDs []string) ([]internal.StepExecution, error) {
+	dtos, err := s.Factory.NewReadSession().ListStepExecutions(operationIDs)
+	if err != nil {
+		return nil, err
+	}
+	executions := make([]internal.StepExecution, 0, len(dtos))
+	for _, dto := range dtos {
+		executions = append(executions, internal.StepExecution{
+			ID:             dto.ID,
+			OperationID:    dto.OperationID,
+			InstanceID:     dto.InstanceID,
+			Stage:          dto.Stage,
+			Step:           dto.Step,
+			Attempt:        dto.Attempt,
+			StartedAt:      dto.StartedAt,
+			FinishedAt:     dto.FinishedAt,
+			Result:         internal.StepExecutionResult(dto.Result),
+			RetryAfter:     time.Duration(dto.RetryAfterSeconds) * time.Second,
+			ErrorReason:    dto.ErrorReason,
+			ErrorComponent: dto.ErrorComponent,
+		})
+	}
+	return executions, nil
+}
+
+func (s *StepExecution) DeleteByOperationID(operationID string) error {
+	return s.Factory.NewWriteSession().DeleteStepExecutions(operationID)
+}
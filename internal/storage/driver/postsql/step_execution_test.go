@@ -0,0 +1,71 @@
+package postsql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepExecution(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+
+	startedAt := time.Now().UTC().Truncate(time.Second)
+	fixExecution := func(id, operationID, step string, result internal.StepExecutionResult, offset time.Duration) internal.StepExecution {
+		return internal.StepExecution{
+			ID:          id,
+			OperationID: operationID,
+			InstanceID:  "instance-id",
+			Stage:       "create_runtime",
+			Step:        step,
+			StartedAt:   startedAt.Add(offset),
+			FinishedAt:  startedAt.Add(offset + time.Second),
+			Result:      result,
+		}
+	}
+
+	retry := fixExecution("exec-1", "op-1", "Create_Runtime_Resource", internal.StepExecutionRetry, 0)
+	retry.RetryAfter = 10 * time.Second
+	retry.ErrorReason = "err_kim_runtime_not_ready"
+	retry.ErrorComponent = "infrastructure-manager"
+	require.NoError(t, brokerStorage.StepExecutions().Insert(retry))
+	require.NoError(t, brokerStorage.StepExecutions().Insert(fixExecution("exec-2", "op-1", "Create_Runtime_Resource", internal.StepExecutionSucceeded, time.Minute)))
+	require.NoError(t, brokerStorage.StepExecutions().Insert(fixExecution("exec-3", "op-1", "Check_Runtime_Resource", internal.StepExecutionSucceeded, 2*time.Minute)))
+	require.NoError(t, brokerStorage.StepExecutions().Insert(fixExecution("exec-4", "op-2", "Create_Runtime_Resource", internal.StepExecutionFailed, 3*time.Minute)))
+
+	executions, err := brokerStorage.StepExecutions().ListByOperationIDs([]string{"op-1"})
+	require.NoError(t, err)
+	require.Len(t, executions, 3)
+
+	assert.Equal(t, "exec-1", executions[0].ID)
+	assert.Equal(t, 1, executions[0].Attempt)
+	assert.Equal(t, internal.StepExecutionRetry, executions[0].Result)
+	assert.Equal(t, 10*time.Second, executions[0].RetryAfter)
+	assert.Equal(t, "err_kim_runtime_not_ready", executions[0].ErrorReason)
+	assert.Equal(t, "infrastructure-manager", executions[0].ErrorComponent)
+	assert.Equal(t, 2, executions[1].Attempt)
+	assert.Equal(t, internal.StepExecutionSucceeded, executions[1].Result)
+	assert.Equal(t, 1, executions[2].Attempt)
+	assert.Equal(t, "Check_Runtime_Resource", executions[2].Step)
+
+	executions, err = brokerStorage.StepExecutions().ListByOperationIDs([]string{"op-1", "op-2"})
+	require.NoError(t, err)
+	assert.Len(t, executions, 4)
+	assert.Equal(t, 1, executions[3].Attempt)
+
+	require.NoError(t, brokerStorage.StepExecutions().DeleteByOperationID("op-1"))
+
+	executions, err = brokerStorage.StepExecutions().ListByOperationIDs([]string{"op-1", "op-2"})
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "exec-4", executions[0].ID)
+}
@@ -123,6 +123,41 @@ type Actions interface {
 	ListActionsByInstanceID(instanceID string) ([]runtime.Action, error)
 }
 
+// QuotaReservations are stored in the database, so that the reservations of all KEB replicas are counted.
+type QuotaReservations interface {
+	// Upsert stores the reservation of the instance, or renews it if the instance already has one.
+	Upsert(reservation internal.QuotaReservation) error
+	// ListByPlanID returns the reservations for the plan created after the given time.
+	ListByPlanID(planID string, createdAfter time.Time) ([]internal.QuotaReservation, error)
+	Delete(instanceID string) error
+	// DeleteCreatedBefore deletes the reservations which were not released, for example because the replica was stopped.
+	DeleteCreatedBefore(createdBefore time.Time) error
+}
+
+type StepExecutions interface {
+	// Insert stores the step execution with the next attempt number of the step within the operation.
+	Insert(execution internal.StepExecution) error
+	ListByOperationIDs(operationIDs []string) ([]internal.StepExecution, error)
+	DeleteByOperationID(operationID string) error
+}
+
+type AuditLog interface {
+	// Append stores the entry with the next sequence number, chained to the last entry by its hash, and returns the stored entry.
+	Append(entry internal.AuditEntry) (internal.AuditEntry, error)
+	List(filter dbmodel.AuditFilter) ([]internal.AuditEntry, int, error)
+	// ListAfter returns up to limit entries with the sequence number greater than the given one, ordered by the sequence number.
+	ListAfter(sequence int64, limit int) ([]internal.AuditEntry, error)
+}
+
+type InstanceTags interface {
+	GetByInstanceID(instanceID string) (map[string]string, error)
+	// GetByInstanceIDs returns the tags of the given instances, instances without tags are omitted.
+	GetByInstanceIDs(instanceIDs []string) (map[string]map[string]string, error)
+	// Patch sets the tags with non-nil values and removes the tags with nil values.
+	Patch(instanceID string, patch map[string]*string) error
+	DeleteByInstanceID(instanceID string) error
+}
+
 type TimeZones interface {
 	GetTimeZone() (string, error)
 }
@@ -63,6 +63,11 @@ type ReadSession interface {
 	ListExpiredBindings() ([]dbmodel.BindingDTO, error)
 	GetBindingsStatistics() (dbmodel.BindingStatsDTO, error)
 	ListActions(instanceID string) ([]runtime.Action, error)
+	ListStepExecutions(operationIDs []string) ([]dbmodel.StepExecutionDTO, error)
+	GetInstanceTags(instanceIDs []string) ([]dbmodel.InstanceTagDTO, error)
+	ListQuotaReservations(planID string, createdAfter time.Time) ([]dbmodel.QuotaReservationDTO, error)
+	ListAuditEntries(filter dbmodel.AuditFilter) ([]dbmodel.AuditEntryDTO, int, error)
+	ListAuditEntriesAfter(sequence int64, limit int) ([]dbmodel.AuditEntryDTO, error)
 	GetTimeZone() (string, dberr.Error)
 }
 
@@ -84,6 +89,15 @@ type WriteSession interface {
 	DeleteBinding(instanceID, bindingID string) dberr.Error
 	UpdateInstanceLastOperation(instanceID, operationID string) error
 	InsertAction(actionType runtime.ActionType, instanceID, message, oldValue, newValue string) dberr.Error
+	InsertStepExecution(execution dbmodel.StepExecutionDTO) dberr.Error
+	DeleteStepExecutions(operationID string) dberr.Error
+	UpsertInstanceTag(tag dbmodel.InstanceTagDTO) dberr.Error
+	DeleteInstanceTags(instanceID string, keys []string) dberr.Error
+	UpsertQuotaReservation(reservation dbmodel.QuotaReservationDTO) dberr.Error
+	DeleteQuotaReservations(instanceID string, createdBefore time.Time) dberr.Error
+	LockAuditLog() dberr.Error
+	GetLastAuditEntry() (dbmodel.AuditEntryDTO, dberr.Error)
+	InsertAuditEntry(entry dbmodel.AuditEntryDTO) dberr.Error
 }
 
 type Transaction interface {
@@ -20,6 +20,10 @@ const (
 	InstancesArchivedTableName = "instances_archived"
 	BindingsTableName          = "bindings"
 	ActionsTableName           = "actions"
+	StepExecutionsTableName    = "step_executions"
+	InstanceTagsTableName      = "instance_tags"
+	QuotaReservationsTableName = "quota_reservations"
+	AuditLogTableName          = "audit_log"
 )
 
 // InitializeDatabase opens database connection and initializes schema if it does not exist
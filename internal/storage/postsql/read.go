@@ -3,6 +3,7 @@ package postsql
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +16,8 @@ import (
 
 	"github.com/gocraft/dbr"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 )
 
 type readSession struct {
@@ -195,7 +198,7 @@ func (r readSession) FindAllInstancesForSubAccounts(subAccountslist []string) ([
 func (r readSession) GetLastOperation(instanceID string, types []internal.OperationType) (dbmodel.OperationDTO, dberr.Error) {
 	inst := dbr.Eq("instance_id", instanceID)
 	state := dbr.Neq("state", []string{internal.OperationStatePending, internal.OperationStateCanceled})
-	condition := dbr.And(inst, state)
+	condition := dbr.And(inst, state, notTransfer())
 	if len(types) > 0 {
 		condition = dbr.And(condition, dbr.Expr("type IN ?", types))
 	}
@@ -212,7 +215,7 @@ func (r readSession) GetLastOperation(instanceID string, types []internal.Operat
 }
 
 func (r readSession) GetLastOperationWithAllStates(instanceID string) (dbmodel.OperationDTO, dberr.Error) {
-	condition := dbr.Eq("instance_id", instanceID)
+	condition := dbr.And(dbr.Eq("instance_id", instanceID), notTransfer())
 	operation, err := r.getLastOperation(condition)
 	if err != nil {
 		switch {
@@ -449,6 +452,11 @@ func (r readSession) getOperation(condition dbr.Builder) (dbmodel.OperationDTO,
 	return operation, nil
 }
 
+// notTransfer excludes transfer operations, which do not change the runtime, from the last operation of the instance
+func notTransfer() dbr.Builder {
+	return dbr.Neq("type", internal.OperationTypeTransfer)
+}
+
 func (r readSession) getLastOperation(condition dbr.Builder) (dbmodel.OperationDTO, dberr.Error) {
 	var operation dbmodel.OperationDTO
 
@@ -641,18 +649,15 @@ func (r readSession) ListInstances(filter dbmodel.InstanceFilter) ([]dbmodel.Ins
 	stmt := r.session.Select("o.data", "o.state", "o.type", fmt.Sprintf("%s.*", InstancesTableName)).
 		From(InstancesTableName).
 		Join(dbr.I(OperationTableName).As("o"), fmt.Sprintf("%s.last_operation_id = o.id", InstancesTableName)).
-		OrderBy(fmt.Sprintf("%s.%s", InstancesTableName, CreatedAtField))
+		OrderBy(fmt.Sprintf("%s.%s", InstancesTableName, CreatedAtField)).
+		OrderBy(fmt.Sprintf("%s.instance_id", InstancesTableName))
 
 	if len(filter.States) > 0 || filter.Suspended != nil {
 		stateFilters := buildInstanceStateFilters("o", filter)
 		stmt.Where(stateFilters)
 	}
 
-	// Add pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
-		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
-	}
-
+	addPagination(stmt, filter)
 	addInstanceFilters(stmt, filter, "o")
 
 	_, err := stmt.Load(&instances)
@@ -682,18 +687,15 @@ func (r readSession) ListInstancesWithSubaccountStates(filter dbmodel.InstanceFi
 		From(InstancesTableName).
 		Join(dbr.I(OperationTableName).As("o1"), fmt.Sprintf("%s.last_operation_id = o1.id", InstancesTableName)).
 		LeftJoin(dbr.I(SubaccountStatesTableName).As("ss"), fmt.Sprintf("%s.sub_account_id = ss.id", InstancesTableName)).
-		OrderBy(fmt.Sprintf("%s.%s", InstancesTableName, CreatedAtField))
+		OrderBy(fmt.Sprintf("%s.%s", InstancesTableName, CreatedAtField)).
+		OrderBy(fmt.Sprintf("%s.instance_id", InstancesTableName))
 
 	if len(filter.States) > 0 || filter.Suspended != nil {
 		stateFilters := buildInstanceStateFilters("o1", filter)
 		stmt.Where(stateFilters)
 	}
 
-	// Add pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
-		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
-	}
-
+	addPagination(stmt, filter)
 	addInstanceFilters(stmt, filter, "o1")
 
 	_, err := stmt.Load(&instances)
@@ -810,6 +812,21 @@ func buildInstanceStateFilters(table string, filter dbmodel.InstanceFilter) dbr.
 	return dbr.Or(exprs...)
 }
 
+// addPagination adds the offset or, when the cursor is set, the keyset pagination. The cursor is not a filter,
+// so it must not be used when the total count of instances is calculated.
+func addPagination(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
+	if filter.After != nil {
+		stmt.Where(fmt.Sprintf("(%s.%s, %s.instance_id) > (?, ?)", InstancesTableName, CreatedAtField, InstancesTableName), filter.After.CreatedAt, filter.After.ID)
+		if filter.PageSize > 0 {
+			stmt.Limit(uint64(filter.PageSize))
+		}
+		return
+	}
+	if filter.Page > 0 && filter.PageSize > 0 {
+		stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	}
+}
+
 func addInstanceFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter, table string) {
 	if len(filter.GlobalAccountIDs) > 0 {
 		stmt.Where("instances.global_account_id IN ?", filter.GlobalAccountIDs)
@@ -858,6 +875,43 @@ func addInstanceFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter, tab
 	if filter.BindingExists != nil && *filter.BindingExists {
 		stmt.Where("exists (select instance_id from bindings where bindings.instance_id=instances.instance_id)")
 	}
+
+	if filter.TagSelector != nil {
+		addInstanceTagFilters(stmt, filter.TagSelector)
+	}
+}
+
+// addInstanceTagFilters translates every requirement of the label selector to a subquery on the instance tags,
+// with the same semantics as labels.Selector.Matches
+func addInstanceTagFilters(stmt *dbr.SelectStmt, selector labels.Selector) {
+	requirements, _ := selector.Requirements()
+	tagQuery := fmt.Sprintf("select 1 from %s t where t.instance_id=instances.instance_id and t.key = ?", InstanceTagsTableName)
+	for _, r := range requirements {
+		values := r.Values().List()
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			stmt.Where(fmt.Sprintf("exists (%s and t.value IN ?)", tagQuery), r.Key(), values)
+		case selection.NotEquals, selection.NotIn:
+			stmt.Where(fmt.Sprintf("not exists (%s and t.value IN ?)", tagQuery), r.Key(), values)
+		case selection.Exists:
+			stmt.Where(fmt.Sprintf("exists (%s)", tagQuery), r.Key())
+		case selection.DoesNotExist:
+			stmt.Where(fmt.Sprintf("not exists (%s)", tagQuery), r.Key())
+		case selection.GreaterThan, selection.LessThan:
+			operator := ">"
+			if r.Operator() == selection.LessThan {
+				operator = "<"
+			}
+			bound, err := strconv.ParseInt(values[0], 10, 64)
+			if err != nil {
+				// the selector parser accepts only integers, so such a requirement cannot match
+				stmt.Where("false")
+				continue
+			}
+			// the value is cast only when it is an integer, the tags with other values do not match
+			stmt.Where(fmt.Sprintf("exists (%s and case when t.value ~ '^-?[0-9]{1,18}$' then t.value::bigint %s ? else false end)", tagQuery, operator), r.Key(), bound)
+		}
+	}
 }
 
 func addOperationFilters(stmt *dbr.SelectStmt, filter dbmodel.OperationFilter) {
@@ -1016,6 +1070,97 @@ func (r readSession) ListActions(instanceID string) ([]runtime.Action, error) {
 	return actions, err
 }
 
+func (r readSession) GetInstanceTags(instanceIDs []string) ([]dbmodel.InstanceTagDTO, error) {
+	var tags []dbmodel.InstanceTagDTO
+	if len(instanceIDs) == 0 {
+		return tags, nil
+	}
+	_, err := r.session.Select("*").
+		From(InstanceTagsTableName).
+		Where("instance_id IN ?", instanceIDs).
+		OrderAsc("key").
+		Load(&tags)
+	return tags, err
+}
+
+func (r readSession) ListQuotaReservations(planID string, createdAfter time.Time) ([]dbmodel.QuotaReservationDTO, error) {
+	var reservations []dbmodel.QuotaReservationDTO
+	_, err := r.session.Select("*").
+		From(QuotaReservationsTableName).
+		Where(dbr.Eq("plan_id", planID)).
+		Where(dbr.Gt("created_at", createdAfter)).
+		Load(&reservations)
+	return reservations, err
+}
+
+func (r readSession) ListStepExecutions(operationIDs []string) ([]dbmodel.StepExecutionDTO, error) {
+	var executions []dbmodel.StepExecutionDTO
+	if len(operationIDs) == 0 {
+		return executions, nil
+	}
+	_, err := r.session.Select("*").
+		From(StepExecutionsTableName).
+		Where("operation_id IN ?", operationIDs).
+		OrderAsc("started_at").
+		Load(&executions)
+	return executions, err
+}
+
+func (r readSession) ListAuditEntries(filter dbmodel.AuditFilter) ([]dbmodel.AuditEntryDTO, int, error) {
+	var entries []dbmodel.AuditEntryDTO
+	stmt := r.session.Select("*").
+		From(AuditLogTableName).
+		OrderDesc("sequence")
+	addAuditFilter(stmt, filter)
+	if filter.Page > 0 && filter.PageSize > 0 {
+		stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	}
+	if _, err := stmt.Load(&entries); err != nil {
+		return nil, 0, err
+	}
+
+	var totalCount int
+	countStmt := r.session.Select("count(*)").From(AuditLogTableName)
+	addAuditFilter(countStmt, filter)
+	if err := countStmt.LoadOne(&totalCount); err != nil {
+		return nil, 0, err
+	}
+	return entries, totalCount, nil
+}
+
+func addAuditFilter(stmt *dbr.SelectStmt, filter dbmodel.AuditFilter) {
+	if filter.InstanceID != "" {
+		stmt.Where(dbr.Eq("instance_id", filter.InstanceID))
+	}
+	if filter.Action != "" {
+		stmt.Where(dbr.Eq("action", filter.Action))
+	}
+	if filter.Principal != "" {
+		stmt.Where(dbr.Or(
+			dbr.Eq("principal_subject", filter.Principal),
+			dbr.Eq("principal_client_id", filter.Principal),
+			dbr.Eq("principal_identity", filter.Principal),
+		))
+	}
+	if filter.From != nil {
+		stmt.Where(dbr.Gte("created_at", *filter.From))
+	}
+	if filter.To != nil {
+		stmt.Where(dbr.Lt("created_at", *filter.To))
+	}
+}
+
+func (r readSession) ListAuditEntriesAfter(sequence int64, limit int) ([]dbmodel.AuditEntryDTO, error) {
+	var entries []dbmodel.AuditEntryDTO
+	_, err := r.session.Select("*").
+		From(AuditLogTableName).
+		Where(dbr.Gt("sequence", sequence)).
+		OrderAsc("sequence").
+		Limit(uint64(limit)).
+		Load(&entries)
+	return entries, err
+}
+
 func addInstanceArchivedFilter(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
 	if len(filter.InstanceIDs) > 0 {
 		stmt.Where("instance_id IN ?", filter.InstanceIDs)
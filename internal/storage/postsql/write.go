@@ -1,6 +1,8 @@
 package postsql
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/events"
@@ -136,6 +138,7 @@ func (ws writeSession) InsertInstance(instance dbmodel.InstanceDTO) dberr.Error
 		Pair("provider", instance.Provider).
 		Pair("deleted_at", instance.DeletedAt).
 		Pair("expired_at", instance.ExpiredAt).
+		Pair("expires_at", instance.ExpiresAt).
 		Pair("version", instance.Version).
 		Pair("empty_updates", instance.EmptyUpdates).
 		Exec()
@@ -183,6 +186,7 @@ func (ws writeSession) UpdateInstance(instance dbmodel.InstanceDTO) dberr.Error
 		Set("deleted_at", instance.DeletedAt).
 		Set("version", instance.Version+1).
 		Set("expired_at", instance.ExpiredAt).
+		Set("expires_at", instance.ExpiresAt).
 		Set("empty_updates", instance.EmptyUpdates).
 		Exec()
 	if err != nil {
@@ -428,6 +432,143 @@ func (ws writeSession) InsertAction(actionType runtime.ActionType, instanceID, m
 	return nil
 }
 
+// InsertStepExecution stores the step execution with the attempt number following the previous runs of the step within the operation.
+func (ws writeSession) InsertStepExecution(execution dbmodel.StepExecutionDTO) dberr.Error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, operation_id, instance_id, stage, step, attempt, started_at, finished_at, result, retry_after_seconds, error_reason, error_component)
+SELECT ?, ?, ?, ?, ?, COALESCE(MAX(attempt), 0) + 1, ?, ?, ?, ?, ?, ?
+FROM %s WHERE operation_id = ? AND step = ?`, StepExecutionsTableName, StepExecutionsTableName)
+	_, err := ws.insertBySql(query,
+		execution.ID, execution.OperationID, execution.InstanceID, execution.Stage, execution.Step,
+		execution.StartedAt, execution.FinishedAt, execution.Result, execution.RetryAfterSeconds, execution.ErrorReason, execution.ErrorComponent,
+		execution.OperationID, execution.Step).
+		Exec()
+	if err != nil {
+		return dberr.Internal("failed to insert step execution: %s", err)
+	}
+	return nil
+}
+
+func (ws writeSession) DeleteStepExecutions(operationID string) dberr.Error {
+	_, err := ws.deleteFrom(StepExecutionsTableName).
+		Where(dbr.Eq("operation_id", operationID)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("failed to delete step executions: %s", err)
+	}
+	return nil
+}
+
+func (ws writeSession) UpsertInstanceTag(tag dbmodel.InstanceTagDTO) dberr.Error {
+	query := fmt.Sprintf(`INSERT INTO %s (instance_id, key, value, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (instance_id, key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`, InstanceTagsTableName)
+	_, err := ws.insertBySql(query, tag.InstanceID, tag.Key, tag.Value, tag.CreatedAt, tag.UpdatedAt).Exec()
+	if err != nil {
+		return dberr.Internal("failed to upsert instance tag: %s", err)
+	}
+	return nil
+}
+
+// DeleteInstanceTags deletes the given tags of the instance, or all its tags when no keys are given
+func (ws writeSession) DeleteInstanceTags(instanceID string, keys []string) dberr.Error {
+	stmt := ws.deleteFrom(InstanceTagsTableName).
+		Where(dbr.Eq("instance_id", instanceID))
+	if len(keys) > 0 {
+		stmt = stmt.Where("key IN ?", keys)
+	}
+	_, err := stmt.Exec()
+	if err != nil {
+		return dberr.Internal("failed to delete instance tags: %s", err)
+	}
+	return nil
+}
+
+// UpsertQuotaReservation stores the reservation of the instance, the reservation of an instance which already has one is renewed
+func (ws writeSession) UpsertQuotaReservation(reservation dbmodel.QuotaReservationDTO) dberr.Error {
+	query := fmt.Sprintf(`INSERT INTO %s (instance_id, global_account_id, sub_account_id, plan_id, created_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (instance_id) DO UPDATE SET global_account_id = EXCLUDED.global_account_id, sub_account_id = EXCLUDED.sub_account_id,
+plan_id = EXCLUDED.plan_id, created_at = EXCLUDED.created_at`, QuotaReservationsTableName)
+	_, err := ws.insertBySql(query, reservation.InstanceID, reservation.GlobalAccountID, reservation.SubAccountID, reservation.PlanID, reservation.CreatedAt).Exec()
+	if err != nil {
+		return dberr.Internal("failed to upsert quota reservation: %s", err)
+	}
+	return nil
+}
+
+// DeleteQuotaReservations deletes the reservation of the instance, or all reservations created before the given time when the instance ID is empty
+func (ws writeSession) DeleteQuotaReservations(instanceID string, createdBefore time.Time) dberr.Error {
+	stmt := ws.deleteFrom(QuotaReservationsTableName)
+	if instanceID != "" {
+		stmt = stmt.Where(dbr.Eq("instance_id", instanceID))
+	} else {
+		stmt = stmt.Where(dbr.Lt("created_at", createdBefore))
+	}
+	_, err := stmt.Exec()
+	if err != nil {
+		return dberr.Internal("failed to delete quota reservations: %s", err)
+	}
+	return nil
+}
+
+// LockAuditLog locks the audit log table until the end of the transaction, so the entries are appended one by one to the hash chain.
+// The table can still be read.
+func (ws writeSession) LockAuditLog() dberr.Error {
+	if ws.transaction == nil {
+		return dberr.Internal("the audit log can be locked only within a transaction")
+	}
+	_, err := ws.transaction.Exec(fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", AuditLogTableName))
+	if err != nil {
+		return dberr.Internal("failed to lock audit log: %s", err)
+	}
+	return nil
+}
+
+func (ws writeSession) GetLastAuditEntry() (dbmodel.AuditEntryDTO, dberr.Error) {
+	var entry dbmodel.AuditEntryDTO
+	stmt := ws.session.Select("*")
+	if ws.transaction != nil {
+		stmt = ws.transaction.Select("*")
+	}
+	err := stmt.From(AuditLogTableName).
+		OrderDesc("sequence").
+		Limit(1).
+		LoadOne(&entry)
+	if err != nil {
+		if errors.Is(err, dbr.ErrNotFound) {
+			return dbmodel.AuditEntryDTO{}, dberr.NotFound("audit log is empty")
+		}
+		return dbmodel.AuditEntryDTO{}, dberr.Internal("failed to get last audit entry: %s", err)
+	}
+	return entry, nil
+}
+
+func (ws writeSession) InsertAuditEntry(entry dbmodel.AuditEntryDTO) dberr.Error {
+	_, err := ws.insertInto(AuditLogTableName).
+		Pair("sequence", entry.Sequence).
+		Pair("id", entry.ID).
+		Pair("created_at", entry.CreatedAt).
+		Pair("action", entry.Action).
+		Pair("method", entry.Method).
+		Pair("path", entry.Path).
+		Pair("request_id", entry.RequestID).
+		Pair("principal_platform", entry.PrincipalPlatform).
+		Pair("principal_identity", entry.PrincipalIdentity).
+		Pair("principal_subject", entry.PrincipalSubject).
+		Pair("principal_client_id", entry.PrincipalClientID).
+		Pair("principal_verified", entry.PrincipalVerified).
+		Pair("user_agent", entry.UserAgent).
+		Pair("instance_id", entry.InstanceID).
+		Pair("parameters", entry.Parameters).
+		Pair("status_code", entry.StatusCode).
+		Pair("outcome", entry.Outcome).
+		Pair("previous_hash", entry.PreviousHash).
+		Pair("hash", entry.Hash).
+		Exec()
+	if err != nil {
+		return dberr.Internal("failed to insert audit entry: %s", err)
+	}
+	return nil
+}
+
 func (ws writeSession) Commit() dberr.Error {
 	err := ws.transaction.Commit()
 	if err != nil {
@@ -449,6 +590,14 @@ func (ws writeSession) insertInto(table string) *dbr.InsertStmt {
 	return ws.session.InsertInto(table)
 }
 
+func (ws writeSession) insertBySql(query string, values ...interface{}) *dbr.InsertStmt {
+	if ws.transaction != nil {
+		return ws.transaction.InsertBySql(query, values...)
+	}
+
+	return ws.session.InsertBySql(query, values...)
+}
+
 func (ws writeSession) deleteFrom(table string) *dbr.DeleteStmt {
 	if ws.transaction != nil {
 		return ws.transaction.DeleteFrom(table)
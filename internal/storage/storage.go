@@ -24,6 +24,10 @@ type BrokerStorage interface {
 	InstancesArchived() InstancesArchived
 	Bindings() Bindings
 	Actions() Actions
+	StepExecutions() StepExecutions
+	InstanceTags() InstanceTags
+	QuotaReservations() QuotaReservations
+	AuditLog() AuditLog
 	TimeZones() TimeZones
 }
 
@@ -59,6 +63,10 @@ func NewFromConfigAndConnectionURL(cfg Config, evcfg events.Config, cipher postg
 		instancesArchived: postgres.NewInstanceArchived(factory),
 		bindings:          postgres.NewBinding(factory, cipher),
 		actions:           postgres.NewAction(factory),
+		stepExecutions:    postgres.NewStepExecution(factory),
+		instanceTags:      postgres.NewInstanceTags(factory),
+		quotaReservations: postgres.NewQuotaReservation(factory),
+		auditLog:          postgres.NewAuditLog(factory),
 		timezones:         postgres.NewTimeZones(factory),
 	}, connection, nil
 }
@@ -66,14 +74,19 @@ func NewFromConfigAndConnectionURL(cfg Config, evcfg events.Config, cipher postg
 func NewMemoryStorage() BrokerStorage {
 	op := memory.NewOperation()
 	ss := memory.NewSubaccountStates()
+	tags := memory.NewInstanceTags()
 	return storage{
 		operation:         op,
 		subaccountStates:  ss,
-		instance:          memory.NewInstance(op, ss),
+		instance:          memory.NewInstance(op, ss, tags),
 		events:            events.New(events.Config{}, newInMemoryEvents()),
 		instancesArchived: memory.NewInstanceArchivedInMemoryStorage(),
 		bindings:          memory.NewBinding(),
 		actions:           memory.NewAction(),
+		stepExecutions:    memory.NewStepExecution(),
+		instanceTags:      tags,
+		quotaReservations: memory.NewQuotaReservation(),
+		auditLog:          memory.NewAuditLog(),
 	}
 }
 
@@ -133,6 +146,10 @@ type storage struct {
 	instancesArchived InstancesArchived
 	bindings          Bindings
 	actions           Actions
+	stepExecutions    StepExecutions
+	instanceTags      InstanceTags
+	quotaReservations QuotaReservations
+	auditLog          AuditLog
 	timezones         TimeZones
 }
 
@@ -172,4 +189,20 @@ func (s storage) Actions() Actions {
 	return s.actions
 }
 
+func (s storage) StepExecutions() StepExecutions {
+	return s.stepExecutions
+}
+
+func (s storage) InstanceTags() InstanceTags {
+	return s.instanceTags
+}
+
+func (s storage) QuotaReservations() QuotaReservations {
+	return s.quotaReservations
+}
+
+func (s storage) AuditLog() AuditLog {
+	return s.auditLog
+}
+
 func (s storage) TimeZones() TimeZones { return s.timezones }
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/health"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 )
 
@@ -24,6 +25,7 @@ type (
 		RuntimeConfigurationConfigMapName string
 		AlwaysSubaccountFromDatabase      bool   `envconfig:"default=false"`
 		EventsServiceVersion              string `envconfig:"default=v1"`
+		Readiness                         health.Config
 	}
 
 	CisEndpointConfig struct {
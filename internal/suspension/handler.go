@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
@@ -18,6 +19,7 @@ type ContextUpdateHandler struct {
 	operations          storage.Operations
 	provisioningQueue   Adder
 	deprovisioningQueue Adder
+	residencyPolicies   *residency.Policies
 
 	log *slog.Logger
 }
@@ -31,10 +33,17 @@ func NewContextUpdateHandler(operations storage.Operations, provisioningQueue Ad
 		operations:          operations,
 		provisioningQueue:   provisioningQueue,
 		deprovisioningQueue: deprovisioningQueue,
+		residencyPolicies:   residency.DefaultPolicies(),
 		log:                 l,
 	}
 }
 
+// WithResidencyPolicies replaces the default residency policies used to mark the EU Access of the unsuspended runtimes.
+func (h *ContextUpdateHandler) WithResidencyPolicies(policies *residency.Policies) *ContextUpdateHandler {
+	h.residencyPolicies = policies
+	return h
+}
+
 // Handle performs suspension/unsuspension for given instance.
 // Applies only when 'Active' parameter has changes and ServicePlanID is `Trial`
 func (h *ContextUpdateHandler) Handle(instance *internal.Instance, newCtx internal.ERSContext) (bool, error) {
@@ -68,7 +77,7 @@ func (h *ContextUpdateHandler) handleContextChange(newCtx internal.ERSContext, i
 		l.Debug(fmt.Sprintf("Context.Active flag was not changed, the current value: %v", isActivated))
 		if isActivated {
 			// instance is marked as Active and incoming context update is unsuspension
-			// TODO: consider retriggering failed unsuspension here
+			// failed unsuspension is retriggered by the suspension recovery, see Recovery
 			l.Info(fmt.Sprintf("Context.Active flag is true - not triggering suspension for instance ID %s", instance.InstanceID))
 			return false, nil
 		}
@@ -93,6 +102,7 @@ func (h *ContextUpdateHandler) handleContextChange(newCtx internal.ERSContext, i
 			return false, nil
 		}
 		if lastDeprovisioning != nil && lastDeprovisioning.State == domain.Failed {
+			// the suspension recovery retriggers the suspension and then the unsuspension, see Recovery
 			err := fmt.Errorf("Preceding suspension has failed, unable to reliably unsuspend")
 			return false, apiresponses.NewFailureResponse(err, http.StatusInternalServerError, "provisioning")
 		}
@@ -131,7 +141,8 @@ func (h *ContextUpdateHandler) unsuspend(instance *internal.Instance, log *slog.
 		return nil
 	}
 	id := uuid.New().String()
-	operation, err := internal.NewProvisioningOperationWithID(id, instance.InstanceID, instance.Parameters)
+	euAccess := h.residencyPolicies.IsEUAccess(instance.Parameters.PlatformRegion)
+	operation, err := internal.NewProvisioningOperationWithID(id, instance.InstanceID, instance.Parameters, euAccess)
 	if err != nil {
 		log.Error(fmt.Sprintf("unable to create provisioning operation: %s", err.Error()))
 		return err
@@ -142,6 +153,8 @@ func (h *ContextUpdateHandler) unsuspend(instance *internal.Instance, log *slog.
 		h.log.Error(fmt.Sprintf("unable to extract shoot name: %s", err.Error()))
 		return err
 	}
+	// the stored instance details may come from a provisioning before the residency policies were introduced
+	operation.EuAccess = euAccess
 	operation.State = internal.OperationStatePending
 	log.Info(fmt.Sprintf("Starting unsuspension: shootName=%s shootDomain=%s", operation.ShootName, operation.ShootDomain))
 	// RuntimeID must be cleaned  - this mean that there is no runtime in the provisioner/director
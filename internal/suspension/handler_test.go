@@ -3,6 +3,7 @@ package suspension
 import (
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
 	"github.com/stretchr/testify/assert"
@@ -172,6 +174,39 @@ func TestUnsuspension(t *testing.T) {
 	assert.Equal(t, shootDomainC012345, op.ShootDomain)
 }
 
+func TestUnsuspension_EUAccessFromResidencyPolicies(t *testing.T) {
+	// given
+	provisioning := NewDummyQueue()
+	deprovisioning := NewDummyQueue()
+	st := storage.NewMemoryStorage()
+
+	policies, err := residency.NewPolicies(strings.NewReader(`cf-eu30: { euAccess: true }`))
+	require.NoError(t, err)
+	svc := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger()).
+		WithResidencyPolicies(policies)
+	instance := fixInstance(fixInactiveErsContext())
+	instance.Parameters.PlatformRegion = "cf-eu30"
+	instance.InstanceDetails.EuAccess = false
+
+	err = st.Instances().Insert(*instance)
+	require.NoError(t, err)
+
+	deprovisioningOperation := fixture.FixDeprovisioningOperation("d-op", "instance-id")
+	deprovisioningOperation.Temporary = true
+	err = st.Operations().InsertDeprovisioningOperation(deprovisioningOperation)
+	require.NoError(t, err)
+
+	// when
+	changed, err := svc.Handle(instance, fixActiveErsContext())
+	require.NoError(t, err)
+	assert.True(t, changed, "handler to change active flag")
+
+	// then
+	op, err := st.Operations().GetProvisioningOperationByInstanceID("instance-id")
+	require.NoError(t, err)
+	assert.True(t, op.EuAccess)
+}
+
 func TestUnsuspensionForDeprovisioningInstance(t *testing.T) {
 	// given
 	provisioning := NewDummyQueue()
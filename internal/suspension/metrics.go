@@ -0,0 +1,53 @@
+package suspension
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// exposed metrics:
+// - kcp_keb_v2_suspension_recovery_retriggered_total
+// - kcp_keb_v2_suspension_recovery_stuck_instances
+// - kcp_keb_v2_suspension_recovery_errors_total
+
+const (
+	prometheusNamespace = "kcp"
+	prometheusSubsystem = "keb_v2"
+)
+
+type RecoveryMetrics struct {
+	retriggered *prometheus.CounterVec
+	stuck       *prometheus.GaugeVec
+	errors      prometheus.Counter
+}
+
+func NewRecoveryMetrics(reg prometheus.Registerer) *RecoveryMetrics {
+	m := &RecoveryMetrics{
+		retriggered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "suspension_recovery_retriggered_total",
+			Help:      "Total number of suspensions and unsuspensions of trial instances retriggered by the suspension recovery.",
+		}, []string{"operation"}),
+		stuck: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "suspension_recovery_stuck_instances",
+			Help:      "Number of trial instances whose failed suspension or unsuspension reached the limit of attempts during the last suspension recovery run.",
+		}, []string{"operation"}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "suspension_recovery_errors_total",
+			Help:      "Total number of errors which occurred while recovering the suspension of an instance.",
+		}),
+	}
+	reg.MustRegister(m.retriggered, m.stuck, m.errors)
+	return m
+}
+
+func (m *RecoveryMetrics) setStuck(stuck map[string]int) {
+	m.stuck.Reset()
+	for operation, count := range stuck {
+		m.stuck.WithLabelValues(operation).Set(float64(count))
+	}
+}
@@ -0,0 +1,262 @@
+package suspension
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+const (
+	SuspensionOperation   = "suspension"
+	UnsuspensionOperation = "unsuspension"
+
+	// recoveryPageSize is the number of instances read from the storage at once
+	recoveryPageSize = 100
+)
+
+type RecoveryConfig struct {
+	Enabled  bool          `envconfig:"default=false"`
+	Interval time.Duration `envconfig:"default=10m"`
+	// Backoff is the time to wait after a failed operation before it is retriggered, it is doubled with every failed attempt
+	Backoff time.Duration `envconfig:"default=10m"`
+	// MaxAttempts is the number of retriggers after which the instance is reported as stuck
+	MaxAttempts int `envconfig:"default=5"`
+}
+
+// Recovery retriggers failed suspensions and unsuspensions of trial instances, which are otherwise retriggered only
+// when ERS sends the context update again.
+type Recovery struct {
+	cfg        RecoveryConfig
+	instances  storage.Instances
+	operations storage.Operations
+	handler    *ContextUpdateHandler
+	metrics    *RecoveryMetrics
+	now        func() time.Time
+	log        *slog.Logger
+}
+
+type RecoveryResult struct {
+	Checked     int
+	Retriggered int
+	Stuck       int
+	Errors      int
+}
+
+// recoveryAction is the operation which brings the instance to the state requested by ERS
+type recoveryAction struct {
+	// target is the state requested by ERS, suspension or unsuspension
+	target string
+	// attempts is the number of failed operations since the instance reached the target state
+	attempts int
+	// operation is the last operation of the instance
+	operation internal.Operation
+	suspend   bool
+}
+
+func NewRecovery(cfg RecoveryConfig, db storage.BrokerStorage, handler *ContextUpdateHandler, metrics *RecoveryMetrics, log *slog.Logger) *Recovery {
+	return &Recovery{
+		cfg:        cfg,
+		instances:  db.Instances(),
+		operations: db.Operations(),
+		handler:    handler,
+		metrics:    metrics,
+		now:        time.Now,
+		log:        log,
+	}
+}
+
+// Start runs the recovery periodically until the context is done.
+func (r *Recovery) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.run()
+			}
+		}
+	}()
+}
+
+func (r *Recovery) run() {
+	result, err := r.RecoverAll()
+	if err != nil {
+		r.log.Error(fmt.Sprintf("suspension recovery failed: %s", err))
+		return
+	}
+	r.log.Info(fmt.Sprintf("suspension recovery finished: %d instances checked, %d retriggered, %d stuck, %d errors",
+		result.Checked, result.Retriggered, result.Stuck, result.Errors))
+}
+
+// RecoverAll retriggers the failed suspensions and unsuspensions of all trial instances. Only the instances which are not deleted
+// and whose last operation is a failed one or a succeeded deprovisioning are read from the storage, page by page.
+func (r *Recovery) RecoverAll() (RecoveryResult, error) {
+	filter := dbmodel.InstanceFilter{
+		PlanIDs:           []string{broker.TrialPlanID},
+		States:            []dbmodel.InstanceState{dbmodel.InstanceFailed, dbmodel.InstanceDeprovisioned},
+		DeletionAttempted: ptr.Bool(false),
+		PageSize:          recoveryPageSize,
+		After:             &pagination.Cursor{},
+	}
+
+	result := RecoveryResult{}
+	stuck := map[string]int{}
+	for {
+		instances, _, _, err := r.instances.List(filter)
+		if err != nil {
+			return RecoveryResult{}, fmt.Errorf("while listing trial instances: %w", err)
+		}
+		for _, instance := range instances {
+			result.Checked++
+			retriggered, stuckOperation, err := r.recover(&instance)
+			switch {
+			case err != nil:
+				r.log.Error(fmt.Sprintf("while recovering suspension of instance %s: %s", instance.InstanceID, err))
+				r.metrics.errors.Inc()
+				result.Errors++
+			case stuckOperation != "":
+				stuck[stuckOperation]++
+				result.Stuck++
+			case retriggered:
+				result.Retriggered++
+			}
+		}
+		if len(instances) < recoveryPageSize {
+			break
+		}
+		last := instances[len(instances)-1]
+		filter.After = &pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.InstanceID}
+	}
+
+	r.metrics.setStuck(stuck)
+	return result, nil
+}
+
+// recover retriggers the operation for the instance if needed, it returns the target state if the instance is stuck
+func (r *Recovery) recover(instance *internal.Instance) (bool, string, error) {
+	log := r.log.With("instanceID", instance.InstanceID)
+	action, found, err := r.nextAction(instance)
+	if err != nil || !found {
+		return false, "", err
+	}
+
+	if action.attempts > r.cfg.MaxAttempts {
+		log.Warn(fmt.Sprintf("%s failed %d times, the instance is not recovered automatically", action.target, action.attempts))
+		// the event is created once, in the first run after the last attempt failed
+		if r.now().Sub(action.operation.UpdatedAt) < r.cfg.Interval {
+			events.Errorf(instance.InstanceID, action.operation.ID, fmt.Errorf("%s failed %d times", action.target, action.attempts),
+				"Suspension recovery gave up, the instance must be recovered manually")
+		}
+		return false, action.target, nil
+	}
+	if wait := r.backoff(action.attempts); r.now().Sub(action.operation.UpdatedAt) < wait {
+		log.Debug(fmt.Sprintf("waiting %s after the operation %s before retriggering", wait, action.operation.ID))
+		return false, "", nil
+	}
+
+	// ERS may have started an operation since the operations were listed, it must not run concurrently with the retriggered one
+	lastOperation, err := r.operations.GetLastOperationWithAllStates(instance.InstanceID)
+	if err != nil {
+		return false, "", fmt.Errorf("while getting the last operation: %w", err)
+	}
+	if lastOperation.ID != action.operation.ID || lastOperation.State == domain.InProgress || lastOperation.State == internal.OperationStatePending {
+		log.Info(fmt.Sprintf("operation %s (%s) was started in the meantime, %s is not retriggered", lastOperation.ID, lastOperation.State, action.target))
+		return false, "", nil
+	}
+
+	operation := UnsuspensionOperation
+	if action.suspend {
+		operation = SuspensionOperation
+		err = r.handler.suspend(instance, log)
+	} else {
+		err = r.handler.unsuspend(instance, log)
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("while retriggering %s: %w", operation, err)
+	}
+
+	r.metrics.retriggered.WithLabelValues(operation).Inc()
+	log.Info(fmt.Sprintf("%s triggered to recover %s, attempt %d of %d", operation, action.target, action.attempts+1, r.cfg.MaxAttempts+1))
+	events.Infof(instance.InstanceID, action.operation.ID, "Suspension recovery triggered %s to recover %s, attempt %d of %d", operation, action.target, action.attempts+1, r.cfg.MaxAttempts+1)
+	return true, "", nil
+}
+
+// nextAction compares the state of the instance requested by ERS with its last operation:
+//   - a failed suspension or unsuspension is followed by the suspension, which removes the partially created
+//     or deleted runtime, also if the instance is active in ERS,
+//   - a succeeded suspension of the instance active in ERS is followed by the unsuspension.
+func (r *Recovery) nextAction(instance *internal.Instance) (recoveryAction, bool, error) {
+	operations, err := r.operations.ListOperationsByInstanceID(instance.InstanceID)
+	if err != nil {
+		return recoveryAction{}, false, fmt.Errorf("while listing operations: %w", err)
+	}
+	if len(operations) == 0 {
+		return recoveryAction{}, false, nil
+	}
+
+	// the operations are sorted from the newest one
+	last := operations[0]
+	lastKind := suspensionKind(operations, 0)
+	if lastKind == "" {
+		return recoveryAction{}, false, nil
+	}
+
+	action := recoveryAction{target: SuspensionOperation, operation: last}
+	if active := instance.Parameters.ErsContext.Active == nil || *instance.Parameters.ErsContext.Active; active && !instance.IsExpired() {
+		action.target = UnsuspensionOperation
+	}
+	for i, operation := range operations {
+		kind := suspensionKind(operations, i)
+		if kind == "" || (kind == action.target && operation.State == domain.Succeeded) {
+			break
+		}
+		if operation.State == domain.Failed {
+			action.attempts++
+		}
+	}
+
+	switch {
+	case last.State == domain.Failed:
+		action.suspend = true
+	case last.State == domain.Succeeded && lastKind == SuspensionOperation && action.target == UnsuspensionOperation:
+		action.suspend = false
+	default:
+		return recoveryAction{}, false, nil
+	}
+	return action, true, nil
+}
+
+func (r *Recovery) backoff(attempts int) time.Duration {
+	wait := r.cfg.Backoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+	}
+	return wait
+}
+
+// suspensionKind returns the type of the operation with the given index, the operations are sorted from the newest one.
+// The unsuspension is the provisioning which is not the first operation of the instance.
+func suspensionKind(operations []internal.Operation, index int) string {
+	operation := operations[index]
+	switch {
+	case operation.Type == internal.OperationTypeDeprovision && operation.Temporary:
+		return SuspensionOperation
+	case operation.Type == internal.OperationTypeProvision && index < len(operations)-1:
+		return UnsuspensionOperation
+	}
+	return ""
+}
@@ -0,0 +1,234 @@
+package suspension
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixOperationState struct {
+	operationType internal.OperationType
+	temporary     bool
+	state         domain.LastOperationState
+}
+
+var (
+	provisioned         = fixOperationState{operationType: internal.OperationTypeProvision, state: domain.Succeeded}
+	suspended           = fixOperationState{operationType: internal.OperationTypeDeprovision, temporary: true, state: domain.Succeeded}
+	suspensionFailed    = fixOperationState{operationType: internal.OperationTypeDeprovision, temporary: true, state: domain.Failed}
+	unsuspended         = fixOperationState{operationType: internal.OperationTypeProvision, state: domain.Succeeded}
+	unsuspensionFailed  = fixOperationState{operationType: internal.OperationTypeProvision, state: domain.Failed}
+	provisioningFailed  = fixOperationState{operationType: internal.OperationTypeProvision, state: domain.Failed}
+	suspensionStarted   = fixOperationState{operationType: internal.OperationTypeDeprovision, temporary: true, state: domain.InProgress}
+	recoveryTestNow     = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	recoveryTestBackoff = 10 * time.Minute
+)
+
+func TestRecovery(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		ersActive  *bool
+		expired    bool
+		operations []fixOperationState
+		// lastOperationAge is the time since the last operation was updated
+		lastOperationAge time.Duration
+		// notListed is set if the last operation excludes the instance from the recovery
+		notListed bool
+
+		expectedSuspension   bool
+		expectedUnsuspension bool
+		expectedStuck        string
+	}{
+		"should retrigger failed suspension": {
+			ersActive:          ptr.Bool(false),
+			operations:         []fixOperationState{provisioned, suspensionFailed},
+			lastOperationAge:   recoveryTestBackoff,
+			expectedSuspension: true,
+		},
+		"should wait for the backoff before retriggering failed suspension": {
+			ersActive:        ptr.Bool(false),
+			operations:       []fixOperationState{provisioned, suspensionFailed},
+			lastOperationAge: recoveryTestBackoff - time.Minute,
+		},
+		"should double the backoff with every failed attempt": {
+			ersActive:        ptr.Bool(false),
+			operations:       []fixOperationState{provisioned, suspensionFailed, suspensionFailed},
+			lastOperationAge: 2*recoveryTestBackoff - time.Minute,
+		},
+		"should retrigger failed suspension of active instance to clean up the runtime": {
+			ersActive:          ptr.Bool(true),
+			operations:         []fixOperationState{provisioned, suspensionFailed},
+			lastOperationAge:   recoveryTestBackoff,
+			expectedSuspension: true,
+		},
+		"should suspend the instance before retriggering failed unsuspension": {
+			ersActive:          ptr.Bool(true),
+			operations:         []fixOperationState{provisioned, suspended, unsuspensionFailed},
+			lastOperationAge:   recoveryTestBackoff,
+			expectedSuspension: true,
+		},
+		"should unsuspend suspended instance which is active": {
+			ersActive:            ptr.Bool(true),
+			operations:           []fixOperationState{provisioned, suspended, unsuspensionFailed, suspended},
+			lastOperationAge:     2 * recoveryTestBackoff,
+			expectedUnsuspension: true,
+		},
+		"should not unsuspend expired instance": {
+			ersActive:        ptr.Bool(true),
+			expired:          true,
+			operations:       []fixOperationState{provisioned, suspended},
+			lastOperationAge: time.Hour,
+		},
+		"should not retrigger suspended instance which is not active": {
+			ersActive:        ptr.Bool(false),
+			operations:       []fixOperationState{provisioned, suspended},
+			lastOperationAge: time.Hour,
+		},
+		"should not retrigger unsuspended instance": {
+			ersActive:        ptr.Bool(true),
+			operations:       []fixOperationState{provisioned, suspended, unsuspended},
+			lastOperationAge: time.Hour,
+			notListed:        true,
+		},
+		"should not retrigger suspension in progress": {
+			ersActive:        ptr.Bool(false),
+			operations:       []fixOperationState{provisioned, suspensionStarted},
+			lastOperationAge: time.Hour,
+			notListed:        true,
+		},
+		"should not retrigger failed provisioning": {
+			ersActive:        ptr.Bool(true),
+			operations:       []fixOperationState{provisioningFailed},
+			lastOperationAge: time.Hour,
+		},
+		"should report instance stuck after the limit of attempts": {
+			ersActive:        ptr.Bool(true),
+			operations:       []fixOperationState{provisioned, suspended, unsuspensionFailed, suspended, unsuspensionFailed, suspensionFailed},
+			lastOperationAge: time.Hour,
+			expectedStuck:    UnsuspensionOperation,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			provisioning := NewDummyQueue()
+			deprovisioning := NewDummyQueue()
+			st := storage.NewMemoryStorage()
+			metrics := NewRecoveryMetrics(prometheus.NewRegistry())
+
+			instance := fixInstance(internal.ERSContext{Active: tc.ersActive})
+			if tc.expired {
+				instance.ExpiredAt = ptr.Time(recoveryTestNow.Add(-24 * time.Hour))
+			}
+			require.NoError(t, st.Instances().Insert(*instance))
+			for i, state := range tc.operations {
+				updatedAt := recoveryTestNow.Add(-tc.lastOperationAge - time.Duration(len(tc.operations)-1-i)*time.Hour)
+				require.NoError(t, st.Operations().InsertOperation(fixRecoveryOperation(fmt.Sprintf("op-%d", i), instance.InstanceID, state, updatedAt)))
+			}
+
+			handler := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+			recovery := NewRecovery(RecoveryConfig{Interval: 10 * time.Minute, Backoff: recoveryTestBackoff, MaxAttempts: 2}, st, handler, metrics, fixLogger())
+			recovery.now = func() time.Time { return recoveryTestNow }
+
+			// when
+			result, err := recovery.RecoverAll()
+
+			// then
+			require.NoError(t, err)
+			if tc.notListed {
+				assert.Zero(t, result.Checked)
+			} else {
+				assert.Equal(t, 1, result.Checked)
+			}
+			assert.Equal(t, tc.expectedSuspension, len(deprovisioning.IDs) == 1)
+			assert.Equal(t, tc.expectedUnsuspension, len(provisioning.IDs) == 1)
+			if tc.expectedStuck != "" {
+				assert.Equal(t, 1, result.Stuck)
+				assert.Equal(t, float64(1), testutil.ToFloat64(metrics.stuck.WithLabelValues(tc.expectedStuck)))
+			} else {
+				assert.Zero(t, result.Stuck)
+			}
+			if tc.expectedSuspension || tc.expectedUnsuspension {
+				assert.Equal(t, 1, result.Retriggered)
+			}
+		})
+	}
+}
+
+func TestRecovery_SkipsNonTrialInstances(t *testing.T) {
+	// given
+	provisioning := NewDummyQueue()
+	deprovisioning := NewDummyQueue()
+	st := storage.NewMemoryStorage()
+
+	instance := fixture.FixInstance("aws-instance-id")
+	instance.Parameters.ErsContext.Active = ptr.Bool(false)
+	require.NoError(t, st.Instances().Insert(instance))
+	require.NoError(t, st.Operations().InsertOperation(fixRecoveryOperation("op-1", instance.InstanceID, suspensionFailed, recoveryTestNow.Add(-time.Hour))))
+
+	handler := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+	recovery := NewRecovery(RecoveryConfig{Backoff: recoveryTestBackoff, MaxAttempts: 2}, st, handler, NewRecoveryMetrics(prometheus.NewRegistry()), fixLogger())
+	recovery.now = func() time.Time { return recoveryTestNow }
+
+	// when
+	result, err := recovery.RecoverAll()
+
+	// then
+	require.NoError(t, err)
+	assert.Zero(t, result.Checked)
+	assertQueue(t, deprovisioning)
+	assertQueue(t, provisioning)
+}
+
+func TestRecovery_ListsInstancesPageByPage(t *testing.T) {
+	// given
+	provisioning := NewDummyQueue()
+	deprovisioning := NewDummyQueue()
+	st := storage.NewMemoryStorage()
+
+	count := recoveryPageSize + 1
+	for i := 0; i < count; i++ {
+		instance := fixInstance(internal.ERSContext{Active: ptr.Bool(false)})
+		instance.InstanceID = fmt.Sprintf("instance-%03d", i)
+		instance.CreatedAt = recoveryTestNow.Add(-time.Duration(count-i) * time.Minute)
+		require.NoError(t, st.Instances().Insert(*instance))
+		require.NoError(t, st.Operations().InsertOperation(fixRecoveryOperation(fmt.Sprintf("op-%03d", i), instance.InstanceID, suspensionFailed, recoveryTestNow.Add(-time.Hour))))
+	}
+	deleted := fixInstance(internal.ERSContext{Active: ptr.Bool(false)})
+	deleted.InstanceID = "deleted-instance"
+	deleted.DeletedAt = recoveryTestNow.Add(-time.Hour)
+	require.NoError(t, st.Instances().Insert(*deleted))
+	require.NoError(t, st.Operations().InsertOperation(fixRecoveryOperation("op-deleted", deleted.InstanceID, suspensionFailed, recoveryTestNow.Add(-time.Hour))))
+
+	handler := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+	recovery := NewRecovery(RecoveryConfig{Interval: 10 * time.Minute, Backoff: recoveryTestBackoff, MaxAttempts: 2}, st, handler, NewRecoveryMetrics(prometheus.NewRegistry()), fixLogger())
+	recovery.now = func() time.Time { return recoveryTestNow }
+
+	// when
+	result, err := recovery.RecoverAll()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, count, result.Checked)
+	assert.Equal(t, count, result.Retriggered)
+	assert.Len(t, deprovisioning.IDs, count)
+	assertQueue(t, provisioning)
+}
+
+func fixRecoveryOperation(id, instanceID string, state fixOperationState, updatedAt time.Time) internal.Operation {
+	operation := fixture.FixOperation(id, instanceID, state.operationType)
+	operation.Temporary = state.temporary
+	operation.State = state.state
+	operation.CreatedAt = updatedAt.Add(-time.Minute)
+	operation.UpdatedAt = updatedAt
+	return operation
+}
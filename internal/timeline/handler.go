@@ -0,0 +1,83 @@
+package timeline
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+const (
+	FormatParam = "format"
+	FormatText  = "text"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler interface {
+	AttachRoutes(r router)
+}
+
+type handler struct {
+	service *Service
+	maxPage int
+	log     *slog.Logger
+}
+
+// NewHandler creates the handler of the endpoint which returns the timeline of the instance.
+func NewHandler(service *Service, maxPage int, log *slog.Logger) Handler {
+	return &handler{
+		service: service,
+		maxPage: maxPage,
+		log:     log.With("service", "TimelineEndpoint"),
+	}
+}
+
+func (h *handler) AttachRoutes(r router) {
+	r.HandleFunc("GET /instances/{instance_id}/timeline", h.getTimeline)
+}
+
+func (h *handler) getTimeline(w http.ResponseWriter, req *http.Request) {
+	instanceID := req.PathValue("instance_id")
+
+	pageSize, page, err := pagination.ExtractPaginationConfigFromRequest(req, h.maxPage)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
+
+	entries, err := h.service.Timeline(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		h.log.Error(fmt.Sprintf("while building timeline of instance %s: %s", instanceID, err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	from := min((page-1)*pageSize, len(entries))
+	to := min(from+pageSize, len(entries))
+	data := entries[from:to]
+
+	if req.URL.Query().Get(FormatParam) == FormatText {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := WriteText(w, data); err != nil {
+			h.log.Error(fmt.Sprintf("while writing timeline of instance %s: %s", instanceID, err))
+		}
+		return
+	}
+
+	httputil.WriteResponse(w, http.StatusOK, Page{
+		Data:       append([]Entry{}, data...),
+		Count:      len(data),
+		TotalCount: len(entries),
+	})
+}
@@ -0,0 +1,134 @@
+package timeline
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	created := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	stageFinished := created.Add(5 * time.Minute)
+
+	require.NoError(t, db.Instances().Insert(fixture.FixInstance("inst-1")))
+	provisioning := fixture.FixProvisioningOperation("op-1", "inst-1")
+	provisioning.CreatedAt = created
+	provisioning.UpdatedAt = created.Add(10 * time.Minute)
+	provisioning.State = domain.Succeeded
+	provisioning.Description = "Processing finished"
+	provisioning.StageTimes = []internal.StageTime{
+		{Name: "start", StartedAt: created, FinishedAt: &stageFinished, Steps: map[string]time.Duration{"Init": time.Minute}},
+	}
+	require.NoError(t, db.Operations().InsertOperation(provisioning))
+	require.NoError(t, db.Actions().InsertAction(pkg.PlanUpdateActionType, "inst-1", "plan updated", "aws", "build-runtime-aws"))
+	require.NoError(t, db.Bindings().Insert(&internal.Binding{
+		ID:         "binding-1",
+		InstanceID: "inst-1",
+		CreatedAt:  created.Add(20 * time.Minute),
+		ExpiresAt:  created.Add(30 * time.Minute),
+		CreatedBy:  "john.smith@email.com",
+	}))
+
+	router := httputil.NewRouter()
+	NewHandler(NewService(db), 100, slog.Default()).AttachRoutes(router)
+
+	t.Run("should return the entries in chronological order", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/instances/inst-1/timeline", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, w.Code)
+		var page Page
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		require.Equal(t, 7, page.TotalCount)
+		types := make([]string, 0, len(page.Data))
+		for _, entry := range page.Data {
+			types = append(types, entry.Type)
+		}
+		assert.Equal(t, []string{
+			"provision started",
+			"stage started",
+			"stage finished",
+			"provision succeeded",
+			"binding created",
+			"binding expired",
+			string(pkg.PlanUpdateActionType),
+		}, types)
+		assert.Equal(t, "5m0s", page.Data[2].Duration)
+		assert.Equal(t, map[string]string{"Init": "1m0s"}, page.Data[2].Details)
+	})
+
+	t.Run("should paginate the entries", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/instances/inst-1/timeline?page=2&page_size=3", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, w.Code)
+		var page Page
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.Equal(t, 3, page.Count)
+		assert.Equal(t, 7, page.TotalCount)
+		assert.Equal(t, "provision succeeded", page.Data[0].Type)
+	})
+
+	t.Run("should render the entries as plain text", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/instances/inst-1/timeline?format=text&page_size=1", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "2025-01-01T10:00:00Z operation provision started [op-1]"))
+	})
+
+	t.Run("should return the archived instance data", func(t *testing.T) {
+		// given
+		require.NoError(t, db.InstancesArchived().Insert(internal.InstanceArchived{
+			InstanceID:                   "inst-2",
+			ProvisioningStartedAt:        created,
+			ProvisioningFinishedAt:       created.Add(time.Hour),
+			ProvisioningState:            domain.Succeeded,
+			FirstDeprovisioningStartedAt: created.Add(2 * time.Hour),
+			LastDeprovisioningFinishedAt: created.Add(3 * time.Hour),
+		}))
+
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/instances/inst-2/timeline", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, w.Code)
+		var page Page
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		require.Equal(t, 4, page.TotalCount)
+		assert.Equal(t, SourceArchive, page.Data[0].Source)
+		assert.Equal(t, "succeeded", page.Data[1].Message)
+	})
+
+	t.Run("should return not found for unknown instance", func(t *testing.T) {
+		// when
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/instances/unknown/timeline", nil))
+
+		// then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,265 @@
+package timeline
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+type Source string
+
+const (
+	SourceOperation Source = "operation"
+	SourceStage     Source = "stage"
+	SourceEvent     Source = "event"
+	SourceAction    Source = "action"
+	SourceBinding   Source = "binding"
+	SourceArchive   Source = "archive"
+)
+
+type Entry struct {
+	Time        time.Time         `json:"time"`
+	Source      Source            `json:"source"`
+	Type        string            `json:"type"`
+	OperationID string            `json:"operationID,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Duration    string            `json:"duration,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+type Page struct {
+	Data       []Entry `json:"data"`
+	Count      int     `json:"count"`
+	TotalCount int     `json:"totalCount"`
+}
+
+// Service builds the timeline of the instance from operations, events, actions, bindings and the archived instance data.
+type Service struct {
+	instances         storage.Instances
+	instancesArchived storage.InstancesArchived
+	operations        storage.Operations
+	events            storage.Events
+	actions           storage.Actions
+	bindings          storage.Bindings
+	now               func() time.Time
+}
+
+func NewService(db storage.BrokerStorage) *Service {
+	return &Service{
+		instances:         db.Instances(),
+		instancesArchived: db.InstancesArchived(),
+		operations:        db.Operations(),
+		events:            db.Events(),
+		actions:           db.Actions(),
+		bindings:          db.Bindings(),
+		now:               time.Now,
+	}
+}
+
+// Timeline returns the chronologically ordered entries of the instance. It returns the not found error
+// if neither the instance, nor the archived instance, nor any operation of the instance exists.
+func (s *Service) Timeline(instanceID string) ([]Entry, error) {
+	var entries []Entry
+
+	instanceFound := true
+	if _, err := s.instances.GetByID(instanceID); err != nil {
+		if !dberr.IsNotFound(err) {
+			return nil, fmt.Errorf("while getting instance %s: %w", instanceID, err)
+		}
+		instanceFound = false
+	}
+
+	operations, err := s.operations.ListOperationsByInstanceID(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("while listing operations of instance %s: %w", instanceID, err)
+	}
+	for _, operation := range operations {
+		entries = append(entries, operationEntries(operation)...)
+	}
+
+	if !instanceFound {
+		archived, err := s.instancesArchived.GetByInstanceID(instanceID)
+		switch {
+		case err == nil:
+			entries = append(entries, archiveEntries(archived)...)
+		case !dberr.IsNotFound(err):
+			return nil, fmt.Errorf("while getting archived instance %s: %w", instanceID, err)
+		case len(operations) == 0:
+			return nil, dberr.NotFound("instance %s not found", instanceID)
+		}
+	}
+
+	// events are not stored if they are disabled
+	if s.events != nil {
+		instanceEvents, err := s.events.ListEvents(events.EventFilter{InstanceIDs: []string{instanceID}})
+		if err != nil {
+			return nil, fmt.Errorf("while listing events of instance %s: %w", instanceID, err)
+		}
+		for _, event := range instanceEvents {
+			entry := Entry{Time: event.CreatedAt, Source: SourceEvent, Type: string(event.Level), Message: event.Message}
+			if event.OperationID != nil {
+				entry.OperationID = *event.OperationID
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	actions, err := s.actions.ListActionsByInstanceID(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("while listing actions of instance %s: %w", instanceID, err)
+	}
+	for _, action := range actions {
+		entries = append(entries, Entry{
+			Time:    action.CreatedAt,
+			Source:  SourceAction,
+			Type:    string(action.Type),
+			Message: action.Message,
+			Details: nonEmpty(map[string]string{"oldValue": action.OldValue, "newValue": action.NewValue}),
+		})
+	}
+
+	bindings, err := s.bindings.ListByInstanceID(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("while listing bindings of instance %s: %w", instanceID, err)
+	}
+	for _, binding := range bindings {
+		entries = append(entries, s.bindingEntries(binding)...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, nil
+}
+
+func operationEntries(operation internal.Operation) []Entry {
+	entries := []Entry{{
+		Time:        operation.CreatedAt,
+		Source:      SourceOperation,
+		Type:        fmt.Sprintf("%s started", operation.Type),
+		OperationID: operation.ID,
+		Details:     nonEmpty(map[string]string{"finishedStages": strings.Join(operation.FinishedStages, ",")}),
+	}}
+
+	for _, stage := range operation.StageTimes {
+		entries = append(entries, Entry{
+			Time:        stage.StartedAt,
+			Source:      SourceStage,
+			Type:        "stage started",
+			OperationID: operation.ID,
+			Message:     stage.Name,
+		})
+		if stage.FinishedAt == nil {
+			continue
+		}
+		steps := make(map[string]string, len(stage.Steps))
+		for step, duration := range stage.Steps {
+			steps[step] = duration.String()
+		}
+		entries = append(entries, Entry{
+			Time:        *stage.FinishedAt,
+			Source:      SourceStage,
+			Type:        "stage finished",
+			OperationID: operation.ID,
+			Message:     stage.Name,
+			Duration:    stage.FinishedAt.Sub(stage.StartedAt).String(),
+			Details:     nonEmpty(steps),
+		})
+	}
+
+	switch operation.State {
+	case domain.Succeeded, domain.Failed:
+		entries = append(entries, Entry{
+			Time:        operation.UpdatedAt,
+			Source:      SourceOperation,
+			Type:        fmt.Sprintf("%s %s", operation.Type, operation.State),
+			OperationID: operation.ID,
+			Message:     operation.Description,
+			Duration:    operation.UpdatedAt.Sub(operation.CreatedAt).String(),
+			Details:     nonEmpty(map[string]string{"error": operation.LastError.Error()}),
+		})
+	}
+	return entries
+}
+
+func archiveEntries(archived internal.InstanceArchived) []Entry {
+	var entries []Entry
+	add := func(t time.Time, entryType, message string) {
+		if t.IsZero() {
+			return
+		}
+		entries = append(entries, Entry{Time: t, Source: SourceArchive, Type: entryType, Message: message})
+	}
+	add(archived.ProvisioningStartedAt, "provisioning started", "")
+	add(archived.ProvisioningFinishedAt, "provisioning finished", string(archived.ProvisioningState))
+	add(archived.FirstDeprovisioningStartedAt, "first deprovisioning started", "")
+	add(archived.FirstDeprovisioningFinishedAt, "first deprovisioning finished", "")
+	add(archived.LastDeprovisioningFinishedAt, "last deprovisioning finished", "")
+	return entries
+}
+
+// bindingEntries returns the creation of the binding and its expiration, if the binding is already expired.
+// Deleted bindings are not stored, their deletion is a part of the timeline as an event.
+func (s *Service) bindingEntries(binding internal.Binding) []Entry {
+	entries := []Entry{{
+		Time:    binding.CreatedAt,
+		Source:  SourceBinding,
+		Type:    "binding created",
+		Message: binding.ID,
+		Details: nonEmpty(map[string]string{"createdBy": binding.CreatedBy, "expiresAt": binding.ExpiresAt.Format(time.RFC3339)}),
+	}}
+	if !binding.ExpiresAt.IsZero() && binding.ExpiresAt.Before(s.now()) {
+		entries = append(entries, Entry{Time: binding.ExpiresAt, Source: SourceBinding, Type: "binding expired", Message: binding.ID})
+	}
+	return entries
+}
+
+// WriteText writes the entries in the plain text format, one entry per line.
+func WriteText(w io.Writer, entries []Entry) error {
+	for _, entry := range entries {
+		line := fmt.Sprintf("%s %-9s %s", entry.Time.UTC().Format(time.RFC3339), entry.Source, entry.Type)
+		if entry.OperationID != "" {
+			line += fmt.Sprintf(" [%s]", entry.OperationID)
+		}
+		if entry.Message != "" {
+			line += ": " + entry.Message
+		}
+		if entry.Duration != "" {
+			line += fmt.Sprintf(" (%s)", entry.Duration)
+		}
+		keys := make([]string, 0, len(entry.Details))
+		for key := range entry.Details {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			line += fmt.Sprintf(" %s=%s", key, entry.Details[key])
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nonEmpty(details map[string]string) map[string]string {
+	for key, value := range details {
+		if value == "" {
+			delete(details, key)
+		}
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
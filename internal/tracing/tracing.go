@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type Config struct {
+	Enabled bool `envconfig:"default=false"`
+	// Endpoint is the URL of the OTLP HTTP traces endpoint, for example of a local OpenTelemetry Collector
+	Endpoint    string  `envconfig:"default=http://localhost:4318/v1/traces"`
+	ServiceName string  `envconfig:"default=kyma-environment-broker"`
+	SampleRatio float64 `envconfig:"default=1"`
+}
+
+func (c Config) Validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1, got %v", c.SampleRatio)
+	}
+	return nil
+}
+
+// Setup registers the global tracer provider, which exports the spans to the OTLP endpoint, and the W3C trace context propagator.
+// The returned function flushes the remaining spans and must be called before the application exits.
+// If the tracing is disabled, the no-op global tracer provider is kept and the trace context is not propagated.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("while creating OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("while creating tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// the spans of the operation steps follow the sampling decision of the request which created the operation
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+// Inject returns the trace context of the span from the context, which can be persisted, for example, in the operation.
+// Returns nil if there is no trace context to propagate.
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns the context with the remote span from the persisted trace context, so the new spans continue the original trace
+func Extract(ctx context.Context, traceContext map[string]string) context.Context {
+	if len(traceContext) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(traceContext))
+}
+
+// NewHandler wraps the handler with a span for every request, named after the method and the matched route pattern
+func NewHandler(handler http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(handler, operation, otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+		switch {
+		case r.Pattern == "":
+			return r.Method
+		// the pattern registered with a method, for example "GET /runtimes"
+		case strings.HasPrefix(r.Pattern, r.Method+" "):
+			return r.Pattern
+		default:
+			return r.Method + " " + r.Pattern
+		}
+	}))
+}
+
+// WrapTransport creates a span for every outbound request and propagates the trace context in the request headers.
+// It can be used as the rest.Config wrapper of the Kubernetes clients.
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(rt)
+}
+
+// WrapClient creates a span for every request sent with the client
+func WrapClient(client *http.Client) *http.Client {
+	client.Transport = WrapTransport(client.Transport)
+	return client
+}
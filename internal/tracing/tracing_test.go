@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContext(t *testing.T) {
+	recorder := fixTracerProvider(t)
+
+	t.Run("should continue the trace from the persisted trace context", func(t *testing.T) {
+		// given
+		ctx, span := otel.Tracer("test").Start(context.Background(), "request")
+		traceContext := Inject(ctx)
+		span.End()
+
+		// when
+		_, step := otel.Tracer("test").Start(Extract(context.Background(), traceContext), "step")
+		step.End()
+
+		// then
+		require.Contains(t, traceContext, "traceparent")
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+		assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+	})
+
+	t.Run("should not inject the trace context without a span", func(t *testing.T) {
+		// when
+		traceContext := Inject(context.Background())
+
+		// then
+		assert.Nil(t, traceContext)
+		assert.Equal(t, context.Background(), Extract(context.Background(), traceContext))
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	recorder := fixTracerProvider(t)
+
+	t.Run("should name the server span after the route and propagate the trace context to the outbound request", func(t *testing.T) {
+		// given
+		var traceparent string
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceparent = r.Header.Get("traceparent")
+		}))
+		defer upstream.Close()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /runtimes/{id}", func(w http.ResponseWriter, r *http.Request) {
+			req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, upstream.URL, nil)
+			require.NoError(t, err)
+			resp, err := WrapClient(&http.Client{}).Do(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+		})
+
+		// when
+		rr := httptest.NewRecorder()
+		NewHandler(mux, "test").ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes/r1", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+		assert.Equal(t, "GET /runtimes/{id}", spans[1].Name())
+		assert.Equal(t, spans[1].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+		assert.Contains(t, traceparent, spans[0].SpanContext().TraceID().String())
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{SampleRatio: 0.5}.Validate())
+	assert.EqualError(t, Config{SampleRatio: 2}.Validate(), "tracing sample ratio must be between 0 and 1, got 2")
+}
+
+func fixTracerProvider(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
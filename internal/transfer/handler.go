@@ -0,0 +1,68 @@
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler interface {
+	AttachRoutes(r router)
+}
+
+type handler struct {
+	service *Service
+	log     *slog.Logger
+}
+
+func NewHandler(service *Service, log *slog.Logger) Handler {
+	return &handler{
+		service: service,
+		log:     log.With("service", "TransferEndpoint"),
+	}
+}
+
+func (h *handler) AttachRoutes(r router) {
+	r.HandleFunc("POST /transfer/service_instance/{instance_id}", h.transferInstance)
+}
+
+func (h *handler) transferInstance(w http.ResponseWriter, req *http.Request) {
+	instanceID := req.PathValue("instance_id")
+	logger := h.log.With("instanceID", instanceID)
+
+	var request Request
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		logger.Warn(fmt.Sprintf("unable to decode request body: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("%w: %s", ErrInvalidRequest, err))
+		return
+	}
+	logger.Info(fmt.Sprintf("Transfer to global account %s triggered, dry run: %t", request.TargetGlobalAccountID, request.DryRun))
+
+	report, err := h.service.Transfer(req.Context(), instanceID, request)
+	switch {
+	case err == nil:
+		httputil.WriteResponse(w, http.StatusOK, report)
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidRequest):
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+	case errors.Is(err, ErrPreflightFailed):
+		httputil.WriteResponse(w, http.StatusConflict, report)
+	default:
+		logger.Error(fmt.Sprintf("transfer failed: %s", err))
+		if report.Error != "" {
+			httputil.WriteResponse(w, http.StatusInternalServerError, report)
+			return
+		}
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
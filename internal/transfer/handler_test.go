@@ -0,0 +1,323 @@
+package transfer_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/quota"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/transfer"
+	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	requestPathFormat     = "/transfer/service_instance/%s"
+	targetGlobalAccountID = "target-ga"
+)
+
+type fakeLabeler struct {
+	updated map[string]string
+	err     error
+}
+
+func (f *fakeLabeler) UpdateLabels(id, newGlobalAccountId string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.updated[id] = newGlobalAccountId
+	return nil
+}
+
+func TestTransfer(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	rulesService, err := rules.NewRulesServiceFromSlice([]string{"azure", "trial"}, sets.New("azure", "trial"), sets.New("azure", "trial"))
+	require.NoError(t, err)
+
+	newHandler := func(db storage.BrokerStorage, brokerConfig broker.Config, quotaClient broker.QuotaClient, labeler transfer.Labeler) http.Handler {
+		router := httputil.NewRouter()
+		service := transfer.NewService(db, brokerConfig, quotaClient, whitelist.Set{}, whitelist.Set{}, rulesService, labeler, logger)
+		transfer.NewHandler(service, logger).AttachRoutes(router)
+		return router
+	}
+
+	t.Run("should receive 404 Not Found response", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		router := newHandler(db, broker.Config{}, &automock.QuotaClient{}, &fakeLabeler{updated: map[string]string{}})
+
+		// when
+		resp := callTransfer(t, router, "not-existing", transfer.Request{TargetGlobalAccountID: targetGlobalAccountID})
+
+		// then
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("should receive 400 Bad Request response when target global account is the current one", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		fixTransferableInstance(t, db, "inst-1", broker.AzurePlanID)
+		router := newHandler(db, broker.Config{}, &automock.QuotaClient{}, &fakeLabeler{updated: map[string]string{}})
+
+		// when
+		resp := callTransfer(t, router, "inst-1", transfer.Request{TargetGlobalAccountID: fixture.GlobalAccountId})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("should only report changes in the dry-run mode", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		fixTransferableInstance(t, db, "inst-1", broker.AzurePlanID)
+		labeler := &fakeLabeler{updated: map[string]string{}}
+		router := newHandler(db, broker.Config{}, &automock.QuotaClient{}, labeler)
+
+		// when
+		resp := callTransfer(t, router, "inst-1", transfer.Request{TargetGlobalAccountID: targetGlobalAccountID, DryRun: true})
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		report := decodeReport(t, resp)
+		assert.True(t, report.DryRun)
+		assert.Len(t, report.Checks, 4)
+		assert.Empty(t, report.Updated)
+
+		instance, err := db.Instances().GetByID("inst-1")
+		require.NoError(t, err)
+		assert.Equal(t, fixture.GlobalAccountId, instance.GlobalAccountID)
+		assert.Empty(t, labeler.updated)
+	})
+
+	t.Run("should transfer the instance", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		fixTransferableInstance(t, db, "inst-1", broker.AzurePlanID)
+		labeler := &fakeLabeler{updated: map[string]string{}}
+		quotaClient := &automock.QuotaClient{}
+		quotaClient.On("GetQuota", mock.Anything, "SA-inst-1", broker.AzurePlanName).Return(1, nil)
+		router := newHandler(db, broker.Config{CheckQuotaLimit: true}, quotaClient, labeler)
+
+		// when
+		resp := callTransfer(t, router, "inst-1", transfer.Request{TargetGlobalAccountID: targetGlobalAccountID})
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		report := decodeReport(t, resp)
+		assert.Equal(t, fixture.GlobalAccountId, report.SourceGlobalAccountID)
+		assert.Len(t, report.Updated, 2)
+		assert.Equal(t, fixture.GlobalAccountId, report.Subscription.TenantName)
+
+		instance, err := db.Instances().GetByID("inst-1")
+		require.NoError(t, err)
+		assert.Equal(t, targetGlobalAccountID, instance.GlobalAccountID)
+		assert.Equal(t, fixture.GlobalAccountId, instance.SubscriptionGlobalAccountID)
+		assert.Equal(t, targetGlobalAccountID, instance.Parameters.ErsContext.GlobalAccountID)
+		assert.Equal(t, map[string]string{"runtime-inst-1": targetGlobalAccountID}, labeler.updated)
+
+		operation, err := db.Operations().GetOperationByID(report.OperationID)
+		require.NoError(t, err)
+		assert.Equal(t, internal.OperationTypeTransfer, operation.Type)
+		assert.Equal(t, domain.Succeeded, operation.State)
+		assert.Equal(t, targetGlobalAccountID, operation.ProvisioningParameters.ErsContext.GlobalAccountID)
+
+		lastOperation, err := db.Operations().GetLastOperation("inst-1")
+		require.NoError(t, err)
+		assert.Equal(t, internal.OperationTypeProvision, lastOperation.Type, "the transfer must not be the last operation")
+
+		actions, err := db.Actions().ListActionsByInstanceID("inst-1")
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, pkg.InstanceTransferActionType, actions[0].Type)
+		assert.Equal(t, fixture.GlobalAccountId, actions[0].OldValue)
+		assert.Equal(t, targetGlobalAccountID, actions[0].NewValue)
+	})
+
+	t.Run("should not move the instance when labels cannot be updated", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		fixTransferableInstance(t, db, "inst-1", broker.AzurePlanID)
+		router := newHandler(db, broker.Config{}, &automock.QuotaClient{}, &fakeLabeler{err: fmt.Errorf("kyma not found")})
+
+		// when
+		resp := callTransfer(t, router, "inst-1", transfer.Request{TargetGlobalAccountID: targetGlobalAccountID})
+
+		// then
+		require.Equal(t, http.StatusInternalServerError, resp.Code)
+		report := decodeReport(t, resp)
+		assert.Contains(t, report.Error, "kyma not found")
+		assert.Empty(t, report.Updated)
+
+		instance, err := db.Instances().GetByID("inst-1")
+		require.NoError(t, err)
+		assert.Equal(t, fixture.GlobalAccountId, instance.GlobalAccountID)
+		assert.Empty(t, instance.SubscriptionGlobalAccountID)
+		assert.Equal(t, fixture.GlobalAccountId, instance.Parameters.ErsContext.GlobalAccountID)
+
+		operation, err := db.Operations().GetOperationByID(report.OperationID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Failed, operation.State)
+		assert.Contains(t, operation.Description, "kyma not found")
+
+		actions, err := db.Actions().ListActionsByInstanceID("inst-1")
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, pkg.InstanceTransferActionType, actions[0].Type)
+		assert.Contains(t, actions[0].Message, "failed")
+		assert.Equal(t, fixture.GlobalAccountId, actions[0].OldValue)
+		assert.Equal(t, fixture.GlobalAccountId, actions[0].NewValue)
+	})
+
+	t.Run("should transfer the instance again after a failed transfer", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		fixTransferableInstance(t, db, "inst-1", broker.AzurePlanID)
+		labeler := &fakeLabeler{updated: map[string]string{}, err: fmt.Errorf("kyma not found")}
+		router := newHandler(db, broker.Config{}, &automock.QuotaClient{}, labeler)
+		resp := callTransfer(t, router, "inst-1", transfer.Request{TargetGlobalAccountID: targetGlobalAccountID})
+		require.Equal(t, http.StatusInternalServerError, resp.Code)
+		labeler.err = nil
+
+		// when
+		resp = callTransfer(t, router, "inst-1", transfer.Request{TargetGlobalAccountID: targetGlobalAccountID})
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		instance, err := db.Instances().GetByID("inst-1")
+		require.NoError(t, err)
+		assert.Equal(t, targetGlobalAccountID, instance.GlobalAccountID)
+	})
+
+	t.Run("should reject the transfer when the target global account limit is exceeded", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		fixTransferableInstance(t, db, "inst-1", broker.AzurePlanID)
+		other := fixture.FixInstance("inst-2")
+		other.GlobalAccountID = targetGlobalAccountID
+		other.SubAccountID = "SA-inst-2"
+		other.ServicePlanID = broker.AzurePlanID
+		require.NoError(t, db.Instances().Insert(other))
+		limits, err := quota.NewLimits(strings.NewReader(fmt.Sprintf("globalAccounts:\n  %s:\n    azure: 1\n", targetGlobalAccountID)))
+		require.NoError(t, err)
+		quotaClient := &automock.QuotaClient{}
+		quotaClient.On("GetQuota", mock.Anything, "SA-inst-1", broker.AzurePlanName).Return(1, nil)
+		router := newHandler(db, broker.Config{CheckQuotaLimit: true}, quota.NewEnforcer(quotaClient, db.Instances(), db.QuotaReservations(), limits), &fakeLabeler{updated: map[string]string{}})
+
+		// when
+		resp := callTransfer(t, router, "inst-1", transfer.Request{TargetGlobalAccountID: targetGlobalAccountID})
+
+		// then
+		require.Equal(t, http.StatusConflict, resp.Code)
+		report := decodeReport(t, resp)
+		for _, check := range report.Checks {
+			assert.Equal(t, check.Name != transfer.CheckQuota, check.Passed, check.Name)
+		}
+	})
+
+	t.Run("should reject the transfer when pre-flight checks fail", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			planID      string
+			lastOpState domain.LastOperationState
+			usedQuota   int
+			failedCheck string
+		}{
+			"last operation in progress": {
+				planID:      broker.AzurePlanID,
+				lastOpState: domain.InProgress,
+				usedQuota:   1,
+				failedCheck: transfer.CheckLastOperation,
+			},
+			"quota exceeded": {
+				planID:      broker.AzurePlanID,
+				lastOpState: domain.Succeeded,
+				usedQuota:   0,
+				failedCheck: transfer.CheckQuota,
+			},
+			"trial already used in the target global account": {
+				planID:      broker.TrialPlanID,
+				lastOpState: domain.Succeeded,
+				usedQuota:   1,
+				failedCheck: transfer.CheckPlanUniqueness,
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// given
+				db := storage.NewMemoryStorage()
+				fixTransferableInstance(t, db, "inst-1", tc.planID)
+				lastOp := fixture.FixOperation("update-op", "inst-1", internal.OperationTypeUpdate)
+				lastOp.State = tc.lastOpState
+				require.NoError(t, db.Operations().InsertOperation(lastOp))
+
+				targetInstance := fixture.FixInstance("inst-2")
+				targetInstance.GlobalAccountID = targetGlobalAccountID
+				require.NoError(t, db.Instances().Insert(targetInstance))
+
+				quotaClient := &automock.QuotaClient{}
+				quotaClient.On("GetQuota", mock.Anything, "SA-inst-1", broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(tc.planID))).Return(tc.usedQuota, nil)
+				labeler := &fakeLabeler{updated: map[string]string{}}
+				router := newHandler(db, broker.Config{CheckQuotaLimit: true, OnlySingleTrialPerGA: true}, quotaClient, labeler)
+
+				// when
+				resp := callTransfer(t, router, "inst-1", transfer.Request{TargetGlobalAccountID: targetGlobalAccountID})
+
+				// then
+				require.Equal(t, http.StatusConflict, resp.Code)
+				report := decodeReport(t, resp)
+				for _, check := range report.Checks {
+					assert.Equal(t, check.Name != tc.failedCheck, check.Passed, check.Name)
+				}
+
+				instance, err := db.Instances().GetByID("inst-1")
+				require.NoError(t, err)
+				assert.Equal(t, fixture.GlobalAccountId, instance.GlobalAccountID)
+				assert.Empty(t, labeler.updated)
+			})
+		}
+	})
+}
+
+func fixTransferableInstance(t *testing.T, db storage.BrokerStorage, instanceID, planID string) {
+	instance := fixture.FixInstance(instanceID)
+	instance.ServicePlanID = planID
+	instance.SubscriptionSecretName = "credentials-binding"
+	require.NoError(t, db.Instances().Insert(instance))
+
+	provisioning := fixture.FixProvisioningOperation(fmt.Sprintf("provisioning-%s", instanceID), instanceID, fixture.WithPlanID(planID))
+	provisioning.ProviderValues = &internal.ProviderValues{Region: "westeurope", ProviderType: "azure"}
+	require.NoError(t, db.Operations().InsertOperation(provisioning))
+}
+
+func callTransfer(t *testing.T, router http.Handler, instanceID string, request transfer.Request) *httptest.ResponseRecorder {
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(requestPathFormat, instanceID), bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeReport(t *testing.T, resp *httptest.ResponseRecorder) transfer.Report {
+	var report transfer.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	return report
+}
@@ -0,0 +1,431 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/residency"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+const (
+	CheckLastOperation  = "lastOperation"
+	CheckQuota          = "quota"
+	CheckHAPRule        = "hapRule"
+	CheckPlanUniqueness = "planUniqueness"
+)
+
+var (
+	ErrInvalidRequest   = errors.New("invalid transfer request")
+	ErrPreflightFailed  = errors.New("transfer pre-flight checks failed")
+	errTransferNotValid = "target global account ID must be set and differ from the current global account ID"
+)
+
+type Config struct {
+	Enabled bool `envconfig:"default=false"`
+}
+
+// Labeler updates the global account label on the custom resources of the runtime.
+type Labeler interface {
+	UpdateLabels(id, newGlobalAccountId string) error
+}
+
+// GlobalAccountLimiter is implemented by quota clients which enforce the limits of instances per plan in global accounts.
+type GlobalAccountLimiter interface {
+	CheckGlobalAccountLimit(globalAccountID, planID string) error
+}
+
+type Request struct {
+	TargetGlobalAccountID string `json:"targetGlobalAccountID"`
+	DryRun                bool   `json:"dryRun"`
+}
+
+type Check struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// Subscription describes how the transfer affects the credentials binding used by the instance.
+type Subscription struct {
+	CredentialsBinding string `json:"credentialsBinding,omitempty"`
+	Shared             bool   `json:"shared"`
+	TenantName         string `json:"tenantName,omitempty"`
+	Message            string `json:"message"`
+}
+
+type Report struct {
+	InstanceID            string       `json:"instanceID"`
+	OperationID           string       `json:"operationID,omitempty"`
+	SourceGlobalAccountID string       `json:"sourceGlobalAccountID"`
+	TargetGlobalAccountID string       `json:"targetGlobalAccountID"`
+	DryRun                bool         `json:"dryRun"`
+	Checks                []Check      `json:"checks"`
+	Subscription          Subscription `json:"subscription"`
+	Updated               []string     `json:"updated,omitempty"`
+	Error                 string       `json:"error,omitempty"`
+}
+
+func (r Report) passed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Service transfers instances between global accounts. In contrast to the subaccount movement triggered by ERS with an update request,
+// the transfer is validated before any change is made. It is recorded as an operation of the transfer type, which is not returned as the last operation
+// of the instance because the runtime does not change, and as an action.
+type Service struct {
+	instances         storage.Instances
+	instancesArchived storage.InstancesArchived
+	operations        storage.Operations
+	actions           storage.Actions
+	brokerConfig      broker.Config
+	quotaClient       broker.QuotaClient
+	quotaWhitelist    whitelist.Set
+	freemiumWhitelist whitelist.Set
+	rulesService      *rules.RulesService
+	labeler           Labeler
+	residencyPolicies *residency.Policies
+	log               *slog.Logger
+}
+
+func NewService(db storage.BrokerStorage, brokerConfig broker.Config, quotaClient broker.QuotaClient, quotaWhitelist, freemiumWhitelist whitelist.Set,
+	rulesService *rules.RulesService, labeler Labeler, log *slog.Logger) *Service {
+	return &Service{
+		instances:         db.Instances(),
+		instancesArchived: db.InstancesArchived(),
+		operations:        db.Operations(),
+		actions:           db.Actions(),
+		brokerConfig:      brokerConfig,
+		quotaClient:       quotaClient,
+		quotaWhitelist:    quotaWhitelist,
+		freemiumWhitelist: freemiumWhitelist,
+		rulesService:      rulesService,
+		labeler:           labeler,
+		residencyPolicies: residency.DefaultPolicies(),
+		log:               log,
+	}
+}
+
+// WithResidencyPolicies replaces the default residency policies the HAP rule matched for the target global account is checked against.
+func (s *Service) WithResidencyPolicies(policies *residency.Policies) *Service {
+	s.residencyPolicies = policies
+	return s
+}
+
+// Transfer runs the pre-flight checks and, if all of them pass and the request is not a dry run, moves the instance to the target global account.
+func (s *Service) Transfer(ctx context.Context, instanceID string, request Request) (Report, error) {
+	instance, err := s.instances.GetByID(instanceID)
+	if err != nil {
+		return Report{}, err
+	}
+	if request.TargetGlobalAccountID == "" || request.TargetGlobalAccountID == instance.GlobalAccountID {
+		return Report{}, fmt.Errorf("%w: %s", ErrInvalidRequest, errTransferNotValid)
+	}
+	log := s.log.With("instanceID", instanceID, "targetGlobalAccountID", request.TargetGlobalAccountID)
+
+	report := Report{
+		InstanceID:            instanceID,
+		SourceGlobalAccountID: instance.GlobalAccountID,
+		TargetGlobalAccountID: request.TargetGlobalAccountID,
+		DryRun:                request.DryRun,
+	}
+	provisioning, err := s.operations.GetProvisioningOperationByInstanceID(instanceID)
+	if err != nil {
+		return report, fmt.Errorf("while getting provisioning operation: %w", err)
+	}
+	report.Checks = []Check{
+		s.checkLastOperation(instance),
+		s.checkQuota(ctx, instance, request.TargetGlobalAccountID),
+		s.checkHAPRule(provisioning),
+		s.checkPlanUniqueness(instance, request.TargetGlobalAccountID),
+	}
+	report.Subscription = s.subscription(instance, provisioning)
+
+	if !report.passed() {
+		log.Info("transfer rejected by pre-flight checks")
+		return report, ErrPreflightFailed
+	}
+	if request.DryRun {
+		log.Info("[dry-run] instance would be transferred")
+		return report, nil
+	}
+
+	return s.transfer(instance, report, log)
+}
+
+func (s *Service) transfer(instance *internal.Instance, report Report, log *slog.Logger) (Report, error) {
+	operation := newTransferOperation(instance, report.TargetGlobalAccountID)
+	if err := s.operations.InsertOperation(operation); err != nil {
+		return report, fmt.Errorf("while inserting transfer operation: %w", err)
+	}
+	report.OperationID = operation.ID
+	log = log.With("operationID", operation.ID)
+
+	// the labels are updated first, so the instance is not moved if the custom resources cannot be labeled
+	if instance.RuntimeID != "" {
+		if err := s.labeler.UpdateLabels(instance.RuntimeID, report.TargetGlobalAccountID); err != nil {
+			return s.fail(operation, report, fmt.Errorf("while updating labels on custom resources: %w", err), log)
+		}
+		report.Updated = append(report.Updated, "Kyma, GardenerCluster, and Runtime CRs: global account ID label updated")
+	}
+
+	updated := *instance
+	if updated.SubscriptionGlobalAccountID == "" {
+		// the credentials binding stays claimed by the global account which provisioned the instance
+		updated.SubscriptionGlobalAccountID = instance.GlobalAccountID
+	}
+	updated.GlobalAccountID = report.TargetGlobalAccountID
+	updated.Parameters.ErsContext.GlobalAccountID = report.TargetGlobalAccountID
+	if _, err := s.instances.Update(updated); err != nil {
+		err = fmt.Errorf("while updating instance: %w", err)
+		if instance.RuntimeID != "" {
+			if revertErr := s.labeler.UpdateLabels(instance.RuntimeID, report.SourceGlobalAccountID); revertErr != nil {
+				err = fmt.Errorf("%w, while restoring labels on custom resources: %s", err, revertErr)
+			} else {
+				report.Updated = append(report.Updated, "Kyma, GardenerCluster, and Runtime CRs: global account ID label restored")
+			}
+		}
+		return s.fail(operation, report, err, log)
+	}
+	report.Updated = append(report.Updated, fmt.Sprintf("instance: global account ID set to %s, subscription global account ID %s", updated.GlobalAccountID, updated.SubscriptionGlobalAccountID))
+
+	message := fmt.Sprintf("Instance transferred from Global Account %s to %s. Updated: %s", report.SourceGlobalAccountID, report.TargetGlobalAccountID, strings.Join(report.Updated, "; "))
+	s.insertAction(instance.InstanceID, message, report.TargetGlobalAccountID, report, log)
+	events.Infof(instance.InstanceID, operation.ID, "Instance transferred from Global Account %s to %s", report.SourceGlobalAccountID, report.TargetGlobalAccountID)
+
+	operation.State = domain.Succeeded
+	operation.Description = message
+	if _, err := s.operations.UpdateOperation(operation); err != nil {
+		log.Error(fmt.Sprintf("while updating transfer operation: %s", err))
+	}
+	log.Info("instance transferred")
+	return report, nil
+}
+
+// fail records the failed transfer as an action and marks the operation as failed. The instance stays in the source global account.
+func (s *Service) fail(operation internal.Operation, report Report, err error, log *slog.Logger) (Report, error) {
+	log.Error(fmt.Sprintf("transfer failed: %s", err))
+	report.Error = err.Error()
+	events.Errorf(operation.InstanceID, operation.ID, err, "Transfer to global account %s failed", report.TargetGlobalAccountID)
+
+	message := fmt.Sprintf("Transfer from Global Account %s to %s failed: %s", report.SourceGlobalAccountID, report.TargetGlobalAccountID, err)
+	if len(report.Updated) > 0 {
+		message = fmt.Sprintf("%s. Updated: %s", message, strings.Join(report.Updated, "; "))
+	}
+	s.insertAction(operation.InstanceID, message, report.SourceGlobalAccountID, report, log)
+
+	operation.State = domain.Failed
+	operation.Description = message
+	if _, updateErr := s.operations.UpdateOperation(operation); updateErr != nil {
+		log.Error(fmt.Sprintf("while updating transfer operation: %s", updateErr))
+	}
+	return report, err
+}
+
+// insertAction records the transfer, the new value is the global account the instance belongs to after the transfer attempt.
+func (s *Service) insertAction(instanceID, message, globalAccountID string, report Report, log *slog.Logger) {
+	if err := s.actions.InsertAction(pkg.InstanceTransferActionType, instanceID, message, report.SourceGlobalAccountID, globalAccountID); err != nil {
+		log.Error(fmt.Sprintf("while inserting action %q for instance ID %s: %v", pkg.InstanceTransferActionType, instanceID, err))
+	}
+}
+
+func (s *Service) checkLastOperation(instance *internal.Instance) Check {
+	check := Check{Name: CheckLastOperation}
+	lastOp, err := s.operations.GetLastOperationWithAllStates(instance.InstanceID)
+	switch {
+	case err != nil:
+		check.Message = fmt.Sprintf("unable to get the last operation: %s", err)
+	case lastOp.Type == internal.OperationTypeDeprovision:
+		check.Message = fmt.Sprintf("the instance is deprovisioned or suspended, last operation %s", lastOp.ID)
+	case lastOp.State != domain.Succeeded:
+		check.Message = fmt.Sprintf("the last operation %s (%s) is %s, it must be succeeded", lastOp.ID, lastOp.Type, lastOp.State)
+	default:
+		check.Passed = true
+		check.Message = fmt.Sprintf("the last operation %s (%s) succeeded", lastOp.ID, lastOp.Type)
+	}
+	return check
+}
+
+// checkQuota checks if the subaccount has enough quota assigned for the plan and if the target global account limit of the plan is not exceeded.
+// The transferred instance is already counted in the used quota of the subaccount, but not in the target global account.
+func (s *Service) checkQuota(ctx context.Context, instance *internal.Instance, targetGlobalAccountID string) Check {
+	check := Check{Name: CheckQuota, Passed: true}
+	if !s.brokerConfig.CheckQuotaLimit {
+		check.Message = "quota limit check is disabled"
+		return check
+	}
+	if whitelist.IsWhitelisted(instance.SubAccountID, s.quotaWhitelist) {
+		check.Message = "the subaccount has unlimited quota"
+		return check
+	}
+
+	_, _, usedQuota, err := s.instances.List(dbmodel.InstanceFilter{
+		SubAccountIDs: []string{instance.SubAccountID},
+		PlanIDs:       []string{instance.ServicePlanID},
+	})
+	if err != nil {
+		check.Passed = false
+		check.Message = fmt.Sprintf("unable to list instances of the subaccount: %s", err)
+		return check
+	}
+	planName := broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(instance.ServicePlanID))
+	assignedQuota, err := s.quotaClient.GetQuota(ctx, instance.SubAccountID, planName)
+	if err != nil {
+		check.Passed = false
+		check.Message = fmt.Sprintf("unable to get the assigned quota for plan %s: %s", planName, err)
+		return check
+	}
+	check.Message = fmt.Sprintf("used quota for plan %s: %d, assigned quota: %d", planName, usedQuota, assignedQuota)
+	if usedQuota > assignedQuota {
+		check.Passed = false
+		return check
+	}
+
+	if limiter, limited := s.quotaClient.(GlobalAccountLimiter); limited {
+		if err := limiter.CheckGlobalAccountLimit(targetGlobalAccountID, instance.ServicePlanID); err != nil {
+			check.Passed = false
+			check.Message = fmt.Sprintf("target global account %s: %s", targetGlobalAccountID, err)
+			return check
+		}
+		check.Message = fmt.Sprintf("%s, the limit of the target global account %s is not exceeded", check.Message, targetGlobalAccountID)
+	}
+	return check
+}
+
+// checkHAPRule checks if the instance still matches a HAP rule which satisfies the residency policy.
+// The rules do not depend on the global account, so a failure means that the rules changed after the instance was provisioned.
+func (s *Service) checkHAPRule(provisioning *internal.ProvisioningOperation) Check {
+	check := Check{Name: CheckHAPRule, Passed: true}
+	if s.rulesService == nil {
+		check.Message = "HAP rules are not configured"
+		return check
+	}
+	if provisioning.ProviderValues == nil {
+		check.Message = "provider values are not recorded for the instance, the check is skipped"
+		return check
+	}
+	attr := provisioningAttributes(provisioning)
+	result, found := s.rulesService.MatchProvisioningAttributesWithValidRuleset(attr)
+	if !found {
+		check.Passed = false
+		check.Message = fmt.Sprintf("no matching rule for provisioning attributes %q", attr)
+		return check
+	}
+	if err := s.residencyPolicies.CheckHAPEUAccess(attr.PlatformRegion, attr.Plan, result.IsEUAccess(), result.Rule()); err != nil {
+		check.Passed = false
+		check.Message = err.Error()
+		return check
+	}
+	check.Message = fmt.Sprintf("matched rule: %s", result.Rule())
+	return check
+}
+
+// checkPlanUniqueness applies the constraints of the trial and free plans to the target global account.
+func (s *Service) checkPlanUniqueness(instance *internal.Instance, targetGlobalAccountID string) Check {
+	check := Check{Name: CheckPlanUniqueness, Passed: true, Message: "the plan is not limited per global account"}
+	switch {
+	case broker.IsTrialPlan(instance.ServicePlanID) && s.brokerConfig.OnlySingleTrialPerGA:
+		count, err := s.instances.GetNumberOfInstancesForGlobalAccountID(targetGlobalAccountID)
+		switch {
+		case err != nil:
+			check.Passed = false
+			check.Message = fmt.Sprintf("unable to check trial instances in the target global account: %s", err)
+		case count > 0:
+			check.Passed = false
+			check.Message = "the target global account already has a trial instance, but there is only one allowed"
+		default:
+			check.Message = "the target global account has no trial instance"
+		}
+	case broker.IsFreemiumPlan(instance.ServicePlanID) && s.brokerConfig.OnlyOneFreePerGA && whitelist.IsNotWhitelisted(targetGlobalAccountID, s.freemiumWhitelist):
+		archived := 0
+		for _, planID := range broker.FreemiumPlanIDs() {
+			count, err := s.instancesArchived.TotalNumberOfInstancesArchivedForGlobalAccountID(targetGlobalAccountID, planID)
+			if err != nil {
+				check.Passed = false
+				check.Message = fmt.Sprintf("unable to check free instances in the target global account: %s", err)
+				return check
+			}
+			archived += count
+		}
+		_, _, count, err := s.instances.List(dbmodel.InstanceFilter{
+			GlobalAccountIDs: []string{targetGlobalAccountID},
+			PlanIDs:          broker.FreemiumPlanIDs(),
+		})
+		switch {
+		case err != nil:
+			check.Passed = false
+			check.Message = fmt.Sprintf("unable to check free instances in the target global account: %s", err)
+		case archived+count > 0:
+			check.Passed = false
+			check.Message = "the target global account has already used the available free service plan quota"
+		default:
+			check.Message = "the target global account has not used the free service plan"
+		}
+	}
+	return check
+}
+
+func (s *Service) subscription(instance *internal.Instance, provisioning *internal.ProvisioningOperation) Subscription {
+	subscription := Subscription{CredentialsBinding: instance.SubscriptionSecretName}
+	if instance.SubscriptionSecretName == "" {
+		subscription.Message = "the instance does not use a credentials binding"
+		return subscription
+	}
+	if s.rulesService != nil && provisioning.ProviderValues != nil {
+		if result, found := s.rulesService.MatchProvisioningAttributesWithValidRuleset(provisioningAttributes(provisioning)); found && result.IsShared() {
+			subscription.Shared = true
+			subscription.Message = "the shared credentials binding is not claimed by any global account and does not change"
+			return subscription
+		}
+	}
+	subscription.TenantName = instance.GetSubscriptionGlobalAccoundID()
+	subscription.Message = fmt.Sprintf("the credentials binding stays claimed by global account %s, new instances in the target global account use credentials bindings claimed by the target global account",
+		subscription.TenantName)
+	return subscription
+}
+
+func provisioningAttributes(provisioning *internal.ProvisioningOperation) *rules.ProvisioningAttributes {
+	return &rules.ProvisioningAttributes{
+		Plan:              broker.AvailablePlans.GetPlanNameOrEmpty(broker.PlanIDType(provisioning.ProvisioningParameters.PlanID)),
+		PlatformRegion:    provisioning.ProvisioningParameters.PlatformRegion,
+		HyperscalerRegion: provisioning.ProviderValues.Region,
+		Hyperscaler:       provisioning.ProviderValues.ProviderType,
+	}
+}
+
+func newTransferOperation(instance *internal.Instance, targetGlobalAccountID string) internal.Operation {
+	parameters := instance.Parameters
+	parameters.ErsContext.GlobalAccountID = targetGlobalAccountID
+	return internal.Operation{
+		ID:                     uuid.New().String(),
+		Version:                0,
+		Description:            fmt.Sprintf("Transfer to global account %s started", targetGlobalAccountID),
+		InstanceID:             instance.InstanceID,
+		State:                  domain.InProgress,
+		CreatedAt:              time.Now(),
+		UpdatedAt:              time.Now(),
+		Type:                   internal.OperationTypeTransfer,
+		InstanceDetails:        instance.InstanceDetails,
+		ProvisioningParameters: parameters,
+		FinishedStages:         make([]string, 0),
+	}
+}
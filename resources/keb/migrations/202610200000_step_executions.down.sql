@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE step_executions;
+
+COMMIT;
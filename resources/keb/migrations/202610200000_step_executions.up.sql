@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS step_executions (
+    id                  varchar(255) NOT NULL PRIMARY KEY,
+    operation_id        varchar(255) NOT NULL,
+    instance_id         varchar(255) NOT NULL,
+    stage               varchar(255) NOT NULL,
+    step                varchar(255) NOT NULL,
+    attempt             integer NOT NULL,
+    started_at          timestamp with time zone NOT NULL,
+    finished_at         timestamp with time zone NOT NULL,
+    result              varchar(32) NOT NULL,
+    retry_after_seconds integer NOT NULL DEFAULT 0,
+    error_reason        varchar(255) NOT NULL DEFAULT '',
+    error_component     varchar(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS step_executions_operation_id ON step_executions USING btree (operation_id);
+CREATE INDEX IF NOT EXISTS step_executions_started_at ON step_executions USING btree (started_at);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS instance_tags (
+    instance_id varchar(255) NOT NULL,
+    key         varchar(317) NOT NULL,
+    value       varchar(63) NOT NULL DEFAULT '',
+    created_at  timestamp with time zone NOT NULL,
+    updated_at  timestamp with time zone NOT NULL,
+    PRIMARY KEY (instance_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS instance_tags_key_value ON instance_tags USING btree (key, value);
+
+COMMIT;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS instances_by_created_at_instance_id;
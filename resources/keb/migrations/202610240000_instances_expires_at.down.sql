@@ -0,0 +1,2 @@
+ALTER TABLE instances
+    DROP COLUMN IF EXISTS expires_at;
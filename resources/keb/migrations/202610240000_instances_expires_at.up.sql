@@ -0,0 +1,2 @@
+ALTER TABLE instances
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
@@ -0,0 +1,40 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    sequence            bigint NOT NULL PRIMARY KEY,
+    id                  varchar(255) NOT NULL UNIQUE,
+    created_at          timestamp with time zone NOT NULL,
+    action              varchar(64) NOT NULL,
+    method              varchar(16) NOT NULL,
+    path                text NOT NULL,
+    request_id          varchar(255) NOT NULL DEFAULT '',
+    principal_platform  varchar(255) NOT NULL DEFAULT '',
+    principal_identity  text NOT NULL DEFAULT '',
+    principal_subject   varchar(255) NOT NULL DEFAULT '',
+    principal_client_id varchar(255) NOT NULL DEFAULT '',
+    principal_verified  boolean NOT NULL DEFAULT false,
+    user_agent          text NOT NULL DEFAULT '',
+    instance_id         varchar(255) NOT NULL DEFAULT '',
+    parameters          text NOT NULL DEFAULT '',
+    status_code         integer NOT NULL,
+    outcome             varchar(32) NOT NULL,
+    previous_hash       varchar(64) NOT NULL,
+    hash                varchar(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_instance_id ON audit_log USING btree (instance_id);
+CREATE INDEX IF NOT EXISTS audit_log_created_at ON audit_log USING btree (created_at);
+
+-- the audit log is append-only, the entries cannot be changed or removed
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+COMMIT;
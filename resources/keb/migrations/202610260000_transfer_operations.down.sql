@@ -0,0 +1,6 @@
+BEGIN;
+
+DELETE FROM operations WHERE type = 'transfer';
+COMMENT ON COLUMN operations.type IS NULL;
+
+COMMIT;
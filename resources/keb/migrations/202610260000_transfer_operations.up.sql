@@ -0,0 +1 @@
+COMMENT ON COLUMN operations.type IS 'provision, deprovision, update, upgradeKyma, upgradeCluster, or transfer';
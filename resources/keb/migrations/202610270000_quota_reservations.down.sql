@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE quota_reservations;
+
+COMMIT;
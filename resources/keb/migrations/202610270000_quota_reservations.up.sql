@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS quota_reservations (
+    instance_id       varchar(255) NOT NULL PRIMARY KEY,
+    global_account_id varchar(255) NOT NULL,
+    sub_account_id    varchar(255) NOT NULL,
+    plan_id           varchar(255) NOT NULL,
+    created_at        timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS quota_reservations_plan_id_created_at ON quota_reservations USING btree (plan_id, created_at);
+
+COMMIT;
@@ -18,6 +18,7 @@ PROTOCOL=docker://
 IMAGE_NAMES=(
   kyma-environment-broker
   kyma-environment-deprovision-retrigger-job
+  kyma-environment-export-job
   kyma-environment-runtime-reconciler
   kyma-environment-subaccount-cleanup-job
   kyma-environment-expirator-job
@@ -23,6 +23,7 @@ rc-tag: ${TAG}
 bdba:
   - europe-docker.pkg.dev/kyma-project/prod/kyma-environment-broker:${TAG}
   - europe-docker.pkg.dev/kyma-project/prod/kyma-environment-deprovision-retrigger-job:${TAG}
+  - europe-docker.pkg.dev/kyma-project/prod/kyma-environment-export-job:${TAG}
   - europe-docker.pkg.dev/kyma-project/prod/kyma-environment-runtime-reconciler:${TAG}
   - europe-docker.pkg.dev/kyma-project/prod/kyma-environment-expirator-job:${TAG}
   - europe-docker.pkg.dev/kyma-project/prod/kyma-environment-subaccount-cleanup-job:${TAG}
@@ -19,6 +19,7 @@ declare -a IMAGES=(
     "kyma-environment-broker:Dockerfile.keb:VERSION=${VERSION}"
     "kyma-environments-cleanup-job:Dockerfile.job:BIN=environmentscleanup"
     "kyma-environment-deprovision-retrigger-job:Dockerfile.job:BIN=deprovisionretrigger"
+    "kyma-environment-export-job:Dockerfile.job:BIN=keb-export"
     "kyma-environment-expirator-job:Dockerfile.job:BIN=expirator"
     "kyma-environment-runtime-reconciler:Dockerfile.runtimereconciler:BIN=runtime-reconciler"
     "kyma-environment-subaccount-cleanup-job:Dockerfile.job:BIN=accountcleanup"